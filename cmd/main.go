@@ -1,14 +1,92 @@
 package main
 
 import (
+	"crypto/tls"
 	"ecom_test/internal/application"
 	"ecom_test/internal/config"
+	"ecom_test/pkg/tlsx"
+	"net/netip"
+	"time"
 )
 
 func main() { //понятно что конфиг надо задавать в env, но т.к. сторонние либы нельзя было использовать сделал просто внутри для упрощения надеюсь не сильно плохо 
 	cfg := config.Config{
 		Addr:            ":8080",
-		ShutdownTimeout: 10,
+		ShutdownTimeout: 10 * time.Second,
+		Health: config.Health{
+			CheckTimeout: time.Second,
+		},
+		TLS: config.TLS{
+			Enabled:        false,
+			CertFile:       "/etc/todo/tls/tls.crt",
+			KeyFile:        "/etc/todo/tls/tls.key",
+			MinVersion:     tls.VersionTLS12,
+			CipherPolicy:   tlsx.CipherPolicyIntermediate,
+			ReloadInterval: 30 * time.Second,
+		},
+		Metrics: config.Metrics{
+			Enabled: true,
+			Path:    "/metrics",
+		},
+		Tracing: config.Tracing{
+			Enabled:     false,
+			ServiceName: "todo-api",
+			Exporter:    config.TracingExporterStdout,
+		},
+		Admin: config.Admin{
+			Addr: ":9090",
+		},
+		Recovery: config.Recovery{
+			CrashDir:   "/tmp/todo-crashes",
+			MaxReports: 50,
+		},
+		RateLimit: config.RateLimit{
+			Enabled: true,
+			Default: config.RateLimitRule{Rate: 20, Burst: 40},
+			Routes: map[string]config.RateLimitRule{
+				"POST /todos": {Rate: 2, Burst: 10},
+			},
+			KeyBy:          config.RateLimitKeyIP,
+			TrustedProxies: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+			IdleTTL:        10 * time.Minute,
+			MaxClients:     100_000,
+		},
+		LoadShedding: config.LoadShedding{
+			Enabled:             true,
+			InitialLimit:        50,
+			MinLimit:            5,
+			MaxLimit:            500,
+			TargetLatency:       200 * time.Millisecond,
+			MaxQueue:            100,
+			QueueTimeout:        50 * time.Millisecond,
+			QueueDepthThreshold: 80,
+		},
+		Auth: config.Auth{
+			Enabled: true,
+			JWT: config.JWT{
+				Enabled:            false,
+				JWKSURL:            "https://sso.internal/.well-known/jwks.json",
+				Issuer:             "https://sso.internal",
+				Audience:           []string{"todo-api"},
+				ClockSkew:          30 * time.Second,
+				CacheTTL:           time.Hour,
+				MinRefreshInterval: 30 * time.Second,
+			},
+		},
+		Tenancy: config.Tenancy{
+			Enabled:       false,
+			Sources:       []string{config.TenantSourceClaim, config.TenantSourceHeader, config.TenantSourceSubdomain},
+			Header:        "X-Tenant-ID",
+			BaseDomain:    "todo.internal",
+			Claim:         "tenant",
+			DefaultTenant: "default",
+			DataDir:       "/tmp/todo-data",
+			DefaultQuota: config.TenantQuota{
+				MaxTasks:     10000,
+				RequestRate:  100,
+				RequestBurst: 200,
+			},
+		},
 	}
 	application.Run(cfg)
 }
@@ -0,0 +1,110 @@
+package application
+
+import (
+	"cmp"
+	"context"
+	"ecom_test/internal/config"
+	"ecom_test/internal/server"
+	"ecom_test/pkg/auth"
+	"ecom_test/pkg/jwt"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+func newKeyStore(ctx context.Context, cfg config.Auth) (*auth.KeyStore, error) {
+	store := auth.NewKeyStore()
+	if !cfg.Enabled {
+		return store, nil
+	}
+
+	for _, k := range cfg.BootstrapKeys {
+		if _, err := store.Import(k.ID, k.Hash, k.Name, k.Subject, k.Tenant, k.Scopes); err != nil {
+			return nil, fmt.Errorf("store.Import: %w", err)
+		}
+	}
+
+	if len(cfg.BootstrapKeys) == 0 {
+		// без ключей в конфиге к API нельзя было бы обратиться вовсе, выдаём одноразовый admin-ключ;
+		// он не привязан к арендатору, поэтому с мультиарендностью годится только для /admin/
+		plaintext, key, err := store.Mint("bootstrap", "admin", "", []string{server.ScopeAdmin, server.ScopeTasksRead, server.ScopeTasksWrite})
+		if err != nil {
+			return nil, fmt.Errorf("store.Mint: %w", err)
+		}
+		if err := writeBootstrapKey(cfg.BootstrapKeyFile, key.ID, plaintext); err != nil {
+			return nil, err
+		}
+		logger(ctx).Warn("no bootstrap api keys configured, minted a temporary admin key; it is shown only once",
+			slog.String("key_id", key.ID),
+			slog.String("key_file", cmp.Or(cfg.BootstrapKeyFile, "stderr")),
+		)
+	}
+	return store, nil
+}
+
+// writeBootstrapKey открытый текст ключа только в файл с правами 0600 или в stderr, мимо журнала.
+func writeBootstrapKey(path, id, plaintext string) error {
+	line := fmt.Sprintf("bootstrap admin api key %s: %s\n", id, plaintext)
+	if path == "" {
+		_, err := io.WriteString(os.Stderr, line)
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("os.OpenFile: %w", err)
+	}
+	// у существующего файла OpenFile права не меняет
+	if err := f.Chmod(0o600); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("f.Chmod: %w", err)
+	}
+	if _, err := io.WriteString(f, line); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write bootstrap key: %w", err)
+	}
+	return f.Close()
+}
+
+// newJWTValidator возвращает nil, если приём JWT выключен.
+func newJWTValidator(ctx context.Context, cfg config.JWT) (*jwt.Validator, error) {
+	if !cfg.Enabled {
+		return nil, nil //nolint:nilnil
+	}
+
+	var keys *jwt.KeySet
+	switch {
+	case cfg.JWKSURL != "" || cfg.JWKSFile != "":
+		source := cfg.JWKSURL
+		if source == "" {
+			source = cfg.JWKSFile
+		}
+		keys = jwt.NewKeySet(source, jwt.KeySetOptions{
+			TTL:                cfg.CacheTTL,
+			MinRefreshInterval: cfg.MinRefreshInterval,
+		})
+		if err := keys.Refresh(ctx); err != nil {
+			// IdP может быть временно недоступен, ключи подтянутся при первом запросе
+			logger(ctx).Warn("initial jwks load failed", slog.String("source", source), slog.String("error", err.Error()))
+		}
+	case cfg.HMACSecret != "":
+		var err error
+		keys, err = jwt.StaticKeySet(jwt.JWKS{Keys: []jwt.JWK{{
+			Kty: "oct",
+			K:   base64.RawURLEncoding.EncodeToString([]byte(cfg.HMACSecret)),
+		}}})
+		if err != nil {
+			return nil, fmt.Errorf("jwt.StaticKeySet: %w", err)
+		}
+	default:
+		return nil, errors.New("jwt enabled but no JWKSURL, JWKSFile or HMACSecret configured")
+	}
+
+	return jwt.NewValidator(keys, jwt.ValidatorOptions{
+		Issuer:   cfg.Issuer,
+		Audience: cfg.Audience,
+		Leeway:   cfg.ClockSkew,
+	}), nil
+}
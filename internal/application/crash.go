@@ -0,0 +1,19 @@
+package application
+
+import (
+	"ecom_test/internal/config"
+	"ecom_test/pkg/crashreport"
+	"fmt"
+)
+
+func newCrashStore(cfg config.Recovery) (*crashreport.Store, error) {
+	if cfg.CrashDir == "" {
+		return nil, nil
+	}
+
+	store, err := crashreport.NewStore(cfg.CrashDir, cfg.MaxReports)
+	if err != nil {
+		return nil, fmt.Errorf("crashreport.NewStore: %w", err)
+	}
+	return store, nil
+}
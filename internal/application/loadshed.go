@@ -0,0 +1,41 @@
+package application
+
+import (
+	"ecom_test/internal/config"
+	"ecom_test/pkg/health"
+	"ecom_test/pkg/loadshed"
+	"ecom_test/pkg/metrics"
+)
+
+func newLoadShedder(cfg config.LoadShedding, healthRegistry *health.Registry, metricsRegistry *metrics.Registry) *loadshed.Limiter {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	limiter := loadshed.New(loadshed.Options{
+		InitialLimit:  cfg.InitialLimit,
+		MinLimit:      cfg.MinLimit,
+		MaxLimit:      cfg.MaxLimit,
+		TargetLatency: cfg.TargetLatency,
+		MaxQueue:      cfg.MaxQueue,
+		QueueTimeout:  cfg.QueueTimeout,
+	})
+
+	if cfg.QueueDepthThreshold > 0 {
+		healthRegistry.Register("queue_depth", health.Threshold(func() int {
+			return limiter.Stats().Queued
+		}, cfg.QueueDepthThreshold), 0)
+	}
+
+	metricsRegistry.NewGaugeFunc("concurrency_limit", "Current adaptive concurrency limit.", nil, func() float64 {
+		return float64(limiter.Stats().Limit)
+	})
+	metricsRegistry.NewGaugeFunc("concurrency_in_flight", "Requests holding a concurrency slot.", nil, func() float64 {
+		return float64(limiter.Stats().InFlight)
+	})
+	metricsRegistry.NewGaugeFunc("concurrency_queue_depth", "Requests waiting for a concurrency slot.", nil, func() float64 {
+		return float64(limiter.Stats().Queued)
+	})
+
+	return limiter
+}
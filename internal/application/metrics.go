@@ -0,0 +1,24 @@
+package application
+
+import "ecom_test/pkg/metrics"
+
+type repositoryStats interface {
+	Stats() (total, completed int)
+}
+
+func registerRepositoryMetrics(registry *metrics.Registry, repository repositoryStats) {
+	const help = "Number of tasks in the repository by state."
+
+	registry.NewGaugeFunc("todo_tasks", help, []metrics.Label{{Name: "state", Value: "open"}}, func() float64 {
+		total, completed := repository.Stats()
+		return float64(total - completed)
+	})
+	registry.NewGaugeFunc("todo_tasks", help, []metrics.Label{{Name: "state", Value: "completed"}}, func() float64 {
+		_, completed := repository.Stats()
+		return float64(completed)
+	})
+	registry.NewGaugeFunc("todo_tasks_count", "Total number of tasks in the repository.", nil, func() float64 {
+		total, _ := repository.Stats()
+		return float64(total)
+	})
+}
@@ -0,0 +1,33 @@
+package application
+
+import (
+	"ecom_test/internal/config"
+	"ecom_test/pkg/ratelimit"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// newLimiter проверяет правила до старта, чтобы ошибка в конфиге не превращалась в вечный 429.
+func newLimiter(cfg config.RateLimit) (*ratelimit.Limiter, error) {
+	if err := validateRule("default", cfg.Default); err != nil {
+		return nil, err
+	}
+	for _, pattern := range slices.Sorted(maps.Keys(cfg.Routes)) {
+		if err := validateRule(pattern, cfg.Routes[pattern]); err != nil {
+			return nil, err
+		}
+	}
+
+	return ratelimit.New(ratelimit.Options{
+		IdleTTL: cfg.IdleTTL,
+		MaxKeys: cfg.MaxClients,
+	}), nil
+}
+
+func validateRule(name string, rule config.RateLimitRule) error {
+	if err := (ratelimit.Limit{Rate: rule.Rate, Burst: rule.Burst}).Validate(); err != nil {
+		return fmt.Errorf("rate limit rule %q: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package application
+
+import (
+	"ecom_test/internal/config"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/domain/service"
+	"ecom_test/internal/infrastructure/persistance"
+	"fmt"
+)
+
+const defaultTenantID = "default"
+
+// newTenants арендатор по умолчанию существует всегда, с выключенной мультиарендностью он единственный.
+func newTenants(cfg config.Tenancy) (*persistance.TenantRouter, *service.TenantService, error) {
+	if cfg.DefaultTenant == "" {
+		cfg.DefaultTenant = defaultTenantID
+	}
+	quota := entity.TenantQuota{
+		MaxTasks:     cfg.DefaultQuota.MaxTasks,
+		RequestRate:  cfg.DefaultQuota.RequestRate,
+		RequestBurst: cfg.DefaultQuota.RequestBurst,
+	}
+
+	router, err := persistance.NewTenantRouter(cfg.DataDir, entity.Tenant{
+		ID:     cfg.DefaultTenant,
+		Name:   "Default",
+		Status: entity.TenantActive,
+		Quota:  quota,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("persistance.NewTenantRouter: %w", err)
+	}
+	return router, service.NewTenantService(router, cfg.DefaultTenant, quota), nil
+}
@@ -0,0 +1,31 @@
+package application
+
+import (
+	"context"
+	"ecom_test/internal/config"
+	"ecom_test/pkg/tlsx"
+	"fmt"
+)
+
+func newCertificates(ctx context.Context, cfg config.TLS) (*tlsx.Reloader, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	if cfg.DevSelfSigned {
+		logger(ctx).Warn("tls: using in-memory self-signed certificate, do not use in production")
+		reloader, err := tlsx.NewSelfSigned(cfg.DevHosts)
+		if err != nil {
+			return nil, fmt.Errorf("tlsx.NewSelfSigned: %w", err)
+		}
+		return reloader, nil
+	}
+
+	reloader, err := tlsx.NewReloader(cfg.CertFile, cfg.KeyFile, cfg.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("tlsx.NewReloader: %w", err)
+	}
+	go reloader.Watch(ctx, cfg.ReloadInterval)
+
+	return reloader, nil
+}
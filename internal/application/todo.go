@@ -4,12 +4,15 @@ import (
 	"context"
 	"ecom_test/internal/config"
 	"ecom_test/internal/domain/service"
-	"ecom_test/internal/infrastructure/persistance"
 	"ecom_test/internal/server"
 	"ecom_test/pkg/application/modules"
 	"ecom_test/pkg/contextx"
+	"ecom_test/pkg/health"
+	"ecom_test/pkg/metrics"
+	"ecom_test/pkg/tlsx"
 	"log"
 	"log/slog"
+	"net/http"
 	"os/signal"
 	"syscall"
 )
@@ -19,15 +22,115 @@ func Run(cfg config.Config) {
 	defer stop()
 	ctx = contextx.WithLogger(ctx, slog.Default())
 
-	repository := persistance.NewTaskRepository()
-	service := service.NewTaskService(repository)
-	server := server.NewServer(cfg, service)
+	repository, tenants, err := newTenants(cfg.Tenancy)
+	if err != nil {
+		log.Fatalf("Failed to open tenant storage: %v", err)
+	}
+	go repository.Run(ctx, cfg.Tenancy.SnapshotInterval)
+	projects := service.NewProjectService(repository)
+	tasks := service.NewTaskService(repository,
+		service.WithShares(repository),
+		service.WithProjects(repository),
+		service.WithBoards(repository),
+		service.WithSearch(repository),
+	)
+	boards := service.NewBoardService(repository, tasks)
+	views := service.NewViewService(repository, tasks)
+	feeds := service.NewFeedService(repository, tasks)
+
+	healthRegistry := health.NewRegistry(cfg.Health.CheckTimeout)
+	healthRegistry.Register("repository", health.Ping(repository), 0)
+	if cfg.Tenancy.DataDir != "" {
+		// без записи на диск снимки арендаторов молча перестанут сохраняться
+		healthRegistry.Register("data_dir", health.DirWritable(cfg.Tenancy.DataDir), 0)
+	}
+
+	metricsRegistry := metrics.NewRegistry()
+	metricsRegistry.Register(metrics.NewRuntimeCollector())
+	registerRepositoryMetrics(metricsRegistry, repository)
+
+	if cfg.TLS.Enabled {
+		cfg.TLS.ClientAuth, err = tlsx.ClientAuth(cfg.TLS.ClientAuth, cfg.TLS.ClientCAFile)
+		if err != nil {
+			log.Fatalf("Invalid tls client auth: %v", err)
+		}
+	}
+	certificates, err := newCertificates(ctx, cfg.TLS)
+	if err != nil {
+		log.Fatalf("Failed to load tls certificates: %v", err)
+	}
+
+	tracer, err := newTracer(cfg.Tracing)
+	if err != nil {
+		log.Fatalf("Failed to create tracer: %v", err)
+	}
+
+	crashes, err := newCrashStore(cfg.Recovery)
+	if err != nil {
+		log.Fatalf("Failed to open crash report directory: %v", err)
+	}
+
+	limiter, err := newLimiter(cfg.RateLimit)
+	if err != nil {
+		log.Fatalf("Invalid rate limit config: %v", err)
+	}
+	go limiter.Run(ctx, 0)
+	metricsRegistry.NewGaugeFunc("ratelimit_buckets", "Number of active rate limiter buckets.", nil, func() float64 {
+		return float64(limiter.Len())
+	})
 
-	httpModule := modules.HTTPServer{ShutdownTimeout: cfg.ShutdownTimeout}
+	shedder := newLoadShedder(cfg.LoadShedding, healthRegistry, metricsRegistry)
+
+	keys, err := newKeyStore(ctx, cfg.Auth)
+	if err != nil {
+		log.Fatalf("Failed to load api keys: %v", err)
+	}
+	validator, err := newJWTValidator(ctx, cfg.Auth.JWT)
+	if err != nil {
+		log.Fatalf("Failed to configure jwt: %v", err)
+	}
+
+	serverOptions := []server.Option{
+		server.WithHealth(healthRegistry),
+		server.WithCertificates(certificates),
+		server.WithMetrics(metricsRegistry),
+		server.WithTracer(tracer),
+		server.WithCrashReports(crashes),
+		server.WithRateLimiter(limiter),
+		server.WithLoadShedder(shedder),
+		server.WithKeyStore(keys),
+		server.WithJWT(validator),
+		server.WithTenants(tenants),
+		server.WithProjects(projects),
+		server.WithBoards(boards),
+		server.WithViews(views),
+		server.WithFeeds(feeds),
+	}
+	servers := []*http.Server{server.NewServer(cfg, tasks, serverOptions...)}
+	if admin := server.NewAdminServer(cfg, serverOptions...); admin != nil {
+		servers = append(servers, admin)
+	}
+
+	httpModule := modules.HTTPServer{
+		ShutdownTimeout: cfg.ShutdownTimeout,
+		OnShutdown:      healthRegistry.Shutdown,
+	}
 
 	logger(ctx).Info("start http server")
-	if err := httpModule.Run(ctx, server); err != nil {
+	if err := httpModule.RunAll(ctx, servers...); err != nil {
 		log.Fatalf("Server stopped with error: %v", err)
 	}
+
+	if err := repository.Flush(); err != nil {
+		logger(ctx).Error("failed to flush tenant storage", slog.String("error", err.Error()))
+	}
+
+	if tracer != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cfg.ShutdownTimeout)
+		defer cancel()
+		if err := tracer.Shutdown(shutdownCtx); err != nil {
+			logger(ctx).Error("tracer shutdown failed", slog.String("error", err.Error()))
+		}
+	}
 	logger(ctx).Info("application stopped successfully")
 }
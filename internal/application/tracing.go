@@ -0,0 +1,28 @@
+package application
+
+import (
+	"ecom_test/internal/config"
+	"ecom_test/pkg/tracing"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+func newTracer(cfg config.Tracing) (*tracing.Tracer, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	var exporter tracing.Exporter
+	switch cfg.Exporter {
+	case config.TracingExporterStdout, "":
+		exporter = tracing.NewStdoutExporter(os.Stdout)
+	case config.TracingExporterOTLP:
+		exporter = tracing.NewOTLPExporter(cfg.OTLPEndpoint, cfg.ServiceName, nil, &http.Client{Timeout: 10 * time.Second})
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+
+	return tracing.NewTracer(exporter, tracing.Options{}), nil
+}
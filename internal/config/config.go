@@ -1,8 +1,187 @@
 package config
 
-import "time"
+import (
+	"crypto/tls"
+	"net/netip"
+	"time"
+)
 
 type Config struct {
 	Addr            string
 	ShutdownTimeout time.Duration
+	Health          Health
+	TLS             TLS
+	Metrics         Metrics
+	Tracing         Tracing
+	Admin           Admin
+	Recovery        Recovery
+	RateLimit       RateLimit
+	LoadShedding    LoadShedding
+	Auth            Auth
+	Tenancy         Tenancy
+}
+
+type Health struct {
+	CheckTimeout time.Duration
+}
+
+type TLS struct {
+	Enabled  bool
+	CertFile string
+	KeyFile  string
+	// MinVersion одна из констант tls.VersionTLS*, по умолчанию TLS 1.2.
+	MinVersion uint16
+	// CipherPolicy одна из политик tlsx.CipherPolicy*.
+	CipherPolicy string
+	// ClientCAFile бандл CA для проверки клиентских сертификатов, пустой означает без mTLS.
+	ClientCAFile string
+	// ClientAuth по умолчанию tls.RequireAndVerifyClientCert если задан ClientCAFile; режимы
+	// с проверкой сертификата без ClientCAFile считаются ошибкой конфигурации.
+	ClientAuth tls.ClientAuthType
+	// ReloadInterval период проверки ротации файлов сертификата.
+	ReloadInterval time.Duration
+	// DevSelfSigned генерирует самоподписанный сертификат в памяти вместо чтения файлов.
+	DevSelfSigned bool
+	DevHosts      []string
+}
+
+type Metrics struct {
+	Enabled bool
+	Path    string
+}
+
+type Admin struct {
+	// Addr адрес отдельного служебного листенера, пустой означает что метрики
+	// публикуются на основном, а отчёты о падениях недоступны по HTTP.
+	Addr string
+}
+
+type Recovery struct {
+	// CrashDir каталог для отчётов о паниках, пустой отключает запись на диск.
+	CrashDir   string
+	MaxReports int
+}
+
+const (
+	TracingExporterStdout = "stdout"
+	TracingExporterOTLP   = "otlp"
+)
+
+type Tracing struct {
+	Enabled     bool
+	ServiceName string
+	// Exporter одно из TracingExporter*.
+	Exporter string
+	// OTLPEndpoint полный URL коллектора, например http://collector:4318/v1/traces.
+	OTLPEndpoint string
+}
+
+const (
+	RateLimitKeyIP = "ip"
+	// RateLimitKeyAPIKey лимит по проверенному ключу или JWT-субъекту, анонимные запросы по адресу.
+	RateLimitKeyAPIKey = "api_key"
+)
+
+type RateLimit struct {
+	Enabled bool
+	// Default общий лимит клиента на все маршруты без собственного правила.
+	Default RateLimitRule
+	// Routes лимиты по маршруту: "POST /todos" или "/todos/{id}", нулевой Rate снимает ограничение.
+	Routes map[string]RateLimitRule
+	// KeyBy одно из RateLimitKey*.
+	KeyBy          string
+	TrustedProxies []netip.Prefix
+	IdleTTL        time.Duration
+	MaxClients     int
+}
+
+type RateLimitRule struct {
+	// Rate запросов в секунду
+	Rate float64
+	// Burst ёмкость корзины, при Rate > 0 не меньше 1, иначе сервер не стартует.
+	Burst int
+}
+
+type LoadShedding struct {
+	Enabled       bool
+	InitialLimit  int
+	MinLimit      int
+	MaxLimit      int
+	TargetLatency time.Duration
+	MaxQueue      int
+	QueueTimeout  time.Duration
+	// QueueDepthThreshold глубина очереди, при которой readiness начинает падать.
+	QueueDepthThreshold int
+}
+
+type Auth struct {
+	// Enabled false пропускает все запросы как анонимные со всеми правами.
+	Enabled bool
+	// BootstrapKeys ключи, заданные SHA-256 хэшем (auth.Hash), открытый текст в конфиге не хранится.
+	BootstrapKeys []BootstrapKey
+	// BootstrapKeyFile куда записать временный admin-ключ, если BootstrapKeys пуст: файл с правами
+	// 0600, пустой путь означает stderr. В журнал ключ не попадает.
+	BootstrapKeyFile string
+	JWT              JWT
+}
+
+// JWT приём токенов SSO. Ключи берутся из JWKSFile, JWKSURL или HMACSecret (для HS256).
+type JWT struct {
+	Enabled    bool
+	JWKSFile   string
+	JWKSURL    string
+	HMACSecret string
+	Issuer     string
+	Audience   []string
+	ClockSkew  time.Duration
+	// CacheTTL как долго JWKS считается свежим, неизвестный kid обновляет его раньше,
+	// но не чаще MinRefreshInterval.
+	CacheTTL           time.Duration
+	MinRefreshInterval time.Duration
+}
+
+// BootstrapKey Tenant привязывает ключ к арендатору; ключ без него при включённой мультиарендности
+// годится только для /admin/.
+type BootstrapKey struct {
+	ID      string
+	Hash    string
+	Name    string
+	Subject string
+	Tenant  string
+	Scopes  []string
+}
+
+const (
+	TenantSourceHeader    = "header"
+	TenantSourceSubdomain = "subdomain"
+	TenantSourceClaim     = "claim"
+)
+
+// Tenancy арендатор определяется по Sources в указанном порядке. С Enabled == false
+// все запросы обслуживаются арендатором DefaultTenant.
+type Tenancy struct {
+	Enabled bool
+	Sources []string
+	// Header заголовок для источника header, по умолчанию X-Tenant-ID.
+	Header string
+	// BaseDomain для источника subdomain: team-a.todo.internal -> team-a.
+	BaseDomain string
+	// Claim claim токена для источника claim, токен с ним нельзя использовать в чужом арендаторе.
+	// Ключи и токены без арендатора отклоняются везде, кроме /admin/ и проб.
+	Claim         string
+	DefaultTenant string
+	// DataDir корень хранилища, у каждого арендатора свой подкаталог. Пустой значит только в памяти.
+	// Изменённые данные сохраняются раз в SnapshotInterval и при остановке, так что при аварийном
+	// завершении (паника, OOM, SIGKILL) теряются изменения не более чем за SnapshotInterval.
+	DataDir string
+	// SnapshotInterval по умолчанию persistance.DefaultSnapshotInterval (5 секунд).
+	SnapshotInterval time.Duration
+	// DefaultQuota квота для арендаторов, созданных без явной квоты.
+	DefaultQuota TenantQuota
+}
+
+type TenantQuota struct {
+	MaxTasks     int
+	RequestRate  float64
+	RequestBurst int
 }
@@ -0,0 +1,39 @@
+package entity
+
+import "time"
+
+// Board канбан-доска владельца, колонки отображают статусы задач.
+type Board struct {
+	ID      int
+	Name    string
+	OwnerID string
+	// ProjectID ограничивает доску задачами проекта, nil показывает все задачи владельца.
+	ProjectID *int
+	Columns   []Column
+	CreatedAt time.Time
+}
+
+type Column struct {
+	Name   string
+	Status TaskStatus
+	// WIPLimit максимум карточек в колонке, 0 без ограничения.
+	WIPLimit int
+}
+
+// Column колонка доски для статуса, у каждого статуса не больше одной колонки.
+func (b Board) Column(status TaskStatus) (Column, bool) {
+	for _, c := range b.Columns {
+		if c.Status == status {
+			return c, true
+		}
+	}
+	return Column{}, false
+}
+
+// Covers попадает ли задача в область доски.
+func (b Board) Covers(task Task) bool {
+	if task.OwnerID != b.OwnerID {
+		return false
+	}
+	return b.ProjectID == nil || (task.ProjectID != nil && *task.ProjectID == *b.ProjectID)
+}
@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// Feed секретная ссылка на календарь сроков пользователя, у пользователя не больше одной.
+// Хранится только SHA-256 токена, сам токен показывается один раз при выпуске.
+type Feed struct {
+	OwnerID   string
+	TokenHash string
+	CreatedAt time.Time
+	// ETag отпечаток содержимого ленты, ModifiedAt момент, когда он в последний раз изменился.
+	ETag       string
+	ModifiedAt time.Time
+}
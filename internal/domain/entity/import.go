@@ -0,0 +1,9 @@
+package entity
+
+// ImportItem задача из файла импорта. Project имя проекта из файла, Err ошибка разбора строки.
+type ImportItem struct {
+	Line    int
+	Task    Task
+	Project string
+	Err     error
+}
@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+type Project struct {
+	ID          int
+	Name        string
+	Description string
+	OwnerID     string
+	// ArchivedAt не nil у архивного проекта, его задачи скрыты из списков по умолчанию.
+	ArchivedAt *time.Time
+	CreatedAt  time.Time
+}
+
+func (p Project) Archived() bool {
+	return p.ArchivedAt != nil
+}
+
+// ProjectDeletePolicy что делать с задачами при удалении непустого проекта.
+type ProjectDeletePolicy string
+
+const (
+	// DeleteReject отказывает в удалении, пока в проекте есть задачи.
+	DeleteReject ProjectDeletePolicy = "reject"
+	// DeleteCascade удаляет задачи вместе с проектом.
+	DeleteCascade ProjectDeletePolicy = "cascade"
+	// DeleteOrphan оставляет задачи без проекта.
+	DeleteOrphan ProjectDeletePolicy = "orphan"
+)
+
+func (p ProjectDeletePolicy) Valid() bool {
+	switch p {
+	case DeleteReject, DeleteCascade, DeleteOrphan:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleOwner  Role = "owner"
+)
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleViewer, RoleEditor, RoleOwner:
+		return true
+	}
+	return false
+}
+
+// Share доступ пользователя к чужой задаче.
+type Share struct {
+	TaskID    int
+	UserID    string
+	Role      Role
+	GrantedBy string
+	CreatedAt time.Time
+}
+
+// ProjectShare доступ пользователя к чужому проекту, задачи проекта наследуют его роль.
+type ProjectShare struct {
+	ProjectID int
+	UserID    string
+	Role      Role
+	GrantedBy string
+	CreatedAt time.Time
+}
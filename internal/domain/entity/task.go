@@ -1,8 +1,121 @@
 package entity
 
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
 type Task struct {
 	ID          int
 	Title       string
 	Description string
 	IsCompleted bool
+	// OwnerID subject принципала, создавшего задачу.
+	OwnerID string
+	// ProjectID nil у задачи вне проекта.
+	ProjectID *int
+	// Rank ключ ручной сортировки в списке владельца, см. pkg/rank.
+	Rank string
+	// Status этап задачи в процессе, меняется только по правилам StatusTransitions.
+	Status TaskStatus
+	// StatusChangedAt момент последней смены статуса, по нему считается возраст карточки в колонке.
+	StatusChangedAt time.Time
+	// Tags метки в нижнем регистре без повторов.
+	Tags     []string
+	Priority Priority
+	// DueAt срок выполнения, nil без срока.
+	DueAt *time.Time
+	// UID и Resource идентификатор iCalendar и имя файла, которые выбрал CalDAV-клиент, создавший
+	// задачу, и ждёт обратно. У остальных задач пустые.
+	UID      string
+	Resource string
+}
+
+// Priority пустое значение означает, что приоритет не задан, и считается ниже low.
+type Priority string
+
+const (
+	PriorityNone   Priority = ""
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
+)
+
+// Level порядковый номер приоритета для сравнения, -1 у неизвестного значения.
+func (p Priority) Level() int {
+	switch p {
+	case PriorityNone:
+		return 0
+	case PriorityLow:
+		return 1
+	case PriorityMedium:
+		return 2
+	case PriorityHigh:
+		return 3
+	}
+	return -1
+}
+
+func (p Priority) Valid() bool {
+	return p.Level() >= 0
+}
+
+// HasTag сравнение без учёта регистра.
+func (t Task) HasTag(tag string) bool {
+	return slices.ContainsFunc(t.Tags, func(have string) bool { return strings.EqualFold(have, tag) })
+}
+
+// CurrentStatus задачи, созданные до появления статусов, выводятся из IsCompleted.
+func (t Task) CurrentStatus() TaskStatus {
+	if t.Status != "" {
+		return t.Status
+	}
+	if t.IsCompleted {
+		return StatusDone
+	}
+	return StatusTodo
+}
+
+type TaskStatus string
+
+const (
+	StatusTodo       TaskStatus = "todo"
+	StatusInProgress TaskStatus = "in_progress"
+	StatusReview     TaskStatus = "review"
+	StatusDone       TaskStatus = "done"
+)
+
+// StatusTransitions допустимые переходы, из done задачу можно только переоткрыть.
+var StatusTransitions = map[TaskStatus][]TaskStatus{ //nolint:gochecknoglobals
+	StatusTodo:       {StatusInProgress, StatusDone},
+	StatusInProgress: {StatusTodo, StatusReview, StatusDone},
+	StatusReview:     {StatusInProgress, StatusDone},
+	StatusDone:       {StatusTodo},
+}
+
+func (s TaskStatus) Valid() bool {
+	_, ok := StatusTransitions[s]
+	return ok
+}
+
+func (s TaskStatus) CanTransition(to TaskStatus) bool {
+	return slices.Contains(StatusTransitions[s], to)
+}
+
+// CanReach в статус to можно попасть цепочкой допустимых переходов.
+func (s TaskStatus) CanReach(to TaskStatus) bool {
+	seen := map[TaskStatus]bool{s: true}
+	for queue := []TaskStatus{s}; len(queue) > 0; queue = queue[1:] {
+		if queue[0] == to {
+			return true
+		}
+		for _, next := range StatusTransitions[queue[0]] {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false
 }
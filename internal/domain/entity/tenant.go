@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+type TenantStatus string
+
+const (
+	TenantActive    TenantStatus = "active"
+	TenantSuspended TenantStatus = "suspended"
+)
+
+// TenantQuota нулевые значения снимают ограничение.
+type TenantQuota struct {
+	MaxTasks     int
+	RequestRate  float64
+	RequestBurst int
+}
+
+type Tenant struct {
+	ID        string
+	Name      string
+	Status    TenantStatus
+	Quota     TenantQuota
+	CreatedAt time.Time
+}
@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"slices"
+	"time"
+)
+
+// ViewFields поля задачи, которые можно выбрать для показа в сохранённом представлении.
+var ViewFields = []string{ //nolint:gochecknoglobals
+	"id", "title", "description", "is_completed", "owner_id", "project_id", "rank",
+	"status", "status_changed_at", "tags", "priority", "due_at",
+}
+
+// View сохранённый именованный фильтр. Запрос выполняется над задачами того, кто открывает
+// представление, поэтому общий доступ раскрывает только сам фильтр, а не задачи владельца.
+type View struct {
+	ID      int
+	Name    string
+	OwnerID string
+	// Query запрос на языке пакета query, пустой показывает все задачи.
+	Query string
+	Sort  string
+	// Fields показываемые поля, пустой список означает все.
+	Fields []string
+	// SharedWith пользователи, которым представление доступно только на чтение.
+	SharedWith []string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// VisibleTo владелец и пользователи из SharedWith.
+func (v View) VisibleTo(user string) bool {
+	return v.OwnerID == user || slices.Contains(v.SharedWith, user)
+}
@@ -11,6 +11,43 @@ var (
 	ErrEmptyTitle      = errors.New("task title cannot be empty")
 	ErrTaskAlreadyDone = errors.New("task is already completed")
 	ErrInvalidID       = errors.New("invalid task identifier")
+	ErrForbidden       = errors.New("operation is not permitted for this role")
+	ErrShareNotFound   = errors.New("share not found")
+	ErrInvalidShare    = errors.New("invalid share")
+	ErrTenantNotFound  = errors.New("tenant not found")
+	ErrTenantExists    = errors.New("tenant already exists")
+	ErrTenantSuspended = errors.New("tenant is suspended")
+	ErrInvalidTenant   = errors.New("invalid tenant")
+	ErrQuotaExceeded   = errors.New("tenant task quota exceeded")
+
+	// ErrPreconditionFailed сохранённая задача не совпала с версией, которую ожидал условный запрос.
+	ErrPreconditionFailed = errors.New("task does not match the expected version")
+
+	ErrProjectNotFound     = errors.New("project not found")
+	ErrEmptyProjectName    = errors.New("project name cannot be empty")
+	ErrProjectNotEmpty     = errors.New("project still has tasks")
+	ErrProjectArchived     = errors.New("project is archived")
+	ErrInvalidDeletePolicy = errors.New("invalid project delete policy")
+
+	ErrInvalidMove = errors.New("invalid move anchors")
+	ErrInvalidSort = errors.New("invalid sort order")
+
+	ErrBoardNotFound     = errors.New("board not found")
+	ErrInvalidBoard      = errors.New("invalid board")
+	ErrInvalidStatus     = errors.New("invalid task status")
+	ErrInvalidTransition = errors.New("status transition is not allowed")
+	ErrWIPLimitExceeded  = errors.New("column WIP limit exceeded")
+
+	ErrInvalidQuery      = errors.New("invalid query")
+	ErrInvalidPriority   = errors.New("invalid task priority")
+	ErrSearchUnavailable = errors.New("search is not configured")
+
+	ErrViewNotFound = errors.New("view not found")
+	ErrInvalidView  = errors.New("invalid view")
+
+	ErrInvalidImport = errors.New("invalid import file")
+
+	ErrFeedNotFound = errors.New("calendar feed not found")
 )
 
 type TaskError struct {
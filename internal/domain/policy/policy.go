@@ -0,0 +1,69 @@
+package policy
+
+import (
+	"ecom_test/internal/domain/entity"
+	"slices"
+)
+
+type Action string
+
+const (
+	ActionView   Action = "view"
+	ActionEdit   Action = "edit"
+	ActionDelete Action = "delete"
+	// ActionShare выдача и отзыв доступа другим пользователям.
+	ActionShare Action = "share"
+)
+
+var permissions = map[entity.Role][]Action{ //nolint:gochecknoglobals
+	entity.RoleViewer: {ActionView},
+	entity.RoleEditor: {ActionView, ActionEdit},
+	entity.RoleOwner:  {ActionView, ActionEdit, ActionDelete, ActionShare},
+}
+
+// seniority старшинство ролей, когда у пользователя их несколько.
+var seniority = map[entity.Role]int{ //nolint:gochecknoglobals
+	entity.RoleViewer: 1,
+	entity.RoleEditor: 2,
+	entity.RoleOwner:  3,
+}
+
+// Can единственное место, где роли сопоставляются с действиями.
+func Can(role entity.Role, action Action) bool {
+	return slices.Contains(permissions[role], action)
+}
+
+// RoleOf роль пользователя в задаче: владелец задачи всегда owner, остальные получают роль из share.
+func RoleOf(task *entity.Task, share *entity.Share, userID string) (entity.Role, bool) {
+	if task.OwnerID == userID {
+		return entity.RoleOwner, true
+	}
+	if share != nil && share.TaskID == task.ID && share.UserID == userID {
+		return share.Role, true
+	}
+	return "", false
+}
+
+// ProjectRoleOf роль пользователя в проекте: владелец проекта всегда owner, остальные получают роль из share.
+func ProjectRoleOf(project *entity.Project, share *entity.ProjectShare, userID string) (entity.Role, bool) {
+	if project.OwnerID == userID {
+		return entity.RoleOwner, true
+	}
+	if share != nil && share.ProjectID == project.ID && share.UserID == userID {
+		return share.Role, true
+	}
+	return "", false
+}
+
+// TaskRoleOf как RoleOf, но задача проекта наследует роль доступа к проекту; из прямой
+// и унаследованной роли действует старшая.
+func TaskRoleOf(task *entity.Task, share *entity.Share, project *entity.ProjectShare, userID string) (entity.Role, bool) {
+	role, ok := RoleOf(task, share, userID)
+	if project == nil || task.ProjectID == nil || project.ProjectID != *task.ProjectID || project.UserID != userID {
+		return role, ok
+	}
+	if !ok || seniority[project.Role] > seniority[role] {
+		return project.Role, true
+	}
+	return role, true
+}
@@ -0,0 +1,108 @@
+package policy
+
+import (
+	"ecom_test/internal/domain/entity"
+	"testing"
+)
+
+func TestCan(t *testing.T) {
+	tests := []struct {
+		role   entity.Role
+		action Action
+		want   bool
+	}{
+		{entity.RoleViewer, ActionView, true},
+		{entity.RoleViewer, ActionEdit, false},
+		{entity.RoleViewer, ActionDelete, false},
+		{entity.RoleViewer, ActionShare, false},
+		{entity.RoleEditor, ActionView, true},
+		{entity.RoleEditor, ActionEdit, true},
+		{entity.RoleEditor, ActionDelete, false},
+		{entity.RoleEditor, ActionShare, false},
+		{entity.RoleOwner, ActionView, true},
+		{entity.RoleOwner, ActionEdit, true},
+		{entity.RoleOwner, ActionDelete, true},
+		{entity.RoleOwner, ActionShare, true},
+		{entity.Role("admin"), ActionView, false},
+		{entity.Role(""), ActionView, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role)+"/"+string(tt.action), func(t *testing.T) {
+			if got := Can(tt.role, tt.action); got != tt.want {
+				t.Errorf("Can(%q, %q) = %v, want %v", tt.role, tt.action, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoleOf(t *testing.T) {
+	task := &entity.Task{ID: 1, OwnerID: "alice"}
+	share := &entity.Share{TaskID: 1, UserID: "bob", Role: entity.RoleEditor}
+
+	tests := []struct {
+		name   string
+		user   string
+		share  *entity.Share
+		want   entity.Role
+		wantOK bool
+	}{
+		{name: "Owner", user: "alice", want: entity.RoleOwner, wantOK: true},
+		{name: "Shared", user: "bob", share: share, want: entity.RoleEditor, wantOK: true},
+		{name: "Share for other user", user: "carol", share: share},
+		{name: "Share for other task", user: "bob", share: &entity.Share{TaskID: 2, UserID: "bob", Role: entity.RoleOwner}},
+		{name: "Stranger", user: "carol"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := RoleOf(task, tt.share, tt.user)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("RoleOf() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTaskRoleOf(t *testing.T) {
+	projectID := 7
+	task := &entity.Task{ID: 1, OwnerID: "alice", ProjectID: &projectID}
+
+	tests := []struct {
+		name    string
+		user    string
+		share   *entity.Share
+		project *entity.ProjectShare
+		want    entity.Role
+		wantOK  bool
+	}{
+		{name: "Owner", user: "alice", project: &entity.ProjectShare{ProjectID: 7, UserID: "alice", Role: entity.RoleViewer}, want: entity.RoleOwner, wantOK: true},
+		{name: "Inherited from project", user: "bob", project: &entity.ProjectShare{ProjectID: 7, UserID: "bob", Role: entity.RoleEditor}, want: entity.RoleEditor, wantOK: true},
+		{
+			name: "Task share above project share", user: "bob",
+			share:   &entity.Share{TaskID: 1, UserID: "bob", Role: entity.RoleOwner},
+			project: &entity.ProjectShare{ProjectID: 7, UserID: "bob", Role: entity.RoleViewer},
+			want:    entity.RoleOwner, wantOK: true,
+		},
+		{
+			name: "Project share above task share", user: "bob",
+			share:   &entity.Share{TaskID: 1, UserID: "bob", Role: entity.RoleViewer},
+			project: &entity.ProjectShare{ProjectID: 7, UserID: "bob", Role: entity.RoleEditor},
+			want:    entity.RoleEditor, wantOK: true,
+		},
+		{name: "Share of other project", user: "bob", project: &entity.ProjectShare{ProjectID: 8, UserID: "bob", Role: entity.RoleEditor}},
+		{name: "Share for other user", user: "carol", project: &entity.ProjectShare{ProjectID: 7, UserID: "bob", Role: entity.RoleEditor}},
+		{name: "Stranger", user: "carol"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := TaskRoleOf(task, tt.share, tt.project, tt.user)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("TaskRoleOf() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	loose := &entity.Task{ID: 2, OwnerID: "alice"}
+	if _, ok := TaskRoleOf(loose, nil, &entity.ProjectShare{ProjectID: 7, UserID: "bob", Role: entity.RoleOwner}, "bob"); ok {
+		t.Errorf("TaskRoleOf() granted access to a task outside the shared project")
+	}
+}
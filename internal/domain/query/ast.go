@@ -0,0 +1,75 @@
+package query
+
+import "strings"
+
+// Node узел AST. String возвращает каноническую запись с минимумом скобок,
+// разбор которой даёт дерево с той же записью.
+type Node interface {
+	String() string
+}
+
+type And struct {
+	Left, Right Node
+}
+
+type Or struct {
+	Left, Right Node
+}
+
+type Not struct {
+	Expr Node
+}
+
+// Cmp сравнение поля со значением, Pos позиция имени поля для ошибок компиляции.
+type Cmp struct {
+	Field string
+	Op    string
+	Value string
+	Pos   int
+}
+
+func (n And) String() string {
+	return group(n.Left, false) + " AND " + group(n.Right, false)
+}
+
+func (n Or) String() string {
+	return n.Left.String() + " OR " + n.Right.String()
+}
+
+func (n Not) String() string {
+	return "NOT " + group(n.Expr, true)
+}
+
+// group берёт в скобки OR внутри AND и любую связку под NOT.
+func group(n Node, underNot bool) string {
+	switch n.(type) {
+	case Or:
+		return "(" + n.String() + ")"
+	case And:
+		if underNot {
+			return "(" + n.String() + ")"
+		}
+	}
+	return n.String()
+}
+
+func (n Cmp) String() string {
+	return n.Field + n.Op + quote(n.Value)
+}
+
+// quote оставляет значение словом, если лексер разберёт его обратно так же.
+func quote(value string) string {
+	if value != "" && keyword(value) == "" && strings.IndexFunc(value, func(r rune) bool { return !isWordRune(r) }) < 0 {
+		return value
+	}
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range value {
+		if r == '"' || r == '\\' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
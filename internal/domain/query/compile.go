@@ -0,0 +1,205 @@
+package query
+
+import (
+	"ecom_test/internal/domain/entity"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Predicate скомпилированный запрос, вызывается хранилищем для каждой задачи.
+type Predicate func(entity.Task) bool
+
+const dateLayout = "2006-01-02"
+
+// Compile проверяет поля и значения и собирает предикат. now нужен для today/tomorrow/yesterday
+// и берётся в UTC, как и сроки задач.
+func Compile(node Node, now time.Time) (Predicate, error) {
+	c := compiler{today: truncateDay(now.UTC())}
+	return c.compile(node)
+}
+
+// ParseAndCompile Parse и Compile одним вызовом.
+func ParseAndCompile(src string, now time.Time) (Predicate, error) {
+	node, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	return Compile(node, now)
+}
+
+type compiler struct {
+	today time.Time
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (c compiler) compile(node Node) (Predicate, error) {
+	switch n := node.(type) {
+	case And:
+		left, right, err := c.pair(n.Left, n.Right)
+		if err != nil {
+			return nil, err
+		}
+		return func(t entity.Task) bool { return left(t) && right(t) }, nil
+	case Or:
+		left, right, err := c.pair(n.Left, n.Right)
+		if err != nil {
+			return nil, err
+		}
+		return func(t entity.Task) bool { return left(t) || right(t) }, nil
+	case Not:
+		expr, err := c.compile(n.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return func(t entity.Task) bool { return !expr(t) }, nil
+	case Cmp:
+		return c.cmp(n)
+	}
+	return nil, errorf(0, "unknown node %T", node)
+}
+
+func (c compiler) pair(l, r Node) (Predicate, Predicate, error) {
+	left, err := c.compile(l)
+	if err != nil {
+		return nil, nil, err
+	}
+	right, err := c.compile(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return left, right, nil
+}
+
+func (c compiler) cmp(n Cmp) (Predicate, error) {
+	switch n.Field {
+	case "status":
+		return c.status(n)
+	case "tag":
+		tag := n.Value
+		return equality(n, func(t entity.Task) bool { return t.HasTag(tag) })
+	case "priority":
+		return c.priority(n)
+	case "due":
+		return c.due(n)
+	case "title":
+		needle := strings.ToLower(n.Value)
+		return equality(n, func(t entity.Task) bool { return strings.Contains(strings.ToLower(t.Title), needle) })
+	case "owner":
+		owner := n.Value
+		return equality(n, func(t entity.Task) bool { return t.OwnerID == owner })
+	case "project":
+		return c.project(n)
+	}
+	return nil, errorf(n.Pos, "unknown field %q, expected one of status, tag, priority, due, title, owner, project", n.Field)
+}
+
+// equality для полей, где имеет смысл только совпадение (: или =) и его отрицание (!=).
+func equality(n Cmp, match Predicate) (Predicate, error) {
+	switch n.Op {
+	case ":", "=":
+		return match, nil
+	case "!=":
+		return func(t entity.Task) bool { return !match(t) }, nil
+	}
+	return nil, errorf(n.Pos, "field %q does not support %q", n.Field, n.Op)
+}
+
+// status open означает любой статус, кроме done.
+func (c compiler) status(n Cmp) (Predicate, error) {
+	value := entity.TaskStatus(strings.ToLower(n.Value))
+	if value == "open" {
+		return equality(n, func(t entity.Task) bool { return t.CurrentStatus() != entity.StatusDone })
+	}
+	if !value.Valid() {
+		return nil, errorf(n.Pos, "unknown status %q, expected open, todo, in_progress, review or done", n.Value)
+	}
+	return equality(n, func(t entity.Task) bool { return t.CurrentStatus() == value })
+}
+
+func (c compiler) priority(n Cmp) (Predicate, error) {
+	value := entity.Priority(strings.ToLower(n.Value))
+	if value == "none" {
+		value = entity.PriorityNone
+	}
+	if !value.Valid() {
+		return nil, errorf(n.Pos, "unknown priority %q, expected none, low, medium or high", n.Value)
+	}
+	level := value.Level()
+	return ordered(n, func(t entity.Task) (int, bool) { return t.Priority.Level() - level, true })
+}
+
+// due сравнивает по календарным дням в UTC, due:none отбирает задачи без срока.
+func (c compiler) due(n Cmp) (Predicate, error) {
+	if strings.EqualFold(n.Value, "none") {
+		return equality(n, func(t entity.Task) bool { return t.DueAt == nil })
+	}
+
+	var day time.Time
+	switch strings.ToLower(n.Value) {
+	case "today":
+		day = c.today
+	case "tomorrow":
+		day = c.today.AddDate(0, 0, 1)
+	case "yesterday":
+		day = c.today.AddDate(0, 0, -1)
+	default:
+		parsed, err := time.Parse(dateLayout, n.Value)
+		if err != nil {
+			return nil, errorf(n.Pos, "invalid date %q, expected YYYY-MM-DD, today, tomorrow, yesterday or none", n.Value)
+		}
+		day = parsed
+	}
+
+	return ordered(n, func(t entity.Task) (int, bool) {
+		if t.DueAt == nil {
+			return 0, false
+		}
+		due := truncateDay(t.DueAt.UTC())
+		return due.Compare(day), true
+	})
+}
+
+// project:none отбирает задачи вне проектов.
+func (c compiler) project(n Cmp) (Predicate, error) {
+	if strings.EqualFold(n.Value, "none") {
+		return equality(n, func(t entity.Task) bool { return t.ProjectID == nil })
+	}
+	id, err := strconv.Atoi(n.Value)
+	if err != nil {
+		return nil, errorf(n.Pos, "invalid project id %q", n.Value)
+	}
+	return equality(n, func(t entity.Task) bool { return t.ProjectID != nil && *t.ProjectID == id })
+}
+
+// ordered cmp возвращает знак сравнения значения задачи с эталоном, ok=false у задачи без значения:
+// такая задача не подходит ни под одно сравнение, кроме !=.
+func ordered(n Cmp, cmp func(entity.Task) (int, bool)) (Predicate, error) {
+	var accept func(int) bool
+	switch n.Op {
+	case ":", "=":
+		accept = func(d int) bool { return d == 0 }
+	case "!=":
+		return func(t entity.Task) bool {
+			d, ok := cmp(t)
+			return !ok || d != 0
+		}, nil
+	case "<":
+		accept = func(d int) bool { return d < 0 }
+	case "<=":
+		accept = func(d int) bool { return d <= 0 }
+	case ">":
+		accept = func(d int) bool { return d > 0 }
+	case ">=":
+		accept = func(d int) bool { return d >= 0 }
+	default:
+		return nil, errorf(n.Pos, "field %q does not support %q", n.Field, n.Op)
+	}
+	return func(t entity.Task) bool {
+		d, ok := cmp(t)
+		return ok && accept(d)
+	}, nil
+}
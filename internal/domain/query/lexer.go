@@ -0,0 +1,180 @@
+// Package query язык фильтрации задач: `status:open AND tag:backend AND (due<2026-10-23 OR priority:high)`.
+// Лексер и парсер написаны вручную и строят AST, Compile превращает его в предикат над entity.Task.
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokEOF:
+		return "end of query"
+	case tokWord:
+		return "word"
+	case tokString:
+		return "string"
+	case tokOp:
+		return "operator"
+	case tokLParen:
+		return "'('"
+	case tokRParen:
+		return "')'"
+	}
+	return "token"
+}
+
+type token struct {
+	kind tokenKind
+	text string
+	// pos номер символа (не байта) от единицы, в нём же сообщаются ошибки
+	pos int
+}
+
+func (t token) describe() string {
+	if t.kind == tokEOF {
+		return t.kind.String()
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// Error ошибка разбора с позицией символа в запросе, начиная с единицы.
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("position %d: %s", e.Pos, e.Msg)
+}
+
+func errorf(pos int, format string, args ...any) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// operators двухсимвольные раньше односимвольных, чтобы <= не распалось на < и =.
+var operators = []string{"<=", ">=", "!=", ":", "=", "<", ">"} //nolint:gochecknoglobals
+
+func isWordRune(r rune) bool {
+	return !unicode.IsSpace(r) && !strings.ContainsRune(`():<>=!"`, r)
+}
+
+type lexer struct {
+	src  string
+	off  int
+	pos  int
+	peek *token
+}
+
+func newLexer(src string) *lexer {
+	return &lexer{src: src, pos: 1}
+}
+
+func (l *lexer) advance(n int) {
+	l.pos += utf8.RuneCountInString(l.src[l.off : l.off+n])
+	l.off += n
+}
+
+func (l *lexer) Peek() (token, error) {
+	if l.peek == nil {
+		t, err := l.scan()
+		if err != nil {
+			return token{}, err
+		}
+		l.peek = &t
+	}
+	return *l.peek, nil
+}
+
+func (l *lexer) Next() (token, error) {
+	t, err := l.Peek()
+	l.peek = nil
+	return t, err
+}
+
+func (l *lexer) scan() (token, error) {
+	for l.off < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.off:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		l.advance(size)
+	}
+	if l.off == len(l.src) {
+		return token{kind: tokEOF, pos: l.pos}, nil
+	}
+
+	start := token{pos: l.pos}
+	rest := l.src[l.off:]
+	switch {
+	case rest[0] == '(':
+		l.advance(1)
+		start.kind, start.text = tokLParen, "("
+		return start, nil
+	case rest[0] == ')':
+		l.advance(1)
+		start.kind, start.text = tokRParen, ")"
+		return start, nil
+	case rest[0] == '"':
+		return l.scanString(start)
+	}
+	for _, op := range operators {
+		if strings.HasPrefix(rest, op) {
+			l.advance(len(op))
+			start.kind, start.text = tokOp, op
+			return start, nil
+		}
+	}
+	if rest[0] == '!' {
+		return token{}, errorf(l.pos, "unexpected '!', did you mean '!='")
+	}
+
+	end := strings.IndexFunc(rest, func(r rune) bool { return !isWordRune(r) })
+	if end < 0 {
+		end = len(rest)
+	}
+	if !utf8.ValidString(rest[:end]) {
+		return token{}, errorf(l.pos, "invalid UTF-8")
+	}
+	l.advance(end)
+	start.kind, start.text = tokWord, rest[:end]
+	return start, nil
+}
+
+// scanString строка в двойных кавычках, \" и \\ экранируются.
+func (l *lexer) scanString(start token) (token, error) {
+	l.advance(1)
+	var sb strings.Builder
+	for l.off < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.off:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			return token{}, errorf(l.pos, "invalid UTF-8")
+		case r == '"':
+			l.advance(size)
+			start.kind, start.text = tokString, sb.String()
+			return start, nil
+		case r == '\\' && l.off+1 < len(l.src) && strings.ContainsRune(`"\`, rune(l.src[l.off+1])):
+			sb.WriteByte(l.src[l.off+1])
+			l.advance(2)
+			continue
+		}
+		sb.WriteRune(r)
+		l.advance(size)
+	}
+	return token{}, errorf(start.pos, "unterminated string")
+}
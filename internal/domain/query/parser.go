@@ -0,0 +1,172 @@
+package query
+
+import "strings"
+
+// Грамматика:
+//
+//	expr    = or
+//	or      = and { "OR" and }
+//	and     = unary { ["AND"] unary }
+//	unary   = "NOT" unary | primary
+//	primary = "(" expr ")" | field op value
+//	op      = ":" | "=" | "!=" | "<" | "<=" | ">" | ">="
+//	value   = word | "string"
+//
+// Ключевые слова без учёта регистра. Соседние условия без оператора объединяются через AND.
+
+// maxDepth ограничивает вложенность скобок и NOT, чтобы запрос не переполнил стек.
+const maxDepth = 64
+
+func keyword(word string) string {
+	switch upper := strings.ToUpper(word); upper {
+	case "AND", "OR", "NOT":
+		return upper
+	}
+	return ""
+}
+
+type parser struct {
+	lex   *lexer
+	depth int
+}
+
+// Parse разбирает запрос в AST, ошибки имеют тип *Error.
+func Parse(src string) (Node, error) {
+	p := &parser{lex: newLexer(src)}
+	node, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	t, err := p.lex.Next()
+	if err != nil {
+		return nil, err
+	}
+	if t.kind != tokEOF {
+		return nil, errorf(t.pos, "unexpected %s", t.describe())
+	}
+	return node, nil
+}
+
+func (p *parser) isKeyword(t token, kw string) bool {
+	return t.kind == tokWord && keyword(t.text) == kw
+}
+
+func (p *parser) or() (Node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t, err := p.lex.Peek()
+		if err != nil {
+			return nil, err
+		}
+		if !p.isKeyword(t, "OR") {
+			return left, nil
+		}
+		_, _ = p.lex.Next()
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = Or{Left: left, Right: right}
+	}
+}
+
+func (p *parser) and() (Node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t, err := p.lex.Peek()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case p.isKeyword(t, "AND"):
+			_, _ = p.lex.Next()
+		case t.kind == tokEOF, t.kind == tokRParen, p.isKeyword(t, "OR"):
+			return left, nil
+		}
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = And{Left: left, Right: right}
+	}
+}
+
+func (p *parser) enter(pos int) error {
+	p.depth++
+	if p.depth > maxDepth {
+		return errorf(pos, "query is nested too deeply")
+	}
+	return nil
+}
+
+func (p *parser) unary() (Node, error) {
+	t, err := p.lex.Peek()
+	if err != nil {
+		return nil, err
+	}
+	if p.isKeyword(t, "NOT") {
+		_, _ = p.lex.Next()
+		if err := p.enter(t.pos); err != nil {
+			return nil, err
+		}
+		expr, err := p.unary()
+		p.depth--
+		if err != nil {
+			return nil, err
+		}
+		return Not{Expr: expr}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (Node, error) {
+	t, err := p.lex.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case t.kind == tokLParen:
+		if err := p.enter(t.pos); err != nil {
+			return nil, err
+		}
+		node, err := p.or()
+		p.depth--
+		if err != nil {
+			return nil, err
+		}
+		closing, err := p.lex.Next()
+		if err != nil {
+			return nil, err
+		}
+		if closing.kind != tokRParen {
+			return nil, errorf(closing.pos, "expected ')' to close '(' at position %d, got %s", t.pos, closing.describe())
+		}
+		return node, nil
+	case t.kind != tokWord || keyword(t.text) != "":
+		return nil, errorf(t.pos, "expected field name, got %s", t.describe())
+	}
+
+	field := t
+	op, err := p.lex.Next()
+	if err != nil {
+		return nil, err
+	}
+	if op.kind != tokOp {
+		return nil, errorf(op.pos, "expected operator after %q, got %s", field.text, op.describe())
+	}
+	value, err := p.lex.Next()
+	if err != nil {
+		return nil, err
+	}
+	if value.kind != tokWord && value.kind != tokString {
+		return nil, errorf(value.pos, "expected value after %q, got %s", field.text+op.text, value.describe())
+	}
+	return Cmp{Field: strings.ToLower(field.text), Op: op.text, Value: value.text, Pos: field.pos}, nil
+}
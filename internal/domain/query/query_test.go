@@ -0,0 +1,194 @@
+package query
+
+import (
+	"ecom_test/internal/domain/entity"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{src: "status:open", want: "status:open"},
+		{src: "status:open tag:backend", want: "status:open AND tag:backend"},
+		{src: "a:1 OR b:2 AND c:3", want: "a:1 OR b:2 AND c:3"},
+		{src: "(a:1 OR b:2) AND c:3", want: "(a:1 OR b:2) AND c:3"},
+		{src: "not a:1 and not (b:2 or c:3)", want: "NOT a:1 AND NOT (b:2 OR c:3)"},
+		{src: `title:"buy milk" due<=2026-10-23`, want: `title:"buy milk" AND due<=2026-10-23`},
+		{src: `tag:"or"`, want: `tag:"or"`},
+		{src: `title:"say \"hi\""`, want: `title:"say \"hi\""`},
+		{src: "Priority >= high", want: "priority>=high"},
+		{
+			src:  "status:open AND tag:backend AND (due<2026-10-23 OR priority:high)",
+			want: "status:open AND tag:backend AND (due<2026-10-23 OR priority:high)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			node, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := node.String(); got != tt.want {
+				t.Errorf("Parse() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		src     string
+		wantPos int
+		wantMsg string
+	}{
+		{src: "", wantPos: 1, wantMsg: "expected field name, got end of query"},
+		{src: "status", wantPos: 7, wantMsg: "expected operator"},
+		{src: "status:", wantPos: 8, wantMsg: "expected value"},
+		{src: "status:open AND", wantPos: 16, wantMsg: "expected field name"},
+		{src: "(status:open", wantPos: 13, wantMsg: "expected ')' to close '(' at position 1"},
+		{src: "status:open)", wantPos: 12, wantMsg: `unexpected ")"`},
+		{src: `title:"unterminated`, wantPos: 7, wantMsg: "unterminated string"},
+		{src: "задача:да OR :x", wantPos: 14, wantMsg: `expected field name, got ":"`},
+		{src: "tag!backend", wantPos: 4, wantMsg: "did you mean '!='"},
+		{src: strings.Repeat("(", maxDepth+1) + "a:1", wantPos: maxDepth + 1, wantMsg: "nested too deeply"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := Parse(tt.src)
+			var perr *Error
+			if !errors.As(err, &perr) {
+				t.Fatalf("Parse() error = %v, want *Error", err)
+			}
+			if perr.Pos != tt.wantPos || !strings.Contains(perr.Msg, tt.wantMsg) {
+				t.Errorf("Parse() error = %v, want position %d: %s", err, tt.wantPos, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	now := time.Date(2026, 10, 19, 15, 0, 0, 0, time.UTC)
+	day := func(d int) *time.Time {
+		due := time.Date(2026, 10, d, 12, 0, 0, 0, time.UTC)
+		return &due
+	}
+	project := 7
+
+	tasks := map[string]entity.Task{
+		"api":   {Title: "Fix API", Tags: []string{"backend"}, DueAt: day(21), Priority: entity.PriorityMedium},
+		"db":    {Title: "Migrate DB", Tags: []string{"backend", "db"}, DueAt: day(30), Priority: entity.PriorityHigh, ProjectID: &project},
+		"ui":    {Title: "Polish UI", Tags: []string{"frontend"}, Priority: entity.PriorityHigh},
+		"done":  {Title: "Old API task", Tags: []string{"Backend"}, DueAt: day(1), Status: entity.StatusDone},
+		"noise": {Title: "Buy milk", OwnerID: "bob"},
+	}
+
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{src: "status:open AND tag:backend AND (due<2026-10-23 OR priority:high)", want: "api db"},
+		{src: "tag:backend", want: "api db done"},
+		{src: "NOT tag:backend", want: "noise ui"},
+		{src: "priority>=medium", want: "api db ui"},
+		{src: "priority:none", want: "done noise"},
+		{src: "due:none", want: "noise ui"},
+		{src: "due<today", want: "done"},
+		{src: "due<=2026-10-21", want: "api done"},
+		{src: "due:2026-10-21", want: "api"},
+		{src: "due>2026-10-21", want: "db"},
+		{src: "due!=2026-10-21", want: "db done noise ui"},
+		{src: "title:api", want: "api done"},
+		{src: "status:done OR owner:bob", want: "done noise"},
+		{src: "project:7", want: "db"},
+		{src: "project:none AND x:1", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			match, err := ParseAndCompile(tt.src, now)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("ParseAndCompile() expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAndCompile() error = %v", err)
+			}
+			var got []string
+			for _, name := range []string{"api", "db", "done", "noise", "ui"} {
+				if match(tasks[name]) {
+					got = append(got, name)
+				}
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("matched %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		src     string
+		wantPos int
+	}{
+		{src: "colour:red", wantPos: 1},
+		{src: "status:open AND status:blocked", wantPos: 17},
+		{src: "tag<backend", wantPos: 1},
+		{src: "due<friday", wantPos: 1},
+		{src: "priority:urgent", wantPos: 1},
+		{src: "project:abc", wantPos: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := ParseAndCompile(tt.src, time.Now())
+			var perr *Error
+			if !errors.As(err, &perr) || perr.Pos != tt.wantPos {
+				t.Errorf("ParseAndCompile() error = %v, want error at position %d", err, tt.wantPos)
+			}
+		})
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"status:open AND tag:backend AND (due<2026-10-23 OR priority:high)",
+		`title:"a \"b\" \\ c" OR NOT (x:1 y:2)`,
+		"NOT NOT a:b",
+		"((a:1))",
+		"a:1 OR",
+		`"`,
+		"задача:да",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, src string) {
+		node, err := Parse(src)
+		if err != nil {
+			var perr *Error
+			if !errors.As(err, &perr) {
+				t.Fatalf("Parse(%q) error %v is not *Error", src, err)
+			}
+			if perr.Pos < 1 || perr.Pos > len([]rune(src))+1 {
+				t.Fatalf("Parse(%q) error position %d out of range", src, perr.Pos)
+			}
+			return
+		}
+
+		canonical := node.String()
+		again, err := Parse(canonical)
+		if err != nil {
+			t.Fatalf("Parse(%q) of canonical form of %q failed: %v", canonical, src, err)
+		}
+		if again.String() != canonical {
+			t.Fatalf("canonical form is not stable: %q -> %q", canonical, again.String())
+		}
+		_, _ = Compile(node, time.Now())
+	})
+}
@@ -0,0 +1,298 @@
+package quickadd
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxAmount ограничение для «in N days», чтобы опечатка не уводила срок на тысячелетия вперёд.
+const maxAmount = 1000
+
+// parser собирает срок из фрагмента дня и фрагмента времени, которые могут стоять в любом порядке.
+type parser struct {
+	now time.Time
+	// day полночь дня срока в зоне now, нулевое значение пока день не распознан.
+	day time.Time
+	// clock минуты от полуночи, -1 пока время не распознано.
+	clock int
+}
+
+// match возвращает число слов, поглощённых правилом срока, 0 если ни одно правило не подошло.
+func (p *parser) match(words []word) int {
+	if p.day.IsZero() {
+		if n := p.matchDay(words); n > 0 {
+			return n
+		}
+	}
+	if p.clock < 0 {
+		if n := p.matchClock(words); n > 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+// due время без дня означает ближайшее такое время: сегодня, если оно ещё не прошло, иначе завтра.
+func (p *parser) due() (time.Time, bool) {
+	if p.day.IsZero() && p.clock < 0 {
+		return time.Time{}, false
+	}
+	day := p.day
+	if day.IsZero() {
+		day = p.today()
+		if !at(day, p.clock).After(p.now) {
+			day = day.AddDate(0, 0, 1)
+		}
+	}
+	if p.clock < 0 {
+		return day.UTC(), true
+	}
+	return at(day, p.clock).UTC(), true
+}
+
+func (p *parser) today() time.Time {
+	return time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+}
+
+// at через time.Date, а не Add, чтобы переход на летнее время не сдвигал часы.
+func at(day time.Time, clock int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), clock/60, clock%60, 0, 0, day.Location())
+}
+
+func keys(words []word, n int) []string {
+	out := make([]string, n)
+	for i := 0; i < n && i < len(words); i++ {
+		out[i] = words[i].key
+	}
+	return out
+}
+
+var (
+	weekdays = map[string]time.Weekday{ //nolint:gochecknoglobals
+		"monday":      time.Monday,
+		"tuesday":     time.Tuesday,
+		"wednesday":   time.Wednesday,
+		"thursday":    time.Thursday,
+		"friday":      time.Friday,
+		"saturday":    time.Saturday,
+		"sunday":      time.Sunday,
+		"понедельник": time.Monday,
+		"вторник":     time.Tuesday,
+		"среда":       time.Wednesday,
+		"среду":       time.Wednesday,
+		"четверг":     time.Thursday,
+		"пятница":     time.Friday,
+		"пятницу":     time.Friday,
+		"суббота":     time.Saturday,
+		"субботу":     time.Saturday,
+		"воскресенье": time.Sunday,
+	}
+
+	// dayPrefixes слова перед днём недели или неделей: «on friday», «в следующую пятницу», «на следующей неделе».
+	// next отмечает те, без которых «week»/«неделе» не считается сроком.
+	dayPrefixes = map[string]bool{ //nolint:gochecknoglobals
+		"on":        false,
+		"this":      false,
+		"в":         false,
+		"во":        false,
+		"на":        false,
+		"next":      true,
+		"следующий": true,
+		"следующую": true,
+		"следующее": true,
+		"следующей": true,
+		"следующем": true,
+	}
+
+	units = map[string]string{ //nolint:gochecknoglobals
+		"minute": "minute", "minutes": "minute", "min": "minute", "mins": "minute",
+		"минуту": "minute", "минуты": "minute", "минут": "minute",
+		"hour": "hour", "hours": "hour", "час": "hour", "часа": "hour", "часов": "hour",
+		"day": "day", "days": "day", "день": "day", "дня": "day", "дней": "day",
+		"week": "week", "weeks": "week", "неделю": "week", "недели": "week", "недель": "week",
+		"month": "month", "months": "month", "месяц": "month", "месяца": "month", "месяцев": "month",
+	}
+)
+
+// matchDay «monday» и «next monday» оба означают ближайший понедельник после сегодняшнего дня.
+func (p *parser) matchDay(words []word) int {
+	k := keys(words, 3)
+	today := p.today()
+
+	switch k[0] {
+	case "today", "сегодня":
+		p.day = today
+		return 1
+	case "tomorrow", "завтра":
+		p.day = today.AddDate(0, 0, 1)
+		return 1
+	case "послезавтра":
+		p.day = today.AddDate(0, 0, 2)
+		return 1
+	case "day":
+		if k[1] == "after" && k[2] == "tomorrow" {
+			p.day = today.AddDate(0, 0, 2)
+			return 3
+		}
+	case "in", "через":
+		return p.matchRelative(words)
+	}
+
+	if day, ok := p.absolute(k[0]); ok {
+		p.day = day
+		return 1
+	}
+
+	n, next := 0, false
+	for n < 2 && n < len(words) {
+		isNext, ok := dayPrefixes[words[n].key]
+		if !ok {
+			break
+		}
+		next = next || isNext
+		n++
+	}
+	if n >= len(words) {
+		return 0
+	}
+	target := words[n].key
+	if wd, ok := weekdays[target]; ok {
+		ahead := (int(wd)-int(today.Weekday())+6)%7 + 1
+		p.day = today.AddDate(0, 0, ahead)
+		return n + 1
+	}
+	if !next {
+		return 0
+	}
+	switch target {
+	case "week", "неделе":
+		p.day = today.AddDate(0, 0, 7)
+		return n + 1
+	case "month", "месяце":
+		p.day = today.AddDate(0, 1, 0)
+		return n + 1
+	}
+	return 0
+}
+
+// matchRelative «in 3 days», «in a week», «через 2 часа», «через неделю». Часы и минуты задают и время.
+func (p *parser) matchRelative(words []word) int {
+	k := keys(words, 3)
+	amount, n := 1, 1
+	switch k[1] {
+	case "a", "an", "one", "один", "одну":
+		n = 2
+	default:
+		if v, err := strconv.Atoi(k[1]); err == nil {
+			if v <= 0 || v > maxAmount {
+				return 0
+			}
+			amount, n = v, 2
+		}
+	}
+	unit, ok := units[k[n]]
+	if !ok {
+		return 0
+	}
+
+	today := p.today()
+	switch unit {
+	case "minute", "hour":
+		d := time.Duration(amount) * time.Minute
+		if unit == "hour" {
+			d = time.Duration(amount) * time.Hour
+		}
+		due := p.now.Add(d)
+		p.day = time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, due.Location())
+		p.clock = due.Hour()*60 + due.Minute()
+	case "day":
+		p.day = today.AddDate(0, 0, amount)
+	case "week":
+		p.day = today.AddDate(0, 0, 7*amount)
+	case "month":
+		p.day = today.AddDate(0, amount, 0)
+	}
+	return n + 1
+}
+
+// absolute 2026-10-25, 25.10.2026 и 25.10; дата без года ближайшая, не раньше сегодняшней.
+// В короткой форме месяц всегда из двух цифр, иначе «Release 2.1» читалось бы как 2 января.
+func (p *parser) absolute(key string) (time.Time, bool) {
+	loc := p.now.Location()
+	if day, err := time.ParseInLocation("2006-01-02", key, loc); err == nil {
+		return day, true
+	}
+	if day, err := time.ParseInLocation("2.1.2006", key, loc); err == nil {
+		return day, true
+	}
+	dd, mm, ok := strings.Cut(key, ".")
+	if !ok || !digits(dd) || len(dd) > 2 || !digits(mm) || len(mm) != 2 {
+		return time.Time{}, false
+	}
+	today := p.today()
+	day, err := time.ParseInLocation("2.1.2006", key+"."+strconv.Itoa(today.Year()), loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if day.Before(today) {
+		day = day.AddDate(1, 0, 0)
+	}
+	return day, true
+}
+
+// matchClock «15:00», «at 9:30», «в 15:00», «3pm», «3:30pm». Число без двоеточия и am/pm временем не считается.
+func (p *parser) matchClock(words []word) int {
+	n := 0
+	if k := words[0].key; (k == "at" || k == "в") && len(words) > 1 {
+		n = 1
+	}
+	clock, ok := parseClock(words[n].key)
+	if !ok {
+		return 0
+	}
+	p.clock = clock
+	return n + 1
+}
+
+func parseClock(key string) (int, bool) {
+	half := ""
+	for _, suffix := range []string{"am", "pm"} {
+		if rest, ok := strings.CutSuffix(key, suffix); ok {
+			key, half = rest, suffix
+		}
+	}
+
+	hh, mm, hasMinutes := strings.Cut(key, ":")
+	if !hasMinutes && half == "" {
+		return 0, false
+	}
+	if !digits(hh) || len(hh) > 2 || hasMinutes && (!digits(mm) || len(mm) != 2) {
+		return 0, false
+	}
+	h, _ := strconv.Atoi(hh)
+	m, _ := strconv.Atoi(mm)
+	if m > 59 {
+		return 0, false
+	}
+
+	switch half {
+	case "":
+		if h > 23 {
+			return 0, false
+		}
+	default:
+		if h < 1 || h > 12 {
+			return 0, false
+		}
+		h %= 12
+		if half == "pm" {
+			h += 12
+		}
+	}
+	return h*60 + m, true
+}
+
+func digits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
@@ -0,0 +1,177 @@
+// Package quickadd разбор строки быстрого ввода: `Prepare release notes tomorrow 15:00 #release !high @project-web`.
+// Распознанные фрагменты вырезаются из заголовка, остальные слова остаются в нём как есть.
+package quickadd
+
+import (
+	"ecom_test/internal/domain/entity"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Kind вид распознанного фрагмента.
+type Kind string
+
+const (
+	KindDue      Kind = "due"
+	KindTag      Kind = "tag"
+	KindPriority Kind = "priority"
+	KindProject  Kind = "project"
+)
+
+// Token распознанный фрагмент ввода. Start и End номера символов (не байтов) от нуля, End не включается.
+type Token struct {
+	Kind  Kind
+	Text  string
+	Value string
+	Start int
+	End   int
+}
+
+// Result Project имя проекта из @упоминания, найти проект по нему должен вызывающий.
+type Result struct {
+	Title    string
+	Tags     []string
+	Priority entity.Priority
+	Project  string
+	// DueAt в UTC, nil если срок не указан. Срок без времени приходится на полночь дня в зоне now.
+	DueAt  *time.Time
+	Tokens []Token
+}
+
+// word слово ввода, key его вид для сравнения со словарём.
+type word struct {
+	text  string
+	key   string
+	start int
+	end   int
+}
+
+func split(input string) []word {
+	var (
+		words []word
+		start = -1
+		pos   int
+		from  int
+	)
+	flush := func(to, end int) {
+		if start < 0 {
+			return
+		}
+		text := input[from:to]
+		words = append(words, word{text: text, key: keyOf(text), start: start, end: end})
+		start = -1
+	}
+	for i, r := range input {
+		if unicode.IsSpace(r) {
+			flush(i, pos)
+		} else if start < 0 {
+			start, from = pos, i
+		}
+		pos++
+	}
+	flush(len(input), pos)
+	return words
+}
+
+// keyOf нижний регистр, ё как е и без завершающей пунктуации: «завтра,» совпадает с «завтра».
+func keyOf(text string) string {
+	key := strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+	return strings.TrimRightFunc(key, func(r rune) bool { return r == ',' || r == '.' || r == ';' || r == '!' || r == '?' })
+}
+
+// Parse now задаёт «сегодня» и часовой пояс для относительных дат, в тестах передаётся фиксированное время.
+// Из повторяющихся сроков, приоритетов и проектов берётся первый, остальные остаются в заголовке.
+func Parse(input string, now time.Time) Result {
+	runes := []rune(input)
+	words := split(input)
+	used := make([]bool, len(words))
+	p := parser{now: now, clock: -1}
+
+	var res Result
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		var tok *Token
+		n := 1
+		switch {
+		case strings.HasPrefix(w.text, "#"):
+			if tag, ok := tagOf(strings.TrimPrefix(w.key, "#")); ok {
+				res.Tags = append(res.Tags, tag)
+				tok = &Token{Kind: KindTag, Value: tag}
+			}
+		case strings.HasPrefix(w.text, "!"):
+			if prio, ok := priorities[strings.TrimPrefix(w.key, "!")]; ok && res.Priority == entity.PriorityNone {
+				res.Priority = prio
+				tok = &Token{Kind: KindPriority, Value: string(prio)}
+			}
+		case strings.HasPrefix(w.text, "@"):
+			if name := strings.TrimPrefix(w.key, "@"); name != "" && res.Project == "" {
+				res.Project = name
+				tok = &Token{Kind: KindProject, Value: name}
+			}
+		default:
+			if n = p.match(words[i:]); n > 0 {
+				tok = &Token{Kind: KindDue}
+			}
+		}
+		if tok == nil {
+			continue
+		}
+
+		last := words[i+n-1]
+		tok.Text = string(runes[w.start:last.end])
+		tok.Start, tok.End = w.start, last.end
+		res.Tokens = append(res.Tokens, *tok)
+		for j := i; j < i+n; j++ {
+			used[j] = true
+		}
+		i += n - 1
+	}
+
+	title := make([]string, 0, len(words))
+	for i, w := range words {
+		if !used[i] {
+			title = append(title, w.text)
+		}
+	}
+	res.Title = strings.Join(title, " ")
+
+	if due, ok := p.due(); ok {
+		res.DueAt = &due
+		for i := range res.Tokens {
+			if res.Tokens[i].Kind == KindDue {
+				res.Tokens[i].Value = due.Format(time.RFC3339)
+			}
+		}
+	}
+	return res
+}
+
+// tagOf метка должна содержать хотя бы одну букву, чтобы «#123» оставался ссылкой на задачу в заголовке.
+func tagOf(key string) (string, bool) {
+	letter := false
+	for _, r := range key {
+		switch {
+		case unicode.IsLetter(r):
+			letter = true
+		case unicode.IsDigit(r) || r == '-' || r == '_':
+		default:
+			return "", false
+		}
+	}
+	return key, letter
+}
+
+// priorities !1 самый высокий, как принято в менеджерах задач.
+var priorities = map[string]entity.Priority{ //nolint:gochecknoglobals
+	"high":    entity.PriorityHigh,
+	"medium":  entity.PriorityMedium,
+	"med":     entity.PriorityMedium,
+	"low":     entity.PriorityLow,
+	"высокий": entity.PriorityHigh,
+	"средний": entity.PriorityMedium,
+	"низкий":  entity.PriorityLow,
+	"1":       entity.PriorityHigh,
+	"2":       entity.PriorityMedium,
+	"3":       entity.PriorityLow,
+}
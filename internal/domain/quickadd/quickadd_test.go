@@ -0,0 +1,110 @@
+package quickadd
+
+import (
+	"ecom_test/internal/domain/entity"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	// понедельник
+	now := time.Date(2026, 10, 19, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		input    string
+		title    string
+		tags     []string
+		priority entity.Priority
+		project  string
+		due      string
+	}{
+		{
+			input:    "Prepare release notes tomorrow 15:00 #release !high @project-web",
+			title:    "Prepare release notes",
+			tags:     []string{"release"},
+			priority: entity.PriorityHigh,
+			project:  "project-web",
+			due:      "2026-10-20T15:00:00Z",
+		},
+		{input: "Buy milk", title: "Buy milk"},
+		{input: "Позвонить маме завтра в 9:00", title: "Позвонить маме", due: "2026-10-20T09:00:00Z"},
+		{input: "Отчёт послезавтра", title: "Отчёт", due: "2026-10-21T00:00:00Z"},
+		{input: "Demo day after tomorrow at 3pm", title: "Demo", due: "2026-10-21T15:00:00Z"},
+		{input: "Review next monday", title: "Review", due: "2026-10-26T00:00:00Z"},
+		{input: "Review friday", title: "Review", due: "2026-10-23T00:00:00Z"},
+		{input: "Ревью в следующую пятницу", title: "Ревью", due: "2026-10-23T00:00:00Z"},
+		{input: "Отпуск через 3 дня", title: "Отпуск", due: "2026-10-22T00:00:00Z"},
+		{input: "Отпуск через неделю", title: "Отпуск", due: "2026-10-26T00:00:00Z"},
+		{input: "Ship in 2 weeks", title: "Ship", due: "2026-11-02T00:00:00Z"},
+		{input: "Call back in 2 hours", title: "Call back", due: "2026-10-19T12:30:00Z"},
+		{input: "Plan on the next week", title: "Plan on the", due: "2026-10-26T00:00:00Z"},
+		{input: "Stand-up 9:00", title: "Stand-up", due: "2026-10-20T09:00:00Z"},
+		{input: "Lunch 12:30", title: "Lunch", due: "2026-10-19T12:30:00Z"},
+		{input: "15:00 сегодня созвон", title: "созвон", due: "2026-10-19T15:00:00Z"},
+		{input: "Налоги 25.04", title: "Налоги", due: "2027-04-25T00:00:00Z"},
+		{input: "Отчёт 5.11.", title: "Отчёт", due: "2026-11-05T00:00:00Z"},
+		{input: "Release 2.1 notes", title: "Release 2.1 notes"},
+		{input: "Release 2.10.1 notes", title: "Release 2.10.1 notes"},
+		{input: "Upgrade to 1.13", title: "Upgrade to 1.13"},
+		{input: "Bump v1.10", title: "Bump v1.10"},
+		{input: "Conference 2026-11-05 #travel #conf", title: "Conference", tags: []string{"travel", "conf"}, due: "2026-11-05T00:00:00Z"},
+		{input: "Fix bug #123 !low !high", title: "Fix bug #123 !high", priority: entity.PriorityLow},
+		{input: "Log in to portal", title: "Log in to portal"},
+		{input: "Meet at 25:00 in 0 days", title: "Meet at 25:00 in 0 days"},
+		{input: "Задача !высокий #бэкенд", title: "Задача", tags: []string{"бэкенд"}, priority: entity.PriorityHigh},
+		{input: "Write tests tomorrow, then deploy", title: "Write tests then deploy", due: "2026-10-20T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := Parse(tt.input, now)
+			if got.Title != tt.title {
+				t.Errorf("Title = %q, want %q", got.Title, tt.title)
+			}
+			if !slices.Equal(got.Tags, tt.tags) {
+				t.Errorf("Tags = %v, want %v", got.Tags, tt.tags)
+			}
+			if got.Priority != tt.priority {
+				t.Errorf("Priority = %q, want %q", got.Priority, tt.priority)
+			}
+			if got.Project != tt.project {
+				t.Errorf("Project = %q, want %q", got.Project, tt.project)
+			}
+			due := ""
+			if got.DueAt != nil {
+				due = got.DueAt.Format(time.RFC3339)
+			}
+			if due != tt.due {
+				t.Errorf("DueAt = %q, want %q", due, tt.due)
+			}
+		})
+	}
+}
+
+func TestParse_Tokens(t *testing.T) {
+	now := time.Date(2026, 10, 19, 10, 30, 0, 0, time.UTC)
+	got := Parse("Созвон завтра в 15:00 #команда", now)
+
+	want := []Token{
+		{Kind: KindDue, Text: "завтра", Value: "2026-10-20T15:00:00Z", Start: 7, End: 13},
+		{Kind: KindDue, Text: "в 15:00", Value: "2026-10-20T15:00:00Z", Start: 14, End: 21},
+		{Kind: KindTag, Text: "#команда", Value: "команда", Start: 22, End: 30},
+	}
+	if !slices.Equal(got.Tokens, want) {
+		t.Errorf("Tokens = %+v, want %+v", got.Tokens, want)
+	}
+}
+
+func TestParse_Location(t *testing.T) {
+	// 23:30 по Москве уже следующий день по UTC, «завтра» считается от местной даты
+	msk := time.FixedZone("MSK", 3*60*60)
+	now := time.Date(2026, 10, 19, 23, 30, 0, 0, msk)
+
+	got := Parse("Report tomorrow 10:00", now)
+	if got.DueAt == nil || !got.DueAt.Equal(time.Date(2026, 10, 20, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("DueAt = %v, want 2026-10-20T07:00:00Z", got.DueAt)
+	}
+	if got.DueAt.Location() != time.UTC {
+		t.Errorf("DueAt must be in UTC, got %v", got.DueAt.Location())
+	}
+}
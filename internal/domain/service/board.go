@@ -0,0 +1,444 @@
+package service
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/domain/policy"
+	"ecom_test/pkg/tracing"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+type BoardRepository interface {
+	CreateBoard(ctx context.Context, board *entity.Board) (int, error)
+	GetBoard(ctx context.Context, id int) (*entity.Board, error)
+	ListBoards(ctx context.Context, ownerID string) ([]entity.Board, error)
+	UpdateBoard(ctx context.Context, board *entity.Board) error
+	DeleteBoard(ctx context.Context, id int) error
+}
+
+// WithBoards включает проверку WIP-лимитов досок при смене статуса задачи.
+func WithBoards(boards BoardRepository) Option {
+	return func(s *TaskService) {
+		s.boards = boards
+	}
+}
+
+// DefaultColumns колонки доски, созданной без явного списка.
+func DefaultColumns() []entity.Column {
+	return []entity.Column{
+		{Name: "To do", Status: entity.StatusTodo},
+		{Name: "In progress", Status: entity.StatusInProgress},
+		{Name: "Review", Status: entity.StatusReview},
+		{Name: "Done", Status: entity.StatusDone},
+	}
+}
+
+// Transition переводит задачу в статус по правилам entity.StatusTransitions с учётом WIP-лимитов.
+func (s *TaskService) Transition(ctx context.Context, id int, status entity.TaskStatus) (_ *entity.Task, err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.Transition")
+	defer func() { span.EndWithError(err) }()
+	span.SetAttribute("task.id", id)
+
+	if !status.Valid() {
+		return nil, domain.Wrap(domain.ErrInvalidStatus, "Transition", id)
+	}
+	task, err := s.authorize(ctx, id, policy.ActionEdit)
+	if err != nil {
+		return nil, domain.Wrap(err, "Transition", id)
+	}
+
+	from := task.CurrentStatus()
+	if from == status {
+		return task, nil
+	}
+	if !from.CanTransition(status) {
+		return nil, domain.Wrap(fmt.Errorf("%w: %s -> %s", domain.ErrInvalidTransition, from, status), "Transition", id)
+	}
+	guard, err := s.wipGuard(ctx, task, status)
+	if err != nil {
+		return nil, domain.Wrap(err, "Transition", id)
+	}
+
+	s.setStatus(task, status)
+	if err := s.updateGuarded(ctx, task, guard); err != nil {
+		return nil, domain.Wrap(err, "Transition", id)
+	}
+	logger(ctx).Info("task status changed", slog.Int("task_id", id),
+		slog.String("from", string(from)), slog.String("to", string(status)))
+	return task, nil
+}
+
+func (s *TaskService) setStatus(task *entity.Task, status entity.TaskStatus) {
+	task.Status = status
+	task.StatusChangedAt = s.now().UTC()
+	task.IsCompleted = status == entity.StatusDone
+}
+
+// unsavedID у новой задачи до записи, чтобы проверка WIP не приняла её за сохранённую задачу с ID 0.
+const unsavedID = -1
+
+// GuardedWriter хранилище, которое проверяет задачи владельца под тем же локом, что и запись,
+// поэтому параллельные запросы не могут вместе превысить WIP-лимит.
+type GuardedWriter interface {
+	CreateIf(ctx context.Context, task *entity.Task, check func(owned []entity.Task) error) (int, error)
+	UpdateIf(ctx context.Context, task *entity.Task, check func(owned []entity.Task) error) error
+	DeleteIf(ctx context.Context, id int, check func(owned []entity.Task) error) error
+}
+
+// wipGuard задача не может войти в колонку, которая уже заполнена до лимита на любой из досок
+// владельца, куда задача попадает. Возвращает проверку по задачам владельца, nil если ни одна
+// колонка с лимитом задачу не принимает. pending ещё не сохранённые задачи той же пачки импорта.
+func (s *TaskService) wipGuard(ctx context.Context, task *entity.Task, status entity.TaskStatus, pending ...entity.Task) (func([]entity.Task) error, error) {
+	if s.boards == nil {
+		return nil, nil
+	}
+	boards, err := s.boards.ListBoards(ctx, task.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	boards = slices.DeleteFunc(boards, func(board entity.Board) bool {
+		column, ok := board.Column(status)
+		return !ok || column.WIPLimit == 0 || !board.Covers(*task)
+	})
+	if len(boards) == 0 {
+		return nil, nil
+	}
+
+	id := task.ID
+	return func(owned []entity.Task) error {
+		for _, board := range boards {
+			column, _ := board.Column(status)
+			count := 0
+			for _, t := range owned {
+				if t.ID != id && t.CurrentStatus() == status && board.Covers(t) {
+					count++
+				}
+			}
+			for _, t := range pending {
+				if t.CurrentStatus() == status && board.Covers(t) {
+					count++
+				}
+			}
+			if count >= column.WIPLimit {
+				return fmt.Errorf("%w: board %d column %q allows %d", domain.ErrWIPLimitExceeded, board.ID, column.Name, column.WIPLimit)
+			}
+		}
+		return nil
+	}, nil
+}
+
+// createGuarded создаёт задачу; guard выполняется атомарно с записью, если хранилище это умеет.
+func (s *TaskService) createGuarded(ctx context.Context, task *entity.Task, guard func([]entity.Task) error) (int, error) {
+	if guard == nil {
+		return s.repo.Create(ctx, task)
+	}
+	if w, ok := s.repo.(GuardedWriter); ok {
+		return w.CreateIf(ctx, task, guard)
+	}
+	if err := s.applyGuard(ctx, task.OwnerID, guard); err != nil {
+		return 0, err
+	}
+	return s.repo.Create(ctx, task)
+}
+
+// updateGuarded как createGuarded для существующей задачи.
+func (s *TaskService) updateGuarded(ctx context.Context, task *entity.Task, guard func([]entity.Task) error) error {
+	if guard == nil {
+		return s.repo.Update(ctx, task)
+	}
+	if w, ok := s.repo.(GuardedWriter); ok {
+		return w.UpdateIf(ctx, task, guard)
+	}
+	if err := s.applyGuard(ctx, task.OwnerID, guard); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, task)
+}
+
+func (s *TaskService) applyGuard(ctx context.Context, ownerID string, guard func([]entity.Task) error) error {
+	owned, err := s.repo.GetAllByOwner(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	return guard(owned)
+}
+
+type BoardService struct {
+	repo  BoardRepository
+	tasks *TaskService
+}
+
+// NewBoardService доска читает задачи и переводит карточки через TaskService,
+// поэтому правила переходов и WIP-лимиты у них общие.
+func NewBoardService(repo BoardRepository, tasks *TaskService) *BoardService {
+	return &BoardService{
+		repo:  repo,
+		tasks: tasks,
+	}
+}
+
+// BoardView колонки доски с карточками в ручном порядке.
+type BoardView struct {
+	Board   entity.Board
+	Columns []ColumnView
+}
+
+type ColumnView struct {
+	Column entity.Column
+	Tasks  []entity.Task
+}
+
+type BoardStats struct {
+	Board   entity.Board
+	Total   int
+	Columns []ColumnStats
+}
+
+// ColumnStats возраст считается от последней смены статуса карточки.
+type ColumnStats struct {
+	Column    entity.Column
+	Count     int
+	OverLimit bool
+	AvgAge    time.Duration
+	MaxAge    time.Duration
+}
+
+func (s *BoardService) validate(ctx context.Context, board *entity.Board) error {
+	board.Name = strings.TrimSpace(board.Name)
+	if board.Name == "" {
+		return fmt.Errorf("%w: name is required", domain.ErrInvalidBoard)
+	}
+	if len(board.Columns) == 0 {
+		board.Columns = DefaultColumns()
+	}
+
+	seen := make(map[entity.TaskStatus]struct{}, len(board.Columns))
+	for i, c := range board.Columns {
+		board.Columns[i].Name = strings.TrimSpace(c.Name)
+		switch {
+		case board.Columns[i].Name == "":
+			return fmt.Errorf("%w: column %d has no name", domain.ErrInvalidBoard, i)
+		case !c.Status.Valid():
+			return fmt.Errorf("%w: column %q has unknown status %q", domain.ErrInvalidBoard, c.Name, c.Status)
+		case c.WIPLimit < 0:
+			return fmt.Errorf("%w: column %q has negative WIP limit", domain.ErrInvalidBoard, c.Name)
+		}
+		if _, ok := seen[c.Status]; ok {
+			return fmt.Errorf("%w: status %q is mapped to several columns", domain.ErrInvalidBoard, c.Status)
+		}
+		seen[c.Status] = struct{}{}
+	}
+
+	if board.ProjectID != nil {
+		if err := s.tasks.checkProject(ctx, *board.ProjectID, board.OwnerID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ownedBoard доски не расшариваются, чужая доска неотличима от несуществующей.
+func (s *BoardService) ownedBoard(ctx context.Context, id int) (*entity.Board, error) {
+	board, err := s.repo.GetBoard(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if owner, ok := caller(ctx); ok && board.OwnerID != owner {
+		return nil, domain.ErrBoardNotFound
+	}
+	return board, nil
+}
+
+func (s *BoardService) Create(ctx context.Context, board *entity.Board) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "BoardService.Create")
+	defer func() { span.EndWithError(err) }()
+
+	if owner, ok := caller(ctx); ok {
+		board.OwnerID = owner
+	}
+	if err := s.validate(ctx, board); err != nil {
+		return 0, fmt.Errorf("board: %w", err)
+	}
+	board.CreatedAt = s.tasks.now().UTC()
+
+	id, err := s.repo.CreateBoard(ctx, board)
+	if err != nil {
+		return 0, fmt.Errorf("board: %w", err)
+	}
+	logger(ctx).Info("board created", slog.Int("board_id", id))
+	return id, nil
+}
+
+func (s *BoardService) Get(ctx context.Context, id int) (_ *entity.Board, err error) {
+	ctx, span := tracing.Start(ctx, "BoardService.Get")
+	defer func() { span.EndWithError(err) }()
+
+	board, err := s.ownedBoard(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("board %d: %w", id, err)
+	}
+	return board, nil
+}
+
+func (s *BoardService) List(ctx context.Context) (_ []entity.Board, err error) {
+	ctx, span := tracing.Start(ctx, "BoardService.List")
+	defer func() { span.EndWithError(err) }()
+
+	owner, _ := caller(ctx)
+	boards, err := s.repo.ListBoards(ctx, owner)
+	if err != nil {
+		return nil, fmt.Errorf("board: %w", err)
+	}
+	return boards, nil
+}
+
+// Update меняет название и колонки, владелец и проект доски остаются прежними.
+func (s *BoardService) Update(ctx context.Context, board *entity.Board) (err error) {
+	ctx, span := tracing.Start(ctx, "BoardService.Update")
+	defer func() { span.EndWithError(err) }()
+
+	existing, err := s.ownedBoard(ctx, board.ID)
+	if err != nil {
+		return fmt.Errorf("board %d: %w", board.ID, err)
+	}
+	board.OwnerID = existing.OwnerID
+	board.ProjectID = existing.ProjectID
+	board.CreatedAt = existing.CreatedAt
+	if err := s.validate(ctx, board); err != nil {
+		return fmt.Errorf("board %d: %w", board.ID, err)
+	}
+
+	if err := s.repo.UpdateBoard(ctx, board); err != nil {
+		return fmt.Errorf("board %d: %w", board.ID, err)
+	}
+	logger(ctx).Info("board updated", slog.Int("board_id", board.ID))
+	return nil
+}
+
+func (s *BoardService) Delete(ctx context.Context, id int) (err error) {
+	ctx, span := tracing.Start(ctx, "BoardService.Delete")
+	defer func() { span.EndWithError(err) }()
+
+	if _, err := s.ownedBoard(ctx, id); err != nil {
+		return fmt.Errorf("board %d: %w", id, err)
+	}
+	if err := s.repo.DeleteBoard(ctx, id); err != nil {
+		return fmt.Errorf("board %d: %w", id, err)
+	}
+	logger(ctx).Info("board deleted", slog.Int("board_id", id))
+	return nil
+}
+
+// cards задачи доски по колонкам, задачи со статусом без колонки на доску не попадают.
+func (s *BoardService) cards(ctx context.Context, board *entity.Board) ([][]entity.Task, error) {
+	tasks, err := s.tasks.repo.GetAllByOwner(ctx, board.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	sortTasks(tasks, SortManual)
+
+	columns := make([][]entity.Task, len(board.Columns))
+	for _, t := range tasks {
+		if !board.Covers(t) {
+			continue
+		}
+		i := slices.IndexFunc(board.Columns, func(c entity.Column) bool { return c.Status == t.CurrentStatus() })
+		if i >= 0 {
+			columns[i] = append(columns[i], t)
+		}
+	}
+	return columns, nil
+}
+
+func (s *BoardService) View(ctx context.Context, id int) (_ *BoardView, err error) {
+	ctx, span := tracing.Start(ctx, "BoardService.View")
+	defer func() { span.EndWithError(err) }()
+
+	board, err := s.ownedBoard(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("board %d: %w", id, err)
+	}
+	cards, err := s.cards(ctx, board)
+	if err != nil {
+		return nil, fmt.Errorf("board %d: %w", id, err)
+	}
+
+	view := &BoardView{Board: *board, Columns: make([]ColumnView, len(board.Columns))}
+	for i, c := range board.Columns {
+		view.Columns[i] = ColumnView{Column: c, Tasks: cards[i]}
+	}
+	return view, nil
+}
+
+func (s *BoardService) Stats(ctx context.Context, id int) (_ *BoardStats, err error) {
+	ctx, span := tracing.Start(ctx, "BoardService.Stats")
+	defer func() { span.EndWithError(err) }()
+
+	board, err := s.ownedBoard(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("board %d: %w", id, err)
+	}
+	cards, err := s.cards(ctx, board)
+	if err != nil {
+		return nil, fmt.Errorf("board %d: %w", id, err)
+	}
+
+	now := s.tasks.now()
+	stats := &BoardStats{Board: *board, Columns: make([]ColumnStats, len(board.Columns))}
+	for i, c := range board.Columns {
+		cs := ColumnStats{
+			Column:    c,
+			Count:     len(cards[i]),
+			OverLimit: c.WIPLimit > 0 && len(cards[i]) > c.WIPLimit,
+		}
+
+		// у задач, не менявших статус после появления досок, момент входа в колонку неизвестен
+		var total time.Duration
+		aged := 0
+		for _, t := range cards[i] {
+			if t.StatusChangedAt.IsZero() {
+				continue
+			}
+			age := now.Sub(t.StatusChangedAt)
+			total += age
+			cs.MaxAge = max(cs.MaxAge, age)
+			aged++
+		}
+		if aged > 0 {
+			cs.AvgAge = total / time.Duration(aged)
+		}
+
+		stats.Columns[i] = cs
+		stats.Total += cs.Count
+	}
+	return stats, nil
+}
+
+// MoveCard переносит карточку в колонку доски, то есть в статус этой колонки.
+func (s *BoardService) MoveCard(ctx context.Context, id, taskID int, column string) (_ *entity.Task, err error) {
+	ctx, span := tracing.Start(ctx, "BoardService.MoveCard")
+	defer func() { span.EndWithError(err) }()
+
+	board, err := s.ownedBoard(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("board %d: %w", id, err)
+	}
+	i := slices.IndexFunc(board.Columns, func(c entity.Column) bool { return strings.EqualFold(c.Name, column) })
+	if i < 0 {
+		return nil, fmt.Errorf("board %d: %w: unknown column %q", id, domain.ErrInvalidBoard, column)
+	}
+
+	task, err := s.tasks.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("board %d: %w", id, err)
+	}
+	if !board.Covers(*task) {
+		return nil, fmt.Errorf("board %d: %w", id, domain.ErrTaskNotFound)
+	}
+	return s.tasks.Transition(ctx, taskID, board.Columns[i].Status)
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/infrastructure/persistance"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTaskService_Transition(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    []entity.TaskStatus
+		wantErr error
+	}{
+		{name: "Happy path", path: []entity.TaskStatus{entity.StatusInProgress, entity.StatusReview, entity.StatusDone}},
+		{name: "Review straight from todo", path: []entity.TaskStatus{entity.StatusReview}, wantErr: domain.ErrInvalidTransition},
+		{name: "Done is reopened to todo only", path: []entity.TaskStatus{entity.StatusDone, entity.StatusInProgress}, wantErr: domain.ErrInvalidTransition},
+		{name: "Unknown status", path: []entity.TaskStatus{"blocked"}, wantErr: domain.ErrInvalidStatus},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := persistance.NewTaskRepository()
+			tasks := NewTaskService(repo)
+			ctx := as("alice")
+			id, _ := tasks.Create(ctx, &entity.Task{Title: "Card"})
+
+			var err error
+			var task *entity.Task
+			for _, status := range tt.path {
+				if task, err = tasks.Transition(ctx, id, status); err != nil {
+					break
+				}
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Transition() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && task.IsCompleted != (task.Status == entity.StatusDone) {
+				t.Errorf("IsCompleted = %v with status %s", task.IsCompleted, task.Status)
+			}
+		})
+	}
+}
+
+// slowReads растягивает окно между чтением задач владельца и записью.
+type slowReads struct {
+	*persistance.TaskRepository
+}
+
+func (r slowReads) GetAllByOwner(ctx context.Context, ownerID string) ([]entity.Task, error) {
+	tasks, err := r.TaskRepository.GetAllByOwner(ctx, ownerID)
+	time.Sleep(10 * time.Millisecond)
+	return tasks, err
+}
+
+func TestTaskService_TransitionWIPLimitIsAtomic(t *testing.T) {
+	repo := persistance.NewTaskRepository()
+	tasks := NewTaskService(slowReads{repo}, WithBoards(repo))
+	ctx := as("alice")
+	board := &entity.Board{Name: "Sprint", Columns: []entity.Column{
+		{Name: "To do", Status: entity.StatusTodo},
+		{Name: "Doing", Status: entity.StatusInProgress, WIPLimit: 1},
+	}}
+	if _, err := NewBoardService(repo, tasks).Create(ctx, board); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	ids := make([]int, 32)
+	for i := range ids {
+		ids[i], _ = tasks.Create(ctx, &entity.Task{Title: "Card"})
+	}
+	var (
+		wg    sync.WaitGroup
+		moved atomic.Int32
+	)
+	for _, id := range ids {
+		wg.Go(func() {
+			if _, err := tasks.Transition(ctx, id, entity.StatusInProgress); err == nil {
+				moved.Add(1)
+			}
+		})
+	}
+	wg.Wait()
+
+	if got := moved.Load(); got != 1 {
+		t.Errorf("%d concurrent transitions passed a WIP limit of 1", got)
+	}
+}
+
+func TestTaskService_CreateInStatus(t *testing.T) {
+	repo := persistance.NewTaskRepository()
+	tasks := NewTaskService(repo, WithBoards(repo))
+	ctx := as("alice")
+	board := &entity.Board{Name: "Sprint", Columns: []entity.Column{{Name: "Review", Status: entity.StatusReview, WIPLimit: 1}}}
+	_, _ = NewBoardService(repo, tasks).Create(ctx, board)
+
+	id, err := tasks.CreateInStatus(ctx, &entity.Task{Title: "First"}, entity.StatusReview)
+	if err != nil {
+		t.Fatalf("CreateInStatus() error = %v", err)
+	}
+	if got, _ := tasks.GetByID(ctx, id); got.CurrentStatus() != entity.StatusReview {
+		t.Errorf("status = %s, want review", got.CurrentStatus())
+	}
+
+	if _, err := tasks.CreateInStatus(ctx, &entity.Task{Title: "Second"}, entity.StatusReview); !errors.Is(err, domain.ErrWIPLimitExceeded) {
+		t.Errorf("CreateInStatus() into a full column error = %v, want ErrWIPLimitExceeded", err)
+	}
+	if _, err := tasks.CreateInStatus(ctx, &entity.Task{Title: "Third"}, "blocked"); !errors.Is(err, domain.ErrInvalidStatus) {
+		t.Errorf("CreateInStatus() with unknown status error = %v, want ErrInvalidStatus", err)
+	}
+	if all, _ := tasks.List(ctx, ListOptions{}); len(all) != 1 {
+		t.Errorf("rejected creates must not leave tasks behind, got %d tasks", len(all))
+	}
+}
+
+func TestBoardService(t *testing.T) {
+	repo := persistance.NewTaskRepository()
+	tasks := NewTaskService(repo, WithBoards(repo), WithProjects(repo))
+	clock := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	tasks.now = func() time.Time { return clock }
+	boards := NewBoardService(repo, tasks)
+	ctx := as("alice")
+
+	board := &entity.Board{Name: "Sprint", Columns: []entity.Column{
+		{Name: "To do", Status: entity.StatusTodo},
+		{Name: "Doing", Status: entity.StatusInProgress, WIPLimit: 2},
+		{Name: "Done", Status: entity.StatusDone},
+	}}
+	if _, err := boards.Create(ctx, board); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	ids := make([]int, 3)
+	for i := range ids {
+		ids[i], _ = tasks.Create(ctx, &entity.Task{Title: "Card"})
+	}
+
+	clock = clock.Add(time.Hour)
+	if _, err := boards.MoveCard(ctx, board.ID, ids[0], "doing"); err != nil {
+		t.Fatalf("MoveCard() error = %v", err)
+	}
+	clock = clock.Add(time.Hour)
+	if _, err := boards.MoveCard(ctx, board.ID, ids[1], "Doing"); err != nil {
+		t.Fatalf("MoveCard() error = %v", err)
+	}
+	if _, err := boards.MoveCard(ctx, board.ID, ids[2], "Doing"); !errors.Is(err, domain.ErrWIPLimitExceeded) {
+		t.Errorf("MoveCard() over limit error = %v, want ErrWIPLimitExceeded", err)
+	}
+	if _, err := boards.MoveCard(ctx, board.ID, ids[2], "Blocked"); !errors.Is(err, domain.ErrInvalidBoard) {
+		t.Errorf("MoveCard() unknown column error = %v, want ErrInvalidBoard", err)
+	}
+	if _, err := boards.View(as("bob"), board.ID); !errors.Is(err, domain.ErrBoardNotFound) {
+		t.Errorf("View() by stranger error = %v, want ErrBoardNotFound", err)
+	}
+
+	view, err := boards.View(ctx, board.ID)
+	if err != nil {
+		t.Fatalf("View() error = %v", err)
+	}
+	if got := [3]int{len(view.Columns[0].Tasks), len(view.Columns[1].Tasks), len(view.Columns[2].Tasks)}; got != [3]int{1, 2, 0} {
+		t.Errorf("cards per column = %v, want [1 2 0]", got)
+	}
+	if view.Columns[1].Tasks[0].ID != ids[0] {
+		t.Errorf("column order = %v, want manual order", view.Columns[1].Tasks)
+	}
+
+	clock = clock.Add(time.Hour)
+	stats, err := boards.Stats(ctx, board.ID)
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	doing := stats.Columns[1]
+	if stats.Total != 3 || doing.Count != 2 || doing.OverLimit {
+		t.Errorf("Stats() = %+v, want 3 cards with 2 in progress", stats)
+	}
+	if doing.MaxAge != 2*time.Hour || doing.AvgAge != 90*time.Minute {
+		t.Errorf("age in column = avg %v max %v, want avg 1h30m max 2h", doing.AvgAge, doing.MaxAge)
+	}
+
+	invalid := []entity.Board{
+		{Name: ""},
+		{Name: "Dup", Columns: []entity.Column{{Name: "A", Status: entity.StatusTodo}, {Name: "B", Status: entity.StatusTodo}}},
+		{Name: "Neg", Columns: []entity.Column{{Name: "A", Status: entity.StatusTodo, WIPLimit: -1}}},
+		{Name: "Bad", Columns: []entity.Column{{Name: "A", Status: "later"}}},
+	}
+	for _, b := range invalid {
+		if _, err := boards.Create(ctx, &b); !errors.Is(err, domain.ErrInvalidBoard) {
+			t.Errorf("Create(%q) error = %v, want ErrInvalidBoard", b.Name, err)
+		}
+	}
+}
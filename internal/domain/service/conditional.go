@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/domain/policy"
+	"ecom_test/pkg/tracing"
+	"fmt"
+	"log/slog"
+)
+
+// Precondition проверка сохранённой задачи для условных запросов вроде If-Match в CalDAV.
+// Выполняется под тем же локом, что и запись, поэтому из двух клиентов, прочитавших одну версию,
+// записать сможет только один. Несовпадение обозначается domain.ErrPreconditionFailed.
+type Precondition func(current entity.Task) error
+
+// ReplaceIf заменяет поля задачи и статус одной записью, если сохранённая задача проходит cond.
+// Статус меняется, если в него можно попасть цепочкой допустимых переходов, WIP-лимит проверяется
+// для нового статуса. Владелец, проект, ключ сортировки и идентификаторы CalDAV не меняются.
+func (s *TaskService) ReplaceIf(ctx context.Context, task *entity.Task, status entity.TaskStatus, cond Precondition) (err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.ReplaceIf")
+	defer func() { span.EndWithError(err) }()
+
+	if task == nil {
+		return domain.Wrap(domain.ErrEmptyTask, "ReplaceIf", 0)
+	}
+	span.SetAttribute("task.id", task.ID)
+	if task.Title == "" {
+		return domain.Wrap(domain.ErrEmptyTitle, "ReplaceIf", task.ID)
+	}
+	if !status.Valid() {
+		return domain.Wrap(fmt.Errorf("%w: %q", domain.ErrInvalidStatus, status), "ReplaceIf", task.ID)
+	}
+	if err := normalize(task); err != nil {
+		return domain.Wrap(err, "ReplaceIf", task.ID)
+	}
+	existing, err := s.authorize(ctx, task.ID, policy.ActionEdit)
+	if err != nil {
+		return domain.Wrap(err, "ReplaceIf", task.ID)
+	}
+	task.OwnerID = existing.OwnerID
+	task.ProjectID = existing.ProjectID
+	task.Rank = existing.Rank
+	task.Status = existing.Status
+	task.StatusChangedAt = existing.StatusChangedAt
+	task.UID = existing.UID
+	task.Resource = existing.Resource
+	task.IsCompleted = existing.IsCompleted
+
+	var guards []func([]entity.Task) error
+	if cond != nil {
+		guards = append(guards, currentGuard(task.ID, cond))
+	}
+	if from := existing.CurrentStatus(); from != status {
+		if !from.CanReach(status) {
+			return domain.Wrap(fmt.Errorf("%w: %s -> %s", domain.ErrInvalidTransition, from, status), "ReplaceIf", task.ID)
+		}
+		wip, err := s.wipGuard(ctx, existing, status)
+		if err != nil {
+			return domain.Wrap(err, "ReplaceIf", task.ID)
+		}
+		if wip != nil {
+			guards = append(guards, wip)
+		}
+		s.setStatus(task, status)
+	}
+
+	if err := s.updateGuarded(ctx, task, allGuards(guards)); err != nil {
+		return domain.Wrap(err, "ReplaceIf", task.ID)
+	}
+	logger(ctx).Info("task replaced", slog.Int("task_id", task.ID))
+	return nil
+}
+
+// DeleteIf как Delete, но задача удаляется, только если сохранённая версия проходит cond.
+func (s *TaskService) DeleteIf(ctx context.Context, id int, cond Precondition) (err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.DeleteIf")
+	defer func() { span.EndWithError(err) }()
+	span.SetAttribute("task.id", id)
+
+	if id < 0 {
+		return domain.Wrap(domain.ErrInvalidID, "DeleteIf", id)
+	}
+	existing, err := s.authorize(ctx, id, policy.ActionDelete)
+	if err != nil {
+		return domain.Wrap(err, "DeleteIf", id)
+	}
+
+	var guard func([]entity.Task) error
+	if cond != nil {
+		guard = currentGuard(id, cond)
+	}
+	switch w, ok := s.repo.(GuardedWriter); {
+	case guard == nil:
+		err = s.repo.Delete(ctx, id)
+	case ok:
+		err = w.DeleteIf(ctx, id, guard)
+	default:
+		if err = s.applyGuard(ctx, existing.OwnerID, guard); err == nil {
+			err = s.repo.Delete(ctx, id)
+		}
+	}
+	if err != nil {
+		return domain.Wrap(err, "DeleteIf", id)
+	}
+	logger(ctx).Info("task deleted", slog.Int("task_id", id))
+	return nil
+}
+
+// currentGuard находит задачу id среди задач владельца и проверяет её cond.
+func currentGuard(id int, cond Precondition) func([]entity.Task) error {
+	return func(owned []entity.Task) error {
+		for _, t := range owned {
+			if t.ID == id {
+				return cond(t)
+			}
+		}
+		return domain.ErrTaskNotFound
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/infrastructure/persistance"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// titled условие «задача всё ещё с заголовком title», как If-Match с прочитанным ETag.
+func titled(title string) Precondition {
+	return func(current entity.Task) error {
+		if current.Title != title {
+			return domain.ErrPreconditionFailed
+		}
+		return nil
+	}
+}
+
+func TestTaskService_ReplaceIf(t *testing.T) {
+	repo := persistance.NewTaskRepository()
+	tasks := NewTaskService(slowReads{repo}, WithBoards(repo))
+	ctx := as("alice")
+	id, _ := tasks.Create(ctx, &entity.Task{Title: "v0"})
+
+	var (
+		wg     sync.WaitGroup
+		passed atomic.Int32
+	)
+	for i := range 8 {
+		wg.Go(func() {
+			err := tasks.ReplaceIf(ctx, &entity.Task{ID: id, Title: "v" + strconv.Itoa(i+1)}, entity.StatusTodo, titled("v0"))
+			switch {
+			case err == nil:
+				passed.Add(1)
+			case !errors.Is(err, domain.ErrPreconditionFailed):
+				t.Errorf("ReplaceIf() error = %v", err)
+			}
+		})
+	}
+	wg.Wait()
+	if passed.Load() != 1 {
+		t.Fatalf("%d writers with the same expected version succeeded, want 1", passed.Load())
+	}
+
+	board := &entity.Board{Name: "Sprint", Columns: []entity.Column{{Name: "Review", Status: entity.StatusReview, WIPLimit: 1}}}
+	_, _ = NewBoardService(repo, tasks).Create(ctx, board)
+	other, _ := tasks.Create(ctx, &entity.Task{Title: "other"})
+	if _, err := tasks.Transition(ctx, other, entity.StatusInProgress); err != nil {
+		t.Fatalf("Transition() error = %v", err)
+	}
+	if _, err := tasks.Transition(ctx, other, entity.StatusReview); err != nil {
+		t.Fatalf("Transition() error = %v", err)
+	}
+
+	current, _ := tasks.GetByID(ctx, id)
+	err := tasks.ReplaceIf(ctx, &entity.Task{ID: id, Title: "Renamed"}, entity.StatusReview, titled(current.Title))
+	if !errors.Is(err, domain.ErrWIPLimitExceeded) {
+		t.Fatalf("ReplaceIf() into a full column error = %v, want ErrWIPLimitExceeded", err)
+	}
+	if got, _ := tasks.GetByID(ctx, id); got.Title != current.Title || got.CurrentStatus() != entity.StatusTodo {
+		t.Errorf("rejected ReplaceIf() changed the task: %+v", got)
+	}
+	if err := tasks.ReplaceIf(ctx, &entity.Task{ID: id, Title: "Done"}, entity.StatusDone, titled(current.Title)); err != nil {
+		t.Fatalf("ReplaceIf() error = %v", err)
+	}
+	if got, _ := tasks.GetByID(ctx, id); got.Title != "Done" || !got.IsCompleted {
+		t.Errorf("ReplaceIf() must write fields and status together, got %+v", got)
+	}
+
+	if err := tasks.DeleteIf(ctx, id, titled("v0")); !errors.Is(err, domain.ErrPreconditionFailed) {
+		t.Errorf("DeleteIf() with a stale version error = %v, want ErrPreconditionFailed", err)
+	}
+	if err := tasks.DeleteIf(ctx, id, titled("Done")); err != nil {
+		t.Errorf("DeleteIf() error = %v", err)
+	}
+}
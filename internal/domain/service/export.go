@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/domain/query"
+	"ecom_test/pkg/tracing"
+	"fmt"
+)
+
+// exportPageSize сколько задач читается из хранилища за раз при выгрузке.
+const exportPageSize = 500
+
+// Export передаёт задачи вызывающего в fn по возрастанию ID, читая хранилище страницами, поэтому
+// выборка целиком в памяти не собирается. project имя проекта задачи, пустое у задачи вне проекта.
+// Query и IncludeArchived как у List, Sort не используется. Ошибки запроса возвращаются до первого
+// вызова fn, ошибка fn прерывает выгрузку и возвращается как есть.
+func (s *TaskService) Export(ctx context.Context, opts ListOptions, fn func(task entity.Task, project string) error) (err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.Export")
+	defer func() { span.EndWithError(err) }()
+
+	match := func(entity.Task) bool { return true }
+	if opts.Query != "" {
+		compiled, qerr := query.ParseAndCompile(opts.Query, s.now())
+		if qerr != nil {
+			return domain.Wrap(fmt.Errorf("%w: %w", domain.ErrInvalidQuery, qerr), "Export", 0)
+		}
+		match = compiled
+	}
+
+	owner, _ := caller(ctx)
+	names, hidden, err := s.projectNames(ctx, owner, opts.IncludeArchived)
+	if err != nil {
+		return domain.Wrap(err, "Export", 0)
+	}
+	visible := func(t entity.Task) bool {
+		return !hidden(t) && match(t)
+	}
+
+	exported := 0
+	defer func() { span.SetAttribute("export.tasks", exported) }()
+	for after := -1; ; {
+		page, err := s.repo.FindPage(ctx, owner, after, exportPageSize, visible)
+		if err != nil {
+			return domain.Wrap(err, "Export", 0)
+		}
+		for _, task := range page {
+			project := ""
+			if task.ProjectID != nil {
+				project = names[*task.ProjectID]
+			}
+			if err := fn(task, project); err != nil {
+				return err
+			}
+			exported++
+		}
+		if len(page) < exportPageSize {
+			return nil
+		}
+		after = page[len(page)-1].ID
+	}
+}
+
+// projectNames имена проектов владельца по ID и проверка, скрыта ли задача как лежащая в архивном
+// проекте. С includeArchived скрытых нет.
+func (s *TaskService) projectNames(ctx context.Context, owner string, includeArchived bool) (map[int]string, func(entity.Task) bool, error) {
+	names := make(map[int]string)
+	archived := make(map[int]struct{})
+	if s.projects != nil {
+		projects, err := s.projects.ListProjects(ctx, owner)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, p := range projects {
+			names[p.ID] = p.Name
+			if p.Archived() && !includeArchived {
+				archived[p.ID] = struct{}{}
+			}
+		}
+	}
+	hidden := func(t entity.Task) bool {
+		if t.ProjectID == nil {
+			return false
+		}
+		_, ok := archived[*t.ProjectID]
+		return ok
+	}
+	return names, hidden, nil
+}
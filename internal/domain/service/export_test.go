@@ -0,0 +1,83 @@
+package service
+
+import (
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/infrastructure/persistance"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestTaskService_Export(t *testing.T) {
+	repo := persistance.NewTaskRepository()
+	tasks := NewTaskService(repo, WithProjects(repo))
+	projects := NewProjectService(repo)
+	alice := as("alice")
+
+	home := &entity.Project{Name: "Home"}
+	old := &entity.Project{Name: "Old"}
+	_, _ = projects.Create(alice, home)
+	_, _ = projects.Create(alice, old)
+	_, _ = tasks.Create(as("bob"), &entity.Task{Title: "Bob's"})
+	total := exportPageSize*2 + 1
+	for i := range total {
+		task := &entity.Task{Title: fmt.Sprintf("Task %d", i)}
+		if i%100 == 0 {
+			task.ProjectID = &home.ID
+			task.Tags = []string{"hundred"}
+		}
+		_, _ = tasks.Create(alice, task)
+	}
+	_, _ = tasks.Create(alice, &entity.Task{Title: "Archived", ProjectID: &old.ID})
+	_, _ = projects.SetArchived(alice, old.ID, true)
+
+	collect := func(opts ListOptions) ([]string, map[string]string, error) {
+		var titles []string
+		projectOf := make(map[string]string)
+		err := tasks.Export(alice, opts, func(task entity.Task, project string) error {
+			if task.OwnerID != "alice" {
+				t.Errorf("exported someone else's task %+v", task)
+			}
+			if len(titles) > 0 && task.Title == titles[len(titles)-1] {
+				t.Errorf("task %q exported twice", task.Title)
+			}
+			titles = append(titles, task.Title)
+			projectOf[task.Title] = project
+			return nil
+		})
+		return titles, projectOf, err
+	}
+
+	titles, projectOf, err := collect(ListOptions{})
+	if err != nil || len(titles) != total {
+		t.Fatalf("Export() = %d tasks, %v, want %d", len(titles), err, total)
+	}
+	if titles[0] != "Task 0" || titles[total-1] != fmt.Sprintf("Task %d", total-1) || projectOf["Task 0"] != "Home" {
+		t.Errorf("unexpected export order or project: first %q, last %q, project %q", titles[0], titles[total-1], projectOf["Task 0"])
+	}
+
+	titles, projectOf, _ = collect(ListOptions{IncludeArchived: true})
+	if len(titles) != total+1 || projectOf["Archived"] != "Old" {
+		t.Errorf("IncludeArchived exported %d tasks, want %d with the archived one", len(titles), total+1)
+	}
+
+	titles, _, _ = collect(ListOptions{Query: "tag:hundred"})
+	if len(titles) != total/100+1 {
+		t.Errorf("filtered export = %d tasks, want %d", len(titles), total/100+1)
+	}
+
+	if _, _, err := collect(ListOptions{Query: "due:<"}); !errors.Is(err, domain.ErrInvalidQuery) {
+		t.Errorf("Export() with broken query error = %v, want ErrInvalidQuery", err)
+	}
+
+	stop := errors.New("client gone")
+	calls := 0
+	err = tasks.Export(alice, ListOptions{}, func(entity.Task, string) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Export() = %v after %d calls, want the callback error after one", err, calls)
+	}
+}
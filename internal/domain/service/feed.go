@@ -0,0 +1,175 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/pkg/tracing"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+type FeedRepository interface {
+	SaveFeed(ctx context.Context, feed *entity.Feed) error
+	GetFeed(ctx context.Context, ownerID string) (*entity.Feed, error)
+	FeedByToken(ctx context.Context, tokenHash string) (*entity.Feed, error)
+	TouchFeed(ctx context.Context, tokenHash, etag string, modifiedAt time.Time) error
+	DeleteFeed(ctx context.Context, ownerID string) error
+}
+
+// feedTokenPrefix отличает токен ленты от API-ключа: токен ленты даёт только чтение сроков.
+const feedTokenPrefix = "cal_"
+
+type FeedService struct {
+	repo  FeedRepository
+	tasks *TaskService
+}
+
+// NewFeedService задачи ленты читаются из хранилища TaskService напрямую: у запроса ленты нет
+// Principal, владельца определяет токен.
+func NewFeedService(repo FeedRepository, tasks *TaskService) *FeedService {
+	return &FeedService{
+		repo:  repo,
+		tasks: tasks,
+	}
+}
+
+// FeedItem задача со сроком и имя её проекта, пустое у задачи вне проекта.
+type FeedItem struct {
+	Task    entity.Task
+	Project string
+}
+
+// FeedCalendar содержимое ленты, Feed.ETag и Feed.ModifiedAt уже соответствуют Items.
+type FeedCalendar struct {
+	Feed  entity.Feed
+	Items []FeedItem
+}
+
+// Rotate выпускает новый токен ленты вызывающего, прежний сразу перестаёт работать.
+// Открытый токен возвращается только здесь.
+func (s *FeedService) Rotate(ctx context.Context) (_ string, _ *entity.Feed, err error) {
+	ctx, span := tracing.Start(ctx, "FeedService.Rotate")
+	defer func() { span.EndWithError(err) }()
+
+	owner, ok := caller(ctx)
+	if !ok {
+		return "", nil, domain.ErrForbidden
+	}
+	var secret [32]byte
+	if _, err := rand.Read(secret[:]); err != nil {
+		return "", nil, fmt.Errorf("rand.Read: %w", err)
+	}
+	token := feedTokenPrefix + base64.RawURLEncoding.EncodeToString(secret[:])
+
+	now := s.tasks.now().UTC()
+	feed := &entity.Feed{OwnerID: owner, TokenHash: hashFeedToken(token), CreatedAt: now, ModifiedAt: now}
+	if err := s.repo.SaveFeed(ctx, feed); err != nil {
+		return "", nil, err
+	}
+	logger(ctx).Info("calendar feed rotated", slog.String("owner", owner))
+	return token, feed, nil
+}
+
+func (s *FeedService) Get(ctx context.Context) (_ *entity.Feed, err error) {
+	ctx, span := tracing.Start(ctx, "FeedService.Get")
+	defer func() { span.EndWithError(err) }()
+
+	owner, ok := caller(ctx)
+	if !ok {
+		return nil, domain.ErrFeedNotFound
+	}
+	return s.repo.GetFeed(ctx, owner)
+}
+
+// Revoke отзывает ленту вызывающего, ссылка перестаёт открываться до следующего Rotate.
+func (s *FeedService) Revoke(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "FeedService.Revoke")
+	defer func() { span.EndWithError(err) }()
+
+	owner, ok := caller(ctx)
+	if !ok {
+		return domain.ErrFeedNotFound
+	}
+	if err := s.repo.DeleteFeed(ctx, owner); err != nil {
+		return err
+	}
+	logger(ctx).Info("calendar feed revoked", slog.String("owner", owner))
+	return nil
+}
+
+// Calendar задачи владельца ленты со сроком, кроме задач архивных проектов, по возрастанию ID.
+// Неизвестный и отозванный токен неотличимы: ErrFeedNotFound. Если содержимое изменилось с
+// прошлого запроса, у ленты обновляются ETag и ModifiedAt.
+func (s *FeedService) Calendar(ctx context.Context, token string) (_ *FeedCalendar, err error) {
+	ctx, span := tracing.Start(ctx, "FeedService.Calendar")
+	defer func() { span.EndWithError(err) }()
+
+	if !strings.HasPrefix(token, feedTokenPrefix) {
+		return nil, domain.ErrFeedNotFound
+	}
+	hash := hashFeedToken(token)
+	feed, err := s.repo.FeedByToken(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	names, hidden, err := s.tasks.projectNames(ctx, feed.OwnerID, false)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := s.tasks.repo.Find(ctx, feed.OwnerID, func(t entity.Task) bool {
+		return t.DueAt != nil && !hidden(t)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Find обходит map, порядок случаен; от порядка зависит ETag
+	slices.SortFunc(tasks, func(a, b entity.Task) int { return cmp.Compare(a.ID, b.ID) })
+	cal := &FeedCalendar{Feed: *feed, Items: make([]FeedItem, 0, len(tasks))}
+	for _, task := range tasks {
+		item := FeedItem{Task: task}
+		if task.ProjectID != nil {
+			item.Project = names[*task.ProjectID]
+		}
+		cal.Items = append(cal.Items, item)
+	}
+
+	etag, err := fingerprint(cal.Items)
+	if err != nil {
+		return nil, err
+	}
+	if etag != feed.ETag {
+		modified := s.tasks.now().UTC().Truncate(time.Second)
+		if err := s.repo.TouchFeed(ctx, hash, etag, modified); err != nil {
+			return nil, err
+		}
+		cal.Feed.ETag, cal.Feed.ModifiedAt = etag, modified
+	}
+	return cal, nil
+}
+
+// fingerprint меняется при любом изменении задач ленты, в том числе тех, что не влияют на время
+// последней смены статуса: у задачи нет отметки о последнем изменении.
+func fingerprint(items []FeedItem) (string, error) {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return "", fmt.Errorf("json.Marshal: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/infrastructure/persistance"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFeedService(t *testing.T) {
+	repo := persistance.NewTaskRepository()
+	tasks := NewTaskService(repo, WithProjects(repo))
+	projects := NewProjectService(repo)
+	feeds := NewFeedService(repo, tasks)
+	clock := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	tasks.now = func() time.Time { return clock }
+	alice := as("alice")
+
+	due := time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)
+	home := &entity.Project{Name: "Home"}
+	old := &entity.Project{Name: "Old"}
+	_, _ = projects.Create(alice, home)
+	_, _ = projects.Create(alice, old)
+	rent, _ := tasks.Create(alice, &entity.Task{Title: "Pay rent", DueAt: &due, ProjectID: &home.ID})
+	_, _ = tasks.Create(alice, &entity.Task{Title: "Someday"})
+	_, _ = tasks.Create(alice, &entity.Task{Title: "Archived", DueAt: &due, ProjectID: &old.ID})
+	_, _ = tasks.Create(as("bob"), &entity.Task{Title: "Bob's", DueAt: &due})
+	_, _ = projects.SetArchived(alice, old.ID, true)
+
+	if _, err := feeds.Get(alice); !errors.Is(err, domain.ErrFeedNotFound) {
+		t.Errorf("Get() before Rotate error = %v, want ErrFeedNotFound", err)
+	}
+	if _, _, err := feeds.Rotate(context.Background()); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Rotate() without principal error = %v, want ErrForbidden", err)
+	}
+
+	token, feed, err := feeds.Rotate(alice)
+	if err != nil || !strings.HasPrefix(token, feedTokenPrefix) || strings.Contains(feed.TokenHash, token) {
+		t.Fatalf("Rotate() = %q, %+v, %v", token, feed, err)
+	}
+
+	cal, err := feeds.Calendar(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Calendar() error = %v", err)
+	}
+	if len(cal.Items) != 1 || cal.Items[0].Task.ID != rent || cal.Items[0].Project != "Home" {
+		t.Fatalf("Calendar() items = %+v, want only Pay rent in Home", cal.Items)
+	}
+
+	// несколько задач со сроком: ETag не должен зависеть от порядка обхода хранилища
+	for _, title := range []string{"Call mom", "Dentist", "Taxes", "Gym", "Plumber", "Passport", "Car service", "Vet", "Books"} {
+		_, _ = tasks.Create(alice, &entity.Task{Title: title, DueAt: &due})
+	}
+	clock = clock.Add(time.Minute)
+	cal, _ = feeds.Calendar(context.Background(), token)
+	if len(cal.Items) != 10 {
+		t.Fatalf("Calendar() items = %d, want 10", len(cal.Items))
+	}
+	for i := 1; i < len(cal.Items); i++ {
+		if cal.Items[i-1].Task.ID >= cal.Items[i].Task.ID {
+			t.Fatalf("Calendar() items not in ascending ID order: %d before %d", cal.Items[i-1].Task.ID, cal.Items[i].Task.ID)
+		}
+	}
+	etag, modified := cal.Feed.ETag, cal.Feed.ModifiedAt
+	if etag == "" || !modified.Equal(clock) {
+		t.Errorf("ETag = %q, ModifiedAt = %v", etag, modified)
+	}
+
+	for range 20 {
+		clock = clock.Add(time.Hour)
+		cal, _ = feeds.Calendar(context.Background(), token)
+		if cal.Feed.ETag != etag || !cal.Feed.ModifiedAt.Equal(modified) {
+			t.Fatalf("unchanged feed got new ETag %q or ModifiedAt %v", cal.Feed.ETag, cal.Feed.ModifiedAt)
+		}
+	}
+
+	task, _ := tasks.GetByID(alice, rent)
+	task.Title = "Pay rent today"
+	_ = tasks.Update(alice, task)
+	cal, _ = feeds.Calendar(context.Background(), token)
+	if cal.Feed.ETag == etag || !cal.Feed.ModifiedAt.Equal(clock) {
+		t.Errorf("changed feed kept ETag %q, ModifiedAt %v", cal.Feed.ETag, cal.Feed.ModifiedAt)
+	}
+
+	rotated, _, _ := feeds.Rotate(alice)
+	if _, err := feeds.Calendar(context.Background(), token); !errors.Is(err, domain.ErrFeedNotFound) {
+		t.Errorf("old token after Rotate error = %v, want ErrFeedNotFound", err)
+	}
+	if _, err := feeds.Calendar(context.Background(), rotated); err != nil {
+		t.Errorf("Calendar() with rotated token error = %v", err)
+	}
+
+	if err := feeds.Revoke(alice); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	for _, tok := range []string{rotated, "garbage", feedTokenPrefix} {
+		if _, err := feeds.Calendar(context.Background(), tok); !errors.Is(err, domain.ErrFeedNotFound) {
+			t.Errorf("Calendar(%q) error = %v, want ErrFeedNotFound", tok, err)
+		}
+	}
+	if err := feeds.Revoke(alice); !errors.Is(err, domain.ErrFeedNotFound) {
+		t.Errorf("second Revoke() error = %v, want ErrFeedNotFound", err)
+	}
+}
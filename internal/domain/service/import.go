@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/pkg/tracing"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+// MaxImportRows ограничение одного файла импорта.
+const MaxImportRows = 10000
+
+// BatchCreator хранилище, которое создаёт пачку задач атомарно: либо все, либо ни одной.
+// Задачам без Rank ключи в конце списка назначаются под тем же локом, что и запись, check
+// проверяет задачи владельца под ним же, как GuardedWriter.
+type BatchCreator interface {
+	CreateBatch(ctx context.Context, tasks []*entity.Task, check func(owned []entity.Task) error) ([]int, error)
+}
+
+// ImportRow итог по строке файла: подготовленная задача (с ID после создания) или ошибка.
+type ImportRow struct {
+	Line int
+	Task entity.Task
+	Err  error
+}
+
+// ImportReport Atomic true, если задачи создавались одной пачкой; иначе при сбое записи часть задач
+// могла остаться созданной, и такие строки отмечены ID.
+type ImportReport struct {
+	DryRun  bool
+	Atomic  bool
+	Created int
+	Failed  int
+	Rows    []ImportRow
+}
+
+// Import сначала проверяет все строки теми же правилами, что и Create, и при любой ошибке не создаёт
+// ничего. dryRun только проверяет и возвращает, что было бы создано. Ошибка результата означает сбой
+// хранилища, ошибки строк возвращаются в отчёте.
+func (s *TaskService) Import(ctx context.Context, items []entity.ImportItem, dryRun bool) (_ *ImportReport, err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.Import")
+	defer func() { span.EndWithError(err) }()
+	span.SetAttribute("import.rows", len(items))
+
+	if len(items) > MaxImportRows {
+		return nil, fmt.Errorf("import: %w: %d rows, at most %d allowed", domain.ErrInvalidImport, len(items), MaxImportRows)
+	}
+
+	batch, atomic := s.repo.(BatchCreator)
+	report := &ImportReport{DryRun: dryRun, Atomic: atomic, Rows: make([]ImportRow, 0, len(items))}
+	owner, _ := caller(ctx)
+
+	var (
+		pending []entity.Task
+		guards  []func([]entity.Task) error
+	)
+	for _, item := range items {
+		row := ImportRow{Line: item.Line, Task: item.Task, Err: item.Err}
+		if row.Err == nil {
+			var guard func([]entity.Task) error
+			guard, row.Err = s.prepareImport(ctx, &row.Task, item.Project, owner, pending)
+			if guard != nil {
+				guards = append(guards, lineGuard(item.Line, guard))
+			}
+		}
+		if row.Err != nil {
+			report.Failed++
+		} else {
+			pending = append(pending, row.Task)
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	if report.Failed > 0 || dryRun || len(pending) == 0 {
+		return report, nil
+	}
+
+	tasks := make([]*entity.Task, 0, len(report.Rows))
+	for i := range report.Rows {
+		tasks = append(tasks, &report.Rows[i].Task)
+	}
+
+	// проверки выше шли по снимку без лока, поэтому WIP-лимиты проверяются ещё раз при записи
+	if atomic {
+		if _, err := batch.CreateBatch(ctx, tasks, allGuards(guards)); err != nil {
+			return nil, fmt.Errorf("import: %w", err)
+		}
+		report.Created = len(tasks)
+	} else {
+		for i, task := range tasks {
+			if err := s.createImported(ctx, task); err != nil {
+				report.Rows[i].Err = err
+				report.Failed++
+				continue
+			}
+			report.Created++
+		}
+	}
+	if slices.ContainsFunc(tasks, func(task *entity.Task) bool { return len(task.Rank) > maxRankLength }) {
+		s.rebalanceOwner(ctx, owner)
+	}
+	logger(ctx).Info("tasks imported", slog.Int("created", report.Created), slog.Int("failed", report.Failed))
+	return report, nil
+}
+
+// prepareImport то же, что Create делает с задачей до записи. Возвращает проверку WIP-лимитов,
+// которая учитывает pending и повторяется под локом при записи пачки.
+func (s *TaskService) prepareImport(ctx context.Context, task *entity.Task, project, owner string, pending []entity.Task) (func([]entity.Task) error, error) {
+	if task.Title == "" {
+		return nil, domain.ErrEmptyTitle
+	}
+	if err := normalize(task); err != nil {
+		return nil, err
+	}
+	task.ID = unsavedID
+	task.OwnerID = owner
+	task.ProjectID = nil
+	task.Rank = ""
+	if project != "" {
+		id, err := s.projectByName(ctx, owner, project)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", err, project)
+		}
+		if err := s.checkProject(ctx, id, owner); err != nil {
+			return nil, err
+		}
+		task.ProjectID = &id
+	}
+
+	status := task.CurrentStatus()
+	if !status.Valid() {
+		return nil, fmt.Errorf("%w: %q", domain.ErrInvalidStatus, status)
+	}
+	guard, err := s.wipGuard(ctx, task, status, pending...)
+	if err != nil {
+		return nil, err
+	}
+	if guard != nil {
+		if err := s.applyGuard(ctx, owner, guard); err != nil {
+			return nil, err
+		}
+	}
+	s.setStatus(task, status)
+	return guard, nil
+}
+
+// createImported запись по одной для хранилища без BatchCreator: созданные раньше задачи
+// пачки уже сохранены, поэтому WIP-лимит проверяется без pending.
+func (s *TaskService) createImported(ctx context.Context, task *entity.Task) error {
+	guard, err := s.wipGuard(ctx, task, task.Status)
+	if err != nil {
+		return err
+	}
+	_, err = s.createGuarded(ctx, task, guard)
+	return err
+}
+
+// lineGuard помечает ошибку проверки номером строки файла.
+func lineGuard(line int, guard func([]entity.Task) error) func([]entity.Task) error {
+	return func(owned []entity.Task) error {
+		if err := guard(owned); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		return nil
+	}
+}
+
+// allGuards объединяет проверки строк пачки, nil если проверять нечего.
+func allGuards(guards []func([]entity.Task) error) func([]entity.Task) error {
+	if len(guards) == 0 {
+		return nil
+	}
+	return func(owned []entity.Task) error {
+		for _, guard := range guards {
+			if err := guard(owned); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
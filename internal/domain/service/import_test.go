@@ -0,0 +1,147 @@
+package service
+
+import (
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/infrastructure/persistance"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// plainRepo скрывает CreateBatch, чтобы проверить импорт без атомарной вставки.
+type plainRepo struct {
+	TaskRepository
+}
+
+func TestTaskService_Import(t *testing.T) {
+	repo := persistance.NewTaskRepository()
+	tasks := NewTaskService(repo, WithProjects(repo), WithBoards(repo))
+	projects := NewProjectService(repo)
+	boards := NewBoardService(repo, tasks)
+	alice := as("alice")
+
+	home := &entity.Project{Name: "Home"}
+	_, _ = projects.Create(alice, home)
+	existing, _ := tasks.Create(alice, &entity.Task{Title: "Existing"})
+
+	items := []entity.ImportItem{
+		{Line: 1, Task: entity.Task{Title: "Pay rent", Tags: []string{"Bills"}}, Project: "home"},
+		{Line: 2, Task: entity.Task{Title: "Done already", IsCompleted: true}},
+		{Line: 3, Task: entity.Task{Title: "Reviewing", Status: entity.StatusReview}},
+	}
+
+	report, err := tasks.Import(alice, items, true)
+	if err != nil {
+		t.Fatalf("Import(dry run) error = %v", err)
+	}
+	if report.Failed != 0 || report.Created != 0 || report.Rows[0].Task.ProjectID == nil {
+		t.Errorf("unexpected dry run report %+v", report)
+	}
+	if all, _ := tasks.List(alice, ListOptions{}); len(all) != 1 {
+		t.Fatalf("dry run created tasks: %v", all)
+	}
+
+	bad := append([]entity.ImportItem{}, items...)
+	bad = append(bad,
+		entity.ImportItem{Line: 4, Task: entity.Task{Title: "Urgent", Priority: "urgent"}},
+		entity.ImportItem{Line: 5, Task: entity.Task{Title: "Elsewhere"}, Project: "Work"},
+		entity.ImportItem{Line: 6, Err: domain.ErrInvalidImport},
+	)
+	report, err = tasks.Import(alice, bad, false)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	wantErrs := map[int]error{4: domain.ErrInvalidPriority, 5: domain.ErrProjectNotFound, 6: domain.ErrInvalidImport}
+	for _, row := range report.Rows {
+		if want := wantErrs[row.Line]; !errors.Is(row.Err, want) || (want == nil && row.Err != nil) {
+			t.Errorf("line %d error = %v, want %v", row.Line, row.Err, want)
+		}
+	}
+	if report.Failed != 3 || report.Created != 0 {
+		t.Errorf("Failed = %d, Created = %d, want 3 and 0", report.Failed, report.Created)
+	}
+	if all, _ := tasks.List(alice, ListOptions{}); len(all) != 1 {
+		t.Fatalf("failed import must not create anything, got %v", all)
+	}
+
+	report, err = tasks.Import(alice, items, false)
+	if err != nil || !report.Atomic || report.Created != 3 {
+		t.Fatalf("Import() = %+v, %v", report, err)
+	}
+	list, _ := tasks.List(alice, ListOptions{Sort: SortManual})
+	want := []string{"Existing", "Pay rent", "Done already", "Reviewing"}
+	for i, task := range list {
+		if task.Title != want[i] {
+			t.Errorf("manual order[%d] = %q, want %q", i, task.Title, want[i])
+		}
+	}
+	if list[0].ID != existing || !list[1].HasTag("bills") || list[2].CurrentStatus() != entity.StatusDone ||
+		list[3].CurrentStatus() != entity.StatusReview || list[1].OwnerID != "alice" {
+		t.Errorf("unexpected imported tasks %+v", list)
+	}
+
+	board := &entity.Board{Name: "Inbox", Columns: []entity.Column{{Name: "Todo", Status: entity.StatusTodo, WIPLimit: 3}}}
+	_, _ = boards.Create(alice, board)
+	report, _ = tasks.Import(alice, []entity.ImportItem{{Line: 1, Task: entity.Task{Title: "One"}}, {Line: 2, Task: entity.Task{Title: "Two"}}}, true)
+	if !errors.Is(report.Rows[1].Err, domain.ErrWIPLimitExceeded) {
+		t.Errorf("second row must count the first against the WIP limit, got %v", report.Rows[1].Err)
+	}
+
+	plain := NewTaskService(plainRepo{repo})
+	report, err = plain.Import(as("bob"), []entity.ImportItem{{Line: 1, Task: entity.Task{Title: "Bob's"}}}, false)
+	if err != nil || report.Atomic || report.Created != 1 {
+		t.Errorf("Import() without batch support = %+v, %v", report, err)
+	}
+}
+
+func TestTaskService_ImportConcurrently(t *testing.T) {
+	repo := persistance.NewTaskRepository()
+	tasks := NewTaskService(slowReads{repo}, WithBoards(repo))
+	ctx := as("alice")
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			items := []entity.ImportItem{{Line: 1, Task: entity.Task{Title: "A"}}, {Line: 2, Task: entity.Task{Title: "B"}}}
+			if _, err := tasks.Import(ctx, items, false); err != nil {
+				t.Errorf("Import() error = %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	list, _ := repo.GetAllByOwner(ctx, "alice")
+	ranks := make(map[string]struct{}, len(list))
+	for _, task := range list {
+		ranks[task.Rank] = struct{}{}
+	}
+	if len(list) != 16 || len(ranks) != 16 {
+		t.Fatalf("got %d tasks with %d distinct ranks, want 16 and 16", len(list), len(ranks))
+	}
+
+	board := &entity.Board{Name: "Sprint", Columns: []entity.Column{{Name: "Doing", Status: entity.StatusInProgress, WIPLimit: 4}}}
+	if _, err := NewBoardService(repo, tasks).Create(ctx, board); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	for range 8 {
+		wg.Go(func() {
+			items := []entity.ImportItem{{Line: 1, Task: entity.Task{Title: "Doing", Status: entity.StatusInProgress}}}
+			if _, err := tasks.Import(ctx, items, false); err != nil && !errors.Is(err, domain.ErrWIPLimitExceeded) {
+				t.Errorf("Import() error = %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	list, _ = repo.GetAllByOwner(ctx, "alice")
+	doing := 0
+	for _, task := range list {
+		if task.CurrentStatus() == entity.StatusInProgress {
+			doing++
+		}
+	}
+	if doing != 4 {
+		t.Errorf("%d tasks in progress, want the WIP limit of 4", doing)
+	}
+}
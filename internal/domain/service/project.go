@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/domain/policy"
+	"ecom_test/pkg/tracing"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+type ProjectRepository interface {
+	CreateProject(ctx context.Context, project *entity.Project) (int, error)
+	GetProject(ctx context.Context, id int) (*entity.Project, error)
+	ListProjects(ctx context.Context, ownerID string) ([]entity.Project, error)
+	UpdateProject(ctx context.Context, project *entity.Project) error
+	DeleteProject(ctx context.Context, id int, policy entity.ProjectDeletePolicy) error
+	GetTasksByProject(ctx context.Context, projectID int) ([]entity.Task, error)
+	GetProjectShare(ctx context.Context, projectID int, userID string) (*entity.ProjectShare, error)
+	ListProjectShares(ctx context.Context, projectID int) ([]entity.ProjectShare, error)
+	PutProjectShare(ctx context.Context, share entity.ProjectShare) error
+	DeleteProjectShare(ctx context.Context, projectID int, userID string) error
+	GetSharedProjects(ctx context.Context, userID string) ([]entity.Project, error)
+}
+
+type ProjectService struct {
+	repo ProjectRepository
+}
+
+func NewProjectService(repo ProjectRepository) *ProjectService {
+	return &ProjectService{
+		repo: repo,
+	}
+}
+
+// authorize загружает проект и проверяет действие через policy. Проект, к которому у вызывающего
+// нет доступа, неотличим от несуществующего; ErrForbidden только если проект виден, но роль
+// не позволяет действие.
+func (s *ProjectService) authorize(ctx context.Context, id int, action policy.Action) (*entity.Project, error) {
+	project, err := s.repo.GetProject(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	user, ok := caller(ctx)
+	if !ok {
+		return project, nil
+	}
+
+	var share *entity.ProjectShare
+	if project.OwnerID != user {
+		share, err = s.repo.GetProjectShare(ctx, id, user)
+		if err != nil && !errors.Is(err, domain.ErrShareNotFound) {
+			return nil, err
+		}
+	}
+
+	role, ok := policy.ProjectRoleOf(project, share, user)
+	if !ok {
+		return nil, domain.ErrProjectNotFound
+	}
+	if !policy.Can(role, action) {
+		return nil, domain.ErrForbidden
+	}
+	return project, nil
+}
+
+func (s *ProjectService) Create(ctx context.Context, project *entity.Project) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "ProjectService.Create")
+	defer func() { span.EndWithError(err) }()
+
+	project.Name = strings.TrimSpace(project.Name)
+	if project.Name == "" {
+		return 0, fmt.Errorf("project: %w", domain.ErrEmptyProjectName)
+	}
+	if owner, ok := caller(ctx); ok {
+		project.OwnerID = owner
+	}
+	project.ArchivedAt = nil
+	project.CreatedAt = time.Now().UTC()
+
+	id, err := s.repo.CreateProject(ctx, project)
+	if err != nil {
+		return 0, fmt.Errorf("project: %w", err)
+	}
+	logger(ctx).Info("project created", slog.Int("project_id", id))
+	return id, nil
+}
+
+func (s *ProjectService) Get(ctx context.Context, id int) (_ *entity.Project, err error) {
+	ctx, span := tracing.Start(ctx, "ProjectService.Get")
+	defer func() { span.EndWithError(err) }()
+
+	project, err := s.authorize(ctx, id, policy.ActionView)
+	if err != nil {
+		return nil, fmt.Errorf("project %d: %w", id, err)
+	}
+	return project, nil
+}
+
+func (s *ProjectService) List(ctx context.Context, includeArchived bool) (_ []entity.Project, err error) {
+	ctx, span := tracing.Start(ctx, "ProjectService.List")
+	defer func() { span.EndWithError(err) }()
+
+	owner, _ := caller(ctx)
+	projects, err := s.repo.ListProjects(ctx, owner)
+	if err != nil {
+		return nil, fmt.Errorf("repo.ListProjects: %w", err)
+	}
+	if includeArchived {
+		return projects, nil
+	}
+
+	active := projects[:0]
+	for _, p := range projects {
+		if !p.Archived() {
+			active = append(active, p)
+		}
+	}
+	return active, nil
+}
+
+// Update меняет только название и описание.
+func (s *ProjectService) Update(ctx context.Context, project *entity.Project) (err error) {
+	ctx, span := tracing.Start(ctx, "ProjectService.Update")
+	defer func() { span.EndWithError(err) }()
+
+	project.Name = strings.TrimSpace(project.Name)
+	if project.Name == "" {
+		return fmt.Errorf("project %d: %w", project.ID, domain.ErrEmptyProjectName)
+	}
+
+	existing, err := s.authorize(ctx, project.ID, policy.ActionEdit)
+	if err != nil {
+		return fmt.Errorf("project %d: %w", project.ID, err)
+	}
+	existing.Name = project.Name
+	existing.Description = project.Description
+	if err := s.repo.UpdateProject(ctx, existing); err != nil {
+		return fmt.Errorf("project %d: %w", project.ID, err)
+	}
+	*project = *existing
+	return nil
+}
+
+func (s *ProjectService) SetArchived(ctx context.Context, id int, archived bool) (_ *entity.Project, err error) {
+	ctx, span := tracing.Start(ctx, "ProjectService.SetArchived")
+	defer func() { span.EndWithError(err) }()
+
+	project, err := s.authorize(ctx, id, policy.ActionEdit)
+	if err != nil {
+		return nil, fmt.Errorf("project %d: %w", id, err)
+	}
+	if archived == project.Archived() {
+		return project, nil
+	}
+
+	project.ArchivedAt = nil
+	if archived {
+		now := time.Now().UTC()
+		project.ArchivedAt = &now
+	}
+	if err := s.repo.UpdateProject(ctx, project); err != nil {
+		return nil, fmt.Errorf("project %d: %w", id, err)
+	}
+	logger(ctx).Info("project archive state changed", slog.Int("project_id", id), slog.Bool("archived", archived))
+	return project, nil
+}
+
+func (s *ProjectService) Delete(ctx context.Context, id int, onDelete entity.ProjectDeletePolicy) (err error) {
+	ctx, span := tracing.Start(ctx, "ProjectService.Delete")
+	defer func() { span.EndWithError(err) }()
+
+	if onDelete == "" {
+		onDelete = entity.DeleteReject
+	}
+	if !onDelete.Valid() {
+		return fmt.Errorf("project %d: %w", id, domain.ErrInvalidDeletePolicy)
+	}
+	if _, err := s.authorize(ctx, id, policy.ActionDelete); err != nil {
+		return fmt.Errorf("project %d: %w", id, err)
+	}
+
+	if err := s.repo.DeleteProject(ctx, id, onDelete); err != nil {
+		return fmt.Errorf("project %d: %w", id, err)
+	}
+	logger(ctx).Info("project deleted", slog.Int("project_id", id), slog.String("policy", string(onDelete)))
+	return nil
+}
+
+// Tasks задачи проекта, включая архивный проект: явный запрос показывает всё.
+func (s *ProjectService) Tasks(ctx context.Context, id int) (_ []entity.Task, err error) {
+	ctx, span := tracing.Start(ctx, "ProjectService.Tasks")
+	defer func() { span.EndWithError(err) }()
+
+	if _, err := s.authorize(ctx, id, policy.ActionView); err != nil {
+		return nil, fmt.Errorf("project %d: %w", id, err)
+	}
+	tasks, err := s.repo.GetTasksByProject(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("project %d: %w", id, err)
+	}
+	return tasks, nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/domain/policy"
+	"ecom_test/pkg/tracing"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Share открывает проект пользователю: роль действует на проект и все его задачи, в том числе
+// созданные позже. Повторный вызов меняет роль.
+func (s *ProjectService) Share(ctx context.Context, id int, userID string, role entity.Role) (_ *entity.ProjectShare, err error) {
+	ctx, span := tracing.Start(ctx, "ProjectService.Share")
+	defer func() { span.EndWithError(err) }()
+
+	if userID == "" || !role.Valid() {
+		return nil, fmt.Errorf("project %d: %w", id, domain.ErrInvalidShare)
+	}
+	project, err := s.authorize(ctx, id, policy.ActionShare)
+	if err != nil {
+		return nil, fmt.Errorf("project %d: %w", id, err)
+	}
+	if project.OwnerID == userID {
+		return nil, fmt.Errorf("project %d: %w", id, domain.ErrInvalidShare)
+	}
+
+	grantor, _ := caller(ctx)
+	share := entity.ProjectShare{
+		ProjectID: id,
+		UserID:    userID,
+		Role:      role,
+		GrantedBy: grantor,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.repo.PutProjectShare(ctx, share); err != nil {
+		return nil, fmt.Errorf("project %d: %w", id, err)
+	}
+	logger(ctx).Info("project shared", slog.Int("project_id", id), slog.String("user_id", userID), slog.String("role", string(role)))
+	return &share, nil
+}
+
+// Unshare отзывает доступ к проекту. Пользователь всегда может отказаться от собственного доступа.
+func (s *ProjectService) Unshare(ctx context.Context, id int, userID string) (err error) {
+	ctx, span := tracing.Start(ctx, "ProjectService.Unshare")
+	defer func() { span.EndWithError(err) }()
+
+	action := policy.ActionShare
+	if user, ok := caller(ctx); ok && user == userID {
+		action = policy.ActionView
+	}
+	if _, err := s.authorize(ctx, id, action); err != nil {
+		return fmt.Errorf("project %d: %w", id, err)
+	}
+
+	if err := s.repo.DeleteProjectShare(ctx, id, userID); err != nil {
+		return fmt.Errorf("project %d: %w", id, err)
+	}
+	logger(ctx).Info("project unshared", slog.Int("project_id", id), slog.String("user_id", userID))
+	return nil
+}
+
+func (s *ProjectService) Shares(ctx context.Context, id int) (_ []entity.ProjectShare, err error) {
+	ctx, span := tracing.Start(ctx, "ProjectService.Shares")
+	defer func() { span.EndWithError(err) }()
+
+	if _, err := s.authorize(ctx, id, policy.ActionView); err != nil {
+		return nil, fmt.Errorf("project %d: %w", id, err)
+	}
+	shares, err := s.repo.ListProjectShares(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("project %d: %w", id, err)
+	}
+	return shares, nil
+}
+
+// SharedWithMe проекты других пользователей, к которым у вызывающего есть доступ.
+func (s *ProjectService) SharedWithMe(ctx context.Context) (_ []entity.Project, err error) {
+	ctx, span := tracing.Start(ctx, "ProjectService.SharedWithMe")
+	defer func() { span.EndWithError(err) }()
+
+	user, ok := caller(ctx)
+	if !ok {
+		return []entity.Project{}, nil
+	}
+	projects, err := s.repo.GetSharedProjects(ctx, user)
+	if err != nil {
+		return nil, fmt.Errorf("project: %w", err)
+	}
+	return projects, nil
+}
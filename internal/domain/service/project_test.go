@@ -0,0 +1,129 @@
+package service
+
+import (
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/infrastructure/persistance"
+	"errors"
+	"testing"
+)
+
+func TestProjectService_Archive(t *testing.T) {
+	repo := persistance.NewTaskRepository()
+	projects := NewProjectService(repo)
+	tasks := NewTaskService(repo, WithProjects(repo))
+	ctx := as("alice")
+
+	web := &entity.Project{Name: "Web"}
+	if _, err := projects.Create(ctx, web); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	old := &entity.Project{Name: "Old"}
+	_, _ = projects.Create(ctx, old)
+
+	_, _ = tasks.Create(ctx, &entity.Task{Title: "Loose"})
+	_, _ = tasks.Create(ctx, &entity.Task{Title: "Web task", ProjectID: &web.ID})
+	oldTaskID, _ := tasks.Create(ctx, &entity.Task{Title: "Old task", ProjectID: &old.ID})
+
+	if _, err := projects.SetArchived(ctx, old.ID, true); err != nil {
+		t.Fatalf("SetArchived() error = %v", err)
+	}
+
+	if visible, _ := tasks.GetAll(ctx); len(visible) != 2 {
+		t.Errorf("GetAll() = %d tasks, want archived project tasks hidden", len(visible))
+	}
+	if all, _ := tasks.List(ctx, ListOptions{IncludeArchived: true}); len(all) != 3 {
+		t.Errorf("List(IncludeArchived) = %d tasks, want 3", len(all))
+	}
+	if inOld, _ := projects.Tasks(ctx, old.ID); len(inOld) != 1 || inOld[0].ID != oldTaskID {
+		t.Errorf("Tasks(old) = %v, want archived project tasks on explicit request", inOld)
+	}
+	if active, _ := projects.List(ctx, false); len(active) != 1 || active[0].ID != web.ID {
+		t.Errorf("List() = %v, want only active projects", active)
+	}
+
+	tests := []struct {
+		name      string
+		projectID *int
+		wantErr   error
+	}{
+		{name: "Into archived project", projectID: &old.ID, wantErr: domain.ErrProjectArchived},
+		{name: "Into foreign project", projectID: func() *int {
+			foreign := &entity.Project{Name: "Bob"}
+			_, _ = projects.Create(as("bob"), foreign)
+			return &foreign.ID
+		}(), wantErr: domain.ErrProjectNotFound},
+		{name: "Into active project", projectID: &web.ID},
+		{name: "Out of project", projectID: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := tasks.MoveToProject(ctx, oldTaskID, tt.projectID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("MoveToProject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (task.ProjectID == nil) != (tt.projectID == nil) {
+				t.Errorf("MoveToProject() ProjectID = %v, want %v", task.ProjectID, tt.projectID)
+			}
+		})
+	}
+
+	// обновление задачи не сбрасывает проект
+	_, _ = tasks.MoveToProject(ctx, oldTaskID, &web.ID)
+	_ = tasks.Update(ctx, &entity.Task{ID: oldTaskID, Title: "Renamed"})
+	if task, _ := tasks.GetByID(ctx, oldTaskID); task.ProjectID == nil || *task.ProjectID != web.ID {
+		t.Errorf("Update() lost project: %+v", task)
+	}
+}
+
+func TestProjectService_DeletePolicies(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        entity.ProjectDeletePolicy
+		wantErr       error
+		wantRemaining int
+	}{
+		{name: "Default rejects non-empty", policy: "", wantErr: domain.ErrProjectNotEmpty, wantRemaining: 3},
+		{name: "Reject", policy: entity.DeleteReject, wantErr: domain.ErrProjectNotEmpty, wantRemaining: 3},
+		{name: "Cascade", policy: entity.DeleteCascade, wantRemaining: 1},
+		{name: "Orphan", policy: entity.DeleteOrphan, wantRemaining: 3},
+		{name: "Unknown", policy: "archive", wantErr: domain.ErrInvalidDeletePolicy, wantRemaining: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := persistance.NewTaskRepository()
+			projects := NewProjectService(repo)
+			tasks := NewTaskService(repo, WithProjects(repo), WithShares(repo))
+			ctx := as("alice")
+
+			project := &entity.Project{Name: "Web"}
+			_, _ = projects.Create(ctx, project)
+			_, _ = tasks.Create(ctx, &entity.Task{Title: "Outside"})
+			a, _ := tasks.Create(ctx, &entity.Task{Title: "A", ProjectID: &project.ID})
+			_, _ = tasks.Create(ctx, &entity.Task{Title: "B", ProjectID: &project.ID})
+			_, _ = tasks.Share(ctx, a, "bob", entity.RoleViewer)
+
+			err := projects.Delete(ctx, project.ID, tt.policy)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Delete() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			remaining, _ := tasks.List(ctx, ListOptions{IncludeArchived: true})
+			if len(remaining) != tt.wantRemaining {
+				t.Errorf("remaining tasks = %d, want %d", len(remaining), tt.wantRemaining)
+			}
+			if tt.policy == entity.DeleteOrphan {
+				for _, task := range remaining {
+					if task.ProjectID != nil {
+						t.Errorf("task %d still references deleted project", task.ID)
+					}
+				}
+			}
+			if tt.policy == entity.DeleteCascade {
+				if shared, _ := tasks.SharedWithMe(as("bob")); len(shared) != 0 {
+					t.Errorf("Expected cascade to drop shares, got %v", shared)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/domain/quickadd"
+	"ecom_test/pkg/tracing"
+	"strings"
+	"time"
+)
+
+// QuickAddResult созданная задача и фрагменты ввода, которые разошлись по её полям.
+type QuickAddResult struct {
+	Task   entity.Task
+	Tokens []quickadd.Token
+}
+
+// QuickAdd создаёт задачу из строки быстрого ввода. loc часовой пояс пользователя для «завтра» и «15:00»,
+// nil означает UTC.
+func (s *TaskService) QuickAdd(ctx context.Context, input string, loc *time.Location) (_ *QuickAddResult, err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.QuickAdd")
+	defer func() { span.EndWithError(err) }()
+
+	if loc == nil {
+		loc = time.UTC
+	}
+	parsed := quickadd.Parse(input, s.now().In(loc))
+
+	task := &entity.Task{
+		Title:    parsed.Title,
+		Tags:     parsed.Tags,
+		Priority: parsed.Priority,
+		DueAt:    parsed.DueAt,
+	}
+	if parsed.Project != "" {
+		owner, _ := caller(ctx)
+		id, err := s.projectByName(ctx, owner, parsed.Project)
+		if err != nil {
+			return nil, domain.Wrap(err, "QuickAdd", 0)
+		}
+		task.ProjectID = &id
+	}
+
+	if _, err := s.Create(ctx, task); err != nil {
+		return nil, err
+	}
+	span.SetAttribute("quickadd.tokens", len(parsed.Tokens))
+	return &QuickAddResult{Task: *task, Tokens: parsed.Tokens}, nil
+}
+
+// projectByName без учёта регистра, пробелы в имени проекта считаются дефисами: @project-web находит «Project Web».
+func (s *TaskService) projectByName(ctx context.Context, owner, name string) (int, error) {
+	if s.projects == nil {
+		return 0, domain.ErrProjectNotFound
+	}
+	projects, err := s.projects.ListProjects(ctx, owner)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range projects {
+		if slug(p.Name) == slug(name) {
+			return p.ID, nil
+		}
+	}
+	return 0, domain.ErrProjectNotFound
+}
+
+func slug(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), "-"))
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/infrastructure/persistance"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTaskService_QuickAdd(t *testing.T) {
+	repo := persistance.NewTaskRepository()
+	tasks := NewTaskService(repo, WithProjects(repo))
+	tasks.now = func() time.Time { return time.Date(2026, 10, 19, 22, 0, 0, 0, time.UTC) }
+	projects := NewProjectService(repo)
+	alice, bob := as("alice"), as("bob")
+
+	web := &entity.Project{Name: "Project Web"}
+	_, _ = projects.Create(alice, web)
+
+	res, err := tasks.QuickAdd(alice, "Prepare release notes tomorrow 15:00 #release !high @project-web", nil)
+	if err != nil {
+		t.Fatalf("QuickAdd() error = %v", err)
+	}
+	stored, _ := tasks.GetByID(alice, res.Task.ID)
+	if stored.Title != "Prepare release notes" || stored.Priority != entity.PriorityHigh ||
+		!stored.HasTag("release") || stored.ProjectID == nil || *stored.ProjectID != web.ID {
+		t.Errorf("unexpected task %+v", stored)
+	}
+	if want := time.Date(2026, 10, 20, 15, 0, 0, 0, time.UTC); stored.DueAt == nil || !stored.DueAt.Equal(want) {
+		t.Errorf("DueAt = %v, want %v", stored.DueAt, want)
+	}
+	if len(res.Tokens) != 5 {
+		t.Errorf("Tokens = %+v, want 5 recognized fragments", res.Tokens)
+	}
+
+	// в Москве уже 20 октября, «завтра» это 21-е
+	msk := time.FixedZone("MSK", 3*60*60)
+	res, err = tasks.QuickAdd(alice, "Созвон завтра в 10:00", msk)
+	if err != nil {
+		t.Fatalf("QuickAdd() error = %v", err)
+	}
+	if want := time.Date(2026, 10, 21, 7, 0, 0, 0, time.UTC); !res.Task.DueAt.Equal(want) {
+		t.Errorf("DueAt = %v, want %v", res.Task.DueAt, want)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  error
+	}{
+		{name: "Someone else's project", input: "Spy @project-web", want: domain.ErrProjectNotFound},
+		{name: "Only metadata", input: "tomorrow #release", want: domain.ErrEmptyTitle},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tasks.QuickAdd(bob, tt.input, nil); !errors.Is(err, tt.want) {
+				t.Errorf("QuickAdd() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
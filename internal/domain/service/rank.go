@@ -0,0 +1,165 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/domain/policy"
+	"ecom_test/pkg/rank"
+	"ecom_test/pkg/tracing"
+	"log/slog"
+	"slices"
+)
+
+const (
+	SortID     = "id"
+	SortManual = "manual"
+)
+
+// maxRankLength при более длинном ключе список владельца перенумеровывается заново.
+const maxRankLength = 12
+
+// MoveAnchors задача ставится сразу после After и/или сразу перед Before.
+type MoveAnchors struct {
+	Before *int
+	After  *int
+}
+
+func sortTasks(tasks []entity.Task, order string) {
+	switch order {
+	case SortManual:
+		slices.SortStableFunc(tasks, func(a, b entity.Task) int {
+			return cmp.Or(cmp.Compare(a.Rank, b.Rank), cmp.Compare(a.ID, b.ID))
+		})
+	default:
+		slices.SortFunc(tasks, func(a, b entity.Task) int { return cmp.Compare(a.ID, b.ID) })
+	}
+}
+
+func validSort(order string) bool {
+	return order == "" || order == SortID || order == SortManual
+}
+
+// ownerList список владельца в ручном порядке, задачи без ключа (созданные до ранжирования)
+// получают ключи сразу, иначе относительно них нельзя вставить.
+func (s *TaskService) ownerList(ctx context.Context, ownerID string) ([]entity.Task, error) {
+	tasks, err := s.repo.GetAllByOwner(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	sortTasks(tasks, SortManual)
+
+	if slices.ContainsFunc(tasks, func(t entity.Task) bool { return t.Rank == "" }) {
+		if err := s.rebalance(ctx, tasks); err != nil {
+			return nil, err
+		}
+	}
+	return tasks, nil
+}
+
+// rebalance единственная операция, переписывающая ключи всего списка.
+func (s *TaskService) rebalance(ctx context.Context, tasks []entity.Task) error {
+	keys := rank.Spread(len(tasks))
+	for i := range tasks {
+		if tasks[i].Rank == keys[i] {
+			continue
+		}
+		tasks[i].Rank = keys[i]
+		if err := s.repo.Update(ctx, &tasks[i]); err != nil {
+			return err
+		}
+	}
+	logger(ctx).Info("task ranks rebalanced", slog.Int("tasks", len(tasks)))
+	return nil
+}
+
+// rebalanceOwner перенумеровывает список владельца, когда ключ новой задачи вырос сверх maxRankLength.
+// Задача уже создана, поэтому сбой только логируется: порядок верен, ключи просто длиннее.
+func (s *TaskService) rebalanceOwner(ctx context.Context, ownerID string) {
+	tasks, err := s.ownerList(ctx, ownerID)
+	if err == nil {
+		err = s.rebalance(ctx, tasks)
+	}
+	if err != nil {
+		logger(ctx).Warn("task ranks rebalance failed", slog.String("owner", ownerID), slog.String("error", err.Error()))
+	}
+}
+
+func (s *TaskService) Move(ctx context.Context, id int, anchors MoveAnchors) (_ *entity.Task, err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.Move")
+	defer func() { span.EndWithError(err) }()
+	span.SetAttribute("task.id", id)
+
+	if anchors.Before == nil && anchors.After == nil {
+		return nil, domain.Wrap(domain.ErrInvalidMove, "Move", id)
+	}
+	task, err := s.authorize(ctx, id, policy.ActionEdit)
+	if err != nil {
+		return nil, domain.Wrap(err, "Move", id)
+	}
+
+	list, err := s.ownerList(ctx, task.OwnerID)
+	if err != nil {
+		return nil, domain.Wrap(err, "Move", id)
+	}
+	list = slices.DeleteFunc(list, func(t entity.Task) bool { return t.ID == id })
+
+	pos, err := insertPosition(list, anchors)
+	if err != nil {
+		return nil, domain.Wrap(err, "Move", id)
+	}
+
+	var lo, hi string
+	if pos > 0 {
+		lo = list[pos-1].Rank
+	}
+	if pos < len(list) {
+		hi = list[pos].Rank
+	}
+	key, err := rank.Between(lo, hi)
+	if err != nil {
+		return nil, domain.Wrap(err, "Move", id)
+	}
+
+	if len(key) > maxRankLength {
+		list = slices.Insert(list, pos, *task)
+		if err := s.rebalance(ctx, list); err != nil {
+			return nil, domain.Wrap(err, "Move", id)
+		}
+		moved := list[pos]
+		return &moved, nil
+	}
+
+	task.Rank = key
+	if err := s.repo.Update(ctx, task); err != nil {
+		return nil, domain.Wrap(err, "Move", id)
+	}
+	logger(ctx).Info("task moved", slog.Int("task_id", id), slog.String("rank", key))
+	return task, nil
+}
+
+// insertPosition индекс вставки в список без перемещаемой задачи. Якоря должны быть в том же
+// списке, а при указании обоих After обязан стоять непосредственно перед Before.
+func insertPosition(list []entity.Task, anchors MoveAnchors) (int, error) {
+	indexOf := func(id int) int {
+		return slices.IndexFunc(list, func(t entity.Task) bool { return t.ID == id })
+	}
+
+	pos := -1
+	if anchors.After != nil {
+		i := indexOf(*anchors.After)
+		if i < 0 {
+			return 0, domain.ErrInvalidMove
+		}
+		pos = i + 1
+	}
+	if anchors.Before != nil {
+		i := indexOf(*anchors.Before)
+		if i < 0 || (pos >= 0 && pos != i) {
+			return 0, domain.ErrInvalidMove
+		}
+		pos = i
+	}
+	return pos, nil
+}
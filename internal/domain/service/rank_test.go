@@ -0,0 +1,135 @@
+package service
+
+import (
+	"cmp"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/infrastructure/persistance"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestTaskService_Move(t *testing.T) {
+	repo := persistance.NewTaskRepository()
+	tasks := NewTaskService(repo, WithShares(repo))
+	ctx := as("alice")
+
+	ids := make([]int, 4)
+	for i, title := range []string{"A", "B", "C", "D"} {
+		ids[i], _ = tasks.Create(ctx, &entity.Task{Title: title})
+	}
+	a, b, c, d := ids[0], ids[1], ids[2], ids[3]
+	foreign, _ := tasks.Create(as("bob"), &entity.Task{Title: "Bob"})
+
+	order := func() []int {
+		list, err := tasks.List(ctx, ListOptions{Sort: SortManual})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		got := make([]int, 0, len(list))
+		for _, task := range list {
+			got = append(got, task.ID)
+		}
+		return got
+	}
+	ranks := func() map[int]string {
+		list, _ := tasks.GetAll(ctx)
+		m := make(map[int]string, len(list))
+		for _, task := range list {
+			m[task.ID] = task.Rank
+		}
+		return m
+	}
+	ptr := func(id int) *int { return &id }
+
+	tests := []struct {
+		name    string
+		id      int
+		anchors MoveAnchors
+		want    []int
+		wantErr error
+	}{
+		{name: "To the top", id: d, anchors: MoveAnchors{Before: ptr(a)}, want: []int{d, a, b, c}},
+		{name: "To the bottom", id: d, anchors: MoveAnchors{After: ptr(c)}, want: []int{a, b, c, d}},
+		{name: "Between neighbours", id: a, anchors: MoveAnchors{After: ptr(b), Before: ptr(c)}, want: []int{b, a, c, d}},
+		{name: "Non-adjacent anchors", id: d, anchors: MoveAnchors{After: ptr(b), Before: ptr(d)}, wantErr: domain.ErrInvalidMove},
+		{name: "No anchors", id: a, wantErr: domain.ErrInvalidMove},
+		{name: "Foreign anchor", id: a, anchors: MoveAnchors{After: ptr(foreign)}, wantErr: domain.ErrInvalidMove},
+		{name: "Foreign task", id: foreign, anchors: MoveAnchors{After: ptr(a)}, wantErr: domain.ErrTaskNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := ranks()
+			_, err := tasks.Move(ctx, tt.id, tt.anchors)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Move() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got := order(); !slices.Equal(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+			for id, key := range ranks() {
+				if id != tt.id && key != before[id] {
+					t.Errorf("rank of task %d changed %q -> %q, want only moved task rewritten", id, before[id], key)
+				}
+			}
+		})
+	}
+
+	t.Run("Rebalance", func(t *testing.T) {
+		// перестановка туда-обратно в один и тот же зазор удлиняет ключ до перенумерации
+		for range 200 {
+			if _, err := tasks.Move(ctx, c, MoveAnchors{After: ptr(b), Before: ptr(a)}); err != nil {
+				t.Fatalf("Move() error = %v", err)
+			}
+			if _, err := tasks.Move(ctx, a, MoveAnchors{After: ptr(b), Before: ptr(c)}); err != nil {
+				t.Fatalf("Move() error = %v", err)
+			}
+		}
+		if got := order(); !slices.Equal(got, []int{b, a, c, d}) {
+			t.Errorf("order = %v, want [%d %d %d %d]", got, b, a, c, d)
+		}
+		for id, key := range ranks() {
+			if len(key) > maxRankLength {
+				t.Errorf("rank of task %d = %q, want rebalanced key", id, key)
+			}
+		}
+	})
+
+	if _, err := tasks.List(ctx, ListOptions{Sort: "random"}); !errors.Is(err, domain.ErrInvalidSort) {
+		t.Errorf("List(random) error = %v, want ErrInvalidSort", err)
+	}
+}
+
+func TestTaskService_CreateAppendsConcurrently(t *testing.T) {
+	repo := persistance.NewTaskRepository()
+	tasks := NewTaskService(slowReads{repo})
+	ctx := as("alice")
+
+	var wg sync.WaitGroup
+	for range 16 {
+		wg.Go(func() {
+			if _, err := tasks.Create(ctx, &entity.Task{Title: "Card"}); err != nil {
+				t.Errorf("Create() error = %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	list, _ := repo.GetAllByOwner(ctx, "alice")
+	seen := make(map[string]int, len(list))
+	for _, task := range list {
+		if other, ok := seen[task.Rank]; ok {
+			t.Errorf("tasks %d and %d share rank %q", other, task.ID, task.Rank)
+		}
+		seen[task.Rank] = task.ID
+	}
+	sortTasks(list, SortManual)
+	if !slices.IsSortedFunc(list, func(a, b entity.Task) int { return cmp.Compare(a.ID, b.ID) }) {
+		t.Errorf("manual order differs from creation order")
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/domain/policy"
+	"ecom_test/pkg/search"
+	"ecom_test/pkg/tracing"
+	"errors"
+	"fmt"
+)
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+type SearchRepository interface {
+	SearchTasks(ctx context.Context, q search.Query) ([]search.Hit, error)
+}
+
+// WithSearch включает полнотекстовый поиск по задачам.
+func WithSearch(repo SearchRepository) Option {
+	return func(s *TaskService) {
+		s.search = repo
+	}
+}
+
+// SearchResult TitleHighlight заголовок целиком с подсветкой, Snippet фрагмент описания
+// вокруг первого совпадения, пустой если совпало только в заголовке.
+type SearchResult struct {
+	Task           entity.Task
+	Score          float64
+	TitleHighlight string
+	Snippet        string
+}
+
+// Search индекс общий для хранилища, поэтому результаты фильтруются по тем же правам, что и GetByID.
+func (s *TaskService) Search(ctx context.Context, query string, limit int) (_ []SearchResult, err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.Search")
+	defer func() { span.EndWithError(err) }()
+
+	if s.search == nil {
+		return nil, domain.Wrap(domain.ErrSearchUnavailable, "Search", 0)
+	}
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	limit = min(limit, MaxSearchLimit)
+
+	q, err := search.ParseQuery(query)
+	if err != nil {
+		return nil, domain.Wrap(fmt.Errorf("%w: %w", domain.ErrInvalidQuery, err), "Search", 0)
+	}
+	hits, err := s.search.SearchTasks(ctx, q)
+	if err != nil {
+		return nil, domain.Wrap(err, "Search", 0)
+	}
+
+	results := make([]SearchResult, 0, min(len(hits), limit))
+	for _, hit := range hits {
+		if len(results) == limit {
+			break
+		}
+		task, err := s.authorize(ctx, hit.ID, policy.ActionView)
+		if errors.Is(err, domain.ErrTaskNotFound) || errors.Is(err, domain.ErrForbidden) {
+			continue
+		}
+		if err != nil {
+			return nil, domain.Wrap(err, "Search", hit.ID)
+		}
+
+		results = append(results, SearchResult{
+			Task:           *task,
+			Score:          hit.Score,
+			TitleHighlight: search.Highlight(task.Title, q, search.SnippetOptions{}),
+			Snippet:        search.Snippet(task.Description, q, search.SnippetOptions{}),
+		})
+	}
+	span.SetAttribute("search.hits", len(results))
+	return results, nil
+}
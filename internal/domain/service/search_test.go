@@ -0,0 +1,66 @@
+package service
+
+import (
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/infrastructure/persistance"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestTaskService_Search(t *testing.T) {
+	repo := persistance.NewTaskRepository()
+	tasks := NewTaskService(repo, WithShares(repo), WithSearch(repo))
+	alice, bob := as("alice"), as("bob")
+
+	milk, _ := tasks.Create(alice, &entity.Task{Title: "Купить молоко", Description: "Свежее молоко у дома"})
+	notes, _ := tasks.Create(alice, &entity.Task{Title: "Release notes", Description: "Mention milk import"})
+	bobs, _ := tasks.Create(bob, &entity.Task{Title: "Молоко для кота"})
+	shared, _ := tasks.Create(bob, &entity.Task{Title: "Общий список", Description: "молоко, хлеб"})
+	if _, err := tasks.Share(bob, shared, "alice", entity.RoleViewer); err != nil {
+		t.Fatalf("Share() error = %v", err)
+	}
+
+	ids := func(query string) []int {
+		results, err := tasks.Search(alice, query, 0)
+		if err != nil {
+			t.Fatalf("Search(%q) error = %v", query, err)
+		}
+		var got []int
+		for _, r := range results {
+			got = append(got, r.Task.ID)
+		}
+		return got
+	}
+
+	if got := ids("молока"); !slices.Equal(got, []int{milk, shared}) {
+		t.Errorf("Search() = %v, want own and shared tasks %v without %d", got, []int{milk, shared}, bobs)
+	}
+	if got := ids("releas*"); !slices.Equal(got, []int{notes}) {
+		t.Errorf("Search(prefix) = %v, want [%d]", got, notes)
+	}
+
+	results, _ := tasks.Search(alice, "молоко", 1)
+	if len(results) != 1 || results[0].TitleHighlight != "Купить <mark>молоко</mark>" ||
+		results[0].Snippet != "Свежее <mark>молоко</mark> у дома" {
+		t.Errorf("Search() = %+v, want one highlighted result", results)
+	}
+
+	if err := tasks.Update(alice, &entity.Task{ID: milk, Title: "Купить кефир"}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := tasks.Delete(bob, shared); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got := ids("молоко"); len(got) != 0 {
+		t.Errorf("Search() after update and delete = %v, want none", got)
+	}
+	if got := ids("кефир"); !slices.Equal(got, []int{milk}) {
+		t.Errorf("Search() = %v, want updated title indexed", got)
+	}
+
+	if _, err := tasks.Search(alice, `"oops`, 0); !errors.Is(err, domain.ErrInvalidQuery) {
+		t.Errorf("Search() error = %v, want ErrInvalidQuery", err)
+	}
+}
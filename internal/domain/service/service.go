@@ -4,32 +4,135 @@ import (
 	"context"
 	"ecom_test/internal/domain"
 	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/domain/policy"
+	"ecom_test/internal/domain/query"
+	"ecom_test/pkg/contextx"
+	"ecom_test/pkg/tracing"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
 )
 
 type TaskRepository interface {
 	GetByID(ctx context.Context, id int) (*entity.Task, error)
 	GetAll(ctx context.Context) ([]entity.Task, error)
+	GetAllByOwner(ctx context.Context, ownerID string) ([]entity.Task, error)
+	// Find задачи владельца (всех при пустом ownerID), для которых match вернул true.
+	Find(ctx context.Context, ownerID string, match func(entity.Task) bool) ([]entity.Task, error)
+	// FindPage как Find, но не больше limit задач с ID больше afterID, по возрастанию ID.
+	FindPage(ctx context.Context, ownerID string, afterID, limit int, match func(entity.Task) bool) ([]entity.Task, error)
 	Create(ctx context.Context, task *entity.Task) (int, error)
 	Update(ctx context.Context, task *entity.Task) error
 	Delete(ctx context.Context, id int) error
 }
 
 type TaskService struct {
-	repo TaskRepository
+	repo     TaskRepository
+	shares   ShareRepository
+	projects ProjectRepository
+	boards   BoardRepository
+	search   SearchRepository
+	now      func() time.Time
 }
 
-func NewTaskService(repo TaskRepository) *TaskService {
-	return &TaskService{
+// ListOptions параметры списка задач, нулевое значение даёт список по умолчанию.
+type ListOptions struct {
+	// IncludeArchived показывать задачи архивных проектов.
+	IncludeArchived bool
+	// Sort порядок списка: SortID (по умолчанию) или SortManual по ключу ручной сортировки.
+	Sort string
+	// Query фильтр на языке пакета query, пустая строка без фильтра.
+	Query string
+}
+
+type Option func(*TaskService)
+
+// WithShares включает совместный доступ, без него задачи видны только владельцу.
+func WithShares(shares ShareRepository) Option {
+	return func(s *TaskService) {
+		s.shares = shares
+	}
+}
+
+// WithProjects включает привязку задач к проектам.
+func WithProjects(projects ProjectRepository) Option {
+	return func(s *TaskService) {
+		s.projects = projects
+	}
+}
+
+func NewTaskService(repo TaskRepository, opts ...Option) *TaskService {
+	s := &TaskService{
 		repo: repo,
+		now:  time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// caller возвращает владельца, к которому привязан запрос. Без Principal в контексте
+// вызов считается внутренним (миграции, фоновые задачи) и видит все задачи.
+func caller(ctx context.Context) (string, bool) {
+	principal, err := contextx.PrincipalFromContext(ctx)
+	if err != nil {
+		return "", false
+	}
+	return principal.Subject, true
+}
+
+// authorize загружает задачу и проверяет действие через policy с учётом доступа к её проекту.
+// Задача, к которой у вызывающего нет никакого доступа, отдаёт ErrTaskNotFound, чтобы не раскрывать
+// её существование; ErrForbidden только если задача видна, но роль не позволяет действие.
+func (s *TaskService) authorize(ctx context.Context, id int, action policy.Action) (*entity.Task, error) {
+	task, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	user, ok := caller(ctx)
+	if !ok {
+		return task, nil
+	}
+
+	var share *entity.Share
+	if task.OwnerID != user && s.shares != nil {
+		share, err = s.shares.GetShare(ctx, id, user)
+		if err != nil && !errors.Is(err, domain.ErrShareNotFound) {
+			return nil, err
+		}
+	}
+	var projectShare *entity.ProjectShare
+	if task.OwnerID != user && task.ProjectID != nil && s.projects != nil {
+		projectShare, err = s.projects.GetProjectShare(ctx, *task.ProjectID, user)
+		if err != nil && !errors.Is(err, domain.ErrShareNotFound) {
+			return nil, err
+		}
+	}
+
+	role, ok := policy.TaskRoleOf(task, share, projectShare, user)
+	if !ok {
+		return nil, domain.ErrTaskNotFound
 	}
+	if !policy.Can(role, action) {
+		return nil, domain.ErrForbidden
+	}
+	return task, nil
 }
 
-func (s *TaskService) GetByID(ctx context.Context, id int) (*entity.Task, error) {
+func (s *TaskService) GetByID(ctx context.Context, id int) (_ *entity.Task, err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.GetByID")
+	defer func() { span.EndWithError(err) }()
+	span.SetAttribute("task.id", id)
+
 	if id < 0 {
 		return nil, domain.Wrap(domain.ErrInvalidID, "GetByID", id)
 	}
 
-	task, err := s.repo.GetByID(ctx, id)
+	task, err := s.authorize(ctx, id, policy.ActionView)
 	if err != nil {
 		return nil, domain.Wrap(err, "GetByID", id)
 	}
@@ -37,30 +140,193 @@ func (s *TaskService) GetByID(ctx context.Context, id int) (*entity.Task, error)
 }
 
 func (s *TaskService) GetAll(ctx context.Context) ([]entity.Task, error) {
-	tasks, err := s.repo.GetAll(ctx)
+	return s.List(ctx, ListOptions{})
+}
+
+func (s *TaskService) List(ctx context.Context, opts ListOptions) (_ []entity.Task, err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.List")
+	defer func() { span.EndWithError(err) }()
+
+	if !validSort(opts.Sort) {
+		return nil, domain.Wrap(domain.ErrInvalidSort, "GetAll", 0)
+	}
+	owner, scoped := caller(ctx)
+	var tasks []entity.Task
+	switch {
+	case opts.Query != "":
+		match, qerr := query.ParseAndCompile(opts.Query, s.now())
+		if qerr != nil {
+			return nil, domain.Wrap(fmt.Errorf("%w: %w", domain.ErrInvalidQuery, qerr), "GetAll", 0)
+		}
+		tasks, err = s.repo.Find(ctx, owner, match)
+	case scoped:
+		tasks, err = s.repo.GetAllByOwner(ctx, owner)
+	default:
+		tasks, err = s.repo.GetAll(ctx)
+	}
 	if err != nil {
 		return nil, domain.Wrap(err, "GetAll", 0)
 	}
+
+	if !opts.IncludeArchived && s.projects != nil {
+		tasks, err = s.hideArchived(ctx, owner, tasks)
+		if err != nil {
+			return nil, domain.Wrap(err, "GetAll", 0)
+		}
+	}
+	sortTasks(tasks, opts.Sort)
 	return tasks, nil
 }
 
-func (s *TaskService) Create(ctx context.Context, task *entity.Task) (int, error) {
+// archivedProjects ID архивных проектов владельца, без проектов пустое множество.
+func (s *TaskService) archivedProjects(ctx context.Context, owner string) (map[int]struct{}, error) {
+	archived := make(map[int]struct{})
+	if s.projects == nil {
+		return archived, nil
+	}
+	projects, err := s.projects.ListProjects(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range projects {
+		if p.Archived() {
+			archived[p.ID] = struct{}{}
+		}
+	}
+	return archived, nil
+}
+
+func (s *TaskService) hideArchived(ctx context.Context, owner string, tasks []entity.Task) ([]entity.Task, error) {
+	archived, err := s.archivedProjects(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	if len(archived) == 0 {
+		return tasks, nil
+	}
+
+	visible := tasks[:0]
+	for _, t := range tasks {
+		if t.ProjectID != nil {
+			if _, ok := archived[*t.ProjectID]; ok {
+				continue
+			}
+		}
+		visible = append(visible, t)
+	}
+	return visible, nil
+}
+
+// checkProject проект задачи должен принадлежать владельцу задачи и не быть в архиве.
+func (s *TaskService) checkProject(ctx context.Context, projectID int, ownerID string) error {
+	if s.projects == nil {
+		return domain.ErrProjectNotFound
+	}
+	project, err := s.projects.GetProject(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if project.OwnerID != ownerID {
+		return domain.ErrProjectNotFound
+	}
+	if project.Archived() {
+		return domain.ErrProjectArchived
+	}
+	return nil
+}
+
+// normalize приводит метки к нижнему регистру без пробелов и повторов и проверяет приоритет.
+func normalize(task *entity.Task) error {
+	task.Priority = entity.Priority(strings.ToLower(strings.TrimSpace(string(task.Priority))))
+	if !task.Priority.Valid() {
+		return fmt.Errorf("%w: %q", domain.ErrInvalidPriority, task.Priority)
+	}
+
+	tags := make([]string, 0, len(task.Tags))
+	for _, tag := range task.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	task.Tags = tags
+	return nil
+}
+
+func (s *TaskService) Create(ctx context.Context, task *entity.Task) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.Create")
+	defer func() { span.EndWithError(err) }()
+
+	if task == nil {
+		return 0, domain.Wrap(domain.ErrEmptyTask, "Create", 0)
+	}
+	status := entity.StatusTodo
+	if task.IsCompleted {
+		status = entity.StatusDone
+	}
+	return s.create(ctx, task, status)
+}
+
+// CreateInStatus как Create, но задача сразу получает status, если в него можно попасть из todo
+// допустимыми переходами. WIP-лимит проверяется для status при записи, поэтому при отказе задача
+// не остаётся созданной в другом статусе, как после Create с последующим Transition.
+func (s *TaskService) CreateInStatus(ctx context.Context, task *entity.Task, status entity.TaskStatus) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.CreateInStatus")
+	defer func() { span.EndWithError(err) }()
+
 	if task == nil {
 		return 0, domain.Wrap(domain.ErrEmptyTask, "Create", 0)
 	}
+	if !status.Valid() {
+		return 0, domain.Wrap(fmt.Errorf("%w: %q", domain.ErrInvalidStatus, status), "Create", 0)
+	}
+	if !entity.StatusTodo.CanReach(status) {
+		return 0, domain.Wrap(fmt.Errorf("%w: %s -> %s", domain.ErrInvalidTransition, entity.StatusTodo, status), "Create", 0)
+	}
+	return s.create(ctx, task, status)
+}
 
+func (s *TaskService) create(ctx context.Context, task *entity.Task, status entity.TaskStatus) (int, error) {
 	if task.Title == "" {
 		return 0, domain.Wrap(domain.ErrEmptyTitle, "Create", 0)
 	}
+	if err := normalize(task); err != nil {
+		return 0, domain.Wrap(err, "Create", 0)
+	}
+	if owner, ok := caller(ctx); ok {
+		task.OwnerID = owner
+	}
+	if task.ProjectID != nil {
+		if err := s.checkProject(ctx, *task.ProjectID, task.OwnerID); err != nil {
+			return 0, domain.Wrap(err, "Create", 0)
+		}
+	}
+
+	task.ID = unsavedID
+	guard, err := s.wipGuard(ctx, task, status)
+	if err != nil {
+		return 0, domain.Wrap(err, "Create", 0)
+	}
+	s.setStatus(task, status)
 
-	id, err := s.repo.Create(ctx, task)
+	// пустой ключ хранилище заменяет ключом в конце списка владельца под локом записи
+	task.Rank = ""
+	id, err := s.createGuarded(ctx, task, guard)
 	if err != nil {
 		return 0, domain.Wrap(err, "Create", 0)
 	}
+	task.ID = id
+	if len(task.Rank) > maxRankLength {
+		s.rebalanceOwner(ctx, task.OwnerID)
+	}
+	logger(ctx).Info("task created", slog.Int("task_id", id))
 	return id, nil
 }
 
-func (s *TaskService) Update(ctx context.Context, task *entity.Task) error {
+func (s *TaskService) Update(ctx context.Context, task *entity.Task) (err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.Update")
+	defer func() { span.EndWithError(err) }()
+
 	if task == nil {
 		return domain.Wrap(domain.ErrEmptyTask, "Update", 0)
 	}
@@ -70,22 +336,86 @@ func (s *TaskService) Update(ctx context.Context, task *entity.Task) error {
 	if task.Title == "" {
 		return domain.Wrap(domain.ErrEmptyTitle, "Update", task.ID)
 	}
+	if err := normalize(task); err != nil {
+		return domain.Wrap(err, "Update", task.ID)
+	}
+	var guard func([]entity.Task) error
+	if _, ok := caller(ctx); ok {
+		existing, err := s.authorize(ctx, task.ID, policy.ActionEdit)
+		if err != nil {
+			return domain.Wrap(err, "Update", task.ID)
+		}
+		task.OwnerID = existing.OwnerID
+		task.ProjectID = existing.ProjectID
+		task.Rank = existing.Rank
+		task.Status = existing.Status
+		task.StatusChangedAt = existing.StatusChangedAt
+		task.UID = existing.UID
+		task.Resource = existing.Resource
+
+		// отметка о выполнении это переход в done или обратно в todo по тем же правилам
+		if task.IsCompleted != (existing.CurrentStatus() == entity.StatusDone) {
+			status := entity.StatusTodo
+			if task.IsCompleted {
+				status = entity.StatusDone
+			}
+			if guard, err = s.wipGuard(ctx, existing, status); err != nil {
+				return domain.Wrap(err, "Update", task.ID)
+			}
+			s.setStatus(task, status)
+		}
+	}
 
-	err := s.repo.Update(ctx, task)
+	err = s.updateGuarded(ctx, task, guard)
 	if err != nil {
 		return domain.Wrap(err, "Update", task.ID)
 	}
+	logger(ctx).Info("task updated", slog.Int("task_id", task.ID))
 	return nil
 }
 
-func (s *TaskService) Delete(ctx context.Context, id int) error {
+func (s *TaskService) Delete(ctx context.Context, id int) (err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.Delete")
+	defer func() { span.EndWithError(err) }()
+	span.SetAttribute("task.id", id)
+
 	if id < 0 {
 		return domain.Wrap(domain.ErrInvalidID, "Delete", id)
 	}
+	if _, ok := caller(ctx); ok {
+		if _, err := s.authorize(ctx, id, policy.ActionDelete); err != nil {
+			return domain.Wrap(err, "Delete", id)
+		}
+	}
 
-	err := s.repo.Delete(ctx, id)
+	err = s.repo.Delete(ctx, id)
 	if err != nil {
 		return domain.Wrap(err, "Delete", id)
 	}
+	logger(ctx).Info("task deleted", slog.Int("task_id", id))
 	return nil
 }
+
+// MoveToProject переносит задачу в проект, projectID == nil убирает её из проекта.
+func (s *TaskService) MoveToProject(ctx context.Context, id int, projectID *int) (_ *entity.Task, err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.MoveToProject")
+	defer func() { span.EndWithError(err) }()
+	span.SetAttribute("task.id", id)
+
+	task, err := s.authorize(ctx, id, policy.ActionEdit)
+	if err != nil {
+		return nil, domain.Wrap(err, "MoveToProject", id)
+	}
+	if projectID != nil {
+		if err := s.checkProject(ctx, *projectID, task.OwnerID); err != nil {
+			return nil, domain.Wrap(err, "MoveToProject", id)
+		}
+	}
+
+	task.ProjectID = projectID
+	if err := s.repo.Update(ctx, task); err != nil {
+		return nil, domain.Wrap(err, "MoveToProject", id)
+	}
+	logger(ctx).Info("task moved to project", slog.Int("task_id", id))
+	return task, nil
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"ecom_test/internal/domain"
 	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/domain/query"
+	"ecom_test/internal/infrastructure/persistance"
+	"ecom_test/pkg/contextx"
 	"errors"
 	"testing"
+	"time"
 )
 
 type MockTaskRepository struct {
@@ -14,6 +18,10 @@ type MockTaskRepository struct {
 	GetAllFunc  func(ctx context.Context) ([]entity.Task, error)
 	UpdateFunc  func(ctx context.Context, task *entity.Task) error
 	DeleteFunc  func(ctx context.Context, id int) error
+
+	GetAllByOwnerFunc func(ctx context.Context, ownerID string) ([]entity.Task, error)
+	FindFunc          func(ctx context.Context, ownerID string, match func(entity.Task) bool) ([]entity.Task, error)
+	FindPageFunc      func(ctx context.Context, ownerID string, afterID, limit int, match func(entity.Task) bool) ([]entity.Task, error)
 }
 
 func (m *MockTaskRepository) GetByID(ctx context.Context, id int) (*entity.Task, error) {
@@ -25,6 +33,18 @@ func (m *MockTaskRepository) Create(ctx context.Context, task *entity.Task) (int
 func (m *MockTaskRepository) GetAll(ctx context.Context) ([]entity.Task, error) {
 	return m.GetAllFunc(ctx)
 }
+func (m *MockTaskRepository) GetAllByOwner(ctx context.Context, ownerID string) ([]entity.Task, error) {
+	if m.GetAllByOwnerFunc == nil {
+		return nil, nil
+	}
+	return m.GetAllByOwnerFunc(ctx, ownerID)
+}
+func (m *MockTaskRepository) Find(ctx context.Context, ownerID string, match func(entity.Task) bool) ([]entity.Task, error) {
+	return m.FindFunc(ctx, ownerID, match)
+}
+func (m *MockTaskRepository) FindPage(ctx context.Context, ownerID string, afterID, limit int, match func(entity.Task) bool) ([]entity.Task, error) {
+	return m.FindPageFunc(ctx, ownerID, afterID, limit, match)
+}
 func (m *MockTaskRepository) Update(ctx context.Context, task *entity.Task) error {
 	return m.UpdateFunc(ctx, task)
 }
@@ -263,3 +283,100 @@ func TestTaskService_GetByID(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskService_Ownership(t *testing.T) {
+	tasks := map[int]entity.Task{
+		1: {ID: 1, Title: "Alice task", OwnerID: "alice"},
+		2: {ID: 2, Title: "Bob task", OwnerID: "bob"},
+	}
+	repo := &MockTaskRepository{
+		GetByIDFunc: func(ctx context.Context, id int) (*entity.Task, error) {
+			if task, ok := tasks[id]; ok {
+				return &task, nil
+			}
+			return nil, domain.ErrTaskNotFound
+		},
+		GetAllByOwnerFunc: func(ctx context.Context, ownerID string) ([]entity.Task, error) {
+			var out []entity.Task
+			for _, task := range tasks {
+				if task.OwnerID == ownerID {
+					out = append(out, task)
+				}
+			}
+			return out, nil
+		},
+		CreateFunc: func(ctx context.Context, task *entity.Task) (int, error) {
+			if task.OwnerID != "alice" {
+				t.Errorf("Create() OwnerID = %q, want alice", task.OwnerID)
+			}
+			return 3, nil
+		},
+		UpdateFunc: func(ctx context.Context, task *entity.Task) error {
+			if task.OwnerID != "alice" {
+				t.Errorf("Update() OwnerID = %q, want alice", task.OwnerID)
+			}
+			return nil
+		},
+		DeleteFunc: func(ctx context.Context, id int) error { return nil },
+	}
+	svc := NewTaskService(repo)
+	ctx := contextx.WithPrincipal(context.Background(), contextx.Principal{Subject: "alice"})
+
+	all, err := svc.GetAll(ctx)
+	if err != nil || len(all) != 1 || all[0].ID != 1 {
+		t.Errorf("GetAll() = %v, %v, want only own task", all, err)
+	}
+	if _, err := svc.Create(ctx, &entity.Task{Title: "New", OwnerID: "bob"}); err != nil {
+		t.Errorf("Create() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr error
+	}{
+		{name: "Get own", call: func() error { _, err := svc.GetByID(ctx, 1); return err }},
+		{name: "Get foreign", call: func() error { _, err := svc.GetByID(ctx, 2); return err }, wantErr: domain.ErrTaskNotFound},
+		{name: "Update own", call: func() error { return svc.Update(ctx, &entity.Task{ID: 1, Title: "Edited"}) }},
+		{name: "Update foreign", call: func() error { return svc.Update(ctx, &entity.Task{ID: 2, Title: "Edited"}) }, wantErr: domain.ErrTaskNotFound},
+		{name: "Delete own", call: func() error { return svc.Delete(ctx, 1) }},
+		{name: "Delete foreign", call: func() error { return svc.Delete(ctx, 2) }, wantErr: domain.ErrTaskNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTaskService_ListQuery(t *testing.T) {
+	repo := persistance.NewTaskRepository()
+	tasks := NewTaskService(repo)
+	tasks.now = func() time.Time { return time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC) }
+	alice := as("alice")
+
+	friday := time.Date(2026, 10, 23, 18, 0, 0, 0, time.UTC)
+	api, _ := tasks.Create(alice, &entity.Task{Title: "API", Tags: []string{" Backend "}, DueAt: &friday})
+	db, _ := tasks.Create(alice, &entity.Task{Title: "DB", Tags: []string{"backend"}, Priority: "HIGH"})
+	_, _ = tasks.Create(alice, &entity.Task{Title: "UI", Tags: []string{"frontend"}, Priority: entity.PriorityHigh})
+	_, _ = tasks.Create(as("bob"), &entity.Task{Title: "Bob", Tags: []string{"backend"}, Priority: entity.PriorityHigh})
+
+	got, err := tasks.List(alice, ListOptions{Query: "status:open AND tag:backend AND (due<=2026-10-23 OR priority:high)"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(got) != 2 || got[0].ID != api || got[1].ID != db {
+		t.Errorf("List() = %v, want own backend tasks [%d %d]", got, api, db)
+	}
+
+	_, err = tasks.List(alice, ListOptions{Query: "tag:backend AND"})
+	var perr *query.Error
+	if !errors.Is(err, domain.ErrInvalidQuery) || !errors.As(err, &perr) || perr.Pos != 16 {
+		t.Errorf("List() error = %v, want ErrInvalidQuery at position 16", err)
+	}
+	if _, err := tasks.Create(alice, &entity.Task{Title: "X", Priority: "urgent"}); !errors.Is(err, domain.ErrInvalidPriority) {
+		t.Errorf("Create() error = %v, want ErrInvalidPriority", err)
+	}
+}
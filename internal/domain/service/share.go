@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/domain/policy"
+	"ecom_test/pkg/tracing"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+type ShareRepository interface {
+	GetShare(ctx context.Context, taskID int, userID string) (*entity.Share, error)
+	ListShares(ctx context.Context, taskID int) ([]entity.Share, error)
+	PutShare(ctx context.Context, share entity.Share) error
+	DeleteShare(ctx context.Context, taskID int, userID string) error
+	GetSharedWith(ctx context.Context, userID string) ([]entity.Task, error)
+}
+
+func (s *TaskService) Share(ctx context.Context, taskID int, userID string, role entity.Role) (_ *entity.Share, err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.Share")
+	defer func() { span.EndWithError(err) }()
+	span.SetAttribute("task.id", taskID)
+
+	if s.shares == nil {
+		return nil, domain.Wrap(domain.ErrForbidden, "Share", taskID)
+	}
+	if userID == "" || !role.Valid() {
+		return nil, domain.Wrap(domain.ErrInvalidShare, "Share", taskID)
+	}
+
+	task, err := s.authorize(ctx, taskID, policy.ActionShare)
+	if err != nil {
+		return nil, domain.Wrap(err, "Share", taskID)
+	}
+	if task.OwnerID == userID {
+		return nil, domain.Wrap(domain.ErrInvalidShare, "Share", taskID)
+	}
+
+	grantor, _ := caller(ctx)
+	share := entity.Share{
+		TaskID:    taskID,
+		UserID:    userID,
+		Role:      role,
+		GrantedBy: grantor,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.shares.PutShare(ctx, share); err != nil {
+		return nil, domain.Wrap(err, "Share", taskID)
+	}
+	logger(ctx).Info("task shared", slog.Int("task_id", taskID), slog.String("user_id", userID), slog.String("role", string(role)))
+	return &share, nil
+}
+
+// Unshare отзывает доступ. Пользователь всегда может отказаться от собственного доступа.
+func (s *TaskService) Unshare(ctx context.Context, taskID int, userID string) (err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.Unshare")
+	defer func() { span.EndWithError(err) }()
+	span.SetAttribute("task.id", taskID)
+
+	if s.shares == nil {
+		return domain.Wrap(domain.ErrShareNotFound, "Unshare", taskID)
+	}
+
+	action := policy.ActionShare
+	if user, ok := caller(ctx); ok && user == userID {
+		action = policy.ActionView
+	}
+	if _, err := s.authorize(ctx, taskID, action); err != nil {
+		return domain.Wrap(err, "Unshare", taskID)
+	}
+
+	if err := s.shares.DeleteShare(ctx, taskID, userID); err != nil {
+		return domain.Wrap(err, "Unshare", taskID)
+	}
+	logger(ctx).Info("task unshared", slog.Int("task_id", taskID), slog.String("user_id", userID))
+	return nil
+}
+
+func (s *TaskService) Shares(ctx context.Context, taskID int) (_ []entity.Share, err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.Shares")
+	defer func() { span.EndWithError(err) }()
+	span.SetAttribute("task.id", taskID)
+
+	if _, err := s.authorize(ctx, taskID, policy.ActionView); err != nil {
+		return nil, domain.Wrap(err, "Shares", taskID)
+	}
+	if s.shares == nil {
+		return nil, nil
+	}
+
+	shares, err := s.shares.ListShares(ctx, taskID)
+	if err != nil {
+		return nil, domain.Wrap(err, "Shares", taskID)
+	}
+	return shares, nil
+}
+
+// SharedWithMe задачи других пользователей, к которым у вызывающего есть доступ: открытые
+// напрямую и задачи открытых ему проектов.
+func (s *TaskService) SharedWithMe(ctx context.Context) (_ []entity.Task, err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.SharedWithMe")
+	defer func() { span.EndWithError(err) }()
+
+	user, ok := caller(ctx)
+	if !ok {
+		return []entity.Task{}, nil
+	}
+
+	tasks := []entity.Task{}
+	if s.shares != nil {
+		if tasks, err = s.shares.GetSharedWith(ctx, user); err != nil {
+			return nil, domain.Wrap(err, "SharedWithMe", 0)
+		}
+	}
+	if s.projects != nil {
+		projects, err := s.projects.GetSharedProjects(ctx, user)
+		if err != nil {
+			return nil, domain.Wrap(err, "SharedWithMe", 0)
+		}
+		for _, p := range projects {
+			inProject, err := s.projects.GetTasksByProject(ctx, p.ID)
+			if err != nil {
+				return nil, domain.Wrap(err, "SharedWithMe", 0)
+			}
+			tasks = append(tasks, inProject...)
+		}
+	}
+
+	// задача может быть открыта и напрямую, и через проект
+	sortTasks(tasks, SortID)
+	return slices.CompactFunc(tasks, func(a, b entity.Task) bool { return a.ID == b.ID }), nil
+}
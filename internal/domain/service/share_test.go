@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/infrastructure/persistance"
+	"ecom_test/pkg/contextx"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func as(user string) context.Context {
+	return contextx.WithPrincipal(context.Background(), contextx.Principal{Subject: user})
+}
+
+func TestTaskService_RoleMatrix(t *testing.T) {
+	operations := []struct {
+		name string
+		call func(svc *TaskService, ctx context.Context, id int) error
+	}{
+		{name: "get", call: func(svc *TaskService, ctx context.Context, id int) error {
+			_, err := svc.GetByID(ctx, id)
+			return err
+		}},
+		{name: "list shares", call: func(svc *TaskService, ctx context.Context, id int) error {
+			_, err := svc.Shares(ctx, id)
+			return err
+		}},
+		{name: "update", call: func(svc *TaskService, ctx context.Context, id int) error {
+			return svc.Update(ctx, &entity.Task{ID: id, Title: "Edited"})
+		}},
+		{name: "share", call: func(svc *TaskService, ctx context.Context, id int) error {
+			_, err := svc.Share(ctx, id, "dave", entity.RoleViewer)
+			return err
+		}},
+		{name: "revoke other", call: func(svc *TaskService, ctx context.Context, id int) error {
+			return svc.Unshare(ctx, id, "carol")
+		}},
+		{name: "delete", call: func(svc *TaskService, ctx context.Context, id int) error {
+			return svc.Delete(ctx, id)
+		}},
+	}
+
+	// ожидаемая ошибка для каждой роли по операциям в порядке operations
+	roles := []struct {
+		role entity.Role
+		want []error
+	}{
+		{role: entity.RoleViewer, want: []error{nil, nil, domain.ErrForbidden, domain.ErrForbidden, domain.ErrForbidden, domain.ErrForbidden}},
+		{role: entity.RoleEditor, want: []error{nil, nil, nil, domain.ErrForbidden, domain.ErrForbidden, domain.ErrForbidden}},
+		{role: entity.RoleOwner, want: []error{nil, nil, nil, nil, nil, nil}},
+		// без доступа задача не должна даже обнаруживаться
+		{role: "", want: []error{domain.ErrTaskNotFound, domain.ErrTaskNotFound, domain.ErrTaskNotFound, domain.ErrTaskNotFound, domain.ErrTaskNotFound, domain.ErrTaskNotFound}},
+	}
+
+	for _, rr := range roles {
+		for i, op := range operations {
+			name := string(rr.role)
+			if name == "" {
+				name = "stranger"
+			}
+			t.Run(name+"/"+op.name, func(t *testing.T) {
+				repo := persistance.NewTaskRepository()
+				svc := NewTaskService(repo, WithShares(repo))
+
+				id, _ := svc.Create(as("alice"), &entity.Task{Title: "Shared task"})
+				if _, err := svc.Share(as("alice"), id, "carol", entity.RoleViewer); err != nil {
+					t.Fatalf("Share() error = %v", err)
+				}
+				if rr.role != "" {
+					if _, err := svc.Share(as("alice"), id, "bob", rr.role); err != nil {
+						t.Fatalf("Share() error = %v", err)
+					}
+				}
+
+				if err := op.call(svc, as("bob"), id); !errors.Is(err, rr.want[i]) {
+					t.Errorf("%s as %s: error = %v, wantErr %v", op.name, name, err, rr.want[i])
+				}
+			})
+		}
+	}
+}
+
+func TestTaskService_Sharing(t *testing.T) {
+	repo := persistance.NewTaskRepository()
+	svc := NewTaskService(repo, WithShares(repo))
+
+	id, _ := svc.Create(as("alice"), &entity.Task{Title: "Shared task"})
+	_, _ = svc.Create(as("alice"), &entity.Task{Title: "Private task"})
+
+	tests := []struct {
+		name    string
+		user    string
+		role    entity.Role
+		wantErr error
+	}{
+		{name: "Unknown role", user: "bob", role: "admin", wantErr: domain.ErrInvalidShare},
+		{name: "Empty user", user: "", role: entity.RoleViewer, wantErr: domain.ErrInvalidShare},
+		{name: "Share with owner", user: "alice", role: entity.RoleViewer, wantErr: domain.ErrInvalidShare},
+		{name: "Valid", user: "bob", role: entity.RoleEditor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Share(as("alice"), id, tt.user, tt.role); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Share() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	shared, err := svc.SharedWithMe(as("bob"))
+	if err != nil || len(shared) != 1 || shared[0].ID != id {
+		t.Fatalf("SharedWithMe() = %v, %v, want only the shared task", shared, err)
+	}
+	if own, _ := svc.GetAll(as("bob")); len(own) != 0 {
+		t.Errorf("Expected shared task not to appear in bob's own list, got %v", own)
+	}
+
+	// редактор не меняет владельца задачи
+	if err := svc.Update(as("bob"), &entity.Task{ID: id, Title: "Edited by bob"}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if task, _ := svc.GetByID(as("alice"), id); task.OwnerID != "alice" || task.Title != "Edited by bob" {
+		t.Errorf("Unexpected task after editor update: %+v", task)
+	}
+
+	// пользователь может сам отказаться от доступа
+	if err := svc.Unshare(as("bob"), id, "bob"); err != nil {
+		t.Fatalf("Unshare() self error = %v", err)
+	}
+	if _, err := svc.GetByID(as("bob"), id); !errors.Is(err, domain.ErrTaskNotFound) {
+		t.Errorf("Expected access to be revoked, got %v", err)
+	}
+
+	_, _ = svc.Share(as("alice"), id, "bob", entity.RoleViewer)
+	if err := svc.Delete(as("alice"), id); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if shared, _ := svc.SharedWithMe(as("bob")); len(shared) != 0 {
+		t.Errorf("Expected shares to be dropped with the task, got %v", shared)
+	}
+}
+
+func TestProjectSharing_RoleMatrix(t *testing.T) {
+	operations := []struct {
+		name string
+		call func(env projectShareEnv, ctx context.Context) error
+	}{
+		{name: "get project", call: func(env projectShareEnv, ctx context.Context) error {
+			_, err := env.projects.Get(ctx, env.projectID)
+			return err
+		}},
+		{name: "get task", call: func(env projectShareEnv, ctx context.Context) error {
+			_, err := env.tasks.GetByID(ctx, env.taskID)
+			return err
+		}},
+		{name: "update task", call: func(env projectShareEnv, ctx context.Context) error {
+			return env.tasks.Update(ctx, &entity.Task{ID: env.taskID, Title: "Edited"})
+		}},
+		{name: "rename project", call: func(env projectShareEnv, ctx context.Context) error {
+			return env.projects.Update(ctx, &entity.Project{ID: env.projectID, Name: "Renamed"})
+		}},
+		{name: "share project", call: func(env projectShareEnv, ctx context.Context) error {
+			_, err := env.projects.Share(ctx, env.projectID, "dave", entity.RoleViewer)
+			return err
+		}},
+		{name: "revoke other", call: func(env projectShareEnv, ctx context.Context) error {
+			return env.projects.Unshare(ctx, env.projectID, "carol")
+		}},
+		{name: "delete task", call: func(env projectShareEnv, ctx context.Context) error {
+			return env.tasks.Delete(ctx, env.taskID)
+		}},
+		{name: "delete project", call: func(env projectShareEnv, ctx context.Context) error {
+			return env.projects.Delete(ctx, env.projectID, entity.DeleteCascade)
+		}},
+	}
+
+	// ожидаемая ошибка для каждой роли по операциям в порядке operations
+	roles := []struct {
+		role entity.Role
+		want []error
+	}{
+		{role: entity.RoleViewer, want: []error{nil, nil, domain.ErrForbidden, domain.ErrForbidden, domain.ErrForbidden, domain.ErrForbidden, domain.ErrForbidden, domain.ErrForbidden}},
+		{role: entity.RoleEditor, want: []error{nil, nil, nil, nil, domain.ErrForbidden, domain.ErrForbidden, domain.ErrForbidden, domain.ErrForbidden}},
+		{role: entity.RoleOwner, want: []error{nil, nil, nil, nil, nil, nil, nil, nil}},
+		// без доступа ни проект, ни его задачи не должны даже обнаруживаться
+		{role: "", want: []error{
+			domain.ErrProjectNotFound, domain.ErrTaskNotFound, domain.ErrTaskNotFound, domain.ErrProjectNotFound,
+			domain.ErrProjectNotFound, domain.ErrProjectNotFound, domain.ErrTaskNotFound, domain.ErrProjectNotFound,
+		}},
+	}
+
+	for _, rr := range roles {
+		for i, op := range operations {
+			name := string(rr.role)
+			if name == "" {
+				name = "stranger"
+			}
+			t.Run(name+"/"+op.name, func(t *testing.T) {
+				env := newProjectShareEnv(t)
+				if rr.role != "" {
+					if _, err := env.projects.Share(as("alice"), env.projectID, "bob", rr.role); err != nil {
+						t.Fatalf("Share() error = %v", err)
+					}
+				}
+
+				if err := op.call(env, as("bob")); !errors.Is(err, rr.want[i]) {
+					t.Errorf("%s as %s: error = %v, wantErr %v", op.name, name, err, rr.want[i])
+				}
+			})
+		}
+	}
+}
+
+func TestProjectSharing(t *testing.T) {
+	env := newProjectShareEnv(t)
+	alice, bob := as("alice"), as("bob")
+
+	if _, err := env.projects.Share(alice, env.projectID, "alice", entity.RoleViewer); !errors.Is(err, domain.ErrInvalidShare) {
+		t.Errorf("Share() with owner error = %v, want ErrInvalidShare", err)
+	}
+	if _, err := env.projects.Share(alice, env.projectID, "bob", entity.RoleViewer); err != nil {
+		t.Fatalf("Share() error = %v", err)
+	}
+
+	// задачи, добавленные в проект после выдачи доступа, тоже открыты
+	later, _ := env.tasks.Create(alice, &entity.Task{Title: "Later", ProjectID: &env.projectID})
+	loose, _ := env.tasks.Create(alice, &entity.Task{Title: "Outside"})
+	direct, _ := env.tasks.Create(alice, &entity.Task{Title: "Direct", ProjectID: &env.projectID})
+	if _, err := env.tasks.Share(alice, direct, "bob", entity.RoleEditor); err != nil {
+		t.Fatalf("Share() task error = %v", err)
+	}
+
+	shared, err := env.tasks.SharedWithMe(bob)
+	if err != nil {
+		t.Fatalf("SharedWithMe() error = %v", err)
+	}
+	ids := make([]int, 0, len(shared))
+	for _, task := range shared {
+		ids = append(ids, task.ID)
+	}
+	if !slices.Equal(ids, []int{env.taskID, later, direct}) {
+		t.Errorf("SharedWithMe() = %v, want project tasks once each without %d", ids, loose)
+	}
+	if projects, _ := env.projects.SharedWithMe(bob); len(projects) != 1 || projects[0].ID != env.projectID {
+		t.Errorf("projects SharedWithMe() = %v, want the shared project", projects)
+	}
+
+	// прямой доступ старше унаследованного
+	if err := env.tasks.Update(bob, &entity.Task{ID: direct, Title: "Edited"}); err != nil {
+		t.Errorf("Update() with direct editor share error = %v", err)
+	}
+	if err := env.tasks.Update(bob, &entity.Task{ID: later, Title: "Edited"}); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Update() with inherited viewer role error = %v, want ErrForbidden", err)
+	}
+
+	if err := env.projects.Unshare(bob, env.projectID, "bob"); err != nil {
+		t.Fatalf("Unshare() self error = %v", err)
+	}
+	if _, err := env.tasks.GetByID(bob, later); !errors.Is(err, domain.ErrTaskNotFound) {
+		t.Errorf("GetByID() after unshare error = %v, want ErrTaskNotFound", err)
+	}
+
+	_, _ = env.projects.Share(alice, env.projectID, "bob", entity.RoleViewer)
+	if err := env.projects.Delete(alice, env.projectID, entity.DeleteOrphan); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if projects, _ := env.projects.SharedWithMe(bob); len(projects) != 0 {
+		t.Errorf("Expected project shares to be dropped with the project, got %v", projects)
+	}
+	if _, err := env.tasks.GetByID(bob, later); !errors.Is(err, domain.ErrTaskNotFound) {
+		t.Errorf("GetByID() of orphaned task error = %v, want ErrTaskNotFound", err)
+	}
+}
+
+type projectShareEnv struct {
+	tasks     *TaskService
+	projects  *ProjectService
+	projectID int
+	taskID    int
+}
+
+// newProjectShareEnv проект alice с одной задачей; carol уже имеет доступ на чтение к проекту.
+func newProjectShareEnv(t *testing.T) projectShareEnv {
+	t.Helper()
+	repo := persistance.NewTaskRepository()
+	env := projectShareEnv{
+		tasks:    NewTaskService(repo, WithShares(repo), WithProjects(repo)),
+		projects: NewProjectService(repo),
+	}
+	var err error
+	if env.projectID, err = env.projects.Create(as("alice"), &entity.Project{Name: "Team"}); err != nil {
+		t.Fatalf("Create() project error = %v", err)
+	}
+	if env.taskID, err = env.tasks.Create(as("alice"), &entity.Task{Title: "Card", ProjectID: &env.projectID}); err != nil {
+		t.Fatalf("Create() task error = %v", err)
+	}
+	if _, err := env.projects.Share(as("alice"), env.projectID, "carol", entity.RoleViewer); err != nil {
+		t.Fatalf("Share() error = %v", err)
+	}
+	return env
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// tenantIDPattern идентификатор становится частью пути хранилища, поэтому только [a-z0-9-].
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`) //nolint:gochecknoglobals
+
+type TenantRepository interface {
+	CreateTenant(ctx context.Context, tenant entity.Tenant) error
+	GetTenant(ctx context.Context, id string) (*entity.Tenant, error)
+	ListTenants(ctx context.Context) ([]entity.Tenant, error)
+	UpdateTenant(ctx context.Context, tenant entity.Tenant) error
+	DeleteTenant(ctx context.Context, id string) error
+}
+
+type TenantService struct {
+	repo          TenantRepository
+	defaultTenant string
+	defaultQuota  entity.TenantQuota
+}
+
+// NewTenantService defaultTenant нельзя удалить или приостановить, на нём работают внутренние вызовы.
+func NewTenantService(repo TenantRepository, defaultTenant string, defaultQuota entity.TenantQuota) *TenantService {
+	return &TenantService{
+		repo:          repo,
+		defaultTenant: defaultTenant,
+		defaultQuota:  defaultQuota,
+	}
+}
+
+func (s *TenantService) Create(ctx context.Context, tenant entity.Tenant) (*entity.Tenant, error) {
+	tenant.ID = strings.TrimSpace(tenant.ID)
+	if !tenantIDPattern.MatchString(tenant.ID) {
+		return nil, fmt.Errorf("tenant %q: %w", tenant.ID, domain.ErrInvalidTenant)
+	}
+	if tenant.Quota.MaxTasks < 0 || tenant.Quota.RequestRate < 0 || tenant.Quota.RequestBurst < 0 {
+		return nil, fmt.Errorf("tenant %q quota: %w", tenant.ID, domain.ErrInvalidTenant)
+	}
+	if tenant.Quota == (entity.TenantQuota{}) {
+		tenant.Quota = s.defaultQuota
+	}
+	tenant.Status = entity.TenantActive
+	tenant.CreatedAt = time.Now().UTC()
+
+	if err := s.repo.CreateTenant(ctx, tenant); err != nil {
+		return nil, fmt.Errorf("tenant %q: %w", tenant.ID, err)
+	}
+	logger(ctx).Info("tenant created", slog.String("tenant_id", tenant.ID))
+	return &tenant, nil
+}
+
+func (s *TenantService) Get(ctx context.Context, id string) (*entity.Tenant, error) {
+	tenant, err := s.repo.GetTenant(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("tenant %q: %w", id, err)
+	}
+	return tenant, nil
+}
+
+func (s *TenantService) List(ctx context.Context) ([]entity.Tenant, error) {
+	tenants, err := s.repo.ListTenants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("repo.ListTenants: %w", err)
+	}
+	return tenants, nil
+}
+
+// SetStatus приостанавливает или возобновляет арендатора, данные при этом сохраняются.
+func (s *TenantService) SetStatus(ctx context.Context, id string, status entity.TenantStatus) (*entity.Tenant, error) {
+	if status != entity.TenantActive && status != entity.TenantSuspended {
+		return nil, fmt.Errorf("tenant %q status %q: %w", id, status, domain.ErrInvalidTenant)
+	}
+	if id == s.defaultTenant && status == entity.TenantSuspended {
+		return nil, fmt.Errorf("tenant %q is the default tenant: %w", id, domain.ErrInvalidTenant)
+	}
+
+	tenant, err := s.repo.GetTenant(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("tenant %q: %w", id, err)
+	}
+	tenant.Status = status
+	if err := s.repo.UpdateTenant(ctx, *tenant); err != nil {
+		return nil, fmt.Errorf("tenant %q: %w", id, err)
+	}
+	logger(ctx).Info("tenant status changed", slog.String("tenant_id", id), slog.String("status", string(status)))
+	return tenant, nil
+}
+
+// Delete удаляет арендатора вместе со всеми его задачами.
+func (s *TenantService) Delete(ctx context.Context, id string) error {
+	if id == s.defaultTenant {
+		return fmt.Errorf("tenant %q is the default tenant: %w", id, domain.ErrInvalidTenant)
+	}
+	if err := s.repo.DeleteTenant(ctx, id); err != nil {
+		return fmt.Errorf("tenant %q: %w", id, err)
+	}
+	logger(ctx).Warn("tenant deleted", slog.String("tenant_id", id))
+	return nil
+}
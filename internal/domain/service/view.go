@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/domain/query"
+	"ecom_test/pkg/tracing"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+type ViewRepository interface {
+	CreateView(ctx context.Context, view *entity.View) (int, error)
+	GetView(ctx context.Context, id int) (*entity.View, error)
+	ListViews(ctx context.Context, userID string) ([]entity.View, error)
+	UpdateView(ctx context.Context, view *entity.View) error
+	DeleteView(ctx context.Context, id int) error
+	CountTasks(ctx context.Context, ownerID string, matchers []func(entity.Task) bool) ([]int, error)
+}
+
+type ViewService struct {
+	repo  ViewRepository
+	tasks *TaskService
+}
+
+// NewViewService представления выполняются через TaskService.List, поэтому видят
+// ровно те задачи, что и обычный список с тем же фильтром.
+func NewViewService(repo ViewRepository, tasks *TaskService) *ViewService {
+	return &ViewService{
+		repo:  repo,
+		tasks: tasks,
+	}
+}
+
+// ViewCount представление и число подходящих под него задач вызывающего.
+type ViewCount struct {
+	View  entity.View
+	Count int
+}
+
+func (s *ViewService) validate(view *entity.View) error {
+	view.Name = strings.TrimSpace(view.Name)
+	if view.Name == "" {
+		return fmt.Errorf("%w: name is required", domain.ErrInvalidView)
+	}
+	if !validSort(view.Sort) {
+		return fmt.Errorf("%w: sort %q", domain.ErrInvalidSort, view.Sort)
+	}
+	if strings.TrimSpace(view.Query) != "" {
+		if _, err := query.ParseAndCompile(view.Query, s.tasks.now()); err != nil {
+			return fmt.Errorf("%w: %w", domain.ErrInvalidQuery, err)
+		}
+	}
+
+	fields := make([]string, 0, len(view.Fields))
+	for _, f := range view.Fields {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if !slices.Contains(entity.ViewFields, f) {
+			return fmt.Errorf("%w: unknown field %q", domain.ErrInvalidView, f)
+		}
+		if !slices.Contains(fields, f) {
+			fields = append(fields, f)
+		}
+	}
+	view.Fields = fields
+	return nil
+}
+
+// visibleView представление владельца или расшаренное вызывающему, остальные неотличимы от несуществующих.
+func (s *ViewService) visibleView(ctx context.Context, id int) (*entity.View, error) {
+	view, err := s.repo.GetView(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user, ok := caller(ctx); ok && !view.VisibleTo(user) {
+		return nil, domain.ErrViewNotFound
+	}
+	return view, nil
+}
+
+// ownedView изменять представление и управлять доступом может только владелец.
+func (s *ViewService) ownedView(ctx context.Context, id int) (*entity.View, error) {
+	view, err := s.visibleView(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user, ok := caller(ctx); ok && view.OwnerID != user {
+		return nil, domain.ErrForbidden
+	}
+	return view, nil
+}
+
+func (s *ViewService) Create(ctx context.Context, view *entity.View) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "ViewService.Create")
+	defer func() { span.EndWithError(err) }()
+
+	if owner, ok := caller(ctx); ok {
+		view.OwnerID = owner
+	}
+	if err := s.validate(view); err != nil {
+		return 0, fmt.Errorf("view: %w", err)
+	}
+	view.SharedWith = nil
+	view.CreatedAt = s.tasks.now().UTC()
+	view.UpdatedAt = view.CreatedAt
+
+	id, err := s.repo.CreateView(ctx, view)
+	if err != nil {
+		return 0, fmt.Errorf("view: %w", err)
+	}
+	logger(ctx).Info("view created", slog.Int("view_id", id))
+	return id, nil
+}
+
+func (s *ViewService) Get(ctx context.Context, id int) (_ *entity.View, err error) {
+	ctx, span := tracing.Start(ctx, "ViewService.Get")
+	defer func() { span.EndWithError(err) }()
+
+	view, err := s.visibleView(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("view %d: %w", id, err)
+	}
+	return view, nil
+}
+
+// Update меняет имя, запрос, сортировку и поля, доступ меняется только через Share и Unshare.
+func (s *ViewService) Update(ctx context.Context, view *entity.View) (err error) {
+	ctx, span := tracing.Start(ctx, "ViewService.Update")
+	defer func() { span.EndWithError(err) }()
+
+	existing, err := s.ownedView(ctx, view.ID)
+	if err != nil {
+		return fmt.Errorf("view %d: %w", view.ID, err)
+	}
+	if err := s.validate(view); err != nil {
+		return fmt.Errorf("view %d: %w", view.ID, err)
+	}
+	view.OwnerID = existing.OwnerID
+	view.SharedWith = existing.SharedWith
+	view.CreatedAt = existing.CreatedAt
+	view.UpdatedAt = s.tasks.now().UTC()
+
+	if err := s.repo.UpdateView(ctx, view); err != nil {
+		return fmt.Errorf("view %d: %w", view.ID, err)
+	}
+	logger(ctx).Info("view updated", slog.Int("view_id", view.ID))
+	return nil
+}
+
+func (s *ViewService) Delete(ctx context.Context, id int) (err error) {
+	ctx, span := tracing.Start(ctx, "ViewService.Delete")
+	defer func() { span.EndWithError(err) }()
+
+	if _, err := s.ownedView(ctx, id); err != nil {
+		return fmt.Errorf("view %d: %w", id, err)
+	}
+	if err := s.repo.DeleteView(ctx, id); err != nil {
+		return fmt.Errorf("view %d: %w", id, err)
+	}
+	logger(ctx).Info("view deleted", slog.Int("view_id", id))
+	return nil
+}
+
+// Share открывает представление пользователю на чтение, повторный вызов ничего не меняет.
+func (s *ViewService) Share(ctx context.Context, id int, userID string) (_ *entity.View, err error) {
+	ctx, span := tracing.Start(ctx, "ViewService.Share")
+	defer func() { span.EndWithError(err) }()
+
+	view, err := s.ownedView(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("view %d: %w", id, err)
+	}
+	userID = strings.TrimSpace(userID)
+	if userID == "" || userID == view.OwnerID {
+		return nil, fmt.Errorf("view %d: %w: cannot share with %q", id, domain.ErrInvalidView, userID)
+	}
+	if slices.Contains(view.SharedWith, userID) {
+		return view, nil
+	}
+
+	view.SharedWith = append(view.SharedWith, userID)
+	if err := s.repo.UpdateView(ctx, view); err != nil {
+		return nil, fmt.Errorf("view %d: %w", id, err)
+	}
+	logger(ctx).Info("view shared", slog.Int("view_id", id), slog.String("user_id", userID))
+	return view, nil
+}
+
+// Unshare пользователь может убрать чужое представление из своего списка сам.
+func (s *ViewService) Unshare(ctx context.Context, id int, userID string) (err error) {
+	ctx, span := tracing.Start(ctx, "ViewService.Unshare")
+	defer func() { span.EndWithError(err) }()
+
+	view, err := s.visibleView(ctx, id)
+	if err != nil {
+		return fmt.Errorf("view %d: %w", id, err)
+	}
+	if user, ok := caller(ctx); ok && user != view.OwnerID && user != userID {
+		return fmt.Errorf("view %d: %w", id, domain.ErrForbidden)
+	}
+	i := slices.Index(view.SharedWith, userID)
+	if i < 0 {
+		return fmt.Errorf("view %d: %w", id, domain.ErrShareNotFound)
+	}
+
+	view.SharedWith = slices.Delete(view.SharedWith, i, i+1)
+	if err := s.repo.UpdateView(ctx, view); err != nil {
+		return fmt.Errorf("view %d: %w", id, err)
+	}
+	logger(ctx).Info("view unshared", slog.Int("view_id", id), slog.String("user_id", userID))
+	return nil
+}
+
+// Tasks выполняет представление над задачами вызывающего.
+func (s *ViewService) Tasks(ctx context.Context, id int) (_ *entity.View, _ []entity.Task, err error) {
+	ctx, span := tracing.Start(ctx, "ViewService.Tasks")
+	defer func() { span.EndWithError(err) }()
+
+	view, err := s.visibleView(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("view %d: %w", id, err)
+	}
+	tasks, err := s.tasks.List(ctx, ListOptions{Query: view.Query, Sort: view.Sort})
+	if err != nil {
+		return nil, nil, fmt.Errorf("view %d: %w", id, err)
+	}
+	return view, tasks, nil
+}
+
+// List представления вызывающего со счётчиками. Все счётчики считаются за один проход
+// по задачам хранилища, а не отдельным запросом на каждое представление.
+func (s *ViewService) List(ctx context.Context) (_ []ViewCount, err error) {
+	ctx, span := tracing.Start(ctx, "ViewService.List")
+	defer func() { span.EndWithError(err) }()
+
+	owner, _ := caller(ctx)
+	views, err := s.repo.ListViews(ctx, owner)
+	if err != nil {
+		return nil, fmt.Errorf("view: %w", err)
+	}
+	archived, err := s.tasks.archivedProjects(ctx, owner)
+	if err != nil {
+		return nil, fmt.Errorf("view: %w", err)
+	}
+
+	// задачи архивных проектов не попадают и в сам список, значит и в счётчик
+	visible := func(t entity.Task) bool {
+		if t.ProjectID == nil {
+			return true
+		}
+		_, hidden := archived[*t.ProjectID]
+		return !hidden
+	}
+
+	matchers := make([]func(entity.Task) bool, len(views))
+	for i, v := range views {
+		matchers[i] = visible
+		if strings.TrimSpace(v.Query) == "" {
+			continue
+		}
+		match, err := query.ParseAndCompile(v.Query, s.tasks.now())
+		if err != nil {
+			// запрос проверен при сохранении, сюда попадёт только сломанный снапшот
+			logger(ctx).Warn("view query does not compile", slog.Int("view_id", v.ID), slog.String("error", err.Error()))
+			matchers[i] = func(entity.Task) bool { return false }
+			continue
+		}
+		matchers[i] = func(t entity.Task) bool { return visible(t) && match(t) }
+	}
+
+	counts, err := s.repo.CountTasks(ctx, owner, matchers)
+	if err != nil {
+		return nil, fmt.Errorf("view: %w", err)
+	}
+	result := make([]ViewCount, len(views))
+	for i, v := range views {
+		result[i] = ViewCount{View: v, Count: counts[i]}
+	}
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/infrastructure/persistance"
+	"errors"
+	"testing"
+)
+
+// countingRepo считает проходы по задачам, чтобы проверить, что счётчики берутся за один проход.
+type countingRepo struct {
+	*persistance.TaskRepository
+	passes int
+}
+
+func (r *countingRepo) CountTasks(ctx context.Context, ownerID string, matchers []func(entity.Task) bool) ([]int, error) {
+	r.passes++
+	return r.TaskRepository.CountTasks(ctx, ownerID, matchers)
+}
+
+func TestViewService(t *testing.T) {
+	repo := &countingRepo{TaskRepository: persistance.NewTaskRepository()}
+	tasks := NewTaskService(repo, WithProjects(repo))
+	projects := NewProjectService(repo)
+	views := NewViewService(repo, tasks)
+	alice, bob := as("alice"), as("bob")
+
+	old := &entity.Project{Name: "Old"}
+	_, _ = projects.Create(alice, old)
+	_, _ = tasks.Create(alice, &entity.Task{Title: "API", Tags: []string{"backend"}, Priority: entity.PriorityHigh})
+	_, _ = tasks.Create(alice, &entity.Task{Title: "DB", Tags: []string{"backend"}})
+	_, _ = tasks.Create(alice, &entity.Task{Title: "Archived", Tags: []string{"backend"}, ProjectID: &old.ID})
+	_, _ = tasks.Create(bob, &entity.Task{Title: "Bob API", Tags: []string{"backend"}})
+	_, _ = projects.SetArchived(alice, old.ID, true)
+
+	backend := &entity.View{Name: "Backend", Query: "tag:backend", Sort: SortManual, Fields: []string{"Title", "tags", "title"}}
+	if _, err := views.Create(alice, backend); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	urgent := &entity.View{Name: "Urgent", Query: "priority:high"}
+	_, _ = views.Create(alice, urgent)
+	all := &entity.View{Name: "Everything"}
+	_, _ = views.Create(alice, all)
+
+	if len(backend.Fields) != 2 {
+		t.Errorf("Fields = %v, want normalized [title tags]", backend.Fields)
+	}
+
+	counts, err := views.List(alice)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	want := map[string]int{"Backend": 2, "Urgent": 1, "Everything": 2}
+	for _, c := range counts {
+		if c.Count != want[c.View.Name] {
+			t.Errorf("count(%s) = %d, want %d", c.View.Name, c.Count, want[c.View.Name])
+		}
+	}
+	if repo.passes != 1 {
+		t.Errorf("CountTasks called %d times, want one pass for all views", repo.passes)
+	}
+
+	_, got, err := views.Tasks(alice, backend.ID)
+	if err != nil || len(got) != 2 {
+		t.Errorf("Tasks() = %v, %v, want 2 tasks without archived project", got, err)
+	}
+
+	if _, _, err := views.Tasks(bob, backend.ID); !errors.Is(err, domain.ErrViewNotFound) {
+		t.Errorf("Tasks() by stranger error = %v, want ErrViewNotFound", err)
+	}
+	if _, err := views.Share(alice, backend.ID, "bob"); err != nil {
+		t.Fatalf("Share() error = %v", err)
+	}
+	if _, got, err := views.Tasks(bob, backend.ID); err != nil || len(got) != 1 || got[0].OwnerID != "bob" {
+		t.Errorf("Tasks() by teammate = %v, %v, want the filter applied to bob's own tasks", got, err)
+	}
+	if err := views.Delete(bob, backend.ID); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Delete() by teammate error = %v, want ErrForbidden", err)
+	}
+	if err := views.Unshare(bob, backend.ID, "bob"); err != nil {
+		t.Errorf("Unshare() of own access error = %v", err)
+	}
+
+	invalid := []struct {
+		view    entity.View
+		wantErr error
+	}{
+		{view: entity.View{Name: " "}, wantErr: domain.ErrInvalidView},
+		{view: entity.View{Name: "Bad query", Query: "tag:"}, wantErr: domain.ErrInvalidQuery},
+		{view: entity.View{Name: "Bad sort", Sort: "random"}, wantErr: domain.ErrInvalidSort},
+		{view: entity.View{Name: "Bad field", Fields: []string{"secret"}}, wantErr: domain.ErrInvalidView},
+	}
+	for _, tt := range invalid {
+		if _, err := views.Create(alice, &tt.view); !errors.Is(err, tt.wantErr) {
+			t.Errorf("Create(%q) error = %v, want %v", tt.view.Name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package persistance
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/pkg/tracing"
+	"slices"
+	"sort"
+)
+
+// cloneBoard отвязывает сохранённую доску от слайса колонок и указателя вызывающего.
+func cloneBoard(board entity.Board) entity.Board {
+	board.Columns = slices.Clone(board.Columns)
+	if board.ProjectID != nil {
+		projectID := *board.ProjectID
+		board.ProjectID = &projectID
+	}
+	return board
+}
+
+func (r *TaskRepository) CreateBoard(ctx context.Context, board *entity.Board) (int, error) {
+	_, span := tracing.Start(ctx, "TaskRepository.CreateBoard")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	board.ID = r.boardID
+	r.boards[board.ID] = cloneBoard(*board)
+	r.boardID++
+	return board.ID, nil
+}
+
+func (r *TaskRepository) GetBoard(ctx context.Context, id int) (*entity.Board, error) {
+	_, span := tracing.Start(ctx, "TaskRepository.GetBoard")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if b, ok := r.boards[id]; ok {
+		board := cloneBoard(b)
+		return &board, nil
+	}
+	return nil, domain.ErrBoardNotFound
+}
+
+// ListBoards с пустым ownerID возвращает доски всех владельцев.
+func (r *TaskRepository) ListBoards(ctx context.Context, ownerID string) ([]entity.Board, error) {
+	_, span := tracing.Start(ctx, "TaskRepository.ListBoards")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	boards := make([]entity.Board, 0)
+	for _, b := range r.boards {
+		if ownerID == "" || b.OwnerID == ownerID {
+			boards = append(boards, cloneBoard(b))
+		}
+	}
+	sort.Slice(boards, func(i, j int) bool { return boards[i].ID < boards[j].ID })
+	return boards, nil
+}
+
+func (r *TaskRepository) UpdateBoard(ctx context.Context, board *entity.Board) error {
+	_, span := tracing.Start(ctx, "TaskRepository.UpdateBoard")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.boards[board.ID]; !ok {
+		return domain.ErrBoardNotFound
+	}
+	r.boards[board.ID] = cloneBoard(*board)
+	return nil
+}
+
+func (r *TaskRepository) DeleteBoard(ctx context.Context, id int) error {
+	_, span := tracing.Start(ctx, "TaskRepository.DeleteBoard")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.boards[id]; !ok {
+		return domain.ErrBoardNotFound
+	}
+	delete(r.boards, id)
+	return nil
+}
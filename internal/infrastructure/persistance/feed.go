@@ -0,0 +1,87 @@
+package persistance
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/pkg/tracing"
+	"time"
+)
+
+// SaveFeed заменяет ленту владельца, прежний токен перестаёт находиться.
+func (r *TaskRepository) SaveFeed(ctx context.Context, feed *entity.Feed) error {
+	_, span := tracing.Start(ctx, "TaskRepository.SaveFeed")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if old, ok := r.feeds[feed.OwnerID]; ok {
+		delete(r.feedByToken, old.TokenHash)
+	}
+	r.feeds[feed.OwnerID] = *feed
+	r.feedByToken[feed.TokenHash] = feed.OwnerID
+	return nil
+}
+
+func (r *TaskRepository) GetFeed(ctx context.Context, ownerID string) (*entity.Feed, error) {
+	_, span := tracing.Start(ctx, "TaskRepository.GetFeed")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if f, ok := r.feeds[ownerID]; ok {
+		return &f, nil
+	}
+	return nil, domain.ErrFeedNotFound
+}
+
+func (r *TaskRepository) FeedByToken(ctx context.Context, tokenHash string) (*entity.Feed, error) {
+	_, span := tracing.Start(ctx, "TaskRepository.FeedByToken")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if owner, ok := r.feedByToken[tokenHash]; ok {
+		f := r.feeds[owner]
+		return &f, nil
+	}
+	return nil, domain.ErrFeedNotFound
+}
+
+// TouchFeed обновляет отпечаток ленты, только если её токен всё ещё действует: чтение ленты,
+// начатое до ротации, не должно вернуть старый токен.
+func (r *TaskRepository) TouchFeed(ctx context.Context, tokenHash, etag string, modifiedAt time.Time) error {
+	_, span := tracing.Start(ctx, "TaskRepository.TouchFeed")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	owner, ok := r.feedByToken[tokenHash]
+	if !ok {
+		return domain.ErrFeedNotFound
+	}
+	f := r.feeds[owner]
+	f.ETag, f.ModifiedAt = etag, modifiedAt
+	r.feeds[owner] = f
+	return nil
+}
+
+func (r *TaskRepository) DeleteFeed(ctx context.Context, ownerID string) error {
+	_, span := tracing.Start(ctx, "TaskRepository.DeleteFeed")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.feeds[ownerID]
+	if !ok {
+		return domain.ErrFeedNotFound
+	}
+	delete(r.feedByToken, f.TokenHash)
+	delete(r.feeds, ownerID)
+	return nil
+}
@@ -0,0 +1,128 @@
+package persistance
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/pkg/tracing"
+	"sort"
+)
+
+func (r *TaskRepository) CreateProject(ctx context.Context, project *entity.Project) (int, error) {
+	_, span := tracing.Start(ctx, "TaskRepository.CreateProject")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	project.ID = r.projectID
+	r.projects[project.ID] = *project
+	r.projectID++
+	return project.ID, nil
+}
+
+func (r *TaskRepository) GetProject(ctx context.Context, id int) (*entity.Project, error) {
+	_, span := tracing.Start(ctx, "TaskRepository.GetProject")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if p, ok := r.projects[id]; ok {
+		return &p, nil
+	}
+	return nil, domain.ErrProjectNotFound
+}
+
+// ListProjects с пустым ownerID возвращает проекты всех владельцев.
+func (r *TaskRepository) ListProjects(ctx context.Context, ownerID string) ([]entity.Project, error) {
+	_, span := tracing.Start(ctx, "TaskRepository.ListProjects")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	projects := make([]entity.Project, 0)
+	for _, p := range r.projects {
+		if ownerID == "" || p.OwnerID == ownerID {
+			projects = append(projects, p)
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ID < projects[j].ID })
+	return projects, nil
+}
+
+func (r *TaskRepository) UpdateProject(ctx context.Context, project *entity.Project) error {
+	_, span := tracing.Start(ctx, "TaskRepository.UpdateProject")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.projects[project.ID]; !ok {
+		return domain.ErrProjectNotFound
+	}
+	r.projects[project.ID] = *project
+	return nil
+}
+
+// DeleteProject применяет политику к задачам проекта под одним локом, чтобы между проверкой
+// и удалением в проект не попала новая задача.
+func (r *TaskRepository) DeleteProject(ctx context.Context, id int, policy entity.ProjectDeletePolicy) error {
+	_, span := tracing.Start(ctx, "TaskRepository.DeleteProject")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.projects[id]; !ok {
+		return domain.ErrProjectNotFound
+	}
+
+	tasks := r.byProject[id]
+	switch policy {
+	case entity.DeleteReject:
+		if len(tasks) > 0 {
+			return domain.ErrProjectNotEmpty
+		}
+	case entity.DeleteCascade:
+		for taskID := range tasks {
+			r.unindex(r.data[taskID])
+			r.dropShares(taskID)
+			delete(r.data, taskID)
+		}
+	case entity.DeleteOrphan:
+		for taskID := range tasks {
+			task := r.data[taskID]
+			r.unindex(task)
+			task.ProjectID = nil
+			r.data[taskID] = task
+			r.index(task)
+		}
+	default:
+		return domain.ErrInvalidDeletePolicy
+	}
+
+	for boardID, board := range r.boards {
+		if board.ProjectID != nil && *board.ProjectID == id {
+			delete(r.boards, boardID)
+		}
+	}
+	r.dropProjectShares(id)
+	delete(r.projects, id)
+	return nil
+}
+
+func (r *TaskRepository) GetTasksByProject(ctx context.Context, projectID int) ([]entity.Task, error) {
+	_, span := tracing.Start(ctx, "TaskRepository.GetTasksByProject")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := make([]entity.Task, 0, len(r.byProject[projectID]))
+	for id := range r.byProject[projectID] {
+		tasks = append(tasks, r.data[id])
+	}
+	return tasks, nil
+}
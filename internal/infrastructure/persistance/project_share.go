@@ -0,0 +1,119 @@
+package persistance
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/pkg/tracing"
+	"sort"
+)
+
+func (r *TaskRepository) GetProjectShare(ctx context.Context, projectID int, userID string) (*entity.ProjectShare, error) {
+	_, span := tracing.Start(ctx, "TaskRepository.GetProjectShare")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if share, ok := r.projectShares[projectID][userID]; ok {
+		return &share, nil
+	}
+	return nil, domain.ErrShareNotFound
+}
+
+func (r *TaskRepository) ListProjectShares(ctx context.Context, projectID int) ([]entity.ProjectShare, error) {
+	_, span := tracing.Start(ctx, "TaskRepository.ListProjectShares")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	shares := make([]entity.ProjectShare, 0, len(r.projectShares[projectID]))
+	for _, share := range r.projectShares[projectID] {
+		shares = append(shares, share)
+	}
+	sort.Slice(shares, func(i, j int) bool { return shares[i].UserID < shares[j].UserID })
+	return shares, nil
+}
+
+// PutProjectShare создаёт доступ к проекту или меняет роль существующего.
+func (r *TaskRepository) PutProjectShare(ctx context.Context, share entity.ProjectShare) error {
+	_, span := tracing.Start(ctx, "TaskRepository.PutProjectShare")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.projects[share.ProjectID]; !ok {
+		return domain.ErrProjectNotFound
+	}
+	if existing, ok := r.projectShares[share.ProjectID][share.UserID]; ok {
+		share.CreatedAt = existing.CreatedAt
+	}
+	r.putProjectShare(share)
+	return nil
+}
+
+func (r *TaskRepository) DeleteProjectShare(ctx context.Context, projectID int, userID string) error {
+	_, span := tracing.Start(ctx, "TaskRepository.DeleteProjectShare")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.projectShares[projectID][userID]; !ok {
+		return domain.ErrShareNotFound
+	}
+	r.removeProjectShare(projectID, userID)
+	return nil
+}
+
+// GetSharedProjects проекты других владельцев, открытые пользователю, по возрастанию ID.
+func (r *TaskRepository) GetSharedProjects(ctx context.Context, userID string) ([]entity.Project, error) {
+	_, span := tracing.Start(ctx, "TaskRepository.GetSharedProjects")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	projects := make([]entity.Project, 0, len(r.projectsSharedWith[userID]))
+	for id := range r.projectsSharedWith[userID] {
+		projects = append(projects, r.projects[id])
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ID < projects[j].ID })
+	return projects, nil
+}
+
+func (r *TaskRepository) putProjectShare(share entity.ProjectShare) {
+	byUser, ok := r.projectShares[share.ProjectID]
+	if !ok {
+		byUser = make(map[string]entity.ProjectShare)
+		r.projectShares[share.ProjectID] = byUser
+	}
+	byUser[share.UserID] = share
+
+	projects, ok := r.projectsSharedWith[share.UserID]
+	if !ok {
+		projects = make(map[int]struct{})
+		r.projectsSharedWith[share.UserID] = projects
+	}
+	projects[share.ProjectID] = struct{}{}
+}
+
+func (r *TaskRepository) removeProjectShare(projectID int, userID string) {
+	delete(r.projectShares[projectID], userID)
+	if len(r.projectShares[projectID]) == 0 {
+		delete(r.projectShares, projectID)
+	}
+	delete(r.projectsSharedWith[userID], projectID)
+	if len(r.projectsSharedWith[userID]) == 0 {
+		delete(r.projectsSharedWith, userID)
+	}
+}
+
+// dropProjectShares вызывается под локом при удалении проекта.
+func (r *TaskRepository) dropProjectShares(projectID int) {
+	for userID := range r.projectShares[projectID] {
+		r.removeProjectShare(projectID, userID)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"ecom_test/internal/domain"
 	"ecom_test/internal/domain/entity"
 	"errors"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestTaskRepository_CRUD(t *testing.T) {
@@ -75,3 +77,113 @@ func TestTaskRepository_Errors(t *testing.T) {
 		}
 	})
 }
+
+func TestTaskRepository_GetAllByOwner(t *testing.T) {
+	repo := NewTaskRepository()
+	ctx := context.Background()
+
+	aliceID, _ := repo.Create(ctx, &entity.Task{Title: "A1", OwnerID: "alice"})
+	_, _ = repo.Create(ctx, &entity.Task{Title: "A2", OwnerID: "alice"})
+	bobID, _ := repo.Create(ctx, &entity.Task{Title: "B1", OwnerID: "bob"})
+
+	tasks, err := repo.GetAllByOwner(ctx, "alice")
+	if err != nil || len(tasks) != 2 {
+		t.Fatalf("GetAllByOwner(alice) = %d tasks, err %v, want 2", len(tasks), err)
+	}
+
+	if err := repo.Update(ctx, &entity.Task{ID: bobID, Title: "B1", OwnerID: "alice"}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if err := repo.Delete(ctx, aliceID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	tests := []struct {
+		owner string
+		want  int
+	}{
+		{owner: "alice", want: 2},
+		{owner: "bob", want: 0},
+		{owner: "nobody", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.owner, func(t *testing.T) {
+			tasks, _ := repo.GetAllByOwner(ctx, tt.owner)
+			if len(tasks) != tt.want {
+				t.Errorf("GetAllByOwner(%s) = %d tasks, want %d", tt.owner, len(tasks), tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskRepository_Feeds(t *testing.T) {
+	repo := NewTaskRepository()
+	ctx := context.Background()
+
+	_ = repo.SaveFeed(ctx, &entity.Feed{OwnerID: "alice", TokenHash: "old"})
+	_ = repo.SaveFeed(ctx, &entity.Feed{OwnerID: "alice", TokenHash: "new"})
+	if _, err := repo.FeedByToken(ctx, "old"); !errors.Is(err, domain.ErrFeedNotFound) {
+		t.Errorf("replaced token still resolves, error = %v", err)
+	}
+	if err := repo.TouchFeed(ctx, "old", "etag", time.Now()); !errors.Is(err, domain.ErrFeedNotFound) {
+		t.Errorf("TouchFeed with replaced token error = %v, want ErrFeedNotFound", err)
+	}
+	if err := repo.TouchFeed(ctx, "new", "etag", time.Now()); err != nil {
+		t.Fatalf("TouchFeed() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	if err := repo.SaveSnapshot(path); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+	loaded, err := LoadTaskRepository(path)
+	if err != nil {
+		t.Fatalf("LoadTaskRepository() error = %v", err)
+	}
+	feed, err := loaded.FeedByToken(ctx, "new")
+	if err != nil || feed.OwnerID != "alice" || feed.ETag != "etag" {
+		t.Errorf("FeedByToken() after reload = %+v, %v", feed, err)
+	}
+
+	_ = loaded.DeleteFeed(ctx, "alice")
+	if _, err := loaded.GetFeed(ctx, "alice"); !errors.Is(err, domain.ErrFeedNotFound) {
+		t.Errorf("GetFeed() after DeleteFeed error = %v", err)
+	}
+}
+
+func TestTaskRepository_ProjectShares(t *testing.T) {
+	repo := NewTaskRepository()
+	ctx := context.Background()
+
+	if err := repo.PutProjectShare(ctx, entity.ProjectShare{ProjectID: 1, UserID: "bob"}); !errors.Is(err, domain.ErrProjectNotFound) {
+		t.Errorf("PutProjectShare() for missing project error = %v, want ErrProjectNotFound", err)
+	}
+	id, _ := repo.CreateProject(ctx, &entity.Project{Name: "Team", OwnerID: "alice"})
+	created := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	_ = repo.PutProjectShare(ctx, entity.ProjectShare{ProjectID: id, UserID: "bob", Role: entity.RoleViewer, CreatedAt: created})
+	_ = repo.PutProjectShare(ctx, entity.ProjectShare{ProjectID: id, UserID: "bob", Role: entity.RoleEditor, CreatedAt: created.Add(time.Hour)})
+
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	if err := repo.SaveSnapshot(path); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+	loaded, err := LoadTaskRepository(path)
+	if err != nil {
+		t.Fatalf("LoadTaskRepository() error = %v", err)
+	}
+	share, err := loaded.GetProjectShare(ctx, id, "bob")
+	if err != nil || share.Role != entity.RoleEditor || !share.CreatedAt.Equal(created) {
+		t.Errorf("GetProjectShare() after reload = %+v, %v, want editor role granted at %v", share, err, created)
+	}
+	if projects, _ := loaded.GetSharedProjects(ctx, "bob"); len(projects) != 1 || projects[0].ID != id {
+		t.Errorf("GetSharedProjects() = %v, want project %d", projects, id)
+	}
+
+	_ = loaded.DeleteProject(ctx, id, entity.DeleteReject)
+	if _, err := loaded.GetProjectShare(ctx, id, "bob"); !errors.Is(err, domain.ErrShareNotFound) {
+		t.Errorf("GetProjectShare() after DeleteProject error = %v, want ErrShareNotFound", err)
+	}
+	if projects, _ := loaded.GetSharedProjects(ctx, "bob"); len(projects) != 0 {
+		t.Errorf("GetSharedProjects() after DeleteProject = %v, want none", projects)
+	}
+}
@@ -0,0 +1,24 @@
+package persistance
+
+import (
+	"context"
+	"ecom_test/pkg/search"
+	"ecom_test/pkg/tracing"
+)
+
+// Совпадение в заголовке весит вдвое больше совпадения в описании.
+const (
+	titleWeight       = 2
+	descriptionWeight = 1
+)
+
+// SearchTasks ищет по всем задачам хранилища, права доступа проверяет сервис.
+func (r *TaskRepository) SearchTasks(ctx context.Context, q search.Query) ([]search.Hit, error) {
+	_, span := tracing.Start(ctx, "TaskRepository.SearchTasks")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.text.Search(q), nil
+}
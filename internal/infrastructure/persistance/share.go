@@ -0,0 +1,114 @@
+package persistance
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/pkg/tracing"
+	"sort"
+)
+
+func (r *TaskRepository) GetShare(ctx context.Context, taskID int, userID string) (*entity.Share, error) {
+	_, span := tracing.Start(ctx, "TaskRepository.GetShare")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if share, ok := r.shares[taskID][userID]; ok {
+		return &share, nil
+	}
+	return nil, domain.ErrShareNotFound
+}
+
+func (r *TaskRepository) ListShares(ctx context.Context, taskID int) ([]entity.Share, error) {
+	_, span := tracing.Start(ctx, "TaskRepository.ListShares")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	shares := make([]entity.Share, 0, len(r.shares[taskID]))
+	for _, share := range r.shares[taskID] {
+		shares = append(shares, share)
+	}
+	sort.Slice(shares, func(i, j int) bool { return shares[i].UserID < shares[j].UserID })
+	return shares, nil
+}
+
+// PutShare создаёт доступ или меняет роль существующего.
+func (r *TaskRepository) PutShare(ctx context.Context, share entity.Share) error {
+	_, span := tracing.Start(ctx, "TaskRepository.PutShare")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.data[share.TaskID]; !ok {
+		return domain.ErrTaskNotFound
+	}
+
+	byUser, ok := r.shares[share.TaskID]
+	if !ok {
+		byUser = make(map[string]entity.Share)
+		r.shares[share.TaskID] = byUser
+	}
+	if existing, ok := byUser[share.UserID]; ok {
+		share.CreatedAt = existing.CreatedAt
+	}
+	byUser[share.UserID] = share
+
+	tasks, ok := r.sharedWith[share.UserID]
+	if !ok {
+		tasks = make(map[int]struct{})
+		r.sharedWith[share.UserID] = tasks
+	}
+	tasks[share.TaskID] = struct{}{}
+	return nil
+}
+
+func (r *TaskRepository) DeleteShare(ctx context.Context, taskID int, userID string) error {
+	_, span := tracing.Start(ctx, "TaskRepository.DeleteShare")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.shares[taskID][userID]; !ok {
+		return domain.ErrShareNotFound
+	}
+	r.removeShare(taskID, userID)
+	return nil
+}
+
+func (r *TaskRepository) GetSharedWith(ctx context.Context, userID string) ([]entity.Task, error) {
+	_, span := tracing.Start(ctx, "TaskRepository.GetSharedWith")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := make([]entity.Task, 0, len(r.sharedWith[userID]))
+	for id := range r.sharedWith[userID] {
+		tasks = append(tasks, r.data[id])
+	}
+	return tasks, nil
+}
+
+func (r *TaskRepository) removeShare(taskID int, userID string) {
+	delete(r.shares[taskID], userID)
+	if len(r.shares[taskID]) == 0 {
+		delete(r.shares, taskID)
+	}
+	delete(r.sharedWith[userID], taskID)
+	if len(r.sharedWith[userID]) == 0 {
+		delete(r.sharedWith, userID)
+	}
+}
+
+// dropShares вызывается под локом при удалении задачи.
+func (r *TaskRepository) dropShares(taskID int) {
+	for userID := range r.shares[taskID] {
+		r.removeShare(taskID, userID)
+	}
+}
@@ -0,0 +1,161 @@
+package persistance
+
+import (
+	"ecom_test/internal/domain/entity"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+type snapshot struct {
+	NextID        int                   `json:"next_id"`
+	Tasks         []entity.Task         `json:"tasks"`
+	Shares        []entity.Share        `json:"shares"`
+	NextProjectID int                   `json:"next_project_id"`
+	Projects      []entity.Project      `json:"projects"`
+	ProjectShares []entity.ProjectShare `json:"project_shares"`
+	NextBoardID   int                   `json:"next_board_id"`
+	Boards        []entity.Board        `json:"boards"`
+	NextViewID    int                   `json:"next_view_id"`
+	Views         []entity.View         `json:"views"`
+	Feeds         []entity.Feed         `json:"feeds"`
+}
+
+// SaveSnapshot атомарно (через временный файл и rename) сохраняет содержимое репозитория.
+func (r *TaskRepository) SaveSnapshot(path string) error {
+	r.mu.RLock()
+	snap := snapshot{
+		NextID: r.currentID,
+		Tasks:  make([]entity.Task, 0, len(r.data)),
+	}
+	for _, task := range r.data {
+		snap.Tasks = append(snap.Tasks, task)
+	}
+	snap.NextProjectID = r.projectID
+	for _, p := range r.projects {
+		snap.Projects = append(snap.Projects, p)
+	}
+	snap.NextBoardID = r.boardID
+	for _, b := range r.boards {
+		snap.Boards = append(snap.Boards, b)
+	}
+	snap.NextViewID = r.viewID
+	for _, v := range r.views {
+		snap.Views = append(snap.Views, v)
+	}
+	for _, f := range r.feeds {
+		snap.Feeds = append(snap.Feeds, f)
+	}
+	for _, byUser := range r.shares {
+		for _, share := range byUser {
+			snap.Shares = append(snap.Shares, share)
+		}
+	}
+	for _, byUser := range r.projectShares {
+		for _, share := range byUser {
+			snap.ProjectShares = append(snap.ProjectShares, share)
+		}
+	}
+	r.mu.RUnlock()
+
+	return writeJSON(path, snap)
+}
+
+// LoadTaskRepository восстанавливает репозиторий из снапшота, отсутствующий файл даёт пустой репозиторий.
+func LoadTaskRepository(path string) (*TaskRepository, error) {
+	r := NewTaskRepository()
+
+	var snap snapshot
+	if err := readJSON(path, &snap); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return r, nil
+		}
+		return nil, err
+	}
+
+	r.currentID = snap.NextID
+	r.projectID = snap.NextProjectID
+	for _, p := range snap.Projects {
+		r.projects[p.ID] = p
+		if p.ID >= r.projectID {
+			r.projectID = p.ID + 1
+		}
+	}
+	r.boardID = snap.NextBoardID
+	for _, b := range snap.Boards {
+		r.boards[b.ID] = b
+		if b.ID >= r.boardID {
+			r.boardID = b.ID + 1
+		}
+	}
+	r.viewID = snap.NextViewID
+	for _, v := range snap.Views {
+		r.views[v.ID] = v
+		if v.ID >= r.viewID {
+			r.viewID = v.ID + 1
+		}
+	}
+	for _, f := range snap.Feeds {
+		r.feeds[f.OwnerID] = f
+		r.feedByToken[f.TokenHash] = f.OwnerID
+	}
+	for _, task := range snap.Tasks {
+		r.data[task.ID] = task
+		r.index(task)
+		if task.ID >= r.currentID {
+			r.currentID = task.ID + 1
+		}
+	}
+	for _, share := range snap.Shares {
+		if _, ok := r.data[share.TaskID]; !ok {
+			continue
+		}
+		if r.shares[share.TaskID] == nil {
+			r.shares[share.TaskID] = make(map[string]entity.Share)
+		}
+		r.shares[share.TaskID][share.UserID] = share
+		if r.sharedWith[share.UserID] == nil {
+			r.sharedWith[share.UserID] = make(map[int]struct{})
+		}
+		r.sharedWith[share.UserID][share.TaskID] = struct{}{}
+	}
+	for _, share := range snap.ProjectShares {
+		if _, ok := r.projects[share.ProjectID]; ok {
+			r.putProjectShare(share)
+		}
+	}
+	return r, nil
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("os.MkdirAll: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("os.WriteFile: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("os.Rename: %w", err)
+	}
+	return nil
+}
+
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("os.ReadFile: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("json.Unmarshal %s: %w", path, err)
+	}
+	return nil
+}
@@ -4,23 +4,140 @@ import (
 	"context"
 	"ecom_test/internal/domain"
 	"ecom_test/internal/domain/entity"
+	"ecom_test/pkg/rank"
+	"ecom_test/pkg/search"
+	"ecom_test/pkg/tracing"
+	"slices"
 	"sync"
 )
 
 type TaskRepository struct {
 	mu        sync.RWMutex
 	data      map[int]entity.Task
+	byOwner   map[string]map[int]struct{}
 	currentID int
+	// text полнотекстовый индекс по заголовку и описанию, обновляется вместе с остальными индексами.
+	text *search.Index
+
+	shares     map[int]map[string]entity.Share
+	sharedWith map[string]map[int]struct{}
+
+	projects  map[int]entity.Project
+	byProject map[int]map[int]struct{}
+	projectID int
+	// projectShares доступы к проектам, projectsSharedWith пользователь → проекты.
+	projectShares      map[int]map[string]entity.ProjectShare
+	projectsSharedWith map[string]map[int]struct{}
+
+	boards  map[int]entity.Board
+	boardID int
+
+	views  map[int]entity.View
+	viewID int
+
+	// feeds по владельцу, feedByToken хэш токена → владелец.
+	feeds       map[string]entity.Feed
+	feedByToken map[string]string
 }
 
 func NewTaskRepository() *TaskRepository {
 	return &TaskRepository{
 		data:      make(map[int]entity.Task),
+		byOwner:   make(map[string]map[int]struct{}),
 		currentID: 0,
+		text:      search.NewIndex(titleWeight, descriptionWeight),
+
+		shares:     make(map[int]map[string]entity.Share),
+		sharedWith: make(map[string]map[int]struct{}),
+
+		projects:  make(map[int]entity.Project),
+		byProject: make(map[int]map[int]struct{}),
+
+		projectShares:      make(map[int]map[string]entity.ProjectShare),
+		projectsSharedWith: make(map[string]map[int]struct{}),
+
+		boards: make(map[int]entity.Board),
+		views:  make(map[int]entity.View),
+
+		feeds:       make(map[string]entity.Feed),
+		feedByToken: make(map[string]string),
+	}
+}
+
+func (r *TaskRepository) index(task entity.Task) {
+	ids, ok := r.byOwner[task.OwnerID]
+	if !ok {
+		ids = make(map[int]struct{})
+		r.byOwner[task.OwnerID] = ids
+	}
+	ids[task.ID] = struct{}{}
+	r.text.Add(task.ID, task.Title, task.Description)
+
+	if task.ProjectID != nil {
+		tasks, ok := r.byProject[*task.ProjectID]
+		if !ok {
+			tasks = make(map[int]struct{})
+			r.byProject[*task.ProjectID] = tasks
+		}
+		tasks[task.ID] = struct{}{}
+	}
+}
+
+func (r *TaskRepository) unindex(task entity.Task) {
+	ids := r.byOwner[task.OwnerID]
+	delete(ids, task.ID)
+	if len(ids) == 0 {
+		delete(r.byOwner, task.OwnerID)
+	}
+	r.text.Remove(task.ID)
+
+	if task.ProjectID != nil {
+		tasks := r.byProject[*task.ProjectID]
+		delete(tasks, task.ID)
+		if len(tasks) == 0 {
+			delete(r.byProject, *task.ProjectID)
+		}
+	}
+}
+
+// cloneTask отвязывает сохранённую задачу от указателей вызывающего.
+func cloneTask(task entity.Task) entity.Task {
+	if task.ProjectID != nil {
+		projectID := *task.ProjectID
+		task.ProjectID = &projectID
+	}
+	if task.DueAt != nil {
+		dueAt := *task.DueAt
+		task.DueAt = &dueAt
 	}
+	task.Tags = slices.Clone(task.Tags)
+	return task
+}
+
+// Ping проверяет что хранилище не заблокировано, зависший лок отловит таймаут проверки.
+func (r *TaskRepository) Ping(ctx context.Context) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return ctx.Err()
+}
+
+func (r *TaskRepository) Stats() (total, completed int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, v := range r.data {
+		if v.IsCompleted {
+			completed++
+		}
+	}
+	return len(r.data), completed
 }
 
 func (r *TaskRepository) GetByID(ctx context.Context, id int) (*entity.Task, error) {
+	_, span := tracing.Start(ctx, "TaskRepository.GetByID")
+	defer span.End()
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -32,42 +149,163 @@ func (r *TaskRepository) GetByID(ctx context.Context, id int) (*entity.Task, err
 }
 
 func (r *TaskRepository) Create(ctx context.Context, task *entity.Task) (int, error) {
+	return r.create(ctx, task, 0, nil)
+}
+
+// CreateIf как Create, но задача записывается только если check одобрил задачи владельца,
+// прочитанные под тем же локом, что и вставка.
+func (r *TaskRepository) CreateIf(ctx context.Context, task *entity.Task, check func(owned []entity.Task) error) (int, error) {
+	return r.create(ctx, task, 0, check)
+}
+
+// create с maxTasks > 0 проверяет квоту под тем же локом, что и вставка.
+func (r *TaskRepository) create(ctx context.Context, task *entity.Task, maxTasks int, check func([]entity.Task) error) (int, error) {
+	_, span := tracing.Start(ctx, "TaskRepository.Create")
+	defer span.End()
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if maxTasks > 0 && len(r.data) >= maxTasks {
+		return 0, domain.ErrQuotaExceeded
+	}
+	if check != nil {
+		if err := check(r.ownedLocked(task.OwnerID)); err != nil {
+			return 0, err
+		}
+	}
+	if task.Rank == "" {
+		key, err := r.appendRankLocked(task.OwnerID)
+		if err != nil {
+			return 0, err
+		}
+		task.Rank = key
+	}
+
 	task.ID = r.currentID
-	r.data[task.ID] = *task
+	r.data[task.ID] = cloneTask(*task)
+	r.index(r.data[task.ID])
 	r.currentID++
 
 	return task.ID, nil
 }
 
 func (r *TaskRepository) Delete(ctx context.Context, id int) error {
+	return r.delete(ctx, id, nil)
+}
+
+// DeleteIf как Delete, но задача удаляется только если check одобрил задачи её владельца,
+// прочитанные под тем же локом, что и удаление.
+func (r *TaskRepository) DeleteIf(ctx context.Context, id int, check func(owned []entity.Task) error) error {
+	return r.delete(ctx, id, check)
+}
+
+func (r *TaskRepository) delete(ctx context.Context, id int, check func([]entity.Task) error) error {
+	_, span := tracing.Start(ctx, "TaskRepository.Delete")
+	defer span.End()
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, ok := r.data[id]; !ok {
+	existing, ok := r.data[id]
+	if !ok {
 		return domain.ErrTaskNotFound
 	}
+	if check != nil {
+		if err := check(r.ownedLocked(existing.OwnerID)); err != nil {
+			return err
+		}
+	}
 
+	r.unindex(existing)
+	r.dropShares(id)
 	delete(r.data, id)
 	return nil
 }
 
+// CreateBatch задачи без Rank получают ключи в конце списка владельца. check как у CreateIf,
+// получает задачи владельца пачки; в одной пачке задачи одного владельца.
+func (r *TaskRepository) CreateBatch(ctx context.Context, tasks []*entity.Task, check func(owned []entity.Task) error) ([]int, error) {
+	return r.createBatch(ctx, tasks, 0, check)
+}
+
+// createBatch вставляет все задачи под одним локом, поэтому пачка видна целиком или не видна вовсе.
+func (r *TaskRepository) createBatch(ctx context.Context, tasks []*entity.Task, maxTasks int, check func([]entity.Task) error) ([]int, error) {
+	_, span := tracing.Start(ctx, "TaskRepository.CreateBatch")
+	defer span.End()
+	span.SetAttribute("tasks", len(tasks))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if maxTasks > 0 && len(r.data)+len(tasks) > maxTasks {
+		return nil, domain.ErrQuotaExceeded
+	}
+	if check != nil && len(tasks) > 0 {
+		if err := check(r.ownedLocked(tasks[0].OwnerID)); err != nil {
+			return nil, err
+		}
+	}
+	r.appendRanksLocked(tasks)
+
+	ids := make([]int, 0, len(tasks))
+	for _, task := range tasks {
+		task.ID = r.currentID
+		r.data[task.ID] = cloneTask(*task)
+		r.index(r.data[task.ID])
+		r.currentID++
+		ids = append(ids, task.ID)
+	}
+	return ids, nil
+}
+
 func (r *TaskRepository) Update(ctx context.Context, task *entity.Task) error {
+	return r.update(ctx, task, nil)
+}
+
+// UpdateIf как Update, но задача записывается только если check одобрил задачи владельца,
+// прочитанные под тем же локом, что и запись.
+func (r *TaskRepository) UpdateIf(ctx context.Context, task *entity.Task, check func(owned []entity.Task) error) error {
+	return r.update(ctx, task, check)
+}
+
+func (r *TaskRepository) update(ctx context.Context, task *entity.Task, check func([]entity.Task) error) error {
+	_, span := tracing.Start(ctx, "TaskRepository.Update")
+	defer span.End()
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, ok := r.data[task.ID]; !ok {
+	existing, ok := r.data[task.ID]
+	if !ok {
 		return domain.ErrTaskNotFound
 	}
+	if check != nil {
+		if err := check(r.ownedLocked(existing.OwnerID)); err != nil {
+			return err
+		}
+	}
+
+	// обновление без ключа ручной сортировки или статуса не сбрасывает позицию задачи
+	if task.Rank == "" {
+		task.Rank = existing.Rank
+	}
+	if task.Status == "" {
+		task.Status = existing.Status
+		task.StatusChangedAt = existing.StatusChangedAt
+	}
 
-	r.data[task.ID] = *task
+	r.unindex(existing)
+	r.data[task.ID] = cloneTask(*task)
+	r.index(r.data[task.ID])
 	return nil
 }
 
 // сделал проверку контекста только здесь потому что остальные методы работают мнгновенно или почти мнгновенно
 func (r *TaskRepository) GetAll(ctx context.Context) ([]entity.Task, error) {
+	ctx, span := tracing.Start(ctx, "TaskRepository.GetAll")
+	defer span.End()
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -82,3 +320,124 @@ func (r *TaskRepository) GetAll(ctx context.Context) ([]entity.Task, error) {
 	}
 	return tasks, nil
 }
+
+// Find проверяет предикат под локом чтения, не копируя неподходящие задачи. С пустым ownerID
+// обходит все задачи, иначе только задачи владельца через индекс.
+func (r *TaskRepository) Find(ctx context.Context, ownerID string, match func(entity.Task) bool) ([]entity.Task, error) {
+	ctx, span := tracing.Start(ctx, "TaskRepository.Find")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := make([]entity.Task, 0)
+	visit := func(task entity.Task) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if match(task) {
+			tasks = append(tasks, task)
+		}
+		return nil
+	}
+
+	if ownerID == "" {
+		for _, task := range r.data {
+			if err := visit(task); err != nil {
+				return nil, err
+			}
+		}
+		return tasks, nil
+	}
+	for id := range r.byOwner[ownerID] {
+		if err := visit(r.data[id]); err != nil {
+			return nil, err
+		}
+	}
+	return tasks, nil
+}
+
+// FindPage идёт по ID подряд, поэтому страница не требует сортировки, а вся выгрузка страницами
+// обходит хранилище один раз. Лок держится только на время страницы.
+func (r *TaskRepository) FindPage(ctx context.Context, ownerID string, afterID, limit int, match func(entity.Task) bool) ([]entity.Task, error) {
+	ctx, span := tracing.Start(ctx, "TaskRepository.FindPage")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := make([]entity.Task, 0, min(limit, len(r.data)))
+	for id := max(afterID+1, 0); id < r.currentID && len(tasks) < limit; id++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		task, ok := r.data[id]
+		if !ok || (ownerID != "" && task.OwnerID != ownerID) || !match(task) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// GetAllByOwner обходит только задачи владельца через индекс, без полного скана.
+func (r *TaskRepository) GetAllByOwner(ctx context.Context, ownerID string) ([]entity.Task, error) {
+	ctx, span := tracing.Start(ctx, "TaskRepository.GetAllByOwner")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := r.byOwner[ownerID]
+	tasks := make([]entity.Task, 0, len(ids))
+	for id := range ids {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+			tasks = append(tasks, r.data[id])
+		}
+	}
+	return tasks, nil
+}
+
+// appendRankLocked ключ сразу за последней задачей владельца в ручном порядке. Считается под
+// локом записи, поэтому параллельные создания не получают одинаковый ключ.
+func (r *TaskRepository) appendRankLocked(ownerID string) (string, error) {
+	return rank.Between(r.lastRankLocked(ownerID), "")
+}
+
+// appendRanksLocked ключи задачам пачки без Rank: все продолжают последний ключ владельца,
+// поэтому длина растёт на разрядность пачки, а не на каждую задачу.
+func (r *TaskRepository) appendRanksLocked(tasks []*entity.Task) {
+	unranked := make(map[string][]*entity.Task)
+	for _, task := range tasks {
+		if task.Rank == "" {
+			unranked[task.OwnerID] = append(unranked[task.OwnerID], task)
+		}
+	}
+	for ownerID, owned := range unranked {
+		last := r.lastRankLocked(ownerID)
+		for i, key := range rank.Spread(len(owned)) {
+			owned[i].Rank = last + key
+		}
+	}
+}
+
+func (r *TaskRepository) lastRankLocked(ownerID string) string {
+	last := ""
+	for id := range r.byOwner[ownerID] {
+		last = max(last, r.data[id].Rank)
+	}
+	return last
+}
+
+// ownedLocked задачи владельца для проверок CreateIf и UpdateIf, вызывается под локом.
+func (r *TaskRepository) ownedLocked(ownerID string) []entity.Task {
+	ids := r.byOwner[ownerID]
+	tasks := make([]entity.Task, 0, len(ids))
+	for id := range ids {
+		tasks = append(tasks, r.data[id])
+	}
+	return tasks
+}
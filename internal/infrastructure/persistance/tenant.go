@@ -0,0 +1,676 @@
+package persistance
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/pkg/contextx"
+	"ecom_test/pkg/search"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	tenantsFile = "tenants.json"
+	tasksFile   = "tasks.json"
+
+	// DefaultSnapshotInterval как часто Run сохраняет изменённых арендаторов.
+	DefaultSnapshotInterval = 5 * time.Second
+)
+
+type tenantRepository struct {
+	tenant entity.Tenant
+	tasks  *TaskRepository
+	// dirty данные менялись после последнего снимка.
+	dirty atomic.Bool
+	// saveMu не даёт двум снимкам одного арендатора писать один временный файл.
+	saveMu sync.Mutex
+}
+
+// touch вызывается отложенно после записи: отметка раньше записи позволила бы снимку её пропустить.
+func (t *tenantRepository) touch() {
+	t.dirty.Store(true)
+}
+
+// save отметка снимается до чтения данных, поэтому запись во время снимка попадёт в следующий.
+func (t *tenantRepository) save(path string) error {
+	t.saveMu.Lock()
+	defer t.saveMu.Unlock()
+
+	t.dirty.Store(false)
+	if err := t.tasks.SaveSnapshot(path); err != nil {
+		t.dirty.Store(true)
+		return err
+	}
+	return nil
+}
+
+// TenantRouter изолирует арендаторов: у каждого свой TaskRepository со своей последовательностью ID
+// и свой каталог dataDir/<tenant>. Арендатор берётся из контекста, без него используется арендатор
+// по умолчанию. С пустым dataDir данные живут только в памяти, иначе на диск их сохраняют Run
+// (изменённых арендаторов раз в интервал) и Flush при остановке.
+type TenantRouter struct {
+	dataDir       string
+	defaultTenant string
+
+	mu      sync.RWMutex
+	tenants map[string]*tenantRepository
+}
+
+func NewTenantRouter(dataDir string, defaultTenant entity.Tenant) (*TenantRouter, error) {
+	r := &TenantRouter{
+		dataDir:       dataDir,
+		defaultTenant: defaultTenant.ID,
+		tenants:       make(map[string]*tenantRepository),
+	}
+
+	if dataDir != "" {
+		var tenants []entity.Tenant
+		if err := readJSON(filepath.Join(dataDir, tenantsFile), &tenants); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		for _, tenant := range tenants {
+			tasks, err := LoadTaskRepository(r.tasksPath(tenant.ID))
+			if err != nil {
+				return nil, fmt.Errorf("tenant %q: %w", tenant.ID, err)
+			}
+			r.tenants[tenant.ID] = &tenantRepository{tenant: tenant, tasks: tasks}
+		}
+	}
+
+	if _, ok := r.tenants[defaultTenant.ID]; !ok {
+		if err := r.CreateTenant(context.Background(), defaultTenant); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *TenantRouter) tasksPath(tenantID string) string {
+	return filepath.Join(r.dataDir, tenantID, tasksFile)
+}
+
+// Flush сохраняет реестр и данные всех арендаторов, вызывается при остановке.
+func (r *TenantRouter) Flush() error {
+	if r.dataDir == "" {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return errors.Join(r.saveLocked(false), r.saveRegistryLocked())
+}
+
+// Run сохраняет изменённых арендаторов каждые interval, пока не отменён ctx: после аварийного
+// завершения теряются изменения не более чем за interval. interval <= 0 означает
+// DefaultSnapshotInterval.
+func (r *TenantRouter) Run(ctx context.Context, interval time.Duration) {
+	if r.dataDir == "" {
+		return
+	}
+	if interval <= 0 {
+		interval = DefaultSnapshotInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.flushChanged(); err != nil {
+				contextx.LoggerFromContextOrDefault(ctx).Error("tenant snapshot failed", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+func (r *TenantRouter) flushChanged() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.saveLocked(true)
+}
+
+// saveLocked с onlyDirty пропускает арендаторов без изменений после прошлого снимка.
+func (r *TenantRouter) saveLocked(onlyDirty bool) error {
+	var errs []error
+	for id, t := range r.tenants {
+		if onlyDirty && !t.dirty.Load() {
+			continue
+		}
+		if err := t.save(r.tasksPath(id)); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %q: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *TenantRouter) saveRegistryLocked() error {
+	if r.dataDir == "" {
+		return nil
+	}
+	tenants := make([]entity.Tenant, 0, len(r.tenants))
+	for _, t := range r.tenants {
+		tenants = append(tenants, t.tenant)
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].ID < tenants[j].ID })
+	return writeJSON(filepath.Join(r.dataDir, tenantsFile), tenants)
+}
+
+// route возвращает активного арендатора из контекста.
+func (r *TenantRouter) route(ctx context.Context) (*tenantRepository, error) {
+	t, _, err := r.routeQuota(ctx)
+	return t, err
+}
+
+// routeQuota как route, но дополнительно отдаёт копию квоты, снятую под r.mu:
+// t.tenant после этого может переписать UpdateTenant.
+func (r *TenantRouter) routeQuota(ctx context.Context) (*tenantRepository, entity.TenantQuota, error) {
+	tenantID, err := contextx.TenantFromContext(ctx)
+	if err != nil {
+		tenantID = r.defaultTenant
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.tenants[tenantID]
+	if !ok {
+		return nil, entity.TenantQuota{}, domain.ErrTenantNotFound
+	}
+	if t.tenant.Status == entity.TenantSuspended {
+		return nil, entity.TenantQuota{}, domain.ErrTenantSuspended
+	}
+	return t, t.tenant.Quota, nil
+}
+
+func (r *TenantRouter) CreateTenant(_ context.Context, tenant entity.Tenant) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tenants[tenant.ID]; ok {
+		return domain.ErrTenantExists
+	}
+	if r.dataDir != "" {
+		if err := os.MkdirAll(filepath.Join(r.dataDir, tenant.ID), 0o700); err != nil {
+			return fmt.Errorf("os.MkdirAll: %w", err)
+		}
+	}
+	r.tenants[tenant.ID] = &tenantRepository{tenant: tenant, tasks: NewTaskRepository()}
+	return r.saveRegistryLocked()
+}
+
+func (r *TenantRouter) GetTenant(_ context.Context, id string) (*entity.Tenant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.tenants[id]
+	if !ok {
+		return nil, domain.ErrTenantNotFound
+	}
+	tenant := t.tenant
+	return &tenant, nil
+}
+
+func (r *TenantRouter) ListTenants(_ context.Context) ([]entity.Tenant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tenants := make([]entity.Tenant, 0, len(r.tenants))
+	for _, t := range r.tenants {
+		tenants = append(tenants, t.tenant)
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].ID < tenants[j].ID })
+	return tenants, nil
+}
+
+// UpdateTenant при приостановке сразу сбрасывает данные арендатора на диск.
+func (r *TenantRouter) UpdateTenant(_ context.Context, tenant entity.Tenant) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tenants[tenant.ID]
+	if !ok {
+		return domain.ErrTenantNotFound
+	}
+	t.tenant = tenant
+	if tenant.Status == entity.TenantSuspended && r.dataDir != "" {
+		if err := t.save(r.tasksPath(tenant.ID)); err != nil {
+			return err
+		}
+	}
+	return r.saveRegistryLocked()
+}
+
+func (r *TenantRouter) DeleteTenant(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tenants[id]; !ok {
+		return domain.ErrTenantNotFound
+	}
+	delete(r.tenants, id)
+	if r.dataDir != "" {
+		if err := os.RemoveAll(filepath.Join(r.dataDir, id)); err != nil {
+			return fmt.Errorf("os.RemoveAll: %w", err)
+		}
+	}
+	return r.saveRegistryLocked()
+}
+
+func (r *TenantRouter) Ping(ctx context.Context) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, t := range r.tenants {
+		if err := t.tasks.Ping(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *TenantRouter) Stats() (total, completed int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, t := range r.tenants {
+		tt, tc := t.tasks.Stats()
+		total += tt
+		completed += tc
+	}
+	return total, completed
+}
+
+func (r *TenantRouter) GetByID(ctx context.Context, id int) (*entity.Task, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tasks.GetByID(ctx, id)
+}
+
+func (r *TenantRouter) GetAll(ctx context.Context) ([]entity.Task, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tasks.GetAll(ctx)
+}
+
+func (r *TenantRouter) GetAllByOwner(ctx context.Context, ownerID string) ([]entity.Task, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tasks.GetAllByOwner(ctx, ownerID)
+}
+
+func (r *TenantRouter) Create(ctx context.Context, task *entity.Task) (int, error) {
+	t, quota, err := r.routeQuota(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer t.touch()
+	return t.tasks.create(ctx, task, quota.MaxTasks, nil)
+}
+
+func (r *TenantRouter) CreateIf(ctx context.Context, task *entity.Task, check func(owned []entity.Task) error) (int, error) {
+	t, quota, err := r.routeQuota(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer t.touch()
+	return t.tasks.create(ctx, task, quota.MaxTasks, check)
+}
+
+func (r *TenantRouter) CreateBatch(ctx context.Context, tasks []*entity.Task, check func(owned []entity.Task) error) ([]int, error) {
+	t, quota, err := r.routeQuota(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer t.touch()
+	return t.tasks.createBatch(ctx, tasks, quota.MaxTasks, check)
+}
+
+func (r *TenantRouter) Update(ctx context.Context, task *entity.Task) error {
+	t, err := r.route(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.touch()
+	return t.tasks.Update(ctx, task)
+}
+
+func (r *TenantRouter) UpdateIf(ctx context.Context, task *entity.Task, check func(owned []entity.Task) error) error {
+	t, err := r.route(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.touch()
+	return t.tasks.UpdateIf(ctx, task, check)
+}
+
+func (r *TenantRouter) Delete(ctx context.Context, id int) error {
+	t, err := r.route(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.touch()
+	return t.tasks.Delete(ctx, id)
+}
+
+func (r *TenantRouter) DeleteIf(ctx context.Context, id int, check func(owned []entity.Task) error) error {
+	t, err := r.route(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.touch()
+	return t.tasks.DeleteIf(ctx, id, check)
+}
+
+func (r *TenantRouter) GetShare(ctx context.Context, taskID int, userID string) (*entity.Share, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tasks.GetShare(ctx, taskID, userID)
+}
+
+func (r *TenantRouter) ListShares(ctx context.Context, taskID int) ([]entity.Share, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tasks.ListShares(ctx, taskID)
+}
+
+func (r *TenantRouter) PutShare(ctx context.Context, share entity.Share) error {
+	t, err := r.route(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.touch()
+	return t.tasks.PutShare(ctx, share)
+}
+
+func (r *TenantRouter) DeleteShare(ctx context.Context, taskID int, userID string) error {
+	t, err := r.route(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.touch()
+	return t.tasks.DeleteShare(ctx, taskID, userID)
+}
+
+func (r *TenantRouter) GetSharedWith(ctx context.Context, userID string) ([]entity.Task, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tasks.GetSharedWith(ctx, userID)
+}
+
+func (r *TenantRouter) CreateProject(ctx context.Context, project *entity.Project) (int, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer t.touch()
+	return t.tasks.CreateProject(ctx, project)
+}
+
+func (r *TenantRouter) GetProject(ctx context.Context, id int) (*entity.Project, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tasks.GetProject(ctx, id)
+}
+
+func (r *TenantRouter) ListProjects(ctx context.Context, ownerID string) ([]entity.Project, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tasks.ListProjects(ctx, ownerID)
+}
+
+func (r *TenantRouter) UpdateProject(ctx context.Context, project *entity.Project) error {
+	t, err := r.route(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.touch()
+	return t.tasks.UpdateProject(ctx, project)
+}
+
+func (r *TenantRouter) DeleteProject(ctx context.Context, id int, policy entity.ProjectDeletePolicy) error {
+	t, err := r.route(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.touch()
+	return t.tasks.DeleteProject(ctx, id, policy)
+}
+
+func (r *TenantRouter) GetTasksByProject(ctx context.Context, projectID int) ([]entity.Task, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tasks.GetTasksByProject(ctx, projectID)
+}
+
+func (r *TenantRouter) GetProjectShare(ctx context.Context, projectID int, userID string) (*entity.ProjectShare, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tasks.GetProjectShare(ctx, projectID, userID)
+}
+
+func (r *TenantRouter) ListProjectShares(ctx context.Context, projectID int) ([]entity.ProjectShare, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tasks.ListProjectShares(ctx, projectID)
+}
+
+func (r *TenantRouter) PutProjectShare(ctx context.Context, share entity.ProjectShare) error {
+	t, err := r.route(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.touch()
+	return t.tasks.PutProjectShare(ctx, share)
+}
+
+func (r *TenantRouter) DeleteProjectShare(ctx context.Context, projectID int, userID string) error {
+	t, err := r.route(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.touch()
+	return t.tasks.DeleteProjectShare(ctx, projectID, userID)
+}
+
+func (r *TenantRouter) GetSharedProjects(ctx context.Context, userID string) ([]entity.Project, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tasks.GetSharedProjects(ctx, userID)
+}
+
+func (r *TenantRouter) CreateBoard(ctx context.Context, board *entity.Board) (int, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer t.touch()
+	return t.tasks.CreateBoard(ctx, board)
+}
+
+func (r *TenantRouter) GetBoard(ctx context.Context, id int) (*entity.Board, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tasks.GetBoard(ctx, id)
+}
+
+func (r *TenantRouter) ListBoards(ctx context.Context, ownerID string) ([]entity.Board, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tasks.ListBoards(ctx, ownerID)
+}
+
+func (r *TenantRouter) UpdateBoard(ctx context.Context, board *entity.Board) error {
+	t, err := r.route(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.touch()
+	return t.tasks.UpdateBoard(ctx, board)
+}
+
+func (r *TenantRouter) DeleteBoard(ctx context.Context, id int) error {
+	t, err := r.route(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.touch()
+	return t.tasks.DeleteBoard(ctx, id)
+}
+
+func (r *TenantRouter) SearchTasks(ctx context.Context, q search.Query) ([]search.Hit, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tasks.SearchTasks(ctx, q)
+}
+
+func (r *TenantRouter) Find(ctx context.Context, ownerID string, match func(entity.Task) bool) ([]entity.Task, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tasks.Find(ctx, ownerID, match)
+}
+
+func (r *TenantRouter) FindPage(ctx context.Context, ownerID string, afterID, limit int, match func(entity.Task) bool) ([]entity.Task, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tasks.FindPage(ctx, ownerID, afterID, limit, match)
+}
+
+func (r *TenantRouter) CreateView(ctx context.Context, view *entity.View) (int, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer t.touch()
+	return t.tasks.CreateView(ctx, view)
+}
+
+func (r *TenantRouter) GetView(ctx context.Context, id int) (*entity.View, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tasks.GetView(ctx, id)
+}
+
+func (r *TenantRouter) ListViews(ctx context.Context, userID string) ([]entity.View, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tasks.ListViews(ctx, userID)
+}
+
+func (r *TenantRouter) UpdateView(ctx context.Context, view *entity.View) error {
+	t, err := r.route(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.touch()
+	return t.tasks.UpdateView(ctx, view)
+}
+
+func (r *TenantRouter) DeleteView(ctx context.Context, id int) error {
+	t, err := r.route(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.touch()
+	return t.tasks.DeleteView(ctx, id)
+}
+
+func (r *TenantRouter) CountTasks(ctx context.Context, ownerID string, matchers []func(entity.Task) bool) ([]int, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tasks.CountTasks(ctx, ownerID, matchers)
+}
+
+func (r *TenantRouter) SaveFeed(ctx context.Context, feed *entity.Feed) error {
+	t, err := r.route(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.touch()
+	return t.tasks.SaveFeed(ctx, feed)
+}
+
+func (r *TenantRouter) GetFeed(ctx context.Context, ownerID string) (*entity.Feed, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tasks.GetFeed(ctx, ownerID)
+}
+
+func (r *TenantRouter) FeedByToken(ctx context.Context, tokenHash string) (*entity.Feed, error) {
+	t, err := r.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tasks.FeedByToken(ctx, tokenHash)
+}
+
+func (r *TenantRouter) TouchFeed(ctx context.Context, tokenHash, etag string, modifiedAt time.Time) error {
+	t, err := r.route(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.touch()
+	return t.tasks.TouchFeed(ctx, tokenHash, etag, modifiedAt)
+}
+
+func (r *TenantRouter) DeleteFeed(ctx context.Context, ownerID string) error {
+	t, err := r.route(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.touch()
+	return t.tasks.DeleteFeed(ctx, ownerID)
+}
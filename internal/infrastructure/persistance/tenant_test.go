@@ -0,0 +1,158 @@
+package persistance
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/pkg/contextx"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTenantRouter_Isolation(t *testing.T) {
+	dir := t.TempDir()
+	router, err := NewTenantRouter(dir, entity.Tenant{ID: "default", Status: entity.TenantActive})
+	if err != nil {
+		t.Fatalf("NewTenantRouter failed: %v", err)
+	}
+
+	bg := context.Background()
+	_ = router.CreateTenant(bg, entity.Tenant{ID: "team-a", Status: entity.TenantActive, Quota: entity.TenantQuota{MaxTasks: 2}})
+	_ = router.CreateTenant(bg, entity.Tenant{ID: "team-b", Status: entity.TenantActive})
+	if err := router.CreateTenant(bg, entity.Tenant{ID: "team-a"}); !errors.Is(err, domain.ErrTenantExists) {
+		t.Errorf("Expected ErrTenantExists, got %v", err)
+	}
+
+	ctxA := contextx.WithTenant(bg, "team-a")
+	ctxB := contextx.WithTenant(bg, "team-b")
+
+	idA, _ := router.Create(ctxA, &entity.Task{Title: "A"})
+	idB, _ := router.Create(ctxB, &entity.Task{Title: "B"})
+	if idA != 0 || idB != 0 {
+		t.Errorf("Expected separate ID sequences, got %d and %d", idA, idB)
+	}
+
+	task, err := router.GetByID(ctxB, idA)
+	if err != nil || task.Title != "B" {
+		t.Errorf("Tenant B must see only its own task 0, got %+v, %v", task, err)
+	}
+	if all, _ := router.GetAll(bg); len(all) != 0 {
+		t.Errorf("Default tenant must be empty, got %v", all)
+	}
+
+	batch := []*entity.Task{{Title: "A2"}, {Title: "A3"}}
+	if _, err := router.CreateBatch(ctxA, batch, nil); !errors.Is(err, domain.ErrQuotaExceeded) {
+		t.Errorf("Expected batch over quota to fail with ErrQuotaExceeded, got %v", err)
+	}
+	_, _ = router.Create(ctxA, &entity.Task{Title: "A2"})
+	if _, err := router.Create(ctxA, &entity.Task{Title: "A3"}); !errors.Is(err, domain.ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded, got %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantErr error
+	}{
+		{name: "Unknown tenant", ctx: contextx.WithTenant(bg, "nobody"), wantErr: domain.ErrTenantNotFound},
+		{name: "Active tenant", ctx: ctxB},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := router.GetAll(tt.ctx); !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetAll() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	_ = router.UpdateTenant(bg, entity.Tenant{ID: "team-b", Status: entity.TenantSuspended})
+	if _, err := router.GetAll(ctxB); !errors.Is(err, domain.ErrTenantSuspended) {
+		t.Errorf("Expected ErrTenantSuspended, got %v", err)
+	}
+	_ = router.UpdateTenant(bg, entity.Tenant{ID: "team-b", Status: entity.TenantActive})
+
+	if err := router.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	reopened, err := NewTenantRouter(dir, entity.Tenant{ID: "default", Status: entity.TenantActive})
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	if all, _ := reopened.GetAll(ctxA); len(all) != 2 {
+		t.Errorf("Expected tenant A tasks to survive restart, got %v", all)
+	}
+	if id, _ := reopened.Create(ctxB, &entity.Task{Title: "B2"}); id != 1 {
+		t.Errorf("Expected ID sequence to continue after restart, got %d", id)
+	}
+
+	if err := reopened.DeleteTenant(bg, "team-a"); err != nil {
+		t.Fatalf("DeleteTenant failed: %v", err)
+	}
+	if _, err := reopened.GetAll(ctxA); !errors.Is(err, domain.ErrTenantNotFound) {
+		t.Errorf("Expected deleted tenant to be gone, got %v", err)
+	}
+}
+
+func TestTenantRouter_Run(t *testing.T) {
+	dir := t.TempDir()
+	router, err := NewTenantRouter(dir, entity.Tenant{ID: "default", Status: entity.TenantActive})
+	if err != nil {
+		t.Fatalf("NewTenantRouter failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go router.Run(ctx, 10*time.Millisecond)
+
+	if _, err := router.Create(ctx, &entity.Task{Title: "Survives a crash"}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// Flush не вызывается: данные должны попасть на диск сами, как при аварийном завершении
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		reopened, err := NewTenantRouter(dir, entity.Tenant{ID: "default", Status: entity.TenantActive})
+		if err != nil {
+			t.Fatalf("Reopen failed: %v", err)
+		}
+		if all, _ := reopened.GetAll(ctx); len(all) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected task to be snapshotted without Flush")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Квота читается под блокировкой реестра, поэтому гонку ловит go test -race.
+func TestTenantRouter_CreateWhileUpdatingQuota(t *testing.T) {
+	router, err := NewTenantRouter("", entity.Tenant{ID: "default", Status: entity.TenantActive})
+	if err != nil {
+		t.Fatalf("NewTenantRouter failed: %v", err)
+	}
+	bg := context.Background()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			_, _ = router.Create(bg, &entity.Task{Title: "task"})
+			_, _ = router.CreateBatch(bg, []*entity.Task{{Title: "batch"}}, nil)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			quota := entity.TenantQuota{MaxTasks: 1000 + i}
+			_ = router.UpdateTenant(bg, entity.Tenant{ID: "default", Status: entity.TenantActive, Quota: quota})
+		}
+	}()
+	wg.Wait()
+
+	if all, _ := router.GetAll(bg); len(all) != 100 {
+		t.Errorf("Expected 100 tasks, got %d", len(all))
+	}
+}
@@ -0,0 +1,125 @@
+package persistance
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/pkg/tracing"
+	"slices"
+	"sort"
+)
+
+func cloneView(view entity.View) entity.View {
+	view.Fields = slices.Clone(view.Fields)
+	view.SharedWith = slices.Clone(view.SharedWith)
+	return view
+}
+
+func (r *TaskRepository) CreateView(ctx context.Context, view *entity.View) (int, error) {
+	_, span := tracing.Start(ctx, "TaskRepository.CreateView")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	view.ID = r.viewID
+	r.views[view.ID] = cloneView(*view)
+	r.viewID++
+	return view.ID, nil
+}
+
+func (r *TaskRepository) GetView(ctx context.Context, id int) (*entity.View, error) {
+	_, span := tracing.Start(ctx, "TaskRepository.GetView")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if v, ok := r.views[id]; ok {
+		view := cloneView(v)
+		return &view, nil
+	}
+	return nil, domain.ErrViewNotFound
+}
+
+// ListViews собственные и расшаренные пользователю представления, с пустым userID все.
+func (r *TaskRepository) ListViews(ctx context.Context, userID string) ([]entity.View, error) {
+	_, span := tracing.Start(ctx, "TaskRepository.ListViews")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	views := make([]entity.View, 0)
+	for _, v := range r.views {
+		if userID == "" || v.VisibleTo(userID) {
+			views = append(views, cloneView(v))
+		}
+	}
+	sort.Slice(views, func(i, j int) bool { return views[i].ID < views[j].ID })
+	return views, nil
+}
+
+func (r *TaskRepository) UpdateView(ctx context.Context, view *entity.View) error {
+	_, span := tracing.Start(ctx, "TaskRepository.UpdateView")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.views[view.ID]; !ok {
+		return domain.ErrViewNotFound
+	}
+	r.views[view.ID] = cloneView(*view)
+	return nil
+}
+
+func (r *TaskRepository) DeleteView(ctx context.Context, id int) error {
+	_, span := tracing.Start(ctx, "TaskRepository.DeleteView")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.views[id]; !ok {
+		return domain.ErrViewNotFound
+	}
+	delete(r.views, id)
+	return nil
+}
+
+// CountTasks за один проход по задачам владельца (всем при пустом ownerID) считает,
+// сколько задач подходит под каждый из предикатов.
+func (r *TaskRepository) CountTasks(ctx context.Context, ownerID string, matchers []func(entity.Task) bool) ([]int, error) {
+	ctx, span := tracing.Start(ctx, "TaskRepository.CountTasks")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make([]int, len(matchers))
+	count := func(task entity.Task) {
+		for i, match := range matchers {
+			if match(task) {
+				counts[i]++
+			}
+		}
+	}
+
+	if ownerID == "" {
+		for _, task := range r.data {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			count(task)
+		}
+		return counts, nil
+	}
+	for id := range r.byOwner[ownerID] {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		count(r.data[id])
+	}
+	return counts, nil
+}
@@ -0,0 +1,234 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Поля задачи, на которые отображаются колонки CSV.
+const (
+	fieldTitle       = "title"
+	fieldDescription = "description"
+	fieldStatus      = "status"
+	fieldCompleted   = "is_completed"
+	fieldPriority    = "priority"
+	fieldTags        = "tags"
+	fieldDue         = "due_at"
+	fieldProject     = "project"
+)
+
+// columnAliases заголовки, которые распознаются без явного отображения, в нижнем регистре.
+var columnAliases = map[string]string{ //nolint:gochecknoglobals
+	"title": fieldTitle, "name": fieldTitle, "summary": fieldTitle, "task": fieldTitle, "subject": fieldTitle,
+	"заголовок": fieldTitle, "название": fieldTitle, "задача": fieldTitle,
+	"description": fieldDescription, "notes": fieldDescription, "note": fieldDescription, "описание": fieldDescription,
+	"status": fieldStatus, "статус": fieldStatus,
+	"is_completed": fieldCompleted, "completed": fieldCompleted, "done": fieldCompleted, "выполнено": fieldCompleted,
+	"priority": fieldPriority, "приоритет": fieldPriority,
+	"tags": fieldTags, "tag": fieldTags, "labels": fieldTags, "метки": fieldTags, "теги": fieldTags,
+	"due_at": fieldDue, "due": fieldDue, "due_date": fieldDue, "deadline": fieldDue, "срок": fieldDue,
+	"project": fieldProject, "проект": fieldProject,
+}
+
+func isField(name string) bool {
+	switch name {
+	case fieldTitle, fieldDescription, fieldStatus, fieldCompleted, fieldPriority, fieldTags, fieldDue, fieldProject:
+		return true
+	}
+	return false
+}
+
+// decodeCSV первая запись заголовок. mapping «заголовок → поле» важнее псевдонимов, колонки без
+// поля пропускаются. Без колонки заголовка задачи файл не принимается. Разделитель (запятая,
+// точка с запятой или табуляция) определяется по заголовку: Excel в русской локали пишет «;».
+func decodeCSV(r io.Reader, mapping map[string]string) ([]entity.ImportItem, error) {
+	buffered := bufio.NewReader(r)
+	head, _ := buffered.Peek(4096)
+	reader := csv.NewReader(buffered)
+	reader.Comma = sniffDelimiter(head)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: header: %w", domain.ErrInvalidImport, err)
+	}
+	columns, err := mapColumns(header, mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []entity.ImportItem
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return items, nil
+		}
+		var perr *csv.ParseError
+		if errors.As(err, &perr) {
+			items = append(items, entity.ImportItem{Line: perr.StartLine, Err: fmt.Errorf("%w: %w", domain.ErrInvalidImport, perr.Err)})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", domain.ErrInvalidImport, err)
+		}
+		if blank(record) {
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		item := entity.ImportItem{Line: line}
+		item.Err = fillFromCSV(&item, columns, record)
+		items = append(items, item)
+	}
+}
+
+// sniffDelimiter самый частый из кандидатов в первой строке, запятая при равенстве.
+func sniffDelimiter(head []byte) rune {
+	if i := bytes.IndexByte(head, '\n'); i >= 0 {
+		head = head[:i]
+	}
+	best, count := ',', bytes.Count(head, []byte{','})
+	for _, c := range []rune{';', '\t'} {
+		if n := bytes.Count(head, []byte(string(c))); n > count {
+			best, count = c, n
+		}
+	}
+	return best
+}
+
+func mapColumns(header []string, mapping map[string]string) ([]string, error) {
+	explicit := make(map[string]string, len(mapping))
+	for column, field := range mapping {
+		if !isField(field) {
+			return nil, fmt.Errorf("%w: mapping %q to unknown field %q", domain.ErrInvalidImport, column, field)
+		}
+		explicit[strings.ToLower(strings.TrimSpace(column))] = field
+	}
+
+	columns := make([]string, len(header))
+	hasTitle := false
+	for i, name := range header {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		field, ok := explicit[key]
+		if !ok {
+			field = columnAliases[key]
+		}
+		columns[i] = field
+		hasTitle = hasTitle || field == fieldTitle
+	}
+	if !hasTitle {
+		return nil, fmt.Errorf("%w: no title column in header %q, map one with mapping", domain.ErrInvalidImport, header)
+	}
+	return columns, nil
+}
+
+func blank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func fillFromCSV(item *entity.ImportItem, columns, record []string) error {
+	task := &item.Task
+	for i, value := range record {
+		if i >= len(columns) || columns[i] == "" {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		switch columns[i] {
+		case fieldTitle:
+			task.Title = value
+		case fieldDescription:
+			task.Description = value
+		case fieldStatus:
+			task.Status = entity.TaskStatus(strings.ToLower(value))
+		case fieldCompleted:
+			done, ok := parseBool(value)
+			if !ok {
+				return fmt.Errorf("%w: %s: expected true or false, got %q", domain.ErrInvalidImport, fieldCompleted, value)
+			}
+			task.IsCompleted = done
+		case fieldPriority:
+			task.Priority = entity.Priority(value)
+		case fieldTags:
+			task.Tags = strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' || unicode.IsSpace(r) })
+		case fieldDue:
+			due, err := parseDue(value)
+			if err != nil {
+				return err
+			}
+			task.DueAt = &due
+		case fieldProject:
+			item.Project = value
+		}
+	}
+	return nil
+}
+
+func parseBool(value string) (bool, bool) {
+	switch strings.ToLower(value) {
+	case "true", "1", "yes", "y", "x", "да":
+		return true, true
+	case "false", "0", "no", "n", "нет":
+		return false, true
+	}
+	return false, false
+}
+
+// exportColumns колонки выгрузки, id сверх полей импорта при загрузке обратно пропускается.
+var exportColumns = []string{"id", fieldTitle, fieldDescription, fieldStatus, fieldCompleted, fieldPriority, fieldTags, fieldDue, fieldProject} //nolint:gochecknoglobals
+
+// csvEncoder кавычки и экранирование по RFC 4180 делает encoding/csv.
+type csvEncoder struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVEncoder(w io.Writer) (*csvEncoder, error) {
+	e := &csvEncoder{w: csv.NewWriter(w), record: make([]string, len(exportColumns))}
+	if err := e.w.Write(exportColumns); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *csvEncoder) Encode(task entity.Task, project string) error {
+	due := ""
+	if task.DueAt != nil {
+		due = task.DueAt.UTC().Format(time.RFC3339)
+	}
+	e.record[0] = strconv.Itoa(task.ID)
+	e.record[1] = task.Title
+	e.record[2] = task.Description
+	e.record[3] = string(task.CurrentStatus())
+	e.record[4] = strconv.FormatBool(task.IsCompleted)
+	e.record[5] = string(task.Priority)
+	e.record[6] = strings.Join(task.Tags, ",")
+	e.record[7] = due
+	e.record[8] = project
+	return e.w.Write(e.record)
+}
+
+func (e *csvEncoder) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
@@ -0,0 +1,71 @@
+package transfer
+
+import (
+	"bufio"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/domain/service"
+	"ecom_test/pkg/ical"
+	"fmt"
+	"io"
+	"time"
+)
+
+// feedRefresh как часто клиенту предлагается перечитывать ленту (RFC 7986 и расширение Outlook).
+const feedRefresh = "PT1H"
+
+// FeedOptions Todos и Events выбирают, какими компонентами показывать сроки; хотя бы один нужен.
+type FeedOptions struct {
+	Name   string
+	Todos  bool
+	Events bool
+}
+
+// EncodeFeed календарь подписки на сроки. Каждая задача даёт VTODO, а незавершённая ещё и VEVENT
+// на момент срока: многие календари показывают из подписки только события. Содержимое зависит
+// только от cal, поэтому совпадает между запросами, пока не изменился ETag ленты.
+func EncodeFeed(w io.Writer, cal *service.FeedCalendar, opts FeedOptions) error {
+	buffered := bufio.NewWriter(w)
+	enc := ical.NewEncoder(buffered)
+	_ = beginCalendar(enc,
+		ical.Property{Name: "CALSCALE", Value: "GREGORIAN"},
+		ical.Property{Name: "METHOD", Value: "PUBLISH"},
+		ical.TextProperty("X-WR-CALNAME", opts.Name),
+		ical.Property{Name: "REFRESH-INTERVAL", Params: map[string]string{"VALUE": "DURATION"}, Value: feedRefresh},
+		ical.Property{Name: "X-PUBLISHED-TTL", Value: feedRefresh},
+	)
+	stamp := cal.Feed.ModifiedAt
+	for _, item := range cal.Items {
+		if opts.Todos {
+			_ = enc.Encode(vtodo(item.Task, item.Project, stamp))
+		}
+		if opts.Events && item.Task.DueAt != nil && item.Task.CurrentStatus() != entity.StatusDone {
+			_ = enc.Encode(vevent(item.Task, stamp))
+		}
+	}
+	if err := enc.End("VCALENDAR"); err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
+
+// vevent срок в полночь UTC становится событием на весь день, иначе событием без длительности.
+// TRANSP:TRANSPARENT не даёт сроку занимать время в расписании.
+func vevent(task entity.Task, stamp time.Time) *ical.Component {
+	if !task.StatusChangedAt.IsZero() {
+		stamp = task.StatusChangedAt
+	}
+	event := &ical.Component{Name: "VEVENT", Props: []ical.Property{
+		{Name: "UID", Value: fmt.Sprintf("task-%d-due@todo-api", task.ID)},
+		ical.DateTimeProperty("DTSTAMP", stamp),
+		ical.TextProperty("SUMMARY", task.Title),
+		dueProperty("DTSTART", *task.DueAt),
+		{Name: "TRANSP", Value: "TRANSPARENT"},
+	}}
+	if task.Description != "" {
+		event.Props = append(event.Props, ical.TextProperty("DESCRIPTION", task.Description))
+	}
+	if len(task.Tags) > 0 {
+		event.Props = append(event.Props, ical.Property{Name: "CATEGORIES", Value: ical.JoinList(task.Tags)})
+	}
+	return event
+}
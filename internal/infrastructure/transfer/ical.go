@@ -0,0 +1,255 @@
+package transfer
+
+import (
+	"bufio"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/pkg/ical"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Нестандартные свойства VTODO для того, чего нет в RFC 5545: статус review и проект задачи.
+const (
+	propStatus  = "X-TODO-STATUS"
+	propProject = "X-TODO-PROJECT"
+	prodID      = "-//todo-api//tasks//EN"
+)
+
+// decodeICal задачами становятся VTODO, остальные компоненты (VEVENT, VTIMEZONE) пропускаются.
+func decodeICal(r io.Reader) ([]entity.ImportItem, error) {
+	cal, err := ical.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidImport, err)
+	}
+	if cal.Name != "VCALENDAR" {
+		return nil, fmt.Errorf("%w: expected VCALENDAR, got %s", domain.ErrInvalidImport, cal.Name)
+	}
+
+	todos := cal.Children("VTODO")
+	items := make([]entity.ImportItem, 0, len(todos))
+	for _, todo := range todos {
+		item := entity.ImportItem{Line: todo.Line, Project: todo.Text(propProject)}
+		item.Err = fillFromVTODO(&item.Task, todo)
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func fillFromVTODO(task *entity.Task, todo *ical.Component) error {
+	task.Title = strings.TrimSpace(todo.Text("SUMMARY"))
+	task.Description = todo.Text("DESCRIPTION")
+
+	status, err := statusOfVTODO(todo)
+	if err != nil {
+		return err
+	}
+	task.Status = status
+
+	if p, ok := todo.Get("PRIORITY"); ok {
+		level, err := strconv.Atoi(p.Value)
+		if err != nil || level < 0 || level > 9 {
+			return fmt.Errorf("%w: PRIORITY must be 0-9, got %q", domain.ErrInvalidImport, p.Value)
+		}
+		task.Priority = priorityOfLevel(level)
+	}
+
+	for _, p := range todo.Props {
+		if p.Name == "CATEGORIES" {
+			task.Tags = append(task.Tags, ical.SplitList(p.Value)...)
+		}
+	}
+
+	if p, ok := todo.Get("DUE"); ok {
+		due, _, err := p.Time()
+		if err != nil {
+			return fmt.Errorf("%w: %w", domain.ErrInvalidImport, err)
+		}
+		task.DueAt = &due
+	}
+	return nil
+}
+
+// statusOfVTODO CANCELLED считается выполненной: отдельного статуса для отменённых задач нет.
+// X-TODO-STATUS, записанный выгрузкой, точнее стандартного STATUS.
+func statusOfVTODO(todo *ical.Component) (entity.TaskStatus, error) {
+	if status := entity.TaskStatus(strings.ToLower(todo.Text(propStatus))); status.Valid() {
+		return status, nil
+	}
+	return standardStatus(todo)
+}
+
+func standardStatus(todo *ical.Component) (entity.TaskStatus, error) {
+	if _, ok := todo.Get("COMPLETED"); ok {
+		return entity.StatusDone, nil
+	}
+	switch value := strings.ToUpper(todo.Text("STATUS")); value {
+	case "", "NEEDS-ACTION":
+		return entity.StatusTodo, nil
+	case "IN-PROCESS":
+		return entity.StatusInProgress, nil
+	case "COMPLETED", "CANCELLED":
+		return entity.StatusDone, nil
+	default:
+		return "", fmt.Errorf("%w: unknown VTODO STATUS %q", domain.ErrInvalidImport, value)
+	}
+}
+
+// priorityOfLevel RFC 5545: 1-4 высокий, 5 средний, 6-9 низкий, 0 не задан.
+func priorityOfLevel(level int) entity.Priority {
+	switch {
+	case level == 0:
+		return entity.PriorityNone
+	case level <= 4:
+		return entity.PriorityHigh
+	case level == 5:
+		return entity.PriorityMedium
+	}
+	return entity.PriorityLow
+}
+
+// levelOfPriority обратное к priorityOfLevel: середины диапазонов RFC 5545.
+func levelOfPriority(p entity.Priority) int {
+	switch p {
+	case entity.PriorityHigh:
+		return 1
+	case entity.PriorityMedium:
+		return 5
+	case entity.PriorityLow:
+		return 9
+	}
+	return 0
+}
+
+type icalEncoder struct {
+	w   *bufio.Writer
+	enc *ical.Encoder
+	now func() time.Time
+}
+
+func newICalEncoder(w io.Writer) (*icalEncoder, error) {
+	buffered := bufio.NewWriter(w)
+	e := &icalEncoder{w: buffered, enc: ical.NewEncoder(buffered), now: time.Now}
+	if err := beginCalendar(e.enc); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// beginCalendar BEGIN:VCALENDAR с обязательными VERSION и PRODID и дополнительными свойствами.
+func beginCalendar(enc *ical.Encoder, props ...ical.Property) error {
+	_ = enc.Begin("VCALENDAR")
+	_ = enc.WriteProperty(ical.Property{Name: "VERSION", Value: "2.0"})
+	err := enc.WriteProperty(ical.Property{Name: "PRODID", Value: prodID})
+	for _, p := range props {
+		err = enc.WriteProperty(p)
+	}
+	return err
+}
+
+func (e *icalEncoder) Encode(task entity.Task, project string) error {
+	return e.enc.Encode(vtodo(task, project, e.now()))
+}
+
+func (e *icalEncoder) Close() error {
+	if err := e.enc.End("VCALENDAR"); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+// vtodo UID постоянный для задачи: выбранный CalDAV-клиентом или построенный по ID, DTSTAMP момент смены статуса, а у задач без него stamp.
+func vtodo(task entity.Task, project string, stamp time.Time) *ical.Component {
+	if !task.StatusChangedAt.IsZero() {
+		stamp = task.StatusChangedAt
+	}
+	status := task.CurrentStatus()
+	todo := &ical.Component{Name: "VTODO", Props: []ical.Property{
+		{Name: "UID", Value: todoUID(task)},
+		ical.DateTimeProperty("DTSTAMP", stamp),
+		ical.TextProperty("SUMMARY", task.Title),
+	}}
+	add := func(p ical.Property) { todo.Props = append(todo.Props, p) }
+
+	if task.Description != "" {
+		add(ical.TextProperty("DESCRIPTION", task.Description))
+	}
+	switch status {
+	case entity.StatusDone:
+		add(ical.Property{Name: "STATUS", Value: "COMPLETED"})
+		add(ical.DateTimeProperty("COMPLETED", stamp))
+	case entity.StatusInProgress, entity.StatusReview:
+		add(ical.Property{Name: "STATUS", Value: "IN-PROCESS"})
+	default:
+		add(ical.Property{Name: "STATUS", Value: "NEEDS-ACTION"})
+	}
+	add(ical.TextProperty(propStatus, string(status)))
+	if level := levelOfPriority(task.Priority); level > 0 {
+		add(ical.Property{Name: "PRIORITY", Value: strconv.Itoa(level)})
+	}
+	if len(task.Tags) > 0 {
+		add(ical.Property{Name: "CATEGORIES", Value: ical.JoinList(task.Tags)})
+	}
+	if task.DueAt != nil {
+		add(dueProperty("DUE", *task.DueAt))
+	}
+	if project != "" {
+		add(ical.TextProperty(propProject, project))
+	}
+	return todo
+}
+
+// dueProperty срок ровно в полночь UTC пишется датой без времени.
+func dueProperty(name string, due time.Time) ical.Property {
+	if due = due.UTC(); due.Equal(due.Truncate(24 * time.Hour)) {
+		return ical.DateProperty(name, due)
+	}
+	return ical.DateTimeProperty(name, due)
+}
+
+func todoUID(task entity.Task) string {
+	if task.UID != "" {
+		return task.UID
+	}
+	return fmt.Sprintf("task-%d@todo-api", task.ID)
+}
+
+// TodoCalendar задача отдельным объектом VCALENDAR, как её хранит CalDAV. DTSTAMP у задачи без
+// смены статуса нулевой момент Unix, чтобы данные и их ETag не менялись от запроса к запросу.
+func TodoCalendar(task entity.Task, project string) *ical.Component {
+	return &ical.Component{
+		Name: "VCALENDAR",
+		Props: []ical.Property{
+			{Name: "VERSION", Value: "2.0"},
+			{Name: "PRODID", Value: prodID},
+		},
+		Components: []*ical.Component{vtodo(task, project, time.Unix(0, 0))},
+	}
+}
+
+// TaskFromTodo задача из объекта CalDAV с единственным VTODO. Статус, включая done, пишется в
+// Status; ID, владелец и проект не заполняются. Ошибки оборачивают domain.ErrInvalidImport.
+func TaskFromTodo(cal *ical.Component) (entity.Task, error) {
+	todos := cal.Children("VTODO")
+	if cal.Name != "VCALENDAR" || len(todos) != 1 {
+		return entity.Task{}, fmt.Errorf("%w: expected VCALENDAR with one VTODO", domain.ErrInvalidImport)
+	}
+	task := entity.Task{UID: todos[0].Text("UID")}
+	if err := fillFromVTODO(&task, todos[0]); err != nil {
+		return entity.Task{}, err
+	}
+	// клиент меняет STATUS и COMPLETED, но сохраняет X-TODO-STATUS из прежней версии как есть,
+	// поэтому тот только уточняет IN-PROCESS до review
+	status, err := standardStatus(todos[0])
+	if err != nil {
+		return entity.Task{}, err
+	}
+	if status == entity.StatusInProgress && task.Status == entity.StatusReview {
+		status = entity.StatusReview
+	}
+	task.Status = status
+	return task, nil
+}
@@ -0,0 +1,82 @@
+package transfer
+
+import (
+	"bufio"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// jsonlTask одна строка JSON Lines. ID при импорте не используется: задачи всегда создаются заново.
+type jsonlTask struct {
+	ID          int        `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	Status      string     `json:"status"`
+	IsCompleted bool       `json:"is_completed"`
+	Priority    string     `json:"priority,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	Project     string     `json:"project,omitempty"`
+}
+
+// decodeJSONL по объекту задачи на строку, пустые строки пропускаются.
+func decodeJSONL(r io.Reader) ([]entity.ImportItem, error) {
+	var items []entity.ImportItem
+	err := scanLines(r, func(num int, line string) {
+		if strings.TrimSpace(line) == "" {
+			return
+		}
+		item := entity.ImportItem{Line: num}
+		var v jsonlTask
+		if err := json.Unmarshal([]byte(line), &v); err != nil {
+			item.Err = fmt.Errorf("%w: %w", domain.ErrInvalidImport, err)
+		} else {
+			item.Task = entity.Task{
+				Title:       v.Title,
+				Description: v.Description,
+				Status:      entity.TaskStatus(v.Status),
+				IsCompleted: v.IsCompleted,
+				Priority:    entity.Priority(v.Priority),
+				Tags:        v.Tags,
+				DueAt:       v.DueAt,
+			}
+			item.Project = v.Project
+		}
+		items = append(items, item)
+	})
+	return items, err
+}
+
+type jsonlEncoder struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func newJSONLEncoder(w io.Writer) *jsonlEncoder {
+	buffered := bufio.NewWriter(w)
+	return &jsonlEncoder{w: buffered, enc: json.NewEncoder(buffered)}
+}
+
+// Encode json.Encoder сам завершает запись переводом строки.
+func (e *jsonlEncoder) Encode(task entity.Task, project string) error {
+	return e.enc.Encode(jsonlTask{
+		ID:          task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      string(task.CurrentStatus()),
+		IsCompleted: task.IsCompleted,
+		Priority:    string(task.Priority),
+		Tags:        task.Tags,
+		DueAt:       task.DueAt,
+		Project:     project,
+	})
+}
+
+func (e *jsonlEncoder) Close() error {
+	return e.w.Flush()
+}
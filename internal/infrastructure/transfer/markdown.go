@@ -0,0 +1,87 @@
+package transfer
+
+import (
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// checklistItem `- [ ] текст`, `* [x] текст`, `1. [ ] текст` с любым отступом.
+var checklistItem = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+\[(.?)\](?:\s+(.*))?$`) //nolint:gochecknoglobals
+
+// decodeMarkdown задачами становятся только пункты чеклиста, заголовки и текст вокруг пропускаются.
+// В тексте пункта распознаются #метка, !приоритет, @проект и due:дата.
+func decodeMarkdown(r io.Reader) ([]entity.ImportItem, error) {
+	var items []entity.ImportItem
+	err := scanLines(r, func(num int, line string) {
+		m := checklistItem.FindStringSubmatch(line)
+		if m == nil {
+			return
+		}
+		item := entity.ImportItem{Line: num}
+		switch m[1] {
+		case " ", "":
+		case "x", "X":
+			item.Task.IsCompleted = true
+		default:
+			item.Err = fmt.Errorf("%w: unknown checkbox [%s], expected [ ] or [x]", domain.ErrInvalidImport, m[1])
+		}
+		if item.Err == nil {
+			item.Project, item.Err = parseInline(&item.Task, m[2])
+		}
+		items = append(items, item)
+	})
+	return items, err
+}
+
+func parseInline(task *entity.Task, text string) (string, error) {
+	var (
+		project string
+		title   []string
+	)
+	for _, w := range strings.Fields(text) {
+		switch {
+		case len(w) > 1 && w[0] == '#':
+			task.Tags = append(task.Tags, w[1:])
+		case len(w) > 1 && w[0] == '!' && entity.Priority(strings.ToLower(w[1:])).Valid():
+			task.Priority = entity.Priority(w[1:])
+		case len(w) > 1 && w[0] == '@' && project == "":
+			project = w[1:]
+		case strings.HasPrefix(w, "due:"):
+			due, err := parseDue(strings.TrimPrefix(w, "due:"))
+			if err != nil {
+				return project, err
+			}
+			task.DueAt = &due
+		default:
+			title = append(title, w)
+		}
+	}
+	task.Title = strings.Join(title, " ")
+	return project, nil
+}
+
+// formatMarkdown пункт чеклиста с теми же пометками, что понимает parseInline.
+func formatMarkdown(b *strings.Builder, task entity.Task, project string) {
+	if task.CurrentStatus() == entity.StatusDone {
+		b.WriteString("- [x] ")
+	} else {
+		b.WriteString("- [ ] ")
+	}
+	b.WriteString(oneLine(task.Title))
+	for _, tag := range task.Tags {
+		b.WriteString(" #" + word(tag))
+	}
+	if task.Priority != entity.PriorityNone {
+		b.WriteString(" !" + string(task.Priority))
+	}
+	if task.DueAt != nil {
+		b.WriteString(" due:" + formatDue(*task.DueAt))
+	}
+	if project != "" {
+		b.WriteString(" @" + word(project))
+	}
+}
@@ -0,0 +1,167 @@
+package transfer
+
+import (
+	"bufio"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout = "2006-01-02"
+	// maxLineLength строка todo.txt или Markdown длиннее считается ошибкой файла.
+	maxLineLength = 1 << 20
+)
+
+// decodeTodoTxt формат http://todotxt.org: `x 2026-10-19 (A) Позвонить +Work @phone due:2026-10-25`.
+// +проект становится проектом задачи (первый из указанных), @контекст меткой, (A)/(B)/(C и ниже)
+// приоритетом high/medium/low. Расширения кроме due: и pri: остаются в заголовке.
+func decodeTodoTxt(r io.Reader) ([]entity.ImportItem, error) {
+	var items []entity.ImportItem
+	err := scanLines(r, func(num int, line string) {
+		if strings.TrimSpace(line) == "" {
+			return
+		}
+		item := entity.ImportItem{Line: num}
+		item.Task, item.Project, item.Err = parseTodoTxt(line)
+		items = append(items, item)
+	})
+	return items, err
+}
+
+func scanLines(r io.Reader, fn func(num int, line string)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+	num := 0
+	for scanner.Scan() {
+		num++
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if num == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		fn(num, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: line %d: %w", domain.ErrInvalidImport, num+1, err)
+	}
+	return nil
+}
+
+func parseTodoTxt(line string) (entity.Task, string, error) {
+	var (
+		task    entity.Task
+		project string
+		title   []string
+	)
+	words := strings.Fields(line)
+
+	if len(words) > 0 && words[0] == "x" {
+		task.IsCompleted = true
+		words = words[1:]
+		// дата выполнения и дата создания
+		for range 2 {
+			if len(words) > 0 && isDate(words[0]) {
+				words = words[1:]
+			}
+		}
+	}
+	if len(words) > 0 && len(words[0]) == 3 && words[0][0] == '(' && words[0][2] == ')' && isPriorityLetter(words[0][1]) {
+		task.Priority = priorityOfLetter(words[0][1])
+		words = words[1:]
+	}
+	if !task.IsCompleted && len(words) > 0 && isDate(words[0]) {
+		words = words[1:]
+	}
+
+	for _, w := range words {
+		switch {
+		case len(w) > 1 && w[0] == '+' && project == "":
+			project = w[1:]
+		case len(w) > 1 && w[0] == '@':
+			task.Tags = append(task.Tags, w[1:])
+		case strings.HasPrefix(w, "due:"):
+			due, err := parseDue(strings.TrimPrefix(w, "due:"))
+			if err != nil {
+				return task, project, err
+			}
+			task.DueAt = &due
+		case strings.HasPrefix(w, "pri:") && len(w) == 5 && isPriorityLetter(w[4]):
+			task.Priority = priorityOfLetter(w[4])
+		default:
+			title = append(title, w)
+		}
+	}
+	task.Title = strings.Join(title, " ")
+	return task, project, nil
+}
+
+func isDate(s string) bool {
+	_, err := time.Parse(dateLayout, s)
+	return err == nil
+}
+
+func isPriorityLetter(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
+
+// priorityOfLetter у задачи три уровня приоритета, все буквы после C считаются low.
+func priorityOfLetter(c byte) entity.Priority {
+	switch c {
+	case 'A':
+		return entity.PriorityHigh
+	case 'B':
+		return entity.PriorityMedium
+	}
+	return entity.PriorityLow
+}
+
+// parseDue дата без времени даёт полночь UTC, как due:YYYY-MM-DD в языке запросов.
+func parseDue(value string) (time.Time, error) {
+	for _, layout := range []string{dateLayout, time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: invalid due date %q, expected YYYY-MM-DD or RFC 3339", domain.ErrInvalidImport, value)
+}
+
+// formatTodoTxt обратное к parseTodoTxt. У выполненной задачи приоритет пишется как pri:,
+// потому что строка с x по спецификации продолжается датой, а не (A).
+func formatTodoTxt(b *strings.Builder, task entity.Task, project string) {
+	letter := letterOfPriority(task.Priority)
+	done := task.CurrentStatus() == entity.StatusDone
+	switch {
+	case done:
+		b.WriteString("x ")
+	case letter != 0:
+		b.WriteString("(" + string(letter) + ") ")
+	}
+	b.WriteString(oneLine(task.Title))
+	if project != "" {
+		b.WriteString(" +" + word(project))
+	}
+	for _, tag := range task.Tags {
+		b.WriteString(" @" + word(tag))
+	}
+	if task.DueAt != nil {
+		b.WriteString(" due:" + formatDue(*task.DueAt))
+	}
+	if done && letter != 0 {
+		b.WriteString(" pri:" + string(letter))
+	}
+}
+
+func letterOfPriority(p entity.Priority) byte {
+	switch p {
+	case entity.PriorityHigh:
+		return 'A'
+	case entity.PriorityMedium:
+		return 'B'
+	case entity.PriorityLow:
+		return 'C'
+	}
+	return 0
+}
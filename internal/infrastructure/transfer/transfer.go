@@ -0,0 +1,135 @@
+// Package transfer перевод задач между сервисом и внешними форматами (JSON Lines, todo.txt, CSV,
+// Markdown-чеклисты, iCalendar VTODO). При импорте ошибка отдельной строки попадает в её элемент,
+// ошибка всего файла (нечитаемый CSV, сломанная структура календаря) возвращается из Decode.
+// Выгрузка пишет задачи по одной через Encoder, то, что он записал, читается обратно Decode.
+package transfer
+
+import (
+	"bufio"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatJSONL    Format = "jsonl"
+	FormatTodoTxt  Format = "todotxt"
+	FormatCSV      Format = "csv"
+	FormatMarkdown Format = "markdown"
+	FormatICal     Format = "ical"
+)
+
+// ParseFormat принимает и привычные синонимы: json, ndjson, todo.txt, md, ics.
+func ParseFormat(name string) (Format, bool) {
+	switch strings.ToLower(name) {
+	case "jsonl", "json", "ndjson":
+		return FormatJSONL, true
+	case "todotxt", "todo.txt", "txt":
+		return FormatTodoTxt, true
+	case "csv":
+		return FormatCSV, true
+	case "markdown", "md":
+		return FormatMarkdown, true
+	case "ical", "ics", "icalendar":
+		return FormatICal, true
+	}
+	return "", false
+}
+
+// FormatOf формат по расширению имени файла.
+func FormatOf(filename string) (Format, bool) {
+	return ParseFormat(strings.TrimPrefix(filepath.Ext(filename), "."))
+}
+
+// DecodeOptions Mapping соответствие заголовков CSV полям задачи, дополняет распознавание по умолчанию.
+type DecodeOptions struct {
+	Mapping map[string]string
+}
+
+func Decode(format Format, r io.Reader, opts DecodeOptions) ([]entity.ImportItem, error) {
+	switch format {
+	case FormatJSONL:
+		return decodeJSONL(r)
+	case FormatTodoTxt:
+		return decodeTodoTxt(r)
+	case FormatCSV:
+		return decodeCSV(r, opts.Mapping)
+	case FormatMarkdown:
+		return decodeMarkdown(r)
+	case FormatICal:
+		return decodeICal(r)
+	}
+	return nil, fmt.Errorf("%w: unknown format %q", domain.ErrInvalidImport, format)
+}
+
+// Encoder пишет задачи по одной. Close дописывает окончание файла (END:VCALENDAR) и сбрасывает буфер,
+// без него вывод неполный. project имя проекта задачи, пустое у задачи вне проекта.
+type Encoder interface {
+	Encode(task entity.Task, project string) error
+	Close() error
+}
+
+// NewEncoder сразу пишет начало файла: заголовок CSV, BEGIN:VCALENDAR.
+func NewEncoder(format Format, w io.Writer) (Encoder, error) {
+	switch format {
+	case FormatJSONL:
+		return newJSONLEncoder(w), nil
+	case FormatTodoTxt:
+		return newLineEncoder(w, formatTodoTxt), nil
+	case FormatCSV:
+		return newCSVEncoder(w)
+	case FormatMarkdown:
+		return newLineEncoder(w, formatMarkdown), nil
+	case FormatICal:
+		return newICalEncoder(w)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// lineEncoder формат, где задача занимает одну строку текста.
+type lineEncoder struct {
+	w      *bufio.Writer
+	format func(b *strings.Builder, task entity.Task, project string)
+	line   strings.Builder
+}
+
+func newLineEncoder(w io.Writer, format func(b *strings.Builder, task entity.Task, project string)) *lineEncoder {
+	return &lineEncoder{w: bufio.NewWriter(w), format: format}
+}
+
+func (e *lineEncoder) Encode(task entity.Task, project string) error {
+	e.line.Reset()
+	e.format(&e.line, task, project)
+	e.line.WriteByte('\n')
+	_, err := e.w.WriteString(e.line.String())
+	return err
+}
+
+func (e *lineEncoder) Close() error {
+	return e.w.Flush()
+}
+
+// word одно слово строчного формата: пробелы заменяются дефисами, как в имени проекта при импорте.
+func word(s string) string {
+	return strings.Join(strings.Fields(s), "-")
+}
+
+// oneLine переводы строк в заголовке разорвали бы запись строчного формата.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// formatDue срок ровно в полночь UTC пишется датой, как его понимает parseDue, остальные RFC 3339.
+func formatDue(due time.Time) string {
+	due = due.UTC()
+	if due.Equal(due.Truncate(24 * time.Hour)) {
+		return due.Format(dateLayout)
+	}
+	return due.Format(time.RFC3339)
+}
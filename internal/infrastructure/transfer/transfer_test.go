@@ -0,0 +1,357 @@
+package transfer
+
+import (
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/domain/service"
+	"ecom_test/pkg/ical"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// row краткое описание элемента импорта для сравнения в таблицах.
+type row struct {
+	line     int
+	title    string
+	done     bool
+	status   entity.TaskStatus
+	priority entity.Priority
+	tags     string
+	due      string
+	project  string
+	err      string
+}
+
+func describe(items []entity.ImportItem) []row {
+	out := make([]row, 0, len(items))
+	for _, it := range items {
+		r := row{
+			line:     it.Line,
+			title:    it.Task.Title,
+			done:     it.Task.IsCompleted,
+			status:   it.Task.Status,
+			priority: it.Task.Priority,
+			tags:     strings.Join(it.Task.Tags, ","),
+			project:  it.Project,
+		}
+		if it.Task.DueAt != nil {
+			r.due = it.Task.DueAt.Format(time.RFC3339)
+		}
+		if it.Err != nil {
+			r.err = it.Err.Error()
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func equalRows(a, b []row) bool {
+	return slices.EqualFunc(a, b, func(x, y row) bool {
+		if y.err != "" && strings.Contains(x.err, y.err) {
+			x.err = y.err
+		}
+		return x == y
+	})
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		src     string
+		mapping map[string]string
+		want    []row
+	}{
+		{
+			name:   "todo.txt",
+			format: FormatTodoTxt,
+			src: "(A) 2026-10-01 Call Mom +Family @phone due:2026-10-25\n" +
+				"\n" +
+				"x 2026-10-19 2026-10-01 Pay rent +Home pri:B\n" +
+				"Meet at 10:30 +Work +Other\n" +
+				"Broken due:someday\n",
+			want: []row{
+				{line: 1, title: "Call Mom", priority: entity.PriorityHigh, tags: "phone", project: "Family", due: "2026-10-25T00:00:00Z"},
+				{line: 3, title: "Pay rent", done: true, priority: entity.PriorityMedium, project: "Home"},
+				{line: 4, title: "Meet at 10:30 +Other", project: "Work"},
+				{line: 5, err: "invalid due date"},
+			},
+		},
+		{
+			name:   "CSV",
+			format: FormatCSV,
+			src: "\ufeffName,Notes,Done,Priority,Labels,Deadline,Project,Ignored\n" +
+				"Buy milk,\"2%, not skim\",no,high,\"home, shop\",2026-10-25T15:00:00+03:00,Home,x\n" +
+				",,,,,,,\n" +
+				"Pay rent,,yes,,,,,\n" +
+				"Bad,,maybe,,,,,\n",
+			want: []row{
+				{line: 2, title: "Buy milk", priority: entity.PriorityHigh, tags: "home,shop", due: "2026-10-25T12:00:00Z", project: "Home"},
+				{line: 4, title: "Pay rent", done: true},
+				{line: 5, title: "Bad", err: "expected true or false"},
+			},
+		},
+		{
+			name:    "CSV with mapping",
+			format:  FormatCSV,
+			src:     "Задача;Состояние\nОтчёт;in_progress\n",
+			mapping: map[string]string{"Состояние": "status"},
+			want:    []row{{line: 2, title: "Отчёт", status: entity.StatusInProgress}},
+		},
+		{
+			name:    "CSV mapping overrides aliases",
+			format:  FormatCSV,
+			src:     "Task,Name,State\nWrite,Alice,review\n",
+			mapping: map[string]string{"name": "description", "state": "status"},
+			want:    []row{{line: 2, title: "Write", status: entity.StatusReview}},
+		},
+		{
+			name:   "Markdown",
+			format: FormatMarkdown,
+			src: "# Release\n" +
+				"Some prose\n" +
+				"- [ ] Write notes #release !high due:2026-10-25 @web\n" +
+				"  * [x] Tag build\n" +
+				"1. [X] Numbered\n" +
+				"- [?] Unknown\n" +
+				"- plain bullet\n",
+			want: []row{
+				{line: 3, title: "Write notes", tags: "release", priority: entity.PriorityHigh, due: "2026-10-25T00:00:00Z", project: "web"},
+				{line: 4, title: "Tag build", done: true},
+				{line: 5, title: "Numbered", done: true},
+				{line: 6, err: "unknown checkbox"},
+			},
+		},
+		{
+			name:   "iCalendar",
+			format: FormatICal,
+			src: "BEGIN:VCALENDAR\r\n" +
+				"BEGIN:VTODO\r\nSUMMARY:Ship\\, then rest\r\nPRIORITY:1\r\nCATEGORIES:work,release\r\nDUE:20261025T150000Z\r\nSTATUS:IN-PROCESS\r\nEND:VTODO\r\n" +
+				"BEGIN:VEVENT\r\nSUMMARY:Meeting\r\nEND:VEVENT\r\n" +
+				"BEGIN:VTODO\r\nSUMMARY:Done\r\nCOMPLETED:20261019T100000Z\r\nPRIORITY:5\r\nEND:VTODO\r\n" +
+				"BEGIN:VTODO\r\nSUMMARY:Bad\r\nPRIORITY:11\r\nEND:VTODO\r\n" +
+				"END:VCALENDAR\r\n",
+			want: []row{
+				{line: 2, title: "Ship, then rest", status: entity.StatusInProgress, priority: entity.PriorityHigh, tags: "work,release", due: "2026-10-25T15:00:00Z"},
+				{line: 12, title: "Done", status: entity.StatusDone, priority: entity.PriorityMedium},
+				{line: 17, title: "Bad", status: entity.StatusTodo, err: "PRIORITY must be 0-9"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := Decode(tt.format, strings.NewReader(tt.src), DecodeOptions{Mapping: tt.mapping})
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if got := describe(items); !equalRows(got, tt.want) {
+				t.Errorf("Decode() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecode_FileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		src     string
+		mapping map[string]string
+		want    string
+	}{
+		{name: "CSV without title", format: FormatCSV, src: "foo,bar\n1,2\n", want: "no title column"},
+		{name: "CSV unknown field", format: FormatCSV, src: "a\n", mapping: map[string]string{"a": "owner"}, want: "unknown field"},
+		{name: "Broken calendar", format: FormatICal, src: "BEGIN:VCALENDAR\nBEGIN:VTODO\n", want: "missing END:VTODO"},
+		{name: "Not a calendar", format: FormatICal, src: "BEGIN:VTODO\nEND:VTODO\n", want: "expected VCALENDAR"},
+		{name: "Unknown format", format: "xlsx", want: "unknown format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(tt.format, strings.NewReader(tt.src), DecodeOptions{Mapping: tt.mapping})
+			if !errors.Is(err, domain.ErrInvalidImport) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Decode() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncoder_RoundTrip(t *testing.T) {
+	due := time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)
+	at := time.Date(2026, 10, 25, 15, 30, 0, 0, time.UTC)
+	long := "Длинная задача" + strings.Repeat(" с текстом", 10)
+	tasks := []struct {
+		task    entity.Task
+		project string
+	}{
+		{task: entity.Task{ID: 1, Title: "Buy milk, \"2%\"", Description: "line one\nline two; more", Status: entity.StatusTodo,
+			Priority: entity.PriorityHigh, Tags: []string{"home", "shop"}, DueAt: &due}, project: "Home Office"},
+		{task: entity.Task{ID: 2, Title: "Review\nthe PR", Status: entity.StatusReview, Priority: entity.PriorityLow, DueAt: &at}},
+		{task: entity.Task{ID: 3, Title: "Pay rent", IsCompleted: true, Status: entity.StatusDone, Priority: entity.PriorityMedium,
+			StatusChangedAt: at}, project: "Home"},
+		{task: entity.Task{ID: 4, Title: long, Status: entity.StatusInProgress}},
+	}
+	full := []row{
+		{title: "Buy milk, \"2%\"", status: entity.StatusTodo, priority: entity.PriorityHigh, tags: "home,shop", due: "2026-10-25T00:00:00Z", project: "Home Office"},
+		{title: "Review\nthe PR", status: entity.StatusReview, priority: entity.PriorityLow, due: "2026-10-25T15:30:00Z"},
+		{title: "Pay rent", done: true, status: entity.StatusDone, priority: entity.PriorityMedium, project: "Home"},
+		{title: long, status: entity.StatusInProgress},
+	}
+	// VTODO передаёт выполнение только статусом
+	calendar := slices.Clone(full)
+	calendar[2].done = false
+	// строчные форматы не хранят статус кроме отметки о выполнении, переводы строк и пробелы в именах
+	lines := []row{
+		{title: "Buy milk, \"2%\"", priority: entity.PriorityHigh, tags: "home,shop", due: "2026-10-25T00:00:00Z", project: "Home-Office"},
+		{title: "Review the PR", priority: entity.PriorityLow, due: "2026-10-25T15:30:00Z"},
+		{title: "Pay rent", done: true, priority: entity.PriorityMedium, project: "Home"},
+		{title: long},
+	}
+	tests := []struct {
+		format Format
+		want   []row
+	}{
+		{format: FormatJSONL, want: full},
+		{format: FormatCSV, want: full},
+		{format: FormatICal, want: calendar},
+		{format: FormatTodoTxt, want: lines},
+		{format: FormatMarkdown, want: lines},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var b strings.Builder
+			enc, err := NewEncoder(tt.format, &b)
+			if err != nil {
+				t.Fatalf("NewEncoder() error = %v", err)
+			}
+			for _, it := range tasks {
+				if err := enc.Encode(it.task, it.project); err != nil {
+					t.Fatalf("Encode() error = %v", err)
+				}
+			}
+			if err := enc.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			items, err := Decode(tt.format, strings.NewReader(b.String()), DecodeOptions{})
+			if err != nil {
+				t.Fatalf("Decode() error = %v\n%s", err, b.String())
+			}
+			got := describe(items)
+			for i := range got {
+				got[i].line = 0
+			}
+			if !equalRows(got, tt.want) {
+				t.Errorf("round trip =\n%+v\nwant\n%+v\noutput:\n%s", got, tt.want, b.String())
+			}
+		})
+	}
+}
+
+func TestEncodeFeed(t *testing.T) {
+	date := time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)
+	at := time.Date(2026, 10, 25, 15, 30, 0, 0, time.UTC)
+	cal := &service.FeedCalendar{
+		Feed: entity.Feed{ModifiedAt: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+		Items: []service.FeedItem{
+			{Task: entity.Task{ID: 7, Title: "Отчёт; черновик, " + strings.Repeat("длинный ", 8), DueAt: &date}, Project: "Work"},
+			{Task: entity.Task{ID: 9, Title: "Call", Status: entity.StatusDone, DueAt: &at, StatusChangedAt: at}},
+		},
+	}
+	tests := []struct {
+		name string
+		opts FeedOptions
+		want []string
+	}{
+		{name: "both", opts: FeedOptions{Name: "Tasks", Todos: true, Events: true}, want: []string{"VTODO:task-7@todo-api", "VEVENT:task-7-due@todo-api", "VTODO:task-9@todo-api"}},
+		{name: "events", opts: FeedOptions{Events: true}, want: []string{"VEVENT:task-7-due@todo-api"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var first, second strings.Builder
+			if err := EncodeFeed(&first, cal, tt.opts); err != nil {
+				t.Fatalf("EncodeFeed() error = %v", err)
+			}
+			_ = EncodeFeed(&second, cal, tt.opts)
+			if first.String() != second.String() {
+				t.Errorf("output differs between calls for the same feed")
+			}
+			for _, line := range strings.Split(first.String(), "\r\n") {
+				if len(line) > 75 {
+					t.Errorf("line is not folded: %q", line)
+				}
+			}
+
+			root, err := ical.Decode(strings.NewReader(first.String()))
+			if err != nil {
+				t.Fatalf("Decode() error = %v\n%s", err, first.String())
+			}
+			var got []string
+			for _, c := range root.Components {
+				got = append(got, c.Name+":"+c.Text("UID"))
+				if c.Text("SUMMARY") != cal.Items[0].Task.Title && c.Text("SUMMARY") != "Call" {
+					t.Errorf("SUMMARY = %q", c.Text("SUMMARY"))
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("components = %v, want %v", got, tt.want)
+			}
+			if event := root.Children("VEVENT"); len(event) > 0 {
+				start, _ := event[0].Get("DTSTART")
+				if at, allDay, _ := start.Time(); !allDay || !at.Equal(date) {
+					t.Errorf("DTSTART = %v all day %v, want %v all day", at, allDay, date)
+				}
+			}
+		})
+	}
+}
+
+func TestTaskFromTodo(t *testing.T) {
+	due := time.Date(2026, 10, 25, 15, 30, 0, 0, time.UTC)
+	task := entity.Task{ID: 3, UID: "A1B2@client", Title: "Купить молоко", Status: entity.StatusReview, Priority: entity.PriorityHigh, Tags: []string{"home"}, DueAt: &due}
+
+	got, err := TaskFromTodo(TodoCalendar(task, "Home"))
+	if err != nil {
+		t.Fatalf("TaskFromTodo() error = %v", err)
+	}
+	if got.UID != task.UID || got.Title != task.Title || got.Status != task.Status || got.Priority != task.Priority ||
+		!slices.Equal(got.Tags, task.Tags) || got.DueAt == nil || !got.DueAt.Equal(due) {
+		t.Errorf("TaskFromTodo() = %+v, want %+v", got, task)
+	}
+
+	edited := TodoCalendar(task, "")
+	edited.Components[0].Props = append(edited.Components[0].Props,
+		ical.Property{Name: "STATUS", Value: "COMPLETED"}, ical.Property{Name: "COMPLETED", Value: "20261019T090000Z"})
+	edited.Components[0].Props = slices.DeleteFunc(edited.Components[0].Props, func(p ical.Property) bool {
+		return p.Name == "STATUS" && p.Value == "IN-PROCESS"
+	})
+	if got, _ := TaskFromTodo(edited); got.Status != entity.StatusDone {
+		t.Errorf("status after client completed the task = %q, want done", got.Status)
+	}
+
+	without := TodoCalendar(entity.Task{ID: 3}, "")
+	if uid := without.Components[0].Text("UID"); uid != "task-3@todo-api" {
+		t.Errorf("UID without client UID = %q", uid)
+	}
+
+	tests := []struct {
+		name string
+		cal  *ical.Component
+	}{
+		{name: "not a calendar", cal: &ical.Component{Name: "VTODO"}},
+		{name: "no todo", cal: &ical.Component{Name: "VCALENDAR", Components: []*ical.Component{{Name: "VEVENT"}}}},
+		{name: "two todos", cal: &ical.Component{Name: "VCALENDAR", Components: []*ical.Component{{Name: "VTODO"}, {Name: "VTODO"}}}},
+		{name: "bad priority", cal: &ical.Component{Name: "VCALENDAR", Components: []*ical.Component{
+			{Name: "VTODO", Props: []ical.Property{{Name: "PRIORITY", Value: "high"}}},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := TaskFromTodo(tt.cal); !errors.Is(err, domain.ErrInvalidImport) {
+				t.Errorf("TaskFromTodo() error = %v, want ErrInvalidImport", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"ecom_test/internal/config"
+	"ecom_test/pkg/contextx"
+	"ecom_test/pkg/middlewarex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// authenticate без аутентификации все запросы анонимные; с мультиарендностью анонимный субъект
+// привязан к арендатору по умолчанию, как и любой другой, чтобы не выбирать арендатора заголовком.
+func authenticate(cfg config.Config, o options) func(http.Handler) http.Handler {
+	if !cfg.Auth.Enabled || (o.keys == nil && o.jwt == nil) {
+		anonymous := &contextx.Principal{
+			Subject: "anonymous",
+			Scopes:  []string{contextx.ScopeAll},
+			Method:  "anonymous",
+		}
+		if cfg.Tenancy.Enabled {
+			anonymous.Tenant = cfg.Tenancy.DefaultTenant
+		}
+		return middlewarex.Authenticate(nil, anonymous)
+	}
+
+	// nil-указатели нельзя класть в интерфейс как есть, иначе проверка на nil не сработает
+	var bearer bearerAuthenticator
+	if o.keys != nil {
+		bearer.keys = o.keys
+	}
+	if o.jwt != nil {
+		bearer.jwt = o.jwt
+	}
+	// CalDAV-клиенты умеют только Basic, остальному API вызов Basic не нужен
+	return middlewarex.Authenticate(bearer, nil, davPrefix+"/")
+}
+
+var (
+	errJWTDisabled     = errors.New("jwt authentication is disabled")
+	errAPIKeysDisabled = errors.New("api key authentication is disabled")
+)
+
+// bearerAuthenticator выбирает способ проверки по виду токена:
+// JWT всегда состоит из трёх сегментов через точку, в API-ключе точек нет.
+type bearerAuthenticator struct {
+	keys middlewarex.Authenticator
+	jwt  middlewarex.Authenticator
+}
+
+func (a bearerAuthenticator) Authenticate(ctx context.Context, token string) (contextx.Principal, error) {
+	if strings.Count(token, ".") == 2 {
+		if a.jwt == nil {
+			return contextx.Principal{}, errJWTDisabled
+		}
+		return a.jwt.Authenticate(ctx, token)
+	}
+	if a.keys == nil {
+		return contextx.Principal{}, errAPIKeysDisabled
+	}
+	return a.keys.Authenticate(ctx, token)
+}
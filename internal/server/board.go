@@ -0,0 +1,235 @@
+package server
+
+import (
+	"context"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/domain/service"
+	"ecom_test/internal/server/dto"
+	"ecom_test/pkg/middlewarex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+type BoardService interface {
+	Create(ctx context.Context, board *entity.Board) (int, error)
+	Get(ctx context.Context, id int) (*entity.Board, error)
+	List(ctx context.Context) ([]entity.Board, error)
+	Update(ctx context.Context, board *entity.Board) error
+	Delete(ctx context.Context, id int) error
+	View(ctx context.Context, id int) (*service.BoardView, error)
+	Stats(ctx context.Context, id int) (*service.BoardStats, error)
+	MoveCard(ctx context.Context, id, taskID int, column string) (*entity.Task, error)
+}
+
+type BoardHandler struct {
+	responder
+	service BoardService
+}
+
+func NewBoardHandler(service BoardService) *BoardHandler {
+	return &BoardHandler{
+		service: service,
+	}
+}
+
+func (h *BoardHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.BoardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, r, http.StatusBadRequest, "invalid_body")
+		return
+	}
+
+	board := toBoard(req)
+	if _, err := h.service.Create(r.Context(), board); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusCreated, toBoardResponse(*board))
+}
+
+func (h *BoardHandler) List(w http.ResponseWriter, r *http.Request) {
+	boards, err := h.service.List(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	resp := dto.ListBoardsResponse{Boards: make([]dto.BoardResponse, 0, len(boards))}
+	for _, b := range boards {
+		resp.Boards = append(resp.Boards, toBoardResponse(b))
+	}
+	h.send(w, r, http.StatusOK, resp)
+}
+
+// Get отдаёт доску с карточками по колонкам, внутри колонки в ручном порядке.
+func (h *BoardHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	view, err := h.service.View(r.Context(), id)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	resp := dto.BoardViewResponse{
+		ID:        view.Board.ID,
+		Name:      view.Board.Name,
+		ProjectID: view.Board.ProjectID,
+		Columns:   make([]dto.ColumnViewResponse, 0, len(view.Columns)),
+	}
+	for _, c := range view.Columns {
+		resp.Columns = append(resp.Columns, dto.ColumnViewResponse{
+			Name:     c.Column.Name,
+			Status:   string(c.Column.Status),
+			WIPLimit: c.Column.WIPLimit,
+			Tasks:    toTaskList(c.Tasks).Tasks,
+		})
+	}
+	h.send(w, r, http.StatusOK, resp)
+}
+
+func (h *BoardHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+
+	var req dto.BoardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, r, http.StatusBadRequest, "invalid_body")
+		return
+	}
+
+	board := toBoard(req)
+	board.ID = id
+	if err := h.service.Update(r.Context(), board); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, toBoardResponse(*board))
+}
+
+func (h *BoardHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, dto.DeleteTaskResponse{Status: "success"})
+}
+
+func (h *BoardHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	stats, err := h.service.Stats(r.Context(), id)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	resp := dto.BoardStatsResponse{
+		ID:      stats.Board.ID,
+		Total:   stats.Total,
+		Columns: make([]dto.ColumnStatsResponse, 0, len(stats.Columns)),
+	}
+	for _, c := range stats.Columns {
+		resp.Columns = append(resp.Columns, dto.ColumnStatsResponse{
+			Name:       c.Column.Name,
+			Status:     string(c.Column.Status),
+			Count:      c.Count,
+			WIPLimit:   c.Column.WIPLimit,
+			OverLimit:  c.OverLimit,
+			AvgAgeSecs: c.AvgAge.Seconds(),
+			MaxAgeSecs: c.MaxAge.Seconds(),
+		})
+	}
+	h.send(w, r, http.StatusOK, resp)
+}
+
+func (h *BoardHandler) MoveCard(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	taskID, _ := strconv.Atoi(r.PathValue("task"))
+
+	var req dto.MoveCardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, r, http.StatusBadRequest, "invalid_body")
+		return
+	}
+
+	task, err := h.service.MoveCard(r.Context(), id, taskID, req.Column)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, toTaskResponse(task))
+}
+
+func (h *BoardHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/boards", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			middlewarex.RequireScope(ScopeTasksRead, h.List)(w, r)
+		case http.MethodPost:
+			middlewarex.RequireScope(ScopeTasksWrite, h.Create)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/boards/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			middlewarex.RequireScope(ScopeTasksRead, h.Get)(w, r)
+		case http.MethodPut:
+			middlewarex.RequireScope(ScopeTasksWrite, h.Update)(w, r)
+		case http.MethodDelete:
+			middlewarex.RequireScope(ScopeTasksWrite, h.Delete)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/boards/{id}/stats", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			middlewarex.RequireScope(ScopeTasksRead, h.Stats)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/boards/{id}/cards/{task}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			middlewarex.RequireScope(ScopeTasksWrite, h.MoveCard)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func toBoard(req dto.BoardRequest) *entity.Board {
+	board := &entity.Board{Name: req.Name, ProjectID: req.ProjectID}
+	for _, c := range req.Columns {
+		board.Columns = append(board.Columns, entity.Column{
+			Name:     c.Name,
+			Status:   entity.TaskStatus(c.Status),
+			WIPLimit: c.WIPLimit,
+		})
+	}
+	return board
+}
+
+func toBoardResponse(b entity.Board) dto.BoardResponse {
+	resp := dto.BoardResponse{
+		ID:        b.ID,
+		Name:      b.Name,
+		ProjectID: b.ProjectID,
+		Columns:   make([]dto.ColumnRequest, 0, len(b.Columns)),
+		CreatedAt: b.CreatedAt,
+	}
+	for _, c := range b.Columns {
+		resp.Columns = append(resp.Columns, dto.ColumnRequest{
+			Name:     c.Name,
+			Status:   string(c.Status),
+			WIPLimit: c.WIPLimit,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,245 @@
+package server
+
+import (
+	"context"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/domain/service"
+	"ecom_test/internal/infrastructure/transfer"
+	"ecom_test/pkg/caldav"
+	"ecom_test/pkg/ical"
+	"ecom_test/pkg/middlewarex"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	davPrefix = "/dav"
+	davInbox  = "inbox"
+)
+
+// CalDAVHandler задачи как календари CalDAV для клиентов вроде Apple Reminders, Thunderbird и
+// DAVx5. Авторизация та же, что у API: клиенты передают ключ паролем в Basic.
+type CalDAVHandler struct {
+	dav *caldav.Handler
+}
+
+func NewCalDAVHandler(tasks TaskService, projects ProjectService) *CalDAVHandler {
+	return &CalDAVHandler{
+		dav: caldav.NewHandler(davBackend{tasks: tasks, projects: projects}, davPrefix),
+	}
+}
+
+func (h *CalDAVHandler) RegisterRoutes(mux *http.ServeMux) {
+	// RFC 6764: клиенту достаточно адреса сервера, путь к CalDAV он находит сам
+	mux.HandleFunc("/.well-known/caldav", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, davPrefix+"/", http.StatusMovedPermanently)
+	})
+
+	mux.HandleFunc(davPrefix+"/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut, http.MethodDelete:
+			middlewarex.RequireScope(ScopeTasksWrite, h.dav.ServeHTTP)(w, r)
+		default:
+			middlewarex.RequireScope(ScopeTasksRead, h.dav.ServeHTTP)(w, r)
+		}
+	})
+}
+
+// davBackend календарь inbox для задач вне проектов и project-<id> для каждого неархивного проекта.
+// Объект называется так, как его назвал создавший задачу клиент, иначе task-<id>.ics.
+type davBackend struct {
+	tasks    TaskService
+	projects ProjectService
+}
+
+func (b davBackend) Calendars(ctx context.Context) ([]caldav.Calendar, error) {
+	calendars := []caldav.Calendar{{Name: davInbox, DisplayName: "Inbox"}}
+	if b.projects == nil {
+		return calendars, nil
+	}
+	projects, err := b.projects.List(ctx, false)
+	if err != nil {
+		return nil, davError(err)
+	}
+	for _, p := range projects {
+		calendars = append(calendars, caldav.Calendar{
+			Name:        projectCalendar(p.ID),
+			DisplayName: p.Name,
+			Description: p.Description,
+		})
+	}
+	return calendars, nil
+}
+
+func (b davBackend) Objects(ctx context.Context, calendar string) ([]caldav.Object, error) {
+	projectID, project, err := b.calendar(ctx, calendar)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := b.calendarTasks(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]caldav.Object, 0, len(tasks))
+	for _, task := range tasks {
+		objects = append(objects, caldav.Object{Name: resourceName(task), Data: transfer.TodoCalendar(task, project)})
+	}
+	return objects, nil
+}
+
+// Put изменяет только поля, которые есть в VTODO. Статус меняется той же записью, что и поля, если
+// в него можно попасть допустимыми переходами, с проверкой WIP-лимита, как на доске. cond сверяется
+// с текущей задачей под локом записи.
+func (b davBackend) Put(ctx context.Context, calendar, name string, data *ical.Component, cond caldav.Conditions) (bool, error) {
+	projectID, project, err := b.calendar(ctx, calendar)
+	if err != nil {
+		return false, err
+	}
+	todo, err := transfer.TaskFromTodo(data)
+	if err != nil {
+		return false, davError(err)
+	}
+	existing, err := b.find(ctx, projectID, name)
+	if err != nil {
+		return false, err
+	}
+
+	if existing != nil {
+		task := *existing
+		task.Title = todo.Title
+		task.Description = todo.Description
+		task.Priority = todo.Priority
+		task.Tags = todo.Tags
+		task.DueAt = todo.DueAt
+		return false, davError(b.tasks.ReplaceIf(ctx, &task, todo.Status, matches(cond, project)))
+	}
+
+	if !cond.Met(nil) {
+		return false, caldav.ErrPreconditionFailed
+	}
+	task := &entity.Task{
+		Title:       todo.Title,
+		Description: todo.Description,
+		ProjectID:   projectID,
+		Priority:    todo.Priority,
+		Tags:        todo.Tags,
+		DueAt:       todo.DueAt,
+		UID:         todo.UID,
+		Resource:    name,
+	}
+	if _, err := b.tasks.CreateInStatus(ctx, task, todo.Status); err != nil {
+		return false, davError(err)
+	}
+	return true, nil
+}
+
+func (b davBackend) Delete(ctx context.Context, calendar, name string, cond caldav.Conditions) error {
+	projectID, project, err := b.calendar(ctx, calendar)
+	if err != nil {
+		return err
+	}
+	task, err := b.find(ctx, projectID, name)
+	if err != nil {
+		return err
+	}
+	if task == nil {
+		return caldav.ErrNotFound
+	}
+	return davError(b.tasks.DeleteIf(ctx, task.ID, matches(cond, project)))
+}
+
+// matches условия запроса по объекту, в который превращается сохранённая задача.
+func matches(cond caldav.Conditions, project string) service.Precondition {
+	return func(current entity.Task) error {
+		if !cond.Met(transfer.TodoCalendar(current, project)) {
+			return domain.ErrPreconditionFailed
+		}
+		return nil
+	}
+}
+
+// calendar проект календаря и его имя, у inbox nil и пустое имя.
+func (b davBackend) calendar(ctx context.Context, calendar string) (*int, string, error) {
+	if calendar == davInbox {
+		return nil, "", nil
+	}
+	calendars, err := b.Calendars(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, c := range calendars {
+		if c.Name != calendar {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(calendar, "project-"))
+		if err != nil {
+			break
+		}
+		return &id, c.DisplayName, nil
+	}
+	return nil, "", caldav.ErrNotFound
+}
+
+func (b davBackend) calendarTasks(ctx context.Context, projectID *int) ([]entity.Task, error) {
+	tasks, err := b.tasks.List(ctx, service.ListOptions{})
+	if err != nil {
+		return nil, davError(err)
+	}
+	return slices.DeleteFunc(tasks, func(t entity.Task) bool {
+		if projectID == nil || t.ProjectID == nil {
+			return projectID != t.ProjectID
+		}
+		return *projectID != *t.ProjectID
+	}), nil
+}
+
+// find задача календаря по имени объекта, nil если такой нет.
+func (b davBackend) find(ctx context.Context, projectID *int, name string) (*entity.Task, error) {
+	tasks, err := b.calendarTasks(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		if resourceName(tasks[i]) == name {
+			return &tasks[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func projectCalendar(id int) string {
+	return "project-" + strconv.Itoa(id)
+}
+
+func resourceName(task entity.Task) string {
+	if task.Resource != "" {
+		return task.Resource
+	}
+	return fmt.Sprintf("task-%d.ics", task.ID)
+}
+
+// davError ошибки домена в ошибки caldav, по которым выбирается статус ответа.
+func davError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, domain.ErrTaskNotFound), errors.Is(err, domain.ErrProjectNotFound):
+		return fmt.Errorf("%w: %w", caldav.ErrNotFound, err)
+	case errors.Is(err, domain.ErrEmptyTitle), errors.Is(err, domain.ErrInvalidImport),
+		errors.Is(err, domain.ErrInvalidPriority), errors.Is(err, domain.ErrInvalidStatus):
+		return fmt.Errorf("%w: %w", caldav.ErrInvalidData, err)
+	case errors.Is(err, domain.ErrForbidden):
+		return fmt.Errorf("%w: %w", caldav.ErrForbidden, err)
+	case errors.Is(err, domain.ErrPreconditionFailed):
+		return fmt.Errorf("%w: %w", caldav.ErrPreconditionFailed, err)
+	case errors.Is(err, domain.ErrInvalidTransition), errors.Is(err, domain.ErrWIPLimitExceeded),
+		errors.Is(err, domain.ErrProjectArchived), errors.Is(err, domain.ErrQuotaExceeded):
+		return fmt.Errorf("%w: %w", caldav.ErrConflict, err)
+	}
+	return err
+}
@@ -1,44 +1,394 @@
 package dto
 
+import (
+	"encoding/json"
+	"encoding/xml"
+	"sort"
+	"time"
+)
+
 type CreateTaskRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	ProjectID   *int       `json:"project_id,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	Priority    string     `json:"priority,omitempty"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
 }
 
 type CreateTaskResponse struct {
-	ID int `json:"id"`
+	ID int `json:"id" xml:"id" csv:"id"`
 }
 
 type TaskListItemResponse struct {
-	ID          int    `json:"id"`
-	Title       string `json:"title"`
-	IsCompleted bool   `json:"is_completed"`
+	ID          int    `json:"id" xml:"id" csv:"id"`
+	Title       string `json:"title" xml:"title" csv:"title"`
+	IsCompleted bool   `json:"is_completed" xml:"is_completed" csv:"is_completed"`
+	ProjectID   *int   `json:"project_id,omitempty" xml:"project_id,omitempty" csv:"project_id"`
+	Rank        string `json:"rank" xml:"rank" csv:"rank"`
+	Status      string `json:"status" xml:"status" csv:"status"`
+	// Tags всегда массив, пустой у задачи без меток.
+	Tags     []string   `json:"tags" xml:"tags>tag" csv:"tags"`
+	Priority string     `json:"priority,omitempty" xml:"priority,omitempty" csv:"priority"`
+	DueAt    *time.Time `json:"due_at,omitempty" xml:"due_at,omitempty" csv:"due_at"`
 }
 
 type GetAllTasksResponse struct {
-	Tasks []TaskListItemResponse `json:"tasks"`
+	Tasks []TaskListItemResponse `json:"tasks" xml:"tasks>task" csv:",rows"`
 }
 
 type GetTaskResponse struct {
-	ID          int    `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	IsCompleted bool   `json:"is_completed"`
+	ID              int        `json:"id" xml:"id" csv:"id"`
+	Title           string     `json:"title" xml:"title" csv:"title"`
+	Description     string     `json:"description" xml:"description" csv:"description"`
+	IsCompleted     bool       `json:"is_completed" xml:"is_completed" csv:"is_completed"`
+	OwnerID         string     `json:"owner_id" xml:"owner_id" csv:"owner_id"`
+	ProjectID       *int       `json:"project_id,omitempty" xml:"project_id,omitempty" csv:"project_id"`
+	Rank            string     `json:"rank" xml:"rank" csv:"rank"`
+	Status          string     `json:"status" xml:"status" csv:"status"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty" xml:"status_changed_at,omitempty" csv:"status_changed_at"`
+	Tags            []string   `json:"tags" xml:"tags>tag" csv:"tags"`
+	Priority        string     `json:"priority,omitempty" xml:"priority,omitempty" csv:"priority"`
+	DueAt           *time.Time `json:"due_at,omitempty" xml:"due_at,omitempty" csv:"due_at"`
 }
 
 type UpdateTaskRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	IsCompleted bool   `json:"is_completed"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	IsCompleted bool       `json:"is_completed"`
+	Tags        []string   `json:"tags,omitempty"`
+	Priority    string     `json:"priority,omitempty"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
 }
 
 type UpdateTaskResponse struct {
-	ID          int    `json:"id"`
-	Title       string `json:"title"`
+	ID              int        `json:"id" xml:"id" csv:"id"`
+	Title           string     `json:"title" xml:"title" csv:"title"`
+	Description     string     `json:"description" xml:"description" csv:"description"`
+	IsCompleted     bool       `json:"is_completed" xml:"is_completed" csv:"is_completed"`
+	OwnerID         string     `json:"owner_id" xml:"owner_id" csv:"owner_id"`
+	ProjectID       *int       `json:"project_id,omitempty" xml:"project_id,omitempty" csv:"project_id"`
+	Rank            string     `json:"rank" xml:"rank" csv:"rank"`
+	Status          string     `json:"status" xml:"status" csv:"status"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty" xml:"status_changed_at,omitempty" csv:"status_changed_at"`
+	Tags            []string   `json:"tags" xml:"tags>tag" csv:"tags"`
+	Priority        string     `json:"priority,omitempty" xml:"priority,omitempty" csv:"priority"`
+	DueAt           *time.Time `json:"due_at,omitempty" xml:"due_at,omitempty" csv:"due_at"`
+}
+
+type ShareRequest struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+type ShareResponse struct {
+	UserID    string    `json:"user_id" xml:"user_id" csv:"user_id"`
+	Role      string    `json:"role" xml:"role" csv:"role"`
+	GrantedBy string    `json:"granted_by,omitempty" xml:"granted_by,omitempty" csv:"granted_by"`
+	CreatedAt time.Time `json:"created_at" xml:"created_at" csv:"created_at"`
+}
+
+type ListSharesResponse struct {
+	Shares []ShareResponse `json:"shares" xml:"shares>share" csv:",rows"`
+}
+
+type MoveToProjectRequest struct {
+	// ProjectID null убирает задачу из проекта.
+	ProjectID *int `json:"project_id"`
+}
+
+// MoveTaskRequest задача встаёт сразу после After и/или сразу перед Before.
+type MoveTaskRequest struct {
+	Before *int `json:"before,omitempty"`
+	After  *int `json:"after,omitempty"`
+}
+
+type SearchHitResponse struct {
+	ID             int     `json:"id" xml:"id" csv:"id"`
+	Title          string  `json:"title" xml:"title" csv:"title"`
+	IsCompleted    bool    `json:"is_completed" xml:"is_completed" csv:"is_completed"`
+	Score          float64 `json:"score" xml:"score" csv:"score"`
+	TitleHighlight string  `json:"title_highlight" xml:"title_highlight" csv:"title_highlight"`
+	Snippet        string  `json:"snippet,omitempty" xml:"snippet,omitempty" csv:"snippet"`
+}
+
+type SearchResponse struct {
+	Query   string              `json:"query" xml:"query"`
+	Results []SearchHitResponse `json:"results" xml:"results>result" csv:",rows"`
+}
+
+// QuickAddRequest Timezone имя зоны IANA, например Europe/Moscow.
+type QuickAddRequest struct {
+	Text     string `json:"text"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// QuickAddToken Start и End номера символов в text от нуля, End не включается.
+type QuickAddToken struct {
+	Kind  string `json:"kind" xml:"kind"`
+	Text  string `json:"text" xml:"text"`
+	Value string `json:"value" xml:"value"`
+	Start int    `json:"start" xml:"start"`
+	End   int    `json:"end" xml:"end"`
+}
+
+type QuickAddResponse struct {
+	Task       GetTaskResponse `json:"task" xml:"task"`
+	Recognized []QuickAddToken `json:"recognized" xml:"recognized>token"`
+}
+
+// ImportRowResponse ID есть только у созданной задачи, Error только у отклонённой строки.
+type ImportRowResponse struct {
+	Line      int        `json:"line" xml:"line" csv:"line"`
+	ID        *int       `json:"id,omitempty" xml:"id,omitempty" csv:"id"`
+	Title     string     `json:"title" xml:"title" csv:"title"`
+	Status    string     `json:"status,omitempty" xml:"status,omitempty" csv:"status"`
+	Priority  string     `json:"priority,omitempty" xml:"priority,omitempty" csv:"priority"`
+	Tags      []string   `json:"tags,omitempty" xml:"tags>tag,omitempty" csv:"tags"`
+	DueAt     *time.Time `json:"due_at,omitempty" xml:"due_at,omitempty" csv:"due_at"`
+	ProjectID *int       `json:"project_id,omitempty" xml:"project_id,omitempty" csv:"project_id"`
+	Error     string     `json:"error,omitempty" xml:"error,omitempty" csv:"error"`
+}
+
+type ImportResponse struct {
+	Format  string              `json:"format" xml:"format"`
+	DryRun  bool                `json:"dry_run" xml:"dry_run"`
+	Atomic  bool                `json:"atomic" xml:"atomic"`
+	Total   int                 `json:"total" xml:"total"`
+	Created int                 `json:"created" xml:"created"`
+	Failed  int                 `json:"failed" xml:"failed"`
+	Rows    []ImportRowResponse `json:"rows" xml:"rows>row" csv:",rows"`
+}
+
+type TransitionRequest struct {
+	Status string `json:"status"`
+}
+
+type ProjectRequest struct {
+	Name        string `json:"name"`
 	Description string `json:"description"`
-	IsCompleted bool   `json:"is_completed"`
+}
+
+type ProjectResponse struct {
+	ID          int        `json:"id" xml:"id" csv:"id"`
+	Name        string     `json:"name" xml:"name" csv:"name"`
+	Description string     `json:"description" xml:"description" csv:"description"`
+	Archived    bool       `json:"archived" xml:"archived" csv:"archived"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty" xml:"archived_at,omitempty" csv:"archived_at"`
+	CreatedAt   time.Time  `json:"created_at" xml:"created_at" csv:"created_at"`
+}
+
+type ListProjectsResponse struct {
+	Projects []ProjectResponse `json:"projects" xml:"projects>project" csv:",rows"`
+}
+
+type ColumnRequest struct {
+	Name     string `json:"name" xml:"name"`
+	Status   string `json:"status" xml:"status"`
+	WIPLimit int    `json:"wip_limit" xml:"wip_limit"`
+}
+
+type BoardRequest struct {
+	Name      string          `json:"name"`
+	ProjectID *int            `json:"project_id,omitempty"`
+	Columns   []ColumnRequest `json:"columns"`
+}
+
+type BoardResponse struct {
+	ID        int             `json:"id" xml:"id" csv:"id"`
+	Name      string          `json:"name" xml:"name" csv:"name"`
+	ProjectID *int            `json:"project_id,omitempty" xml:"project_id,omitempty" csv:"project_id"`
+	Columns   []ColumnRequest `json:"columns" xml:"columns>column"`
+	CreatedAt time.Time       `json:"created_at" xml:"created_at" csv:"created_at"`
+}
+
+type ListBoardsResponse struct {
+	Boards []BoardResponse `json:"boards" xml:"boards>board" csv:",rows"`
+}
+
+type ColumnViewResponse struct {
+	Name     string                 `json:"name" xml:"name"`
+	Status   string                 `json:"status" xml:"status"`
+	WIPLimit int                    `json:"wip_limit" xml:"wip_limit"`
+	Tasks    []TaskListItemResponse `json:"tasks" xml:"tasks>task"`
+}
+
+type BoardViewResponse struct {
+	ID        int                  `json:"id" xml:"id"`
+	Name      string               `json:"name" xml:"name"`
+	ProjectID *int                 `json:"project_id,omitempty" xml:"project_id,omitempty"`
+	Columns   []ColumnViewResponse `json:"columns" xml:"columns>column"`
+}
+
+// ColumnStatsResponse возраст карточек в секундах.
+type ColumnStatsResponse struct {
+	Name       string  `json:"name" xml:"name" csv:"name"`
+	Status     string  `json:"status" xml:"status" csv:"status"`
+	Count      int     `json:"count" xml:"count" csv:"count"`
+	WIPLimit   int     `json:"wip_limit" xml:"wip_limit" csv:"wip_limit"`
+	OverLimit  bool    `json:"over_limit" xml:"over_limit" csv:"over_limit"`
+	AvgAgeSecs float64 `json:"avg_age_seconds" xml:"avg_age_seconds" csv:"avg_age_seconds"`
+	MaxAgeSecs float64 `json:"max_age_seconds" xml:"max_age_seconds" csv:"max_age_seconds"`
+}
+
+type BoardStatsResponse struct {
+	ID      int                   `json:"id" xml:"id"`
+	Total   int                   `json:"total" xml:"total"`
+	Columns []ColumnStatsResponse `json:"columns" xml:"columns>column" csv:",rows"`
+}
+
+type MoveCardRequest struct {
+	Column string `json:"column"`
+}
+
+type ViewRequest struct {
+	Name   string   `json:"name"`
+	Query  string   `json:"query"`
+	Sort   string   `json:"sort,omitempty"`
+	Fields []string `json:"fields,omitempty"`
+}
+
+type ViewResponse struct {
+	ID         int       `json:"id" xml:"id" csv:"id"`
+	Name       string    `json:"name" xml:"name" csv:"name"`
+	OwnerID    string    `json:"owner_id" xml:"owner_id" csv:"owner_id"`
+	Query      string    `json:"query" xml:"query" csv:"query"`
+	Sort       string    `json:"sort,omitempty" xml:"sort,omitempty" csv:"sort"`
+	Fields     []string  `json:"fields" xml:"fields>field" csv:"fields"`
+	SharedWith []string  `json:"shared_with" xml:"shared_with>user" csv:"shared_with"`
+	Count      *int      `json:"count,omitempty" xml:"count,omitempty" csv:"count"`
+	CreatedAt  time.Time `json:"created_at" xml:"created_at" csv:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" xml:"updated_at" csv:"updated_at"`
+}
+
+type ListViewsResponse struct {
+	Views []ViewResponse `json:"views" xml:"views>view" csv:",rows"`
+}
+
+// ViewTasksResponse у задач только поля, выбранные в представлении.
+type ViewTasksResponse struct {
+	ViewID int        `json:"view_id" xml:"view_id"`
+	Fields []string   `json:"fields" xml:"fields>field"`
+	Tasks  []ViewTask `json:"tasks" xml:"tasks>task"`
+}
+
+// ViewTask поля задачи в JSON, как их отдаёт представление.
+type ViewTask map[string]json.RawMessage
+
+// MarshalXML поля элементами в порядке имён: строки текстом, массивы элементами item,
+// null пропускается.
+func (t ViewTask) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(t))
+	for k := range t {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var value any
+		if err := json.Unmarshal(t[k], &value); err != nil {
+			return err
+		}
+		if value == nil {
+			continue
+		}
+		el := xml.StartElement{Name: xml.Name{Local: k}}
+		if items, ok := value.([]any); ok {
+			if err := e.EncodeToken(el); err != nil {
+				return err
+			}
+			for _, item := range items {
+				if err := e.EncodeElement(item, xml.StartElement{Name: xml.Name{Local: "item"}}); err != nil {
+					return err
+				}
+			}
+			if err := e.EncodeToken(el.End()); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := e.EncodeElement(value, el); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+type ViewShareRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// ErrorResponse тело ответа с ошибкой, Error код вроде task_not_found или текст ошибки проверки.
+type ErrorResponse struct {
+	Error string `json:"error" xml:"error" csv:"error"`
 }
 
 type DeleteTaskResponse struct {
-	Status string `json:"status"`
+	Status string `json:"status" xml:"status" csv:"status"`
+}
+
+// MintKeyRequest Tenant обязателен при включённой мультиарендности: ключ действует только в нём.
+type MintKeyRequest struct {
+	Name    string   `json:"name"`
+	Subject string   `json:"subject"`
+	Tenant  string   `json:"tenant,omitempty"`
+	Scopes  []string `json:"scopes"`
+}
+
+type APIKeyResponse struct {
+	ID         string     `json:"id" xml:"id" csv:"id"`
+	Name       string     `json:"name" xml:"name" csv:"name"`
+	Subject    string     `json:"subject" xml:"subject" csv:"subject"`
+	Tenant     string     `json:"tenant,omitempty" xml:"tenant,omitempty" csv:"tenant"`
+	Scopes     []string   `json:"scopes" xml:"scopes>scope" csv:"scopes"`
+	CreatedAt  time.Time  `json:"created_at" xml:"created_at" csv:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" xml:"last_used_at,omitempty" csv:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" xml:"revoked_at,omitempty" csv:"revoked_at"`
+}
+
+type MintKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key" xml:"key" csv:"key"`
+}
+
+type ListKeysResponse struct {
+	Keys []APIKeyResponse `json:"keys" xml:"keys>key" csv:",rows"`
+}
+
+type RevokeKeyResponse struct {
+	Status string `json:"status" xml:"status" csv:"status"`
+}
+
+type TenantQuota struct {
+	MaxTasks     int     `json:"max_tasks" xml:"max_tasks" csv:"max_tasks"`
+	RequestRate  float64 `json:"request_rate" xml:"request_rate" csv:"request_rate"`
+	RequestBurst int     `json:"request_burst" xml:"request_burst" csv:"request_burst"`
+}
+
+type CreateTenantRequest struct {
+	ID    string       `json:"id"`
+	Name  string       `json:"name"`
+	Quota *TenantQuota `json:"quota,omitempty"`
+}
+
+type TenantResponse struct {
+	ID        string      `json:"id" xml:"id" csv:"id"`
+	Name      string      `json:"name" xml:"name" csv:"name"`
+	Status    string      `json:"status" xml:"status" csv:"status"`
+	Quota     TenantQuota `json:"quota" xml:"quota" csv:",inline"`
+	CreatedAt time.Time   `json:"created_at" xml:"created_at" csv:"created_at"`
+}
+
+type ListTenantsResponse struct {
+	Tenants []TenantResponse `json:"tenants" xml:"tenants>tenant" csv:",rows"`
+}
+
+// FeedResponse Token и Path только в ответе на выпуск: сохраняется лишь хэш токена.
+type FeedResponse struct {
+	Token      string    `json:"token,omitempty" xml:"token,omitempty" csv:"token"`
+	Path       string    `json:"path,omitempty" xml:"path,omitempty" csv:"path"`
+	CreatedAt  time.Time `json:"created_at" xml:"created_at" csv:"created_at"`
+	ModifiedAt time.Time `json:"modified_at" xml:"modified_at" csv:"modified_at"`
 }
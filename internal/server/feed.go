@@ -0,0 +1,126 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/domain/service"
+	"ecom_test/internal/infrastructure/transfer"
+	"ecom_test/internal/server/dto"
+	"ecom_test/pkg/middlewarex"
+	"net/http"
+	"strings"
+)
+
+type FeedService interface {
+	Rotate(ctx context.Context) (string, *entity.Feed, error)
+	Get(ctx context.Context) (*entity.Feed, error)
+	Revoke(ctx context.Context) error
+	Calendar(ctx context.Context, token string) (*service.FeedCalendar, error)
+}
+
+type FeedHandler struct {
+	responder
+	service FeedService
+}
+
+func NewFeedHandler(service FeedService) *FeedHandler {
+	return &FeedHandler{
+		service: service,
+	}
+}
+
+// Get сведения о ленте вызывающего, саму ссылку показать нельзя: токен хранится только хэшем.
+func (h *FeedHandler) Get(w http.ResponseWriter, r *http.Request) {
+	feed, err := h.service.Get(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, dto.FeedResponse{CreatedAt: feed.CreatedAt, ModifiedAt: feed.ModifiedAt})
+}
+
+// Rotate выпускает ссылку на ленту, прежняя ссылка сразу перестаёт работать.
+func (h *FeedHandler) Rotate(w http.ResponseWriter, r *http.Request) {
+	token, feed, err := h.service.Rotate(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusCreated, dto.FeedResponse{
+		Token:      token,
+		Path:       "/feed/" + token + ".ics",
+		CreatedAt:  feed.CreatedAt,
+		ModifiedAt: feed.ModifiedAt,
+	})
+}
+
+func (h *FeedHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Revoke(r.Context()); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, dto.DeleteTaskResponse{Status: "success"})
+}
+
+// Calendar доступен без авторизации: календарные клиенты не умеют Bearer, доступ даёт токен в пути.
+// type=todo или type=event оставляет один вид компонентов, по умолчанию оба. If-None-Match,
+// If-Modified-Since, HEAD и Range обрабатывает http.ServeContent.
+func (h *FeedHandler) Calendar(w http.ResponseWriter, r *http.Request) {
+	opts := transfer.FeedOptions{Name: "Tasks", Todos: true, Events: true}
+	kind := r.URL.Query().Get("type")
+	switch kind {
+	case "":
+	case "todo":
+		opts.Events = false
+	case "event":
+		opts.Todos = false
+	default:
+		h.sendError(w, r, http.StatusBadRequest, "type must be todo or event")
+		return
+	}
+
+	cal, err := h.service.Calendar(r.Context(), strings.TrimSuffix(r.PathValue("token"), ".ics"))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	var body bytes.Buffer
+	if err := transfer.EncodeFeed(&body, cal, opts); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	etag := cal.Feed.ETag
+	if kind != "" {
+		etag += "-" + kind
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("ETag", `"`+etag+`"`)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	http.ServeContent(w, r, "tasks.ics", cal.Feed.ModifiedAt, bytes.NewReader(body.Bytes()))
+}
+
+func (h *FeedHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			middlewarex.RequireScope(ScopeTasksRead, h.Get)(w, r)
+		case http.MethodPost:
+			middlewarex.RequireScope(ScopeTasksWrite, h.Rotate)(w, r)
+		case http.MethodDelete:
+			middlewarex.RequireScope(ScopeTasksWrite, h.Revoke)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/feed/{token}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			h.Calendar(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
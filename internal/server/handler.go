@@ -3,21 +3,40 @@ package server
 import (
 	"context"
 	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/domain/service"
 	"ecom_test/internal/server/dto"
+	"ecom_test/pkg/middlewarex"
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 type TaskService interface {
 	GetByID(ctx context.Context, id int) (*entity.Task, error)
 	GetAll(ctx context.Context) ([]entity.Task, error)
+	List(ctx context.Context, opts service.ListOptions) ([]entity.Task, error)
 	Create(ctx context.Context, task *entity.Task) (int, error)
+	CreateInStatus(ctx context.Context, task *entity.Task, status entity.TaskStatus) (int, error)
 	Update(ctx context.Context, task *entity.Task) error
 	Delete(ctx context.Context, id int) error
+	ReplaceIf(ctx context.Context, task *entity.Task, status entity.TaskStatus, cond service.Precondition) error
+	DeleteIf(ctx context.Context, id int, cond service.Precondition) error
+	SharedWithMe(ctx context.Context) ([]entity.Task, error)
+	Share(ctx context.Context, taskID int, userID string, role entity.Role) (*entity.Share, error)
+	Unshare(ctx context.Context, taskID int, userID string) error
+	Shares(ctx context.Context, taskID int) ([]entity.Share, error)
+	MoveToProject(ctx context.Context, id int, projectID *int) (*entity.Task, error)
+	Move(ctx context.Context, id int, anchors service.MoveAnchors) (*entity.Task, error)
+	Transition(ctx context.Context, id int, status entity.TaskStatus) (*entity.Task, error)
+	Search(ctx context.Context, query string, limit int) ([]service.SearchResult, error)
+	QuickAdd(ctx context.Context, input string, loc *time.Location) (*service.QuickAddResult, error)
+	Import(ctx context.Context, items []entity.ImportItem, dryRun bool) (*service.ImportReport, error)
+	Export(ctx context.Context, opts service.ListOptions, fn func(task entity.Task, project string) error) error
 }
 
 type TaskHandler struct {
+	responder
 	service TaskService
 }
 
@@ -29,31 +48,90 @@ func NewTaskHandler(service TaskService) *TaskHandler {
 func (h *TaskHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req dto.CreateTaskRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.sendError(w, http.StatusBadRequest, "invalid_body")
+		h.sendError(w, r, http.StatusBadRequest, "invalid_body")
 		return
 	}
 
 	task := &entity.Task{
 		Title:       req.Title,
 		Description: req.Description,
+		ProjectID:   req.ProjectID,
+		Tags:        req.Tags,
+		Priority:    entity.Priority(req.Priority),
+		DueAt:       req.DueAt,
 	}
 
 	id, err := h.service.Create(r.Context(), task)
 	if err != nil {
-		h.handleError(r.Context(), w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
-	h.sendJSON(w, http.StatusCreated, dto.CreateTaskResponse{ID: id})
+	h.send(w, r, http.StatusCreated, dto.CreateTaskResponse{ID: id})
+}
+
+// QuickAdd timezone в запросе задаёт, от какого «сегодня» считать «завтра» и «15:00», по умолчанию UTC.
+func (h *TaskHandler) QuickAdd(w http.ResponseWriter, r *http.Request) {
+	var req dto.QuickAddRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, r, http.StatusBadRequest, "invalid_body")
+		return
+	}
+	loc := time.UTC
+	if req.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(req.Timezone); err != nil {
+			h.sendError(w, r, http.StatusBadRequest, "invalid_timezone")
+			return
+		}
+	}
+
+	res, err := h.service.QuickAdd(r.Context(), req.Text, loc)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	resp := dto.QuickAddResponse{
+		Task:       toTaskResponse(&res.Task),
+		Recognized: make([]dto.QuickAddToken, 0, len(res.Tokens)),
+	}
+	for _, tok := range res.Tokens {
+		resp.Recognized = append(resp.Recognized, dto.QuickAddToken{
+			Kind:  string(tok.Kind),
+			Text:  tok.Text,
+			Value: tok.Value,
+			Start: tok.Start,
+			End:   tok.End,
+		})
+	}
+	h.send(w, r, http.StatusCreated, resp)
 }
 
 func (h *TaskHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	tasks, err := h.service.GetAll(r.Context())
+	var (
+		tasks []entity.Task
+		err   error
+	)
+	if sharedWithMe, _ := strconv.ParseBool(r.URL.Query().Get("shared_with_me")); sharedWithMe {
+		tasks, err = h.service.SharedWithMe(r.Context())
+	} else {
+		includeArchived, _ := strconv.ParseBool(r.URL.Query().Get("include_archived"))
+		tasks, err = h.service.List(r.Context(), service.ListOptions{
+			IncludeArchived: includeArchived,
+			Sort:            r.URL.Query().Get("sort"),
+			Query:           r.URL.Query().Get("q"),
+		})
+	}
 	if err != nil {
-		h.handleError(r.Context(), w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
+	h.send(w, r, http.StatusOK, toTaskList(tasks))
+}
+
+func toTaskList(tasks []entity.Task) dto.GetAllTasksResponse {
 	resp := dto.GetAllTasksResponse{
 		Tasks: make([]dto.TaskListItemResponse, 0, len(tasks)),
 	}
@@ -62,26 +140,133 @@ func (h *TaskHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 			ID:          t.ID,
 			Title:       t.Title,
 			IsCompleted: t.IsCompleted,
+			ProjectID:   t.ProjectID,
+			Rank:        t.Rank,
+			Status:      string(t.CurrentStatus()),
+			Tags:        tagsOf(t),
+			Priority:    string(t.Priority),
+			DueAt:       t.DueAt,
 		})
 	}
+	return resp
+}
 
-	h.sendJSON(w, http.StatusOK, resp)
+// Search запрос: слова, "фразы в кавычках" и префиксы со звёздочкой, все условия через И.
+func (h *TaskHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	results, err := h.service.Search(r.Context(), query, limit)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	resp := dto.SearchResponse{Query: query, Results: make([]dto.SearchHitResponse, 0, len(results))}
+	for _, res := range results {
+		resp.Results = append(resp.Results, dto.SearchHitResponse{
+			ID:             res.Task.ID,
+			Title:          res.Task.Title,
+			IsCompleted:    res.Task.IsCompleted,
+			Score:          res.Score,
+			TitleHighlight: res.TitleHighlight,
+			Snippet:        res.Snippet,
+		})
+	}
+	h.send(w, r, http.StatusOK, resp)
 }
 
 func (h *TaskHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.Atoi(r.PathValue("id"))
 	task, err := h.service.GetByID(r.Context(), id)
 	if err != nil {
-		h.handleError(r.Context(), w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
-	h.sendJSON(w, http.StatusOK, dto.GetTaskResponse{
-		ID:          task.ID,
-		Title:       task.Title,
-		Description: task.Description,
-		IsCompleted: task.IsCompleted,
-	})
+	h.send(w, r, http.StatusOK, toTaskResponse(task))
+}
+
+func toTaskResponse(task *entity.Task) dto.GetTaskResponse {
+	return dto.GetTaskResponse{
+		ID:              task.ID,
+		Title:           task.Title,
+		Description:     task.Description,
+		IsCompleted:     task.IsCompleted,
+		OwnerID:         task.OwnerID,
+		ProjectID:       task.ProjectID,
+		Rank:            task.Rank,
+		Status:          string(task.CurrentStatus()),
+		StatusChangedAt: statusChangedAt(task),
+		Tags:            tagsOf(*task),
+		Priority:        string(task.Priority),
+		DueAt:           task.DueAt,
+	}
+}
+
+func tagsOf(task entity.Task) []string {
+	if task.Tags == nil {
+		return []string{}
+	}
+	return task.Tags
+}
+
+func statusChangedAt(task *entity.Task) *time.Time {
+	if task.StatusChangedAt.IsZero() {
+		return nil
+	}
+	return &task.StatusChangedAt
+}
+
+func (h *TaskHandler) MoveToProject(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+
+	var req dto.MoveToProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, r, http.StatusBadRequest, "invalid_body")
+		return
+	}
+
+	task, err := h.service.MoveToProject(r.Context(), id, req.ProjectID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, toTaskResponse(task))
+}
+
+func (h *TaskHandler) Move(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+
+	var req dto.MoveTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, r, http.StatusBadRequest, "invalid_body")
+		return
+	}
+
+	task, err := h.service.Move(r.Context(), id, service.MoveAnchors{Before: req.Before, After: req.After})
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, toTaskResponse(task))
+}
+
+func (h *TaskHandler) Transition(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+
+	var req dto.TransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, r, http.StatusBadRequest, "invalid_body")
+		return
+	}
+
+	task, err := h.service.Transition(r.Context(), id, entity.TaskStatus(req.Status))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, toTaskResponse(task))
 }
 
 func (h *TaskHandler) Update(w http.ResponseWriter, r *http.Request) {
@@ -89,7 +274,7 @@ func (h *TaskHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 	var req dto.UpdateTaskRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.sendError(w, http.StatusBadRequest, "invalid_body")
+		h.sendError(w, r, http.StatusBadRequest, "invalid_body")
 		return
 	}
 
@@ -98,18 +283,29 @@ func (h *TaskHandler) Update(w http.ResponseWriter, r *http.Request) {
 		Title:       req.Title,
 		Description: req.Description,
 		IsCompleted: req.IsCompleted,
+		Tags:        req.Tags,
+		Priority:    entity.Priority(req.Priority),
+		DueAt:       req.DueAt,
 	}
 
 	if err := h.service.Update(r.Context(), task); err != nil {
-		h.handleError(r.Context(), w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
-	h.sendJSON(w, http.StatusOK, dto.UpdateTaskResponse{
-		ID:          task.ID,
-		Title:       task.Title,
-		Description: task.Description,
-		IsCompleted: task.IsCompleted,
+	h.send(w, r, http.StatusOK, dto.UpdateTaskResponse{
+		ID:              task.ID,
+		Title:           task.Title,
+		Description:     task.Description,
+		IsCompleted:     task.IsCompleted,
+		OwnerID:         task.OwnerID,
+		ProjectID:       task.ProjectID,
+		Rank:            task.Rank,
+		Status:          string(task.CurrentStatus()),
+		StatusChangedAt: statusChangedAt(task),
+		Tags:            tagsOf(*task),
+		Priority:        string(task.Priority),
+		DueAt:           task.DueAt,
 	})
 }
 
@@ -117,20 +313,103 @@ func (h *TaskHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.Atoi(r.PathValue("id"))
 
 	if err := h.service.Delete(r.Context(), id); err != nil {
-		h.handleError(r.Context(), w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
-	h.sendJSON(w, http.StatusOK, dto.DeleteTaskResponse{Status: "success"})
+	h.send(w, r, http.StatusOK, dto.DeleteTaskResponse{Status: "success"})
 }
 
 func (h *TaskHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/todos", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
-			h.GetAll(w, r)
+			middlewarex.RequireScope(ScopeTasksRead, h.GetAll)(w, r)
+		case http.MethodPost:
+			middlewarex.RequireScope(ScopeTasksWrite, h.Create)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/todos/quick", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			middlewarex.RequireScope(ScopeTasksWrite, h.QuickAdd)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/todos/search", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			middlewarex.RequireScope(ScopeTasksRead, h.Search)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/import", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
 		case http.MethodPost:
-			h.Create(w, r)
+			middlewarex.RequireScope(ScopeTasksWrite, h.Import)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/export", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			middlewarex.RequireScope(ScopeTasksRead, h.Export)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/todos/{id}/project", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			middlewarex.RequireScope(ScopeTasksWrite, h.MoveToProject)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/todos/{id}/move", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			middlewarex.RequireScope(ScopeTasksWrite, h.Move)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/todos/{id}/status", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			middlewarex.RequireScope(ScopeTasksWrite, h.Transition)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/todos/{id}/shares", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			middlewarex.RequireScope(ScopeTasksRead, h.ListShares)(w, r)
+		case http.MethodPost:
+			middlewarex.RequireScope(ScopeTasksWrite, h.Share)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/todos/{id}/shares/{user}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodDelete:
+			middlewarex.RequireScope(ScopeTasksWrite, h.Unshare)(w, r)
 		default:
 			w.WriteHeader(http.StatusMethodNotAllowed)
 		}
@@ -139,11 +418,11 @@ func (h *TaskHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/todos/{id}", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
-			h.GetByID(w, r)
+			middlewarex.RequireScope(ScopeTasksRead, h.GetByID)(w, r)
 		case http.MethodPut:
-			h.Update(w, r)
+			middlewarex.RequireScope(ScopeTasksWrite, h.Update)(w, r)
 		case http.MethodDelete:
-			h.Delete(w, r)
+			middlewarex.RequireScope(ScopeTasksWrite, h.Delete)(w, r)
 		default:
 			w.WriteHeader(http.StatusMethodNotAllowed)
 		}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"ecom_test/internal/server/dto"
+	"ecom_test/pkg/auth"
+	"ecom_test/pkg/middlewarex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+)
+
+var knownScopes = []string{ScopeTasksRead, ScopeTasksWrite, ScopeAdmin} //nolint:gochecknoglobals
+
+type KeyStore interface {
+	Mint(name, subject, tenant string, scopes []string) (string, auth.APIKey, error)
+	Revoke(keyID string) error
+	List() []auth.APIKey
+}
+
+type KeyHandler struct {
+	responder
+	store KeyStore
+	// tenants задан при включённой мультиарендности: тогда ключ выпускается для существующего арендатора.
+	tenants TenantService
+}
+
+func NewKeyHandler(store KeyStore, tenants TenantService) *KeyHandler {
+	return &KeyHandler{
+		store:   store,
+		tenants: tenants,
+	}
+}
+
+func (h *KeyHandler) Mint(w http.ResponseWriter, r *http.Request) {
+	var req dto.MintKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, r, http.StatusBadRequest, "invalid_body")
+		return
+	}
+	if req.Name == "" || req.Subject == "" || len(req.Scopes) == 0 {
+		h.sendError(w, r, http.StatusBadRequest, "name, subject and scopes are required")
+		return
+	}
+	for _, scope := range req.Scopes {
+		if !slices.Contains(knownScopes, scope) {
+			h.sendError(w, r, http.StatusBadRequest, "unknown scope: "+scope)
+			return
+		}
+	}
+
+	if h.tenants != nil {
+		if req.Tenant == "" {
+			h.sendError(w, r, http.StatusBadRequest, "tenant is required")
+			return
+		}
+		if _, err := h.tenants.Get(r.Context(), req.Tenant); err != nil {
+			h.handleError(w, r, err)
+			return
+		}
+	}
+
+	plaintext, key, err := h.store.Mint(req.Name, req.Subject, req.Tenant, req.Scopes)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	logger(r.Context()).Info("api key minted", "key_id", key.ID, "key_subject", key.Subject, "key_tenant", key.Tenant)
+	h.send(w, r, http.StatusCreated, dto.MintKeyResponse{
+		APIKeyResponse: toAPIKeyResponse(key),
+		Key:            plaintext,
+	})
+}
+
+func (h *KeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	keys := h.store.List()
+
+	resp := dto.ListKeysResponse{
+		Keys: make([]dto.APIKeyResponse, 0, len(keys)),
+	}
+	for _, k := range keys {
+		resp.Keys = append(resp.Keys, toAPIKeyResponse(k))
+	}
+	h.send(w, r, http.StatusOK, resp)
+}
+
+func (h *KeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := h.store.Revoke(id); err != nil {
+		if errors.Is(err, auth.ErrKeyNotFound) {
+			h.sendError(w, r, http.StatusNotFound, "key_not_found")
+			return
+		}
+		h.handleError(w, r, err)
+		return
+	}
+
+	logger(r.Context()).Info("api key revoked", "key_id", id)
+	h.send(w, r, http.StatusOK, dto.RevokeKeyResponse{Status: "revoked"})
+}
+
+func (h *KeyHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/admin/keys", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			middlewarex.RequireScope(ScopeAdmin, h.List)(w, r)
+		case http.MethodPost:
+			middlewarex.RequireScope(ScopeAdmin, h.Mint)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/admin/keys/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodDelete:
+			middlewarex.RequireScope(ScopeAdmin, h.Revoke)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func toAPIKeyResponse(k auth.APIKey) dto.APIKeyResponse {
+	return dto.APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Subject:    k.Subject,
+		Tenant:     k.Tenant,
+		Scopes:     k.Scopes,
+		CreatedAt:  k.CreatedAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+	}
+}
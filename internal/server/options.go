@@ -0,0 +1,124 @@
+package server
+
+import (
+	"ecom_test/pkg/auth"
+	"ecom_test/pkg/crashreport"
+	"ecom_test/pkg/health"
+	"ecom_test/pkg/jwt"
+	"ecom_test/pkg/loadshed"
+	"ecom_test/pkg/metrics"
+	"ecom_test/pkg/ratelimit"
+	"ecom_test/pkg/tlsx"
+	"ecom_test/pkg/tracing"
+)
+
+type options struct {
+	health       *health.Registry
+	certificates *tlsx.Reloader
+	metrics      *metrics.Registry
+	tracer       *tracing.Tracer
+	crashes      *crashreport.Store
+	limiter      *ratelimit.Limiter
+	shedder      *loadshed.Limiter
+	keys         *auth.KeyStore
+	jwt          *jwt.Validator
+	tenants      TenantService
+	projects     ProjectService
+	boards       BoardService
+	views        ViewService
+	feeds        FeedService
+}
+
+type Option func(*options)
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func WithHealth(registry *health.Registry) Option {
+	return func(o *options) {
+		o.health = registry
+	}
+}
+
+func WithCertificates(reloader *tlsx.Reloader) Option {
+	return func(o *options) {
+		o.certificates = reloader
+	}
+}
+
+func WithMetrics(registry *metrics.Registry) Option {
+	return func(o *options) {
+		o.metrics = registry
+	}
+}
+
+func WithTracer(tracer *tracing.Tracer) Option {
+	return func(o *options) {
+		o.tracer = tracer
+	}
+}
+
+func WithCrashReports(store *crashreport.Store) Option {
+	return func(o *options) {
+		o.crashes = store
+	}
+}
+
+func WithRateLimiter(limiter *ratelimit.Limiter) Option {
+	return func(o *options) {
+		o.limiter = limiter
+	}
+}
+
+func WithLoadShedder(limiter *loadshed.Limiter) Option {
+	return func(o *options) {
+		o.shedder = limiter
+	}
+}
+
+func WithKeyStore(store *auth.KeyStore) Option {
+	return func(o *options) {
+		o.keys = store
+	}
+}
+
+func WithJWT(validator *jwt.Validator) Option {
+	return func(o *options) {
+		o.jwt = validator
+	}
+}
+
+func WithTenants(service TenantService) Option {
+	return func(o *options) {
+		o.tenants = service
+	}
+}
+
+func WithProjects(service ProjectService) Option {
+	return func(o *options) {
+		o.projects = service
+	}
+}
+
+func WithBoards(service BoardService) Option {
+	return func(o *options) {
+		o.boards = service
+	}
+}
+
+func WithViews(service ViewService) Option {
+	return func(o *options) {
+		o.views = service
+	}
+}
+
+func WithFeeds(service FeedService) Option {
+	return func(o *options) {
+		o.feeds = service
+	}
+}
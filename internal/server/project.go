@@ -0,0 +1,271 @@
+package server
+
+import (
+	"context"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/server/dto"
+	"ecom_test/pkg/middlewarex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+type ProjectService interface {
+	Create(ctx context.Context, project *entity.Project) (int, error)
+	Get(ctx context.Context, id int) (*entity.Project, error)
+	List(ctx context.Context, includeArchived bool) ([]entity.Project, error)
+	Update(ctx context.Context, project *entity.Project) error
+	SetArchived(ctx context.Context, id int, archived bool) (*entity.Project, error)
+	Delete(ctx context.Context, id int, policy entity.ProjectDeletePolicy) error
+	Tasks(ctx context.Context, id int) ([]entity.Task, error)
+	SharedWithMe(ctx context.Context) ([]entity.Project, error)
+	Share(ctx context.Context, id int, userID string, role entity.Role) (*entity.ProjectShare, error)
+	Unshare(ctx context.Context, id int, userID string) error
+	Shares(ctx context.Context, id int) ([]entity.ProjectShare, error)
+}
+
+type ProjectHandler struct {
+	responder
+	service ProjectService
+}
+
+func NewProjectHandler(service ProjectService) *ProjectHandler {
+	return &ProjectHandler{
+		service: service,
+	}
+}
+
+func (h *ProjectHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.ProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, r, http.StatusBadRequest, "invalid_body")
+		return
+	}
+
+	project := &entity.Project{Name: req.Name, Description: req.Description}
+	if _, err := h.service.Create(r.Context(), project); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusCreated, toProjectResponse(*project))
+}
+
+// List с ?shared_with_me=true отдаёт чужие проекты, открытые вызывающему.
+func (h *ProjectHandler) List(w http.ResponseWriter, r *http.Request) {
+	var (
+		projects []entity.Project
+		err      error
+	)
+	if sharedWithMe, _ := strconv.ParseBool(r.URL.Query().Get("shared_with_me")); sharedWithMe {
+		projects, err = h.service.SharedWithMe(r.Context())
+	} else {
+		includeArchived, _ := strconv.ParseBool(r.URL.Query().Get("include_archived"))
+		projects, err = h.service.List(r.Context(), includeArchived)
+	}
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	resp := dto.ListProjectsResponse{Projects: make([]dto.ProjectResponse, 0, len(projects))}
+	for _, p := range projects {
+		resp.Projects = append(resp.Projects, toProjectResponse(p))
+	}
+	h.send(w, r, http.StatusOK, resp)
+}
+
+func (h *ProjectHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	project, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, toProjectResponse(*project))
+}
+
+func (h *ProjectHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+
+	var req dto.ProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, r, http.StatusBadRequest, "invalid_body")
+		return
+	}
+
+	project := &entity.Project{ID: id, Name: req.Name, Description: req.Description}
+	if err := h.service.Update(r.Context(), project); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, toProjectResponse(*project))
+}
+
+// Delete политика для непустого проекта задаётся ?policy=reject|cascade|orphan, по умолчанию reject.
+func (h *ProjectHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	policy := entity.ProjectDeletePolicy(r.URL.Query().Get("policy"))
+
+	if err := h.service.Delete(r.Context(), id, policy); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, dto.DeleteTaskResponse{Status: "success"})
+}
+
+func (h *ProjectHandler) setArchived(archived bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(r.PathValue("id"))
+		project, err := h.service.SetArchived(r.Context(), id, archived)
+		if err != nil {
+			h.handleError(w, r, err)
+			return
+		}
+		h.send(w, r, http.StatusOK, toProjectResponse(*project))
+	}
+}
+
+func (h *ProjectHandler) Tasks(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	tasks, err := h.service.Tasks(r.Context(), id)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, toTaskList(tasks))
+}
+
+func (h *ProjectHandler) ListShares(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	shares, err := h.service.Shares(r.Context(), id)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	resp := dto.ListSharesResponse{Shares: make([]dto.ShareResponse, 0, len(shares))}
+	for _, s := range shares {
+		resp.Shares = append(resp.Shares, projectShareResponse(s))
+	}
+	h.send(w, r, http.StatusOK, resp)
+}
+
+func (h *ProjectHandler) Share(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+
+	var req dto.ShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, r, http.StatusBadRequest, "invalid_body")
+		return
+	}
+
+	share, err := h.service.Share(r.Context(), id, req.UserID, entity.Role(req.Role))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, projectShareResponse(*share))
+}
+
+func (h *ProjectHandler) Unshare(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+
+	if err := h.service.Unshare(r.Context(), id, r.PathValue("user")); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, dto.DeleteTaskResponse{Status: "success"})
+}
+
+func (h *ProjectHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/projects", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			middlewarex.RequireScope(ScopeTasksRead, h.List)(w, r)
+		case http.MethodPost:
+			middlewarex.RequireScope(ScopeTasksWrite, h.Create)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/projects/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			middlewarex.RequireScope(ScopeTasksRead, h.Get)(w, r)
+		case http.MethodPut:
+			middlewarex.RequireScope(ScopeTasksWrite, h.Update)(w, r)
+		case http.MethodDelete:
+			middlewarex.RequireScope(ScopeTasksWrite, h.Delete)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/projects/{id}/todos", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			middlewarex.RequireScope(ScopeTasksRead, h.Tasks)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/projects/{id}/shares", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			middlewarex.RequireScope(ScopeTasksRead, h.ListShares)(w, r)
+		case http.MethodPost:
+			middlewarex.RequireScope(ScopeTasksWrite, h.Share)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/projects/{id}/shares/{user}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodDelete:
+			middlewarex.RequireScope(ScopeTasksWrite, h.Unshare)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/projects/{id}/archive", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			middlewarex.RequireScope(ScopeTasksWrite, h.setArchived(true))(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/projects/{id}/unarchive", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			middlewarex.RequireScope(ScopeTasksWrite, h.setArchived(false))(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func toProjectResponse(p entity.Project) dto.ProjectResponse {
+	return dto.ProjectResponse{
+		ID:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Archived:    p.Archived(),
+		ArchivedAt:  p.ArchivedAt,
+		CreatedAt:   p.CreatedAt,
+	}
+}
+
+func projectShareResponse(s entity.ProjectShare) dto.ShareResponse {
+	return dto.ShareResponse{
+		UserID:    s.UserID,
+		Role:      string(s.Role),
+		GrantedBy: s.GrantedBy,
+		CreatedAt: s.CreatedAt,
+	}
+}
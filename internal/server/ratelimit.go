@@ -0,0 +1,42 @@
+package server
+
+import (
+	"ecom_test/internal/config"
+	"ecom_test/pkg/middlewarex"
+	"ecom_test/pkg/ratelimit"
+)
+
+func rateLimitOptions(cfg config.RateLimit, o options, route middlewarex.RouteFunc) middlewarex.RateLimitOptions {
+	// пробы оркестратора приходят с одного адреса и не должны упираться в лимит
+	routes := map[string]ratelimit.Limit{
+		"/healthz": {},
+		"/readyz":  {},
+	}
+	for pattern, rule := range cfg.Routes {
+		routes[pattern] = toLimit(rule)
+	}
+
+	key := middlewarex.KeyByIP(cfg.TrustedProxies)
+	if keyByPrincipal(cfg) {
+		key = middlewarex.KeyByPrincipal(key)
+	}
+
+	return middlewarex.RateLimitOptions{
+		Limiter: o.limiter,
+		Default: toLimit(cfg.Default),
+		Routes:  routes,
+		Key:     key,
+		Route:   route,
+		Metrics: o.metrics,
+	}
+}
+
+func toLimit(rule config.RateLimitRule) ratelimit.Limit {
+	return ratelimit.Limit{Rate: rule.Rate, Burst: rule.Burst}
+}
+
+// keyByPrincipal лимит по субъекту считается после аутентификации, по адресу — до неё,
+// чтобы отсекать лишнее как можно раньше.
+func keyByPrincipal(cfg config.RateLimit) bool {
+	return cfg.KeyBy == config.RateLimitKeyAPIKey
+}
@@ -1,36 +1,209 @@
 package server
 
 import (
-	"context"
+	"bytes"
+	"ecom_test/internal/domain"
+	"ecom_test/internal/server/dto"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+)
 
-	"ecom_test/internal/domain"
+// Типы ответа в порядке предпочтения сервера: при равном q выбирается более ранний.
+const (
+	mimeJSON    = "application/json"
+	mimeXML     = "application/xml"
+	mimeTextXML = "text/xml"
+	mimeCSV     = "text/csv"
+	mimeText    = "text/plain"
 )
 
-func (h *TaskHandler) sendJSON(w http.ResponseWriter, status int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
+// responder общие методы ответа, встраивается во все обработчики пакета.
+type responder struct{}
+
+// send кодирует data в формат из Accept запроса: JSON, XML, CSV для списков и текстовая таблица.
+// Если подходящего формата нет, GET и HEAD получают 406, а ошибки и ответы на изменяющие
+// запросы уходят в JSON: действие уже выполнено, и клиент должен узнать результат.
+func (h responder) send(w http.ResponseWriter, r *http.Request, status int, data any) {
+	if data == nil {
+		w.WriteHeader(status)
+		return
+	}
+	offers := formatsOf(data)
+	mediaType := negotiate(r.Header.Get("Accept"), offers)
+	if mediaType == "" {
+		mediaType = mimeJSON
+		if status < http.StatusBadRequest && (r.Method == http.MethodGet || r.Method == http.MethodHead) {
+			status, data = http.StatusNotAcceptable, dto.ErrorResponse{Error: "not_acceptable"}
+		}
+	}
+
+	var body bytes.Buffer
+	if err := encode(&body, mediaType, data); err != nil {
+		logger(r.Context()).Error("encode response: "+err.Error(), "content_type", mediaType)
+		mediaType, status = mimeJSON, http.StatusInternalServerError
+		body.Reset()
+		_ = encode(&body, mimeJSON, dto.ErrorResponse{Error: "internal_server_error"})
+	}
+	w.Header().Set("Content-Type", contentType(mediaType))
+	w.Header().Add("Vary", "Accept")
 	w.WriteHeader(status)
-	if data != nil {
-		_ = json.NewEncoder(w).Encode(data)
+	_, _ = w.Write(body.Bytes())
+}
+
+func (h responder) sendError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	h.send(w, r, status, dto.ErrorResponse{Error: message})
+}
+
+// formatsOf форматы, в которых можно представить data: CSV только у списков, текст у значений
+// с колонками (теги csv).
+func formatsOf(data any) []string {
+	offers := []string{mimeJSON, mimeXML, mimeTextXML}
+	columns, _, list := tableOf(data)
+	if list {
+		offers = append(offers, mimeCSV)
+	}
+	if len(columns) > 0 {
+		offers = append(offers, mimeText)
+	}
+	return offers
+}
+
+func contentType(mediaType string) string {
+	if mediaType == mimeJSON {
+		return mimeJSON
 	}
+	return mediaType + "; charset=utf-8"
 }
 
-func (h *TaskHandler) sendError(w http.ResponseWriter, status int, message string) {
-	h.sendJSON(w, status, map[string]string{"error": message})
+// encode XML с корневым элементом response, имена элементов из тегов xml в dto.
+func encode(w io.Writer, mediaType string, data any) error {
+	switch mediaType {
+	case mimeXML, mimeTextXML:
+		_, _ = io.WriteString(w, xml.Header)
+		return xml.NewEncoder(w).EncodeElement(data, xml.StartElement{Name: xml.Name{Local: "response"}})
+	case mimeCSV:
+		return writeCSV(w, data)
+	case mimeText:
+		return writeText(w, data)
+	}
+	return json.NewEncoder(w).Encode(data)
 }
 
-func (h *TaskHandler) handleError(ctx context.Context, w http.ResponseWriter, err error) {
-	logger(ctx).Error(err.Error())
-	if errors.Is(err, domain.ErrTaskNotFound) {
-		h.sendError(w, http.StatusNotFound, "task_not_found")
-		return
+// mediaRange элемент Accept; параметры, кроме q, не учитываются.
+type mediaRange struct {
+	typ, sub string
+	q        float64
+}
+
+func parseAccept(header string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		typ, sub, ok := strings.Cut(strings.ToLower(strings.TrimSpace(params[0])), "/")
+		if !ok || typ == "" || sub == "" {
+			continue
+		}
+		m := mediaRange{typ: typ, sub: sub, q: 1}
+		for _, p := range params[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(p), "=")
+			if strings.EqualFold(name, "q") {
+				if q, err := strconv.ParseFloat(value, 64); err == nil && q >= 0 && q <= 1 {
+					m.q = q
+				}
+			}
+		}
+		ranges = append(ranges, m)
 	}
+	return ranges
+}
 
-	if errors.Is(err, domain.ErrEmptyTitle) || errors.Is(err, domain.ErrInvalidID) {
-		h.sendError(w, http.StatusBadRequest, err.Error())
-		return
+// quality вес mediaType по самому точному подходящему диапазону: type/sub точнее type/*,
+// а тот точнее */*. -1 если не подходит ни один.
+func quality(ranges []mediaRange, mediaType string) float64 {
+	typ, sub, _ := strings.Cut(mediaType, "/")
+	best, q := -1, -1.0
+	for _, m := range ranges {
+		specificity := -1
+		switch {
+		case m.typ == typ && m.sub == sub:
+			specificity = 2
+		case m.typ == typ && m.sub == "*":
+			specificity = 1
+		case m.typ == "*" && m.sub == "*":
+			specificity = 0
+		}
+		if specificity > best {
+			best, q = specificity, m.q
+		}
+	}
+	return q
+}
+
+// negotiate тип из offers с наибольшим q, при равенстве первый в offers; без Accept первый
+// из offers. Пустая строка, если все варианты отклонены или не упомянуты.
+func negotiate(accept string, offers []string) string {
+	ranges := parseAccept(accept)
+	if strings.TrimSpace(accept) == "" || len(ranges) == 0 {
+		return offers[0]
+	}
+	chosen, top := "", 0.0
+	for _, offer := range offers {
+		if q := quality(ranges, offer); q > top {
+			chosen, top = offer, q
+		}
+	}
+	return chosen
+}
+
+func (h responder) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	logger(r.Context()).Error(err.Error())
+	switch {
+	case errors.Is(err, domain.ErrTaskNotFound):
+		h.sendError(w, r, http.StatusNotFound, "task_not_found")
+	case errors.Is(err, domain.ErrShareNotFound):
+		h.sendError(w, r, http.StatusNotFound, "share_not_found")
+	case errors.Is(err, domain.ErrProjectNotFound):
+		h.sendError(w, r, http.StatusNotFound, "project_not_found")
+	case errors.Is(err, domain.ErrViewNotFound):
+		h.sendError(w, r, http.StatusNotFound, "view_not_found")
+	case errors.Is(err, domain.ErrBoardNotFound):
+		h.sendError(w, r, http.StatusNotFound, "board_not_found")
+	case errors.Is(err, domain.ErrFeedNotFound):
+		h.sendError(w, r, http.StatusNotFound, "feed_not_found")
+	case errors.Is(err, domain.ErrTenantNotFound):
+		h.sendError(w, r, http.StatusNotFound, "tenant_not_found")
+	case errors.Is(err, domain.ErrForbidden):
+		h.sendError(w, r, http.StatusForbidden, "forbidden")
+	case errors.Is(err, domain.ErrTenantSuspended):
+		h.sendError(w, r, http.StatusForbidden, "tenant_suspended")
+	case errors.Is(err, domain.ErrTenantExists):
+		h.sendError(w, r, http.StatusConflict, "tenant_exists")
+	case errors.Is(err, domain.ErrQuotaExceeded):
+		h.sendError(w, r, http.StatusConflict, "quota_exceeded")
+	case errors.Is(err, domain.ErrProjectNotEmpty):
+		h.sendError(w, r, http.StatusConflict, "project_not_empty")
+	case errors.Is(err, domain.ErrProjectArchived):
+		h.sendError(w, r, http.StatusConflict, "project_archived")
+	case errors.Is(err, domain.ErrPreconditionFailed):
+		h.sendError(w, r, http.StatusPreconditionFailed, "precondition_failed")
+	case errors.Is(err, domain.ErrInvalidTransition):
+		h.sendError(w, r, http.StatusConflict, "invalid_transition")
+	case errors.Is(err, domain.ErrWIPLimitExceeded):
+		h.sendError(w, r, http.StatusConflict, "wip_limit_exceeded")
+	case errors.Is(err, domain.ErrEmptyTitle), errors.Is(err, domain.ErrInvalidID),
+		errors.Is(err, domain.ErrInvalidShare), errors.Is(err, domain.ErrInvalidTenant),
+		errors.Is(err, domain.ErrEmptyProjectName), errors.Is(err, domain.ErrInvalidDeletePolicy),
+		errors.Is(err, domain.ErrInvalidMove), errors.Is(err, domain.ErrInvalidSort),
+		errors.Is(err, domain.ErrInvalidStatus), errors.Is(err, domain.ErrInvalidBoard),
+		errors.Is(err, domain.ErrInvalidQuery), errors.Is(err, domain.ErrInvalidPriority),
+		errors.Is(err, domain.ErrInvalidView), errors.Is(err, domain.ErrInvalidImport):
+		h.sendError(w, r, http.StatusBadRequest, err.Error())
+	default:
+		h.sendError(w, r, http.StatusInternalServerError, "internal_server_error")
 	}
-	h.sendError(w, http.StatusInternalServerError, "internal_server_error")
 }
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"ecom_test/internal/server/dto"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNegotiate(t *testing.T) {
+	all := []string{mimeJSON, mimeXML, mimeTextXML, mimeCSV, mimeText}
+	tests := []struct {
+		name   string
+		accept string
+		offers []string
+		want   string
+	}{
+		{name: "no header", accept: "", offers: all, want: mimeJSON},
+		{name: "any", accept: "*/*", offers: all, want: mimeJSON},
+		{name: "exact", accept: "text/csv", offers: all, want: mimeCSV},
+		{name: "case and spaces", accept: " Application/XML ; q=1", offers: all, want: mimeXML},
+		{name: "highest q", accept: "application/json;q=0.5, text/plain;q=0.9", offers: all, want: mimeText},
+		{name: "tie goes to server order", accept: "text/plain, application/xml", offers: all, want: mimeXML},
+		{name: "subtype wildcard", accept: "text/*", offers: all, want: mimeTextXML},
+		{name: "specific range wins over wildcard", accept: "text/*;q=0.8, text/xml;q=0.1", offers: all, want: mimeCSV},
+		{name: "q=0 excludes", accept: "application/json;q=0, */*;q=0.1", offers: all, want: mimeXML},
+		{name: "csv not offered", accept: "text/csv", offers: []string{mimeJSON, mimeXML, mimeTextXML}, want: ""},
+		{name: "nothing acceptable", accept: "image/png", offers: all, want: ""},
+		{name: "malformed ranges ignored", accept: "json, text/plain", offers: all, want: mimeText},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := negotiate(tt.accept, tt.offers); got != tt.want {
+				t.Errorf("negotiate(%q) = %q, want %q", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResponder_Send(t *testing.T) {
+	created := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	projectID := 3
+	list := dto.GetAllTasksResponse{Tasks: []dto.TaskListItemResponse{
+		{ID: 1, Title: "Купить молоко", Status: "todo", Tags: []string{"home", "shop"}},
+		{ID: 2, Title: "Отчёт\tза месяц", IsCompleted: true, ProjectID: &projectID, Status: "done", Tags: []string{}},
+	}}
+	view := dto.ViewTasksResponse{ViewID: 7, Fields: []string{"id", "title", "tags"}, Tasks: []dto.ViewTask{
+		{"id": json.RawMessage("1"), "title": json.RawMessage(`"Купить молоко"`), "tags": json.RawMessage(`["home"]`)},
+	}}
+	tests := []struct {
+		name        string
+		method      string
+		accept      string
+		status      int
+		data        any
+		wantStatus  int
+		contentType string
+		body        string
+	}{
+		{
+			name: "json by default", method: http.MethodGet, status: http.StatusOK, data: dto.DeleteTaskResponse{Status: "deleted"},
+			wantStatus: http.StatusOK, contentType: "application/json", body: `{"status":"deleted"}` + "\n",
+		},
+		{
+			name: "xml list", method: http.MethodGet, accept: "application/xml", status: http.StatusOK, data: list,
+			wantStatus: http.StatusOK, contentType: "application/xml; charset=utf-8",
+			body: `<response><tasks><task><id>1</id><title>Купить молоко</title><is_completed>false</is_completed>` +
+				`<rank></rank><status>todo</status><tags><tag>home</tag><tag>shop</tag></tags></task>`,
+		},
+		{
+			name: "csv list", method: http.MethodGet, accept: "text/csv", status: http.StatusOK, data: list,
+			wantStatus: http.StatusOK, contentType: "text/csv; charset=utf-8",
+			body: "id,title,is_completed,project_id,rank,status,tags,priority,due_at\n" +
+				"1,Купить молоко,false,,,todo,\"home,shop\",,\n" +
+				"2,Отчёт\tза месяц,true,3,,done,,,\n",
+		},
+		{
+			name: "text list", method: http.MethodGet, accept: "text/plain", status: http.StatusOK, data: list,
+			wantStatus: http.StatusOK, contentType: "text/plain; charset=utf-8",
+			body: "2   Отчёт за месяц  true",
+		},
+		{
+			name: "text object", method: http.MethodGet, accept: "text/plain", status: http.StatusOK,
+			data:       dto.ShareResponse{UserID: "bob", Role: "viewer", CreatedAt: created},
+			wantStatus: http.StatusOK, contentType: "text/plain; charset=utf-8",
+			body: "user_id     bob\nrole        viewer\ngranted_by  \ncreated_at  2026-10-19T12:00:00Z\n",
+		},
+		{
+			name: "csv of view fields", method: http.MethodGet, accept: "text/csv", status: http.StatusOK, data: view,
+			wantStatus: http.StatusOK, contentType: "text/csv; charset=utf-8", body: "id,title,tags\n1,Купить молоко,home\n",
+		},
+		{
+			name: "xml of view fields", method: http.MethodGet, accept: "text/xml", status: http.StatusOK, data: view,
+			wantStatus: http.StatusOK, contentType: "text/xml; charset=utf-8",
+			body: "<tasks><task><id>1</id><tags><item>home</item></tags><title>Купить молоко</title></task></tasks>",
+		},
+		{
+			name: "csv of single object is not acceptable", method: http.MethodGet, accept: "text/csv", status: http.StatusOK,
+			data:       dto.DeleteTaskResponse{Status: "deleted"},
+			wantStatus: http.StatusNotAcceptable, contentType: "application/json", body: `{"error":"not_acceptable"}`,
+		},
+		{
+			name: "mutation falls back to json", method: http.MethodPost, accept: "image/png", status: http.StatusCreated,
+			data:       dto.CreateTaskResponse{ID: 5},
+			wantStatus: http.StatusCreated, contentType: "application/json", body: `{"id":5}`,
+		},
+		{
+			name: "error keeps status", method: http.MethodGet, accept: "image/png", status: http.StatusNotFound,
+			data:       dto.ErrorResponse{Error: "task_not_found"},
+			wantStatus: http.StatusNotFound, contentType: "application/json", body: `{"error":"task_not_found"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/todos", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			responder{}.send(rec, r, tt.status, tt.data)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if got := rec.Header().Get("Vary"); got != "Accept" {
+				t.Errorf("Vary = %q, want Accept", got)
+			}
+			if !strings.Contains(rec.Body.String(), tt.body) {
+				t.Errorf("body does not contain %q\n%s", tt.body, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package server
+
+const (
+	ScopeTasksRead  = "tasks:read"
+	ScopeTasksWrite = "tasks:write"
+	ScopeAdmin      = "admin"
+)
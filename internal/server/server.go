@@ -3,22 +3,144 @@ package server
 import (
 	"ecom_test/internal/config"
 	"ecom_test/pkg/middlewarex"
+	"ecom_test/pkg/tlsx"
 	"net/http"
 	"time"
 )
 
-func NewServer(cfg config.Config, service TaskService) *http.Server {
+func NewServer(cfg config.Config, service TaskService, opts ...Option) *http.Server {
+	o := newOptions(opts)
+
 	mux := http.NewServeMux()
 	handler := NewTaskHandler(service)
 	handler.RegisterRoutes(mux)
+	if o.projects != nil {
+		NewProjectHandler(o.projects).RegisterRoutes(mux)
+	}
+	if o.boards != nil {
+		NewBoardHandler(o.boards).RegisterRoutes(mux)
+	}
+	if o.views != nil {
+		NewViewHandler(o.views).RegisterRoutes(mux)
+	}
+	if o.feeds != nil {
+		NewFeedHandler(o.feeds).RegisterRoutes(mux)
+	}
+	NewCalDAVHandler(service, o.projects).RegisterRoutes(mux)
 
-	var wrappedMux http.Handler = middlewarex.Logger(mux)
+	if cfg.Auth.Enabled && o.keys != nil {
+		var tenants TenantService
+		if cfg.Tenancy.Enabled {
+			tenants = o.tenants
+		}
+		NewKeyHandler(o.keys, tenants).RegisterRoutes(mux)
+	}
+	if cfg.Tenancy.Enabled && o.tenants != nil {
+		NewTenantHandler(o.tenants).RegisterRoutes(mux)
+	}
 
-	return &http.Server{
+	if o.health != nil {
+		mux.Handle("/healthz", o.health.LivenessHandler())
+		mux.Handle("/readyz", o.health.ReadinessHandler())
+	}
+	if o.metrics != nil && cfg.Metrics.Enabled && cfg.Admin.Addr == "" {
+		mux.Handle(metricsPath(cfg.Metrics), o.metrics.Handler())
+	}
+
+	route := middlewarex.MuxRoute(mux)
+	var wrappedMux http.Handler = mux
+	var reporter middlewarex.CrashReporter
+	if o.crashes != nil {
+		reporter = o.crashes
+	}
+	if cfg.Tenancy.Enabled && o.tenants != nil {
+		wrappedMux = middlewarex.Tenant(tenantOptions(cfg, o))(wrappedMux)
+	}
+	rateLimited := cfg.RateLimit.Enabled && o.limiter != nil
+	if rateLimited && keyByPrincipal(cfg.RateLimit) {
+		wrappedMux = middlewarex.RateLimit(rateLimitOptions(cfg.RateLimit, o, route))(wrappedMux)
+	}
+	wrappedMux = authenticate(cfg, o)(wrappedMux)
+	wrappedMux = middlewarex.Recovery(route, o.metrics, reporter)(wrappedMux)
+	wrappedMux = middlewarex.Logger(wrappedMux)
+	if o.tracer != nil {
+		wrappedMux = middlewarex.Tracing(o.tracer, route)(wrappedMux)
+	}
+	if cfg.LoadShedding.Enabled && o.shedder != nil {
+		wrappedMux = middlewarex.LoadShed(middlewarex.LoadShedOptions{
+			Limiter:  o.shedder,
+			Priority: middlewarex.PriorityByMethod(route, "/healthz", "/readyz"),
+			// выгрузка, подписка и REPORT календаря пишутся потоком, пока клиент читает
+			Streaming: middlewarex.StreamingRoutes(route,
+				"GET /export", "GET /feed/{token}", "HEAD /feed/{token}", "REPORT "+davPrefix+"/"),
+			Metrics:    o.metrics,
+			RetryAfter: time.Second,
+		})(wrappedMux)
+	}
+	if rateLimited && !keyByPrincipal(cfg.RateLimit) {
+		wrappedMux = middlewarex.RateLimit(rateLimitOptions(cfg.RateLimit, o, route))(wrappedMux)
+	}
+	if o.metrics != nil {
+		wrappedMux = middlewarex.Metrics(o.metrics, route)(wrappedMux)
+	}
+	wrappedMux = middlewarex.RequestID(route)(wrappedMux)
+
+	srv := &http.Server{
 		Addr:         cfg.Addr,
 		Handler:      wrappedMux,
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  120 * time.Second,
 	}
+
+	if cfg.TLS.Enabled && o.certificates != nil {
+		srv.Handler = middlewarex.ClientCert(wrappedMux)
+		srv.TLSConfig = tlsx.ServerConfig(tlsx.Options{
+			MinVersion:   cfg.TLS.MinVersion,
+			CipherPolicy: cfg.TLS.CipherPolicy,
+			ClientAuth:   cfg.TLS.ClientAuth,
+		}, o.certificates)
+	}
+
+	return srv
+}
+
+// NewAdminServer поднимает служебный листенер, nil если он не сконфигурирован.
+func NewAdminServer(cfg config.Config, opts ...Option) *http.Server {
+	if cfg.Admin.Addr == "" {
+		return nil
+	}
+	o := newOptions(opts)
+
+	mux := http.NewServeMux()
+	if o.health != nil {
+		mux.Handle("/healthz", o.health.LivenessHandler())
+		mux.Handle("/readyz", o.health.ReadinessHandler())
+	}
+	if o.metrics != nil && cfg.Metrics.Enabled {
+		mux.Handle(metricsPath(cfg.Metrics), o.metrics.Handler())
+	}
+	if o.crashes != nil {
+		// в отчётах стеки всех горутин, поэтому только с ключом администратора
+		crashes := http.NewServeMux()
+		o.crashes.RegisterRoutes(crashes)
+		guarded := authenticate(cfg, o)(middlewarex.RequireScope(ScopeAdmin, crashes.ServeHTTP))
+		mux.Handle("/admin/crashes", guarded)
+		mux.Handle("/admin/crashes/", guarded)
+	}
+
+	return &http.Server{
+		Addr:         cfg.Admin.Addr,
+		Handler:      mux,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		IdleTimeout:  120 * time.Second,
+	}
+}
+
+func metricsPath(cfg config.Metrics) string {
+	if cfg.Path == "" {
+		return "/metrics"
+	}
+	return cfg.Path
 }
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"ecom_test/internal/config"
+	"ecom_test/pkg/auth"
+	"ecom_test/pkg/crashreport"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewAdminServer_CrashReportsRequireAdmin(t *testing.T) {
+	crashes, err := crashreport.NewStore(t.TempDir(), 10)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	id, err := crashes.Save(crashreport.Report{Panic: "boom", Goroutines: "goroutine 1 [running]:"})
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	keys := auth.NewKeyStore()
+	admin, _, _ := keys.Mint("ops", "ops", "", []string{ScopeAdmin})
+	reader, _, _ := keys.Mint("ci", "ci", "", []string{ScopeTasksRead})
+
+	cfg := config.Config{Admin: config.Admin{Addr: "127.0.0.1:0"}, Auth: config.Auth{Enabled: true}}
+	handler := NewAdminServer(cfg, WithCrashReports(crashes), WithKeyStore(keys)).Handler
+
+	tests := []struct {
+		name  string
+		path  string
+		token string
+		want  int
+	}{
+		{name: "list without key", path: "/admin/crashes", want: http.StatusUnauthorized},
+		{name: "report without key", path: "/admin/crashes/" + id, want: http.StatusUnauthorized},
+		{name: "report without admin scope", path: "/admin/crashes/" + id, token: reader, want: http.StatusForbidden},
+		{name: "list with admin key", path: "/admin/crashes", token: admin, want: http.StatusOK},
+		{name: "report with admin key", path: "/admin/crashes/" + id, token: admin, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			if rec.Code != tt.want {
+				t.Errorf("GET %s status = %d, want %d", tt.path, rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/server/dto"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+func (h *TaskHandler) ListShares(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	shares, err := h.service.Shares(r.Context(), id)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	resp := dto.ListSharesResponse{Shares: make([]dto.ShareResponse, 0, len(shares))}
+	for _, s := range shares {
+		resp.Shares = append(resp.Shares, shareResponse(s))
+	}
+	h.send(w, r, http.StatusOK, resp)
+}
+
+func (h *TaskHandler) Share(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+
+	var req dto.ShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, r, http.StatusBadRequest, "invalid_body")
+		return
+	}
+
+	share, err := h.service.Share(r.Context(), id, req.UserID, entity.Role(req.Role))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, shareResponse(*share))
+}
+
+func (h *TaskHandler) Unshare(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+
+	if err := h.service.Unshare(r.Context(), id, r.PathValue("user")); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, dto.DeleteTaskResponse{Status: "success"})
+}
+
+func shareResponse(s entity.Share) dto.ShareResponse {
+	return dto.ShareResponse{
+		UserID:    s.UserID,
+		Role:      string(s.Role),
+		GrantedBy: s.GrantedBy,
+		CreatedAt: s.CreatedAt,
+	}
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"ecom_test/internal/server/dto"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var errNotTabular = errors.New("response has no table representation")
+
+// tableOf колонки и строки ответа по тегам csv. Список это поле с тегом csv:",rows", колонки
+// берутся у его элементов; иначе ответ одна строка из собственных полей. Без колонок у ответа
+// нет табличного вида.
+func tableOf(data any) (header []string, rows [][]string, list bool) {
+	if view, ok := data.(dto.ViewTasksResponse); ok {
+		return view.Fields, viewRows(view), true
+	}
+	v := reflect.Indirect(reflect.ValueOf(data))
+	if v.Kind() != reflect.Struct {
+		return nil, nil, false
+	}
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		if f.Tag.Get("csv") != ",rows" {
+			continue
+		}
+		header, _ = flatten(reflect.New(f.Type.Elem()).Elem())
+		items := v.Field(i)
+		rows = make([][]string, 0, items.Len())
+		for j := range items.Len() {
+			_, cells := flatten(items.Index(j))
+			rows = append(rows, cells)
+		}
+		return header, rows, true
+	}
+	header, cells := flatten(v)
+	if len(header) == 0 {
+		return nil, nil, false
+	}
+	return header, [][]string{cells}, false
+}
+
+// flatten поля структуры с тегом csv; встроенные структуры и поля с csv:",inline" раскрываются.
+func flatten(v reflect.Value) (names, cells []string) {
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		tag := f.Tag.Get("csv")
+		if f.Anonymous || tag == ",inline" {
+			n, c := flatten(reflect.Indirect(v.Field(i)))
+			names, cells = append(names, n...), append(cells, c...)
+			continue
+		}
+		if tag == "" || tag == "-" || !f.IsExported() {
+			continue
+		}
+		names = append(names, tag)
+		cells = append(cells, cell(v.Field(i)))
+	}
+	return names, cells
+}
+
+func cell(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch value := v.Interface().(type) {
+	case time.Time:
+		return value.Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case []string:
+		return strings.Join(value, ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// viewRows ячейки из JSON полей задачи: строки без кавычек, массивы через запятую, null пусто.
+func viewRows(view dto.ViewTasksResponse) [][]string {
+	rows := make([][]string, 0, len(view.Tasks))
+	for _, task := range view.Tasks {
+		row := make([]string, len(view.Fields))
+		for i, f := range view.Fields {
+			var value any
+			if err := json.Unmarshal(task[f], &value); err == nil {
+				row[i] = jsonCell(value)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func jsonCell(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case []any:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, jsonCell(item))
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(value)
+}
+
+// writeCSV только для списков: строка заголовка и по строке на элемент.
+func writeCSV(w io.Writer, data any) error {
+	header, rows, list := tableOf(data)
+	if !list {
+		return errNotTabular
+	}
+	cw := csv.NewWriter(w)
+	_ = cw.Write(header)
+	_ = cw.WriteAll(rows)
+	return cw.Error()
+}
+
+// writeText таблица с выравненными колонками; одиночный объект выводится парами поле и значение.
+func writeText(w io.Writer, data any) error {
+	header, rows, list := tableOf(data)
+	if len(header) == 0 {
+		return errNotTabular
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if list {
+		writeLine(tw, header)
+		for _, row := range rows {
+			writeLine(tw, row)
+		}
+	} else {
+		for i, name := range header {
+			writeLine(tw, []string{name, rows[0][i]})
+		}
+	}
+	return tw.Flush()
+}
+
+var cellReplacer = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ") //nolint:gochecknoglobals
+
+func writeLine(w io.Writer, cells []string) {
+	for i, c := range cells {
+		if i > 0 {
+			_, _ = io.WriteString(w, "\t")
+		}
+		_, _ = cellReplacer.WriteString(w, c)
+	}
+	_, _ = io.WriteString(w, "\n")
+}
@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"ecom_test/internal/config"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/server/dto"
+	"ecom_test/pkg/middlewarex"
+	"ecom_test/pkg/ratelimit"
+	"encoding/json"
+	"net/http"
+)
+
+type TenantService interface {
+	Create(ctx context.Context, tenant entity.Tenant) (*entity.Tenant, error)
+	Get(ctx context.Context, id string) (*entity.Tenant, error)
+	List(ctx context.Context) ([]entity.Tenant, error)
+	SetStatus(ctx context.Context, id string, status entity.TenantStatus) (*entity.Tenant, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type TenantHandler struct {
+	responder
+	service TenantService
+}
+
+func NewTenantHandler(service TenantService) *TenantHandler {
+	return &TenantHandler{
+		service: service,
+	}
+}
+
+func (h *TenantHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateTenantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, r, http.StatusBadRequest, "invalid_body")
+		return
+	}
+
+	tenant := entity.Tenant{ID: req.ID, Name: req.Name}
+	if req.Quota != nil {
+		tenant.Quota = entity.TenantQuota{
+			MaxTasks:     req.Quota.MaxTasks,
+			RequestRate:  req.Quota.RequestRate,
+			RequestBurst: req.Quota.RequestBurst,
+		}
+	}
+
+	created, err := h.service.Create(r.Context(), tenant)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusCreated, toTenantResponse(*created))
+}
+
+func (h *TenantHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenants, err := h.service.List(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	resp := dto.ListTenantsResponse{Tenants: make([]dto.TenantResponse, 0, len(tenants))}
+	for _, t := range tenants {
+		resp.Tenants = append(resp.Tenants, toTenantResponse(t))
+	}
+	h.send(w, r, http.StatusOK, resp)
+}
+
+func (h *TenantHandler) Get(w http.ResponseWriter, r *http.Request) {
+	tenant, err := h.service.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, toTenantResponse(*tenant))
+}
+
+func (h *TenantHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context(), r.PathValue("id")); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, dto.DeleteTaskResponse{Status: "success"})
+}
+
+func (h *TenantHandler) setStatus(status entity.TenantStatus) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tenant, err := h.service.SetStatus(r.Context(), r.PathValue("id"), status)
+		if err != nil {
+			h.handleError(w, r, err)
+			return
+		}
+		h.send(w, r, http.StatusOK, toTenantResponse(*tenant))
+	}
+}
+
+func (h *TenantHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/admin/tenants", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			middlewarex.RequireScope(ScopeAdmin, h.List)(w, r)
+		case http.MethodPost:
+			middlewarex.RequireScope(ScopeAdmin, h.Create)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/admin/tenants/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			middlewarex.RequireScope(ScopeAdmin, h.Get)(w, r)
+		case http.MethodDelete:
+			middlewarex.RequireScope(ScopeAdmin, h.Delete)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/admin/tenants/{id}/suspend", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			middlewarex.RequireScope(ScopeAdmin, h.setStatus(entity.TenantSuspended))(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/admin/tenants/{id}/resume", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			middlewarex.RequireScope(ScopeAdmin, h.setStatus(entity.TenantActive))(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func toTenantResponse(t entity.Tenant) dto.TenantResponse {
+	return dto.TenantResponse{
+		ID:     t.ID,
+		Name:   t.Name,
+		Status: string(t.Status),
+		Quota: dto.TenantQuota{
+			MaxTasks:     t.Quota.MaxTasks,
+			RequestRate:  t.Quota.RequestRate,
+			RequestBurst: t.Quota.RequestBurst,
+		},
+		CreatedAt: t.CreatedAt,
+	}
+}
+
+// tenantDirectory отдаёт middlewarex.Tenant статус и квоту запросов арендатора.
+type tenantDirectory struct {
+	service TenantService
+}
+
+func (d tenantDirectory) LookupTenant(ctx context.Context, tenantID string) (middlewarex.TenantInfo, bool) {
+	tenant, err := d.service.Get(ctx, tenantID)
+	if err != nil {
+		return middlewarex.TenantInfo{}, false
+	}
+	return middlewarex.TenantInfo{
+		Active: tenant.Status == entity.TenantActive,
+		Rate:   ratelimit.Limit{Rate: tenant.Quota.RequestRate, Burst: tenant.Quota.RequestBurst},
+	}, true
+}
+
+func tenantOptions(cfg config.Config, o options) middlewarex.TenantOptions {
+	tenancy := cfg.Tenancy
+	sources := make([]middlewarex.TenantSource, 0, len(tenancy.Sources))
+	for _, s := range tenancy.Sources {
+		sources = append(sources, middlewarex.TenantSource(s))
+	}
+	return middlewarex.TenantOptions{
+		Sources:    sources,
+		Header:     tenancy.Header,
+		BaseDomain: tenancy.BaseDomain,
+		Claim:      tenancy.Claim,
+		Default:    tenancy.DefaultTenant,
+		Directory:  tenantDirectory{service: o.tenants},
+		Limiter:    o.limiter,
+		// ключи и арендаторы глобальные, пробы не относятся ни к одному арендатору
+		Exempt:         []string{"/admin/", "/healthz", "/readyz", metricsPath(cfg.Metrics)},
+		RequireBinding: true,
+	}
+}
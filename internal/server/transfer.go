@@ -0,0 +1,195 @@
+package server
+
+import (
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/domain/service"
+	"ecom_test/internal/infrastructure/transfer"
+	"ecom_test/internal/server/dto"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	maxImportSize   = 10 << 20
+	maxImportMemory = 1 << 20
+	// exportFlushEvery через сколько задач выгрузка отправляет накопленное клиенту.
+	exportFlushEvery = 100
+	// exportWriteTimeout сколько даётся на отправку очередной порции: WriteTimeout сервера
+	// обрезал бы большую выгрузку, поэтому срок продлевается на каждом Flush.
+	exportWriteTimeout = 10 * time.Second
+)
+
+// exportTypes Content-Type и расширение файла выгрузки по формату.
+var exportTypes = map[transfer.Format]struct{ contentType, ext string }{ //nolint:gochecknoglobals
+	transfer.FormatJSONL:    {"application/x-ndjson", "jsonl"},
+	transfer.FormatCSV:      {"text/csv; charset=utf-8", "csv"},
+	transfer.FormatTodoTxt:  {"text/plain; charset=utf-8", "txt"},
+	transfer.FormatMarkdown: {"text/markdown; charset=utf-8", "md"},
+	transfer.FormatICal:     {"text/calendar; charset=utf-8", "ics"},
+}
+
+// Import multipart-форма: file обязателен, format (jsonl, todotxt, csv, markdown, ical) по умолчанию
+// определяется по расширению файла, dry_run=true только проверяет, mapping JSON-объект
+// «заголовок CSV → поле задачи». Если хоть одна строка не прошла проверку, не создаётся ничего
+// и ответ 422 с ошибками по строкам.
+func (h *TaskHandler) Import(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	if err := r.ParseMultipartForm(maxImportMemory); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.sendError(w, r, http.StatusRequestEntityTooLarge, "file_too_large")
+			return
+		}
+		h.sendError(w, r, http.StatusBadRequest, "invalid_body")
+		return
+	}
+	defer func() { _ = r.MultipartForm.RemoveAll() }()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		h.sendError(w, r, http.StatusBadRequest, "file is required")
+		return
+	}
+	defer file.Close()
+
+	format, ok := transfer.FormatOf(header.Filename)
+	if name := r.FormValue("format"); name != "" {
+		format, ok = transfer.ParseFormat(name)
+	}
+	if !ok {
+		h.sendError(w, r, http.StatusBadRequest, "unknown format, expected jsonl, todotxt, csv, markdown or ical")
+		return
+	}
+
+	dryRun := false
+	if v := r.FormValue("dry_run"); v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			h.sendError(w, r, http.StatusBadRequest, "dry_run must be true or false")
+			return
+		}
+	}
+	var opts transfer.DecodeOptions
+	if v := r.FormValue("mapping"); v != "" {
+		if err := json.Unmarshal([]byte(v), &opts.Mapping); err != nil {
+			h.sendError(w, r, http.StatusBadRequest, "mapping must be a JSON object of column to field")
+			return
+		}
+	}
+
+	items, err := transfer.Decode(format, file, opts)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	report, err := h.service.Import(r.Context(), items, dryRun)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	status := http.StatusCreated
+	switch {
+	case report.Failed > 0:
+		status = http.StatusUnprocessableEntity
+	case dryRun:
+		status = http.StatusOK
+	}
+	logger(r.Context()).Info("import finished", "format", format, "dry_run", dryRun, "created", report.Created, "failed", report.Failed)
+	h.send(w, r, status, toImportResponse(format, report))
+}
+
+// Export отдаёт задачи файлом по мере чтения из хранилища. format обязателен, q и include_archived
+// как у GET /todos. Ошибка до первой задачи (неверный запрос) приходит обычным JSON-ответом; после
+// начала передачи ответ обрывается, и клиент видит неполный файл, а не тело с ошибкой внутри.
+func (h *TaskHandler) Export(w http.ResponseWriter, r *http.Request) {
+	format, ok := transfer.ParseFormat(r.URL.Query().Get("format"))
+	if !ok {
+		h.sendError(w, r, http.StatusBadRequest, "unknown format, expected jsonl, csv, todotxt, markdown or ical")
+		return
+	}
+	includeArchived, _ := strconv.ParseBool(r.URL.Query().Get("include_archived"))
+	opts := service.ListOptions{IncludeArchived: includeArchived, Query: r.URL.Query().Get("q")}
+
+	var (
+		enc      transfer.Encoder
+		exported int
+	)
+	flusher := http.NewResponseController(w)
+	// start заголовки отправляются только когда известно, что запрос корректен.
+	start := func() error {
+		t := exportTypes[format]
+		w.Header().Set("Content-Type", t.contentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="tasks.`+t.ext+`"`)
+		_ = flusher.SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+		var err error
+		enc, err = transfer.NewEncoder(format, w)
+		return err
+	}
+
+	err := h.service.Export(r.Context(), opts, func(task entity.Task, project string) error {
+		if enc == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(task, project); err != nil {
+			return err
+		}
+		exported++
+		if exported%exportFlushEvery == 0 {
+			_ = flusher.Flush()
+			_ = flusher.SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+		}
+		return nil
+	})
+	if err == nil && enc == nil {
+		err = start()
+	}
+	if err == nil {
+		err = enc.Close()
+	}
+	if err != nil {
+		if enc == nil {
+			h.handleError(w, r, err)
+			return
+		}
+		logger(r.Context()).Warn("export aborted", "format", format, "exported", exported, "error", err)
+		panic(http.ErrAbortHandler)
+	}
+	logger(r.Context()).Info("export finished", "format", format, "exported", exported)
+}
+
+func toImportResponse(format transfer.Format, report *service.ImportReport) dto.ImportResponse {
+	resp := dto.ImportResponse{
+		Format:  string(format),
+		DryRun:  report.DryRun,
+		Atomic:  report.Atomic,
+		Total:   len(report.Rows),
+		Created: report.Created,
+		Failed:  report.Failed,
+		Rows:    make([]dto.ImportRowResponse, 0, len(report.Rows)),
+	}
+	created := !report.DryRun && report.Created > 0
+	for _, row := range report.Rows {
+		item := dto.ImportRowResponse{
+			Line:      row.Line,
+			Title:     row.Task.Title,
+			Status:    string(row.Task.Status),
+			Priority:  string(row.Task.Priority),
+			Tags:      row.Task.Tags,
+			DueAt:     row.Task.DueAt,
+			ProjectID: row.Task.ProjectID,
+		}
+		if row.Err != nil {
+			item.Error = row.Err.Error()
+		} else if created {
+			id := row.Task.ID
+			item.ID = &id
+		}
+		resp.Rows = append(resp.Rows, item)
+	}
+	return resp
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"ecom_test/internal/config"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/domain/service"
+	"ecom_test/pkg/metrics"
+	"ecom_test/pkg/tracing"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// exportOnly отдаёт count задач в Export, выжидая delay перед каждой порцией,
+// остальные методы сервиса не нужны.
+type exportOnly struct {
+	TaskService
+	count int
+	delay time.Duration
+}
+
+func (s exportOnly) Export(_ context.Context, _ service.ListOptions, fn func(entity.Task, string) error) error {
+	for i := 1; i <= s.count; i++ {
+		if i%exportFlushEvery == 1 {
+			time.Sleep(s.delay)
+		}
+		if err := fn(entity.Task{ID: i, Title: "task " + strconv.Itoa(i)}, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type nopExporter struct{}
+
+func (nopExporter) Export(context.Context, []tracing.SpanData) error { return nil }
+func (nopExporter) Shutdown(context.Context) error                   { return nil }
+
+// flushRecorder запоминает размер тела на момент каждого Flush.
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushedAt []int
+}
+
+func (r *flushRecorder) Flush() {
+	r.flushedAt = append(r.flushedAt, r.Body.Len())
+	r.ResponseRecorder.Flush()
+}
+
+func TestExport_FlushesThroughMiddleware(t *testing.T) {
+	tracer := tracing.NewTracer(nopExporter{}, tracing.Options{})
+	defer func() { _ = tracer.Shutdown(context.Background()) }()
+	srv := NewServer(config.Config{}, exportOnly{count: 3 * exportFlushEvery},
+		WithMetrics(metrics.NewRegistry()), WithTracer(tracer))
+
+	rec := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/export?format=jsonl", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if got := strings.Count(rec.Body.String(), "\n"); got != 3*exportFlushEvery {
+		t.Fatalf("exported %d lines, want %d", got, 3*exportFlushEvery)
+	}
+	if len(rec.flushedAt) < 3 {
+		t.Fatalf("flushed %d times, want a flush every %d tasks", len(rec.flushedAt), exportFlushEvery)
+	}
+	if rec.flushedAt[0] == 0 || rec.flushedAt[0] >= rec.Body.Len() {
+		t.Errorf("first flush at %d of %d bytes, want it mid-stream", rec.flushedAt[0], rec.Body.Len())
+	}
+}
+
+func TestExport_OutlivesServerWriteTimeout(t *testing.T) {
+	srv := NewServer(config.Config{}, exportOnly{count: 3 * exportFlushEvery, delay: 100 * time.Millisecond})
+	ts := httptest.NewUnstartedServer(srv.Handler)
+	ts.Config.WriteTimeout = 150 * time.Millisecond
+	ts.Start()
+	defer ts.Close()
+
+	resp, err := ts.Client().Get(ts.URL + "/export?format=jsonl")
+	if err != nil {
+		t.Fatalf("GET /export failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Export was cut off after %d bytes: %v", len(body), err)
+	}
+	if got := strings.Count(string(body), "\n"); got != 3*exportFlushEvery {
+		t.Errorf("exported %d lines, want %d", got, 3*exportFlushEvery)
+	}
+}
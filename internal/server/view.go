@@ -0,0 +1,253 @@
+package server
+
+import (
+	"context"
+	"ecom_test/internal/domain/entity"
+	"ecom_test/internal/domain/service"
+	"ecom_test/internal/server/dto"
+	"ecom_test/pkg/middlewarex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+type ViewService interface {
+	Create(ctx context.Context, view *entity.View) (int, error)
+	Get(ctx context.Context, id int) (*entity.View, error)
+	List(ctx context.Context) ([]service.ViewCount, error)
+	Update(ctx context.Context, view *entity.View) error
+	Delete(ctx context.Context, id int) error
+	Share(ctx context.Context, id int, userID string) (*entity.View, error)
+	Unshare(ctx context.Context, id int, userID string) error
+	Tasks(ctx context.Context, id int) (*entity.View, []entity.Task, error)
+}
+
+type ViewHandler struct {
+	responder
+	service ViewService
+}
+
+func NewViewHandler(service ViewService) *ViewHandler {
+	return &ViewHandler{
+		service: service,
+	}
+}
+
+func (h *ViewHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.ViewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, r, http.StatusBadRequest, "invalid_body")
+		return
+	}
+
+	view := &entity.View{Name: req.Name, Query: req.Query, Sort: req.Sort, Fields: req.Fields}
+	if _, err := h.service.Create(r.Context(), view); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusCreated, toViewResponse(*view))
+}
+
+// List представления пользователя со счётчиками задач для бейджей.
+func (h *ViewHandler) List(w http.ResponseWriter, r *http.Request) {
+	views, err := h.service.List(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	resp := dto.ListViewsResponse{Views: make([]dto.ViewResponse, 0, len(views))}
+	for _, v := range views {
+		item := toViewResponse(v.View)
+		item.Count = &v.Count
+		resp.Views = append(resp.Views, item)
+	}
+	h.send(w, r, http.StatusOK, resp)
+}
+
+func (h *ViewHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	view, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, toViewResponse(*view))
+}
+
+func (h *ViewHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+
+	var req dto.ViewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, r, http.StatusBadRequest, "invalid_body")
+		return
+	}
+
+	view := &entity.View{ID: id, Name: req.Name, Query: req.Query, Sort: req.Sort, Fields: req.Fields}
+	if err := h.service.Update(r.Context(), view); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, toViewResponse(*view))
+}
+
+func (h *ViewHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, dto.DeleteTaskResponse{Status: "success"})
+}
+
+func (h *ViewHandler) Share(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+
+	var req dto.ViewShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, r, http.StatusBadRequest, "invalid_body")
+		return
+	}
+
+	view, err := h.service.Share(r.Context(), id, req.UserID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, toViewResponse(*view))
+}
+
+func (h *ViewHandler) Unshare(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	if err := h.service.Unshare(r.Context(), id, r.PathValue("user")); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.send(w, r, http.StatusOK, dto.DeleteTaskResponse{Status: "success"})
+}
+
+func (h *ViewHandler) Tasks(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	view, tasks, err := h.service.Tasks(r.Context(), id)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	fields := view.Fields
+	if len(fields) == 0 {
+		fields = entity.ViewFields
+	}
+	resp := dto.ViewTasksResponse{
+		ViewID: view.ID,
+		Fields: fields,
+		Tasks:  make([]dto.ViewTask, 0, len(tasks)),
+	}
+	for i := range tasks {
+		projected, err := projectFields(toTaskResponse(&tasks[i]), fields)
+		if err != nil {
+			h.handleError(w, r, err)
+			return
+		}
+		resp.Tasks = append(resp.Tasks, dto.ViewTask(projected))
+	}
+	h.send(w, r, http.StatusOK, resp)
+}
+
+// projectFields оставляет в JSON задачи только выбранные поля, имена полей совпадают с тегами
+// dto.GetTaskResponse, поэтому набор полей задаётся в одном месте.
+func projectFields(task dto.GetTaskResponse, fields []string) (map[string]json.RawMessage, error) {
+	raw, err := json.Marshal(task)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &all); err != nil {
+		return nil, err
+	}
+
+	projected := make(map[string]json.RawMessage, len(fields))
+	for _, f := range fields {
+		if v, ok := all[f]; ok {
+			projected[f] = v
+		} else {
+			projected[f] = json.RawMessage("null")
+		}
+	}
+	return projected, nil
+}
+
+func (h *ViewHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/views", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			middlewarex.RequireScope(ScopeTasksRead, h.List)(w, r)
+		case http.MethodPost:
+			middlewarex.RequireScope(ScopeTasksWrite, h.Create)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/views/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			middlewarex.RequireScope(ScopeTasksRead, h.Get)(w, r)
+		case http.MethodPut:
+			middlewarex.RequireScope(ScopeTasksWrite, h.Update)(w, r)
+		case http.MethodDelete:
+			middlewarex.RequireScope(ScopeTasksWrite, h.Delete)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/views/{id}/todos", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			middlewarex.RequireScope(ScopeTasksRead, h.Tasks)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/views/{id}/shares", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			middlewarex.RequireScope(ScopeTasksWrite, h.Share)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/views/{id}/shares/{user}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodDelete:
+			middlewarex.RequireScope(ScopeTasksWrite, h.Unshare)(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func toViewResponse(v entity.View) dto.ViewResponse {
+	resp := dto.ViewResponse{
+		ID:         v.ID,
+		Name:       v.Name,
+		OwnerID:    v.OwnerID,
+		Query:      v.Query,
+		Sort:       v.Sort,
+		Fields:     v.Fields,
+		SharedWith: v.SharedWith,
+		CreatedAt:  v.CreatedAt,
+		UpdatedAt:  v.UpdatedAt,
+	}
+	if resp.Fields == nil {
+		resp.Fields = []string{}
+	}
+	if resp.SharedWith == nil {
+		resp.SharedWith = []string{}
+	}
+	return resp
+}
@@ -11,6 +11,8 @@ import (
 
 type HTTPServer struct {
 	ShutdownTimeout time.Duration
+	// OnShutdown вызывается до httpServer.Shutdown, пока соединения ещё не закрыты.
+	OnShutdown func()
 }
 
 func (h HTTPServer) Run(
@@ -24,9 +26,18 @@ func (h HTTPServer) Run(
 	serverError := make(chan error, 1)
 
 	go func() {
-		logger(ctx).Info("http server started", slog.String("address", httpServer.Addr))
+		logger(ctx).Info("http server started",
+			slog.String("address", httpServer.Addr),
+			slog.Bool("tls", httpServer.TLSConfig != nil),
+		)
 
-		err := httpServer.ListenAndServe()
+		var err error
+		if httpServer.TLSConfig != nil {
+			// сертификаты отдаёт TLSConfig.GetCertificate, поэтому пути не передаём
+			err = httpServer.ListenAndServeTLS("", "")
+		} else {
+			err = httpServer.ListenAndServe()
+		}
 		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			serverError <- fmt.Errorf("httpServer.ListenAndServe: %w", err)
 		}
@@ -36,6 +47,9 @@ func (h HTTPServer) Run(
 	select {
 	case <-ctx.Done():
 		logger(ctx).Info("shutting down http server", slog.String("address", httpServer.Addr))
+		if h.OnShutdown != nil {
+			h.OnShutdown()
+		}
 
 		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), h.ShutdownTimeout)
 		defer cancel()
@@ -51,3 +65,25 @@ func (h HTTPServer) Run(
 		return err
 	}
 }
+
+// RunAll запускает несколько серверов и гасит все остальные, если любой из них упал.
+func (h HTTPServer) RunAll(ctx context.Context, httpServers ...*http.Server) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, len(httpServers))
+	for _, srv := range httpServers {
+		go func() {
+			errs <- h.Run(ctx, srv)
+		}()
+	}
+
+	var result error
+	for range httpServers {
+		if err := <-errs; err != nil {
+			cancel()
+			result = errors.Join(result, err)
+		}
+	}
+	return result
+}
@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"ecom_test/pkg/contextx"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	MethodAPIKey = "api_key"
+
+	keyPrefix = "tk"
+)
+
+var (
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrKeyNotFound     = errors.New("api key not found")
+	ErrInvalidKey      = errors.New("invalid api key")
+)
+
+// APIKey метаданные ключа, сам ключ не хранится, только его SHA-256.
+// Ключ содержит 256 бит случайных данных, поэтому медленный KDF для него не нужен.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Subject    string     `json:"subject"`
+	Tenant     string     `json:"tenant,omitempty"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+type storedKey struct {
+	meta     APIKey
+	hash     [sha256.Size]byte
+	lastUsed atomic.Int64
+}
+
+type KeyStore struct {
+	mu   sync.RWMutex
+	keys map[string]*storedKey
+	now  func() time.Time
+}
+
+func NewKeyStore() *KeyStore {
+	return &KeyStore{
+		keys: make(map[string]*storedKey),
+		now:  time.Now,
+	}
+}
+
+// Mint создаёт ключ формата tk_<id>_<secret>, открытый текст возвращается только здесь.
+// Ключ с tenant действует только в этом арендаторе.
+func (s *KeyStore) Mint(name, subject, tenant string, scopes []string) (string, APIKey, error) {
+	var id [8]byte
+	var secret [32]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", APIKey{}, fmt.Errorf("rand.Read: %w", err)
+	}
+	if _, err := rand.Read(secret[:]); err != nil {
+		return "", APIKey{}, fmt.Errorf("rand.Read: %w", err)
+	}
+
+	keyID := hex.EncodeToString(id[:])
+	plaintext := keyPrefix + "_" + keyID + "_" + base64.RawURLEncoding.EncodeToString(secret[:])
+
+	key, err := s.Import(keyID, Hash(plaintext), name, subject, tenant, scopes)
+	if err != nil {
+		return "", APIKey{}, err
+	}
+	return plaintext, key, nil
+}
+
+// Import добавляет ключ по готовому хэшу, например из конфигурации.
+func (s *KeyStore) Import(keyID, hashHex, name, subject, tenant string, scopes []string) (APIKey, error) {
+	raw, err := hex.DecodeString(hashHex)
+	if err != nil || len(raw) != sha256.Size {
+		return APIKey{}, fmt.Errorf("key %s: %w", keyID, ErrInvalidKey)
+	}
+
+	stored := &storedKey{meta: APIKey{
+		ID:        keyID,
+		Name:      name,
+		Subject:   subject,
+		Tenant:    tenant,
+		Scopes:    append([]string(nil), scopes...),
+		CreatedAt: s.now(),
+	}}
+	copy(stored.hash[:], raw)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[keyID] = stored
+	return stored.snapshot(), nil
+}
+
+func (s *KeyStore) Revoke(keyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.keys[keyID]
+	if !ok {
+		return ErrKeyNotFound
+	}
+	if stored.meta.RevokedAt == nil {
+		now := s.now()
+		stored.meta.RevokedAt = &now
+	}
+	return nil
+}
+
+func (s *KeyStore) List() []APIKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]APIKey, 0, len(s.keys))
+	for _, stored := range s.keys {
+		keys = append(keys, stored.snapshot())
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	return keys
+}
+
+func (s *KeyStore) Authenticate(ctx context.Context, token string) (contextx.Principal, error) {
+	keyID, ok := ParseKeyID(token)
+	if !ok {
+		return contextx.Principal{}, ErrUnauthenticated
+	}
+
+	s.mu.RLock()
+	stored, ok := s.keys[keyID]
+	var revoked bool
+	if ok {
+		revoked = stored.meta.RevokedAt != nil
+	}
+	s.mu.RUnlock()
+
+	hash := sha256.Sum256([]byte(token))
+	if !ok || revoked || subtle.ConstantTimeCompare(hash[:], stored.hash[:]) != 1 {
+		return contextx.Principal{}, ErrUnauthenticated
+	}
+
+	stored.lastUsed.Store(s.now().UnixNano())
+	return contextx.Principal{
+		Subject: stored.meta.Subject,
+		Scopes:  stored.meta.Scopes,
+		Method:  MethodAPIKey,
+		KeyID:   keyID,
+		Tenant:  stored.meta.Tenant,
+	}, nil
+}
+
+func (k *storedKey) snapshot() APIKey {
+	meta := k.meta
+	meta.Scopes = append([]string(nil), k.meta.Scopes...)
+	if ns := k.lastUsed.Load(); ns != 0 {
+		t := time.Unix(0, ns)
+		meta.LastUsedAt = &t
+	}
+	return meta
+}
+
+// ParseKeyID достаёт идентификатор из ключа формата tk_<id>_<secret>.
+func ParseKeyID(token string) (string, bool) {
+	parts := strings.SplitN(token, "_", 3)
+	if len(parts) != 3 || parts[0] != keyPrefix || len(parts[1]) != 16 || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
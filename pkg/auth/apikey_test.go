@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyStore(t *testing.T) {
+	store := NewKeyStore()
+	ctx := context.Background()
+
+	plaintext, key, err := store.Mint("ci", "robot", "team-a", []string{"tasks:read"})
+	if err != nil {
+		t.Fatalf("Mint failed: %v", err)
+	}
+	if !strings.HasPrefix(plaintext, "tk_"+key.ID+"_") {
+		t.Errorf("Unexpected key format %q", plaintext)
+	}
+
+	listed, _ := json.Marshal(store.List())
+	if strings.Contains(string(listed), plaintext) || strings.Contains(string(listed), strings.Split(plaintext, "_")[2]) {
+		t.Errorf("Listing leaks key material: %s", listed)
+	}
+	if store.List()[0].LastUsedAt != nil {
+		t.Errorf("Expected unused key to have no last_used_at")
+	}
+
+	principal, err := store.Authenticate(ctx, plaintext)
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if principal.Subject != "robot" || principal.Tenant != "team-a" || !principal.HasScope("tasks:read") || principal.HasScope("tasks:write") {
+		t.Errorf("Unexpected principal: %+v", principal)
+	}
+	if store.List()[0].LastUsedAt == nil {
+		t.Errorf("Expected last_used_at to be tracked")
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "Garbage", token: "hello"},
+		{name: "Unknown id", token: "tk_0000000000000000_secret"},
+		{name: "Wrong secret", token: plaintext[:len(plaintext)-1] + "x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := store.Authenticate(ctx, tt.token); !errors.Is(err, ErrUnauthenticated) {
+				t.Errorf("Expected ErrUnauthenticated, got %v", err)
+			}
+		})
+	}
+
+	if err := store.Revoke(key.ID); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if _, err := store.Authenticate(ctx, plaintext); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("Expected revoked key to be rejected, got %v", err)
+	}
+	if err := store.Revoke("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func TestKeyStore_Import(t *testing.T) {
+	store := NewKeyStore()
+	store.now = func() time.Time { return time.Unix(0, 0) }
+
+	token := "tk_0123456789abcdef_bootstrap-secret"
+	if _, err := store.Import("0123456789abcdef", Hash(token), "boot", "admin", "", []string{"admin"}); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if _, err := store.Authenticate(context.Background(), token); err != nil {
+		t.Errorf("Expected imported key to authenticate, got %v", err)
+	}
+	if _, err := store.Import("x", "not-hex", "bad", "admin", "", nil); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey for bad hash, got %v", err)
+	}
+}
@@ -0,0 +1,350 @@
+// Package caldav сервер CalDAV (RFC 4791) для календарей задач поверх произвольного хранилища:
+// обнаружение через PROPFIND, отчёты calendar-query и calendar-multiget, GET, PUT и DELETE
+// объектов с ETag и CTag коллекции (расширение calendarserver.org, по нему клиенты решают,
+// нужна ли синхронизация). Каждый объект календаря один VCALENDAR с одним VTODO.
+package caldav
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"ecom_test/pkg/contextx"
+	"ecom_test/pkg/ical"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxRequestBody ограничение тела PROPFIND, REPORT и PUT.
+const maxRequestBody = 1 << 20
+
+// Ошибки Backend, которые Handler переводит в статусы: 404, 403 с предусловием valid-calendar-data,
+// 403, 409 и 412. Остальные ошибки дают 500 без подробностей.
+var (
+	ErrNotFound           = errors.New("caldav: not found")
+	ErrInvalidData        = errors.New("caldav: invalid calendar data")
+	ErrForbidden          = errors.New("caldav: forbidden")
+	ErrConflict           = errors.New("caldav: conflict")
+	ErrPreconditionFailed = errors.New("caldav: precondition failed")
+)
+
+// Calendar коллекция задач. Name сегмент пути, DisplayName видит пользователь.
+type Calendar struct {
+	Name        string
+	DisplayName string
+	Description string
+}
+
+// Object ресурс календаря, Name последний сегмент пути, обычно с расширением .ics.
+type Object struct {
+	Name string
+	Data *ical.Component
+}
+
+// Backend хранилище календарей текущего пользователя, которого определяет ctx.
+type Backend interface {
+	Calendars(ctx context.Context) ([]Calendar, error)
+	// Objects все объекты календаря, ErrNotFound для неизвестного календаря.
+	Objects(ctx context.Context, calendar string) ([]Object, error)
+	// Put создаёт объект или заменяет существующий с тем же именем и сообщает, создан ли он.
+	// Хранилище вправе нормализовать данные: следующий GET может вернуть не то, что было записано.
+	// cond проверяется по текущей версии объекта под тем же локом, что и запись, при
+	// несовпадении ErrPreconditionFailed.
+	Put(ctx context.Context, calendar, name string, data *ical.Component, cond Conditions) (created bool, err error)
+	// Delete как Put проверяет cond атомарно с удалением.
+	Delete(ctx context.Context, calendar, name string, cond Conditions) error
+}
+
+// Conditions заголовки If-Match и If-None-Match запроса. Сверять их отдельным чтением до записи
+// нельзя: два клиента с одним ETag оба прошли бы проверку, и второй затёр бы изменения первого.
+type Conditions struct {
+	IfMatch     string
+	IfNoneMatch string
+}
+
+func conditions(r *http.Request) Conditions {
+	return Conditions{IfMatch: r.Header.Get("If-Match"), IfNoneMatch: r.Header.Get("If-None-Match")}
+}
+
+// Met выполнены ли условия для текущих данных объекта, nil если объекта нет.
+func (c Conditions) Met(current *ical.Component) bool {
+	etag := ""
+	if current != nil {
+		_, etag = encode(current)
+	}
+	if c.IfMatch != "" && !matchETag(c.IfMatch, etag) {
+		return false
+	}
+	if c.IfNoneMatch != "" && matchETag(c.IfNoneMatch, etag) {
+		return false
+	}
+	return true
+}
+
+type Handler struct {
+	backend Backend
+	prefix  string
+}
+
+// NewHandler prefix путь, на котором смонтирован обработчик, например /dav. Под ним:
+// корень, prefix/principal/, домашняя коллекция prefix/calendars/ и календари в ней.
+func NewHandler(backend Backend, prefix string) *Handler {
+	return &Handler{backend: backend, prefix: strings.TrimSuffix(prefix, "/")}
+}
+
+// kind тип ресурса по пути.
+type kind int
+
+const (
+	kindRoot kind = iota
+	kindPrincipal
+	kindHome
+	kindCalendar
+	kindObject
+)
+
+type target struct {
+	kind     kind
+	calendar string
+	object   string
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	t, ok := h.parsePath(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodOptions:
+		w.Header().Set("DAV", "1, 3, calendar-access")
+		w.Header().Set("Allow", allow(t))
+		w.WriteHeader(http.StatusOK)
+	case "PROPFIND":
+		h.propfind(w, r, t)
+	case "REPORT":
+		h.report(w, r, t)
+	case http.MethodGet, http.MethodHead:
+		if t.kind != kindObject {
+			h.notAllowed(w, t)
+			return
+		}
+		h.get(w, r, t)
+	case http.MethodPut:
+		if t.kind != kindObject {
+			h.notAllowed(w, t)
+			return
+		}
+		h.put(w, r, t)
+	case http.MethodDelete:
+		if t.kind != kindObject {
+			h.notAllowed(w, t)
+			return
+		}
+		h.delete(w, r, t)
+	default:
+		h.notAllowed(w, t)
+	}
+}
+
+// parsePath сегменты пути уже раскодированы net/http.
+func (h *Handler) parsePath(p string) (target, bool) {
+	rel, ok := strings.CutPrefix(p, h.prefix)
+	if !ok || (rel != "" && rel[0] != '/') {
+		return target{}, false
+	}
+	rel = strings.Trim(rel, "/")
+	if rel == "" {
+		return target{kind: kindRoot}, true
+	}
+	parts := strings.Split(rel, "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "principal":
+		return target{kind: kindPrincipal}, true
+	case parts[0] != "calendars" || slices.Contains(parts, ""):
+		return target{}, false
+	case len(parts) == 1:
+		return target{kind: kindHome}, true
+	case len(parts) == 2:
+		return target{kind: kindCalendar, calendar: parts[1]}, true
+	case len(parts) == 3:
+		return target{kind: kindObject, calendar: parts[1], object: parts[2]}, true
+	}
+	return target{}, false
+}
+
+// href путь ресурса с экранированными сегментами, у коллекций с завершающим слешем.
+func (h *Handler) href(t target) string {
+	switch t.kind {
+	case kindPrincipal:
+		return h.prefix + "/principal/"
+	case kindHome:
+		return h.prefix + "/calendars/"
+	case kindCalendar:
+		return h.prefix + "/calendars/" + url.PathEscape(t.calendar) + "/"
+	case kindObject:
+		return h.prefix + "/calendars/" + url.PathEscape(t.calendar) + "/" + url.PathEscape(t.object)
+	}
+	return h.prefix + "/"
+}
+
+func allow(t target) string {
+	if t.kind == kindObject {
+		return "OPTIONS, PROPFIND, REPORT, GET, HEAD, PUT, DELETE"
+	}
+	return "OPTIONS, PROPFIND, REPORT"
+}
+
+func (h *Handler) notAllowed(w http.ResponseWriter, t target) {
+	w.Header().Set("Allow", allow(t))
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
+
+func (h *Handler) calendar(ctx context.Context, name string) (Calendar, error) {
+	calendars, err := h.backend.Calendars(ctx)
+	if err != nil {
+		return Calendar{}, err
+	}
+	for _, c := range calendars {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return Calendar{}, ErrNotFound
+}
+
+// object nil без ошибки, если календарь есть, а объекта в нём нет.
+func (h *Handler) object(ctx context.Context, t target) (*Object, error) {
+	objects, err := h.backend.Objects(ctx, t.calendar)
+	if err != nil {
+		return nil, err
+	}
+	for i := range objects {
+		if objects[i].Name == t.object {
+			return &objects[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request, t target) {
+	obj, err := h.object(r.Context(), t)
+	if err == nil && obj == nil {
+		err = ErrNotFound
+	}
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	data, etag := encode(obj.Data)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", etag)
+	http.ServeContent(w, r, t.object, time.Time{}, bytes.NewReader(data))
+}
+
+// put If-None-Match: * защищает от перезаписи при создании, If-Match от потери чужих изменений.
+// ETag в ответе нет: хранилище нормализует данные, и клиент должен перечитать объект.
+func (h *Handler) put(w http.ResponseWriter, r *http.Request, t target) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	if err != nil {
+		http.Error(w, "request body is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	data, err := ical.Decode(bytes.NewReader(body))
+	if err != nil || data.Name != "VCALENDAR" {
+		h.precondition(w, http.StatusForbidden, nsCalDAV, "valid-calendar-data")
+		return
+	}
+	for _, c := range data.Components {
+		if c.Name != "VTODO" && c.Name != "VTIMEZONE" {
+			h.precondition(w, http.StatusForbidden, nsCalDAV, "supported-calendar-component")
+			return
+		}
+	}
+	if len(data.Children("VTODO")) != 1 {
+		h.precondition(w, http.StatusForbidden, nsCalDAV, "valid-calendar-object-resource")
+		return
+	}
+
+	created, err := h.backend.Put(r.Context(), t.calendar, t.object, data, conditions(r))
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	if created {
+		w.Header().Set("Location", h.href(t))
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request, t target) {
+	if err := h.backend.Delete(r.Context(), t.calendar, t.object, conditions(r)); err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// matchETag список ETag через запятую или *; слабые ETag сравниваются как сильные.
+// Пустой etag означает, что ресурса нет, и не совпадает ни с чем.
+func matchETag(header, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// fail непредвиденные ошибки Backend пишутся в журнал запроса, клиент получает только статус.
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	case errors.Is(err, ErrInvalidData):
+		h.precondition(w, http.StatusForbidden, nsCalDAV, "valid-calendar-data")
+	case errors.Is(err, ErrForbidden):
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	case errors.Is(err, ErrConflict):
+		http.Error(w, http.StatusText(http.StatusConflict), http.StatusConflict)
+	case errors.Is(err, ErrPreconditionFailed):
+		w.WriteHeader(http.StatusPreconditionFailed)
+	default:
+		contextx.LoggerFromContextOrDefault(r.Context()).Error("caldav: " + err.Error())
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+const contentType = "text/calendar; charset=utf-8; component=VTODO"
+
+// encode данные объекта так, как их отдаёт GET, и их ETag.
+func encode(data *ical.Component) ([]byte, string) {
+	var buf bytes.Buffer
+	_ = ical.NewEncoder(&buf).Encode(data)
+	sum := sha256.Sum256(buf.Bytes())
+	return buf.Bytes(), `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// ctag меняется при добавлении, удалении и изменении любого объекта календаря.
+func ctag(objects []Object) string {
+	entries := make([]string, len(objects))
+	for i, obj := range objects {
+		_, etag := encode(obj.Data)
+		entries[i] = obj.Name + "\x00" + etag
+	}
+	slices.Sort(entries)
+	sum := sha256.Sum256([]byte(strings.Join(entries, "\n")))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,332 @@
+package caldav
+
+import (
+	"bufio"
+	"context"
+	"ecom_test/pkg/ical"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// memBackend календари в памяти; Put сохраняет данные как есть.
+type memBackend struct {
+	mu        sync.Mutex
+	calendars []Calendar
+	objects   map[string][]Object
+}
+
+func (b *memBackend) Calendars(context.Context) ([]Calendar, error) {
+	return b.calendars, nil
+}
+
+func (b *memBackend) Objects(_ context.Context, calendar string) ([]Object, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	objects, ok := b.objects[calendar]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return slices.Clone(objects), nil
+}
+
+func (b *memBackend) Put(_ context.Context, calendar, name string, data *ical.Component, cond Conditions) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	objects := b.objects[calendar]
+	i := slices.IndexFunc(objects, func(o Object) bool { return o.Name == name })
+	var current *ical.Component
+	if i >= 0 {
+		current = objects[i].Data
+	}
+	if !cond.Met(current) {
+		return false, ErrPreconditionFailed
+	}
+	if i >= 0 {
+		objects[i].Data = data
+		return false, nil
+	}
+	b.objects[calendar] = append(objects, Object{Name: name, Data: data})
+	return true, nil
+}
+
+func (b *memBackend) Delete(_ context.Context, calendar, name string, cond Conditions) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	i := slices.IndexFunc(b.objects[calendar], func(o Object) bool { return o.Name == name })
+	if i < 0 {
+		return ErrNotFound
+	}
+	if !cond.Met(b.objects[calendar][i].Data) {
+		return ErrPreconditionFailed
+	}
+	b.objects[calendar] = slices.Delete(b.objects[calendar], i, i+1)
+	return nil
+}
+
+func (b *memBackend) names(calendar string) []string {
+	var out []string
+	for _, o := range b.objects[calendar] {
+		out = append(out, o.Name)
+	}
+	return out
+}
+
+func mustDecode(t *testing.T, lines ...string) *ical.Component {
+	t.Helper()
+	c, err := ical.Decode(strings.NewReader(strings.Join(lines, "\r\n")))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	return c
+}
+
+func newBackend(t *testing.T) *memBackend {
+	return &memBackend{
+		calendars: []Calendar{
+			{Name: "inbox", DisplayName: "Входящие"},
+			{Name: "project-2", DisplayName: "Work", Description: "Рабочие задачи"},
+		},
+		objects: map[string][]Object{
+			"inbox": {
+				{Name: "buy-milk.ics", Data: mustDecode(t,
+					"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//test//EN",
+					"BEGIN:VTODO", "UID:milk@example.com", "DTSTAMP:19700101T000000Z", "SUMMARY:Купить молоко",
+					"STATUS:NEEDS-ACTION", "DUE:20261020T090000Z", "END:VTODO",
+					"END:VCALENDAR")},
+				{Name: "report.ics", Data: mustDecode(t,
+					"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//test//EN",
+					"BEGIN:VTODO", "UID:report@example.com", "DTSTAMP:20261001T120000Z", "SUMMARY:Отчёт",
+					"STATUS:COMPLETED", "COMPLETED:20261001T120000Z", "END:VTODO",
+					"END:VCALENDAR")},
+			},
+			"project-2": {},
+		},
+	}
+}
+
+// loadFixture записанный запрос клиента: строка запроса, заголовки, пустая строка и тело.
+// Content-Length в файлах нет, длина берётся по телу.
+func loadFixture(t *testing.T, name string) *http.Request {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, body, _ := strings.Cut(string(raw), "\n\n")
+	r, err := http.ReadRequest(bufio.NewReader(strings.NewReader(head + "\n\n")))
+	if err != nil {
+		t.Fatalf("ReadRequest(%s) error = %v", name, err)
+	}
+	r.Body = io.NopCloser(strings.NewReader(body))
+	r.ContentLength = int64(len(body))
+	return r
+}
+
+func TestHandler_Fixtures(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		status   int
+		contains []string
+		absent   []string
+		inbox    []string
+	}{
+		{
+			fixture: "options.http", status: http.StatusOK,
+		},
+		{
+			fixture: "apple_propfind_root.http", status: http.StatusMultiStatus,
+			contains: []string{
+				"<D:href>/dav/</D:href>",
+				"<D:current-user-principal><D:href>/dav/principal/</D:href></D:current-user-principal>",
+				"<D:resourcetype><D:collection></D:collection></D:resourcetype>",
+				"<D:principal-URL></D:principal-URL></D:prop><D:status>HTTP/1.1 404 Not Found</D:status>",
+			},
+		},
+		{
+			fixture: "apple_propfind_principal.http", status: http.StatusMultiStatus,
+			contains: []string{
+				"<C:calendar-home-set><D:href>/dav/calendars/</D:href></C:calendar-home-set>",
+				"<D:principal-URL><D:href>/dav/principal/</D:href></D:principal-URL>",
+				"<C:calendar-user-address-set></C:calendar-user-address-set><D:displayname></D:displayname><CS:email-address-set></CS:email-address-set></D:prop><D:status>HTTP/1.1 404 Not Found</D:status>",
+			},
+		},
+		{
+			fixture: "davx5_propfind_home.http", status: http.StatusMultiStatus,
+			contains: []string{
+				"<D:href>/dav/calendars/inbox/</D:href>",
+				"<D:displayname>Входящие</D:displayname>",
+				"<C:calendar-description>Рабочие задачи</C:calendar-description>",
+				`<C:supported-calendar-component-set><C:comp name="VTODO"></C:comp></C:supported-calendar-component-set>`,
+				"<D:resourcetype><D:collection></D:collection><C:calendar></C:calendar></D:resourcetype>",
+				"<CS:getctag>",
+				"<D:privilege><D:write></D:write></D:privilege>",
+				"<D:sync-token></D:sync-token>",
+				`<calendar-color xmlns="http://apple.com/ns/ical/"></calendar-color>`,
+			},
+		},
+		{
+			fixture: "davx5_propfind_calendar.http", status: http.StatusMultiStatus,
+			contains: []string{
+				"<D:href>/dav/calendars/inbox/buy-milk.ics</D:href>",
+				"<D:href>/dav/calendars/inbox/report.ics</D:href>",
+				"<D:getetag>&#34;",
+			},
+		},
+		{
+			fixture: "davx5_propfind_missing.http", status: http.StatusNotFound,
+		},
+		{
+			fixture: "davx5_sync_collection.http", status: http.StatusForbidden,
+			contains: []string{"<D:supported-report></D:supported-report>"},
+		},
+		{
+			fixture: "thunderbird_query.http", status: http.StatusMultiStatus,
+			contains: []string{"inbox/buy-milk.ics</D:href>", "inbox/report.ics</D:href>"},
+		},
+		{
+			fixture: "thunderbird_query_range.http", status: http.StatusMultiStatus,
+			contains: []string{"inbox/buy-milk.ics</D:href>"},
+			absent:   []string{"report.ics"},
+		},
+		{
+			fixture: "tasksorg_query_open.http", status: http.StatusMultiStatus,
+			contains: []string{"inbox/buy-milk.ics</D:href>"},
+			absent:   []string{"report.ics"},
+		},
+		{
+			fixture: "tasksorg_multiget.http", status: http.StatusMultiStatus,
+			contains: []string{
+				"<C:calendar-data>BEGIN:VCALENDAR&#xD;&#xA;VERSION:2.0&#xD;&#xA;",
+				"SUMMARY:Купить молоко",
+				"<D:getcontenttype>text/calendar; charset=utf-8; component=VTODO</D:getcontenttype>",
+				"<D:href>https://todo.example.com/dav/calendars/inbox/gone.ics</D:href><D:status>HTTP/1.1 404 Not Found</D:status>",
+			},
+		},
+		{
+			fixture: "davx5_put_new.http", status: http.StatusCreated,
+			inbox: []string{"buy-milk.ics", "report.ics", "5c1f6f0e-0b7e-4d3c-9c0b-1f2a3b4c5d6e.ics"},
+		},
+		{
+			fixture: "davx5_put_exists.http", status: http.StatusPreconditionFailed,
+		},
+		{
+			fixture: "thunderbird_put_stale.http", status: http.StatusPreconditionFailed,
+		},
+		{
+			fixture: "thunderbird_put_event.http", status: http.StatusForbidden,
+			contains: []string{"<C:supported-calendar-component></C:supported-calendar-component>"},
+		},
+		{
+			fixture: "apple_get.http", status: http.StatusOK,
+			contains: []string{"BEGIN:VTODO\r\nUID:milk@example.com\r\n"},
+		},
+		{
+			fixture: "apple_delete.http", status: http.StatusNoContent,
+			inbox: []string{"buy-milk.ics"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(strings.TrimSuffix(tt.fixture, ".http"), func(t *testing.T) {
+			backend := newBackend(t)
+			rec := httptest.NewRecorder()
+			NewHandler(backend, "/dav/").ServeHTTP(rec, loadFixture(t, tt.fixture))
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d\n%s", rec.Code, tt.status, rec.Body)
+			}
+			body := rec.Body.String()
+			for _, s := range tt.contains {
+				if !strings.Contains(body, s) {
+					t.Errorf("body does not contain %q\n%s", s, body)
+				}
+			}
+			for _, s := range tt.absent {
+				if strings.Contains(body, s) {
+					t.Errorf("body contains %q\n%s", s, body)
+				}
+			}
+			if tt.inbox == nil {
+				tt.inbox = []string{"buy-milk.ics", "report.ics"}
+			}
+			if got := backend.names("inbox"); !slices.Equal(got, tt.inbox) {
+				t.Errorf("inbox = %v, want %v", got, tt.inbox)
+			}
+		})
+	}
+}
+
+func TestHandler_Conditional(t *testing.T) {
+	backend := newBackend(t)
+	h := NewHandler(backend, "/dav")
+	serve := func(method, target string, header http.Header, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, strings.NewReader(body))
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	get := serve(http.MethodGet, "/dav/calendars/inbox/buy-milk.ics", nil, "")
+	etag := get.Header().Get("ETag")
+	if get.Code != http.StatusOK || etag == "" {
+		t.Fatalf("GET status = %d, ETag = %q", get.Code, etag)
+	}
+	if rec := serve(http.MethodGet, "/dav/calendars/inbox/buy-milk.ics", http.Header{"If-None-Match": {etag}}, ""); rec.Code != http.StatusNotModified {
+		t.Errorf("GET with current If-None-Match status = %d, want 304", rec.Code)
+	}
+
+	ctagBody := `<propfind xmlns="DAV:" xmlns:CS="http://calendarserver.org/ns/"><prop><CS:getctag/></prop></propfind>`
+	before := serve("PROPFIND", "/dav/calendars/inbox/", http.Header{"Depth": {"0"}}, ctagBody).Body.String()
+
+	updated := strings.Replace(get.Body.String(), "Купить молоко", "Купить молоко и хлеб", 1)
+	if rec := serve(http.MethodPut, "/dav/calendars/inbox/buy-milk.ics", http.Header{"If-Match": {etag}}, updated); rec.Code != http.StatusNoContent {
+		t.Fatalf("PUT with current If-Match status = %d, want 204\n%s", rec.Code, rec.Body)
+	}
+	if rec := serve(http.MethodPut, "/dav/calendars/inbox/buy-milk.ics", http.Header{"If-Match": {etag}}, updated); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("PUT with old If-Match status = %d, want 412", rec.Code)
+	}
+	if after := serve("PROPFIND", "/dav/calendars/inbox/", http.Header{"Depth": {"0"}}, ctagBody).Body.String(); after == before {
+		t.Errorf("getctag did not change after PUT:\n%s", after)
+	}
+	if rec := serve(http.MethodDelete, "/dav/calendars/inbox/buy-milk.ics", http.Header{"If-Match": {etag}}, ""); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("DELETE with old If-Match status = %d, want 412", rec.Code)
+	}
+	if rec := serve(http.MethodDelete, "/dav/calendars/inbox/missing.ics", nil, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("DELETE of missing object status = %d, want 404", rec.Code)
+	}
+
+	// условие проверяет Backend при записи, поэтому из клиентов с одним ETag проходит только один
+	etag = serve(http.MethodGet, "/dav/calendars/inbox/buy-milk.ics", nil, "").Header().Get("ETag")
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		passed int
+	)
+	for i := range 8 {
+		wg.Go(func() {
+			body := strings.Replace(updated, "и хлеб", "и хлеб "+strconv.Itoa(i), 1)
+			if serve(http.MethodPut, "/dav/calendars/inbox/buy-milk.ics", http.Header{"If-Match": {etag}}, body).Code == http.StatusNoContent {
+				mu.Lock()
+				passed++
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	if passed != 1 {
+		t.Errorf("%d concurrent PUTs with the same If-Match succeeded, want 1", passed)
+	}
+}
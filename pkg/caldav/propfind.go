@@ -0,0 +1,289 @@
+package caldav
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+)
+
+// propfindRequest пустое тело равносильно allprop.
+type propfindRequest struct {
+	XMLName  xml.Name  `xml:"DAV: propfind"`
+	AllProp  *struct{} `xml:"DAV: allprop"`
+	PropName *struct{} `xml:"DAV: propname"`
+	Prop     propNames `xml:"DAV: prop"`
+}
+
+// resource ресурс ответа. objects заполняется для календаря, только если понадобился CTag.
+type resource struct {
+	target
+	cal     Calendar
+	obj     *Object
+	objects []Object
+	loaded  bool
+}
+
+// knownProps свойства, которые умеет отдавать Handler, в порядке вывода allprop и propname.
+var knownProps = []xml.Name{
+	{Space: nsDAV, Local: "resourcetype"},
+	{Space: nsDAV, Local: "displayname"},
+	{Space: nsDAV, Local: "current-user-principal"},
+	{Space: nsDAV, Local: "principal-URL"},
+	{Space: nsCalDAV, Local: "calendar-home-set"},
+	{Space: nsCalDAV, Local: "calendar-description"},
+	{Space: nsCalDAV, Local: "supported-calendar-component-set"},
+	{Space: nsCS, Local: "getctag"},
+	{Space: nsDAV, Local: "getetag"},
+	{Space: nsDAV, Local: "getcontenttype"},
+	{Space: nsDAV, Local: "current-user-privilege-set"},
+	{Space: nsDAV, Local: "supported-report-set"},
+	{Space: nsCalDAV, Local: "calendar-data"},
+}
+
+// computed свойства, которые allprop не включает: они дорогие или RFC 3744 и RFC 4791 требуют
+// запрашивать их явно.
+var computed = map[xml.Name]bool{
+	{Space: nsDAV, Local: "current-user-privilege-set"}: true,
+	{Space: nsDAV, Local: "supported-report-set"}:       true,
+	{Space: nsCalDAV, Local: "calendar-data"}:           true,
+}
+
+// propfind Depth: infinity обрабатывается как 1: глубже календарей ресурсов нет.
+func (h *Handler) propfind(w http.ResponseWriter, r *http.Request, t target) {
+	body, err := readXML(w, r)
+	if err != nil {
+		http.Error(w, "request body is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	var req propfindRequest
+	if len(body) > 0 {
+		if err := xml.Unmarshal(body, &req); err != nil {
+			http.Error(w, "malformed PROPFIND body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	resources, err := h.collect(r.Context(), t, r.Header.Get("Depth") != "0")
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	responses := make([]response, 0, len(resources))
+	for _, res := range resources {
+		var resp response
+		switch {
+		case req.PropName != nil:
+			resp, err = h.propNameResponse(r.Context(), res)
+		case req.AllProp != nil || len(req.Prop) == 0:
+			resp, err = h.propResponse(r.Context(), res, nil)
+		default:
+			resp, err = h.propResponse(r.Context(), res, req.Prop)
+		}
+		if err != nil {
+			h.fail(w, r, err)
+			return
+		}
+		responses = append(responses, resp)
+	}
+	h.writeMultistatus(w, responses)
+}
+
+// collect ресурс t и, с children, его прямые потомки.
+func (h *Handler) collect(ctx context.Context, t target, children bool) ([]*resource, error) {
+	self := &resource{target: t}
+	out := []*resource{self}
+	switch t.kind {
+	case kindRoot:
+		if children {
+			out = append(out, &resource{target: target{kind: kindPrincipal}}, &resource{target: target{kind: kindHome}})
+		}
+	case kindHome:
+		if !children {
+			break
+		}
+		calendars, err := h.backend.Calendars(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range calendars {
+			out = append(out, &resource{target: target{kind: kindCalendar, calendar: c.Name}, cal: c})
+		}
+	case kindCalendar:
+		cal, err := h.calendar(ctx, t.calendar)
+		if err != nil {
+			return nil, err
+		}
+		self.cal = cal
+		if !children {
+			break
+		}
+		if err := h.loadObjects(ctx, self); err != nil {
+			return nil, err
+		}
+		for i := range self.objects {
+			obj := &self.objects[i]
+			out = append(out, &resource{target: target{kind: kindObject, calendar: t.calendar, object: obj.Name}, obj: obj})
+		}
+	case kindObject:
+		obj, err := h.object(ctx, t)
+		if err == nil && obj == nil {
+			err = ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		self.obj = obj
+	}
+	return out, nil
+}
+
+func (h *Handler) loadObjects(ctx context.Context, res *resource) error {
+	if res.loaded {
+		return nil
+	}
+	objects, err := h.backend.Objects(ctx, res.calendar)
+	if err != nil {
+		return err
+	}
+	res.objects, res.loaded = objects, true
+	return nil
+}
+
+// propResponse names nil означает allprop. Свойства, которых у ресурса нет, попадают в propstat 404.
+func (h *Handler) propResponse(ctx context.Context, res *resource, names []xml.Name) (response, error) {
+	all := names == nil
+	if all {
+		names = knownProps
+	}
+	var found, missing []element
+	for _, n := range names {
+		if all && computed[n] {
+			continue
+		}
+		value, ok, err := h.prop(ctx, res, n)
+		if err != nil {
+			return response{}, err
+		}
+		switch {
+		case ok:
+			found = append(found, value)
+		case !all:
+			missing = append(missing, element{XMLName: name(n.Space, n.Local)})
+		}
+	}
+	return propstats(h.href(res.target), found, missing), nil
+}
+
+// propNameResponse имена свойств ресурса без значений.
+func (h *Handler) propNameResponse(ctx context.Context, res *resource) (response, error) {
+	var found []element
+	for _, n := range knownProps {
+		_, ok, err := h.prop(ctx, res, n)
+		if err != nil {
+			return response{}, err
+		}
+		if ok {
+			found = append(found, element{XMLName: name(n.Space, n.Local)})
+		}
+	}
+	return propstats(h.href(res.target), found, nil), nil
+}
+
+func propstats(href string, found, missing []element) response {
+	resp := response{Href: href}
+	if len(found) > 0 || len(missing) == 0 {
+		resp.Propstats = append(resp.Propstats, propstat{Prop: prop{Props: found}, Status: status(http.StatusOK)})
+	}
+	if len(missing) > 0 {
+		resp.Propstats = append(resp.Propstats, propstat{Prop: prop{Props: missing}, Status: status(http.StatusNotFound)})
+	}
+	return resp
+}
+
+// prop значение свойства n ресурса, ok=false если такого свойства у ресурса нет.
+func (h *Handler) prop(ctx context.Context, res *resource, n xml.Name) (element, bool, error) {
+	collection := res.kind != kindObject
+	switch n {
+	case xml.Name{Space: nsDAV, Local: "resourcetype"}:
+		value := newElement(nsDAV, "resourcetype")
+		if collection {
+			value.Children = append(value.Children, newElement(nsDAV, "collection"))
+		}
+		switch res.kind {
+		case kindPrincipal:
+			value.Children = append(value.Children, newElement(nsDAV, "principal"))
+		case kindCalendar:
+			value.Children = append(value.Children, newElement(nsCalDAV, "calendar"))
+		}
+		return value, true, nil
+	case xml.Name{Space: nsDAV, Local: "displayname"}:
+		if res.kind == kindCalendar {
+			return textElement(nsDAV, "displayname", res.cal.DisplayName), true, nil
+		}
+	case xml.Name{Space: nsDAV, Local: "current-user-principal"}:
+		return newElement(nsDAV, "current-user-principal", hrefElement(h.href(target{kind: kindPrincipal}))), true, nil
+	case xml.Name{Space: nsDAV, Local: "principal-URL"}:
+		if res.kind == kindPrincipal {
+			return newElement(nsDAV, "principal-URL", hrefElement(h.href(res.target))), true, nil
+		}
+	case xml.Name{Space: nsCalDAV, Local: "calendar-home-set"}:
+		if res.kind == kindPrincipal {
+			return newElement(nsCalDAV, "calendar-home-set", hrefElement(h.href(target{kind: kindHome}))), true, nil
+		}
+	case xml.Name{Space: nsCalDAV, Local: "calendar-description"}:
+		if res.kind == kindCalendar && res.cal.Description != "" {
+			return textElement(nsCalDAV, "calendar-description", res.cal.Description), true, nil
+		}
+	case xml.Name{Space: nsCalDAV, Local: "supported-calendar-component-set"}:
+		if res.kind == kindCalendar {
+			comp := newElement(nsCalDAV, "comp")
+			comp.Attr = []xml.Attr{{Name: xml.Name{Local: "name"}, Value: "VTODO"}}
+			return newElement(nsCalDAV, "supported-calendar-component-set", comp), true, nil
+		}
+	case xml.Name{Space: nsCS, Local: "getctag"}:
+		if res.kind == kindCalendar {
+			if err := h.loadObjects(ctx, res); err != nil {
+				return element{}, false, err
+			}
+			return textElement(nsCS, "getctag", ctag(res.objects)), true, nil
+		}
+	case xml.Name{Space: nsDAV, Local: "getetag"}:
+		if res.obj != nil {
+			_, etag := encode(res.obj.Data)
+			return textElement(nsDAV, "getetag", etag), true, nil
+		}
+	case xml.Name{Space: nsDAV, Local: "getcontenttype"}:
+		if res.obj != nil {
+			return textElement(nsDAV, "getcontenttype", contentType), true, nil
+		}
+	case xml.Name{Space: nsDAV, Local: "current-user-privilege-set"}:
+		return privileges(res.kind == kindCalendar || res.kind == kindObject), true, nil
+	case xml.Name{Space: nsDAV, Local: "supported-report-set"}:
+		if res.kind == kindCalendar {
+			return newElement(nsDAV, "supported-report-set",
+				supportedReport(nsCalDAV, "calendar-query"),
+				supportedReport(nsCalDAV, "calendar-multiget"),
+			), true, nil
+		}
+	case xml.Name{Space: nsCalDAV, Local: "calendar-data"}:
+		if res.obj != nil {
+			data, _ := encode(res.obj.Data)
+			return textElement(nsCalDAV, "calendar-data", string(data)), true, nil
+		}
+	}
+	return element{}, false, nil
+}
+
+// privileges право записи в календари и объекты. Хватит ли его на самом деле, решает
+// авторизация перед Handler: ресурсы одинаковы для всех пользователей.
+func privileges(writable bool) element {
+	set := newElement(nsDAV, "current-user-privilege-set", newElement(nsDAV, "privilege", newElement(nsDAV, "read")))
+	if writable {
+		set.Children = append(set.Children, newElement(nsDAV, "privilege", newElement(nsDAV, "write")))
+	}
+	return set
+}
+
+func supportedReport(space, local string) element {
+	return newElement(nsDAV, "supported-report", newElement(nsDAV, "report", newElement(space, local)))
+}
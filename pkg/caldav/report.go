@@ -0,0 +1,262 @@
+package caldav
+
+import (
+	"context"
+	"ecom_test/pkg/ical"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type multigetRequest struct {
+	AllProp *struct{} `xml:"DAV: allprop"`
+	Prop    propNames `xml:"DAV: prop"`
+	Hrefs   []string  `xml:"DAV: href"`
+}
+
+type queryRequest struct {
+	AllProp *struct{}  `xml:"DAV: allprop"`
+	Prop    propNames  `xml:"DAV: prop"`
+	Filter  compFilter `xml:"urn:ietf:params:xml:ns:caldav filter>comp-filter"`
+}
+
+type compFilter struct {
+	Name         string       `xml:"name,attr"`
+	IsNotDefined *struct{}    `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TimeRange    *timeRange   `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	Props        []propFilter `xml:"urn:ietf:params:xml:ns:caldav prop-filter"`
+	Comps        []compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+type propFilter struct {
+	Name         string     `xml:"name,attr"`
+	IsNotDefined *struct{}  `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TimeRange    *timeRange `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	TextMatch    *textMatch `xml:"urn:ietf:params:xml:ns:caldav text-match"`
+}
+
+// textMatch сравнение всегда без учёта регистра, атрибут collation не учитывается.
+type textMatch struct {
+	Text   string `xml:",chardata"`
+	Negate string `xml:"negate-condition,attr"`
+}
+
+// timeRange границы в UTC вида 20261019T000000Z, любая может отсутствовать.
+type timeRange struct {
+	Start string `xml:"start,attr"`
+	End   string `xml:"end,attr"`
+}
+
+// report вид отчёта определяет корневой элемент тела. Неподдерживаемые, в том числе
+// sync-collection, получают 403 supported-report, и клиенты переходят на сравнение CTag.
+func (h *Handler) report(w http.ResponseWriter, r *http.Request, t target) {
+	body, err := readXML(w, r)
+	if err != nil {
+		http.Error(w, "request body is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	var probe struct{ XMLName xml.Name }
+	if err := xml.Unmarshal(body, &probe); err != nil {
+		http.Error(w, "malformed REPORT body", http.StatusBadRequest)
+		return
+	}
+
+	var responses []response
+	switch probe.XMLName {
+	case xml.Name{Space: nsCalDAV, Local: "calendar-query"}:
+		var req queryRequest
+		if err = xml.Unmarshal(body, &req); err != nil {
+			http.Error(w, "malformed calendar-query", http.StatusBadRequest)
+			return
+		}
+		if t.kind != kindCalendar {
+			h.precondition(w, http.StatusForbidden, nsDAV, "supported-report")
+			return
+		}
+		responses, err = h.query(r.Context(), t, req)
+	case xml.Name{Space: nsCalDAV, Local: "calendar-multiget"}:
+		var req multigetRequest
+		if err = xml.Unmarshal(body, &req); err != nil {
+			http.Error(w, "malformed calendar-multiget", http.StatusBadRequest)
+			return
+		}
+		responses, err = h.multiget(r.Context(), req)
+	default:
+		h.precondition(w, http.StatusForbidden, nsDAV, "supported-report")
+		return
+	}
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	h.writeMultistatus(w, responses)
+}
+
+func reportProps(allProp *struct{}, names propNames) []xml.Name {
+	if allProp != nil || len(names) == 0 {
+		return nil
+	}
+	return names
+}
+
+// query запрос без фильтра возвращает все объекты календаря.
+func (h *Handler) query(ctx context.Context, t target, req queryRequest) ([]response, error) {
+	objects, err := h.backend.Objects(ctx, t.calendar)
+	if err != nil {
+		return nil, err
+	}
+	names := reportProps(req.AllProp, req.Prop)
+	var responses []response
+	for i := range objects {
+		root := &ical.Component{Components: []*ical.Component{objects[i].Data}}
+		if req.Filter.Name != "" && !matchComp(root, req.Filter) {
+			continue
+		}
+		res := &resource{target: target{kind: kindObject, calendar: t.calendar, object: objects[i].Name}, obj: &objects[i]}
+		resp, err := h.propResponse(ctx, res, names)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, resp)
+	}
+	return responses, nil
+}
+
+// multiget href может быть абсолютным URL или путём; ненайденные объекты получают 404 в ответе,
+// а не ошибку всего отчёта.
+func (h *Handler) multiget(ctx context.Context, req multigetRequest) ([]response, error) {
+	names := reportProps(req.AllProp, req.Prop)
+	objects := make(map[string][]Object)
+	responses := make([]response, 0, len(req.Hrefs))
+	for _, href := range req.Hrefs {
+		notFound := response{Href: href, Status: status(http.StatusNotFound)}
+		u, err := url.Parse(strings.TrimSpace(href))
+		if err != nil {
+			responses = append(responses, notFound)
+			continue
+		}
+		t, ok := h.parsePath(u.Path)
+		if !ok || t.kind != kindObject {
+			responses = append(responses, notFound)
+			continue
+		}
+		list, loaded := objects[t.calendar]
+		if !loaded {
+			list, err = h.backend.Objects(ctx, t.calendar)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return nil, err
+			}
+			objects[t.calendar] = list
+		}
+		var obj *Object
+		for i := range list {
+			if list[i].Name == t.object {
+				obj = &list[i]
+			}
+		}
+		if obj == nil {
+			responses = append(responses, notFound)
+			continue
+		}
+		resp, err := h.propResponse(ctx, &resource{target: t, obj: obj}, names)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, resp)
+	}
+	return responses, nil
+}
+
+// matchComp фильтр f подходит, если среди компонентов parent с именем f.Name есть
+// удовлетворяющий всем вложенным условиям; is-not-defined требует, чтобы таких не было.
+func matchComp(parent *ical.Component, f compFilter) bool {
+	children := parent.Children(f.Name)
+	if f.IsNotDefined != nil {
+		return len(children) == 0
+	}
+	for _, c := range children {
+		if matchAll(c, f) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAll(c *ical.Component, f compFilter) bool {
+	if f.TimeRange != nil && !matchTodoRange(c, *f.TimeRange) {
+		return false
+	}
+	for _, pf := range f.Props {
+		if !matchProp(c, pf) {
+			return false
+		}
+	}
+	for _, cf := range f.Comps {
+		if !matchComp(c, cf) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchTodoRange упрощение RFC 4791, раздел 9.9: момент задачи DUE, без него COMPLETED, а задача
+// без обоих попадает в любой интервал.
+func matchTodoRange(c *ical.Component, tr timeRange) bool {
+	for _, field := range []string{"DUE", "COMPLETED"} {
+		if p, ok := c.Get(field); ok {
+			at, _, err := p.Time()
+			return err == nil && tr.contains(at)
+		}
+	}
+	return true
+}
+
+func matchProp(c *ical.Component, f propFilter) bool {
+	var props []ical.Property
+	for _, p := range c.Props {
+		if strings.EqualFold(p.Name, f.Name) {
+			props = append(props, p)
+		}
+	}
+	if f.IsNotDefined != nil {
+		return len(props) == 0
+	}
+	for _, p := range props {
+		if f.TimeRange != nil {
+			at, _, err := p.Time()
+			if err != nil || !f.TimeRange.contains(at) {
+				continue
+			}
+		}
+		if f.TextMatch != nil {
+			found := strings.Contains(strings.ToLower(ical.Unescape(p.Value)), strings.ToLower(f.TextMatch.Text))
+			if found == (f.TextMatch.Negate == "yes") {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// contains полуоткрытый интервал [start, end); неразборчивая граница считается отсутствующей.
+func (tr timeRange) contains(at time.Time) bool {
+	if start, err := parseUTC(tr.Start); err == nil && at.Before(start) {
+		return false
+	}
+	if end, err := parseUTC(tr.End); err == nil && !at.Before(end) {
+		return false
+	}
+	return true
+}
+
+func parseUTC(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, ical.ErrInvalidCalendar
+	}
+	at, _, err := ical.Property{Name: "time-range", Value: value}.Time()
+	return at, err
+}
@@ -0,0 +1,133 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	nsDAV    = "DAV:"
+	nsCalDAV = "urn:ietf:params:xml:ns:caldav"
+	nsCS     = "http://calendarserver.org/ns/"
+)
+
+// prefixes короткие префиксы известных пространств имён. encoding/xml не умеет выбирать префиксы
+// сам и объявляет пространство имён на каждом элементе, поэтому известные имена пишутся с
+// префиксом в Local, а объявления стоят один раз на корневом элементе ответа.
+var prefixes = map[string]string{
+	nsDAV:    "D",
+	nsCalDAV: "C",
+	nsCS:     "CS",
+}
+
+// name имя элемента для записи в ответ.
+func name(space, local string) xml.Name {
+	if prefix, ok := prefixes[space]; ok {
+		return xml.Name{Local: prefix + ":" + local}
+	}
+	return xml.Name{Space: space, Local: local}
+}
+
+// element произвольный элемент значения свойства.
+type element struct {
+	XMLName  xml.Name
+	Attr     []xml.Attr `xml:",any,attr"`
+	Text     string     `xml:",chardata"`
+	Children []element  `xml:",any"`
+}
+
+func newElement(space, local string, children ...element) element {
+	return element{XMLName: name(space, local), Children: children}
+}
+
+func textElement(space, local, text string) element {
+	return element{XMLName: name(space, local), Text: text}
+}
+
+func hrefElement(href string) element {
+	return textElement(nsDAV, "href", href)
+}
+
+type multistatus struct {
+	XMLName   xml.Name   `xml:"D:multistatus"`
+	NS        []xml.Attr `xml:",any,attr"`
+	Responses []response `xml:"D:response"`
+}
+
+// response либо Status для ресурса целиком, либо Propstats по группам свойств.
+type response struct {
+	Href      string     `xml:"D:href"`
+	Status    string     `xml:"D:status,omitempty"`
+	Propstats []propstat `xml:"D:propstat"`
+}
+
+type propstat struct {
+	Prop   prop   `xml:"D:prop"`
+	Status string `xml:"D:status"`
+}
+
+type prop struct {
+	Props []element `xml:",any"`
+}
+
+// davError тело ответа с нарушенным предусловием (RFC 4918, раздел 16).
+type davError struct {
+	XMLName   xml.Name   `xml:"D:error"`
+	NS        []xml.Attr `xml:",any,attr"`
+	Condition element
+}
+
+func namespaces() []xml.Attr {
+	return []xml.Attr{
+		{Name: xml.Name{Local: "xmlns:D"}, Value: nsDAV},
+		{Name: xml.Name{Local: "xmlns:C"}, Value: nsCalDAV},
+		{Name: xml.Name{Local: "xmlns:CS"}, Value: nsCS},
+	}
+}
+
+func status(code int) string {
+	return fmt.Sprintf("HTTP/1.1 %d %s", code, http.StatusText(code))
+}
+
+func (h *Handler) writeXML(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(code)
+	_, _ = io.WriteString(w, xml.Header)
+	_ = xml.NewEncoder(w).Encode(v)
+}
+
+func (h *Handler) writeMultistatus(w http.ResponseWriter, responses []response) {
+	h.writeXML(w, http.StatusMultiStatus, multistatus{NS: namespaces(), Responses: responses})
+}
+
+func (h *Handler) precondition(w http.ResponseWriter, code int, space, local string) {
+	h.writeXML(w, code, davError{NS: namespaces(), Condition: newElement(space, local)})
+}
+
+// propNames имена дочерних элементов DAV:prop, значения в запросе игнорируются.
+type propNames []xml.Name
+
+func (p *propNames) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			*p = append(*p, t.Name)
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// readXML тело запроса с ограничением размера; пустое тело не ошибка.
+func readXML(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	return io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBody))
+}
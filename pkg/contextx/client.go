@@ -0,0 +1,30 @@
+package contextx
+
+import (
+	"context"
+	"fmt"
+)
+
+type contextKeyClientIdentity struct{}
+
+// ClientIdentity описывает клиента, предъявившего сертификат при mTLS.
+type ClientIdentity struct {
+	CommonName   string
+	DNSNames     []string
+	Emails       []string
+	SerialNumber string
+	Fingerprint  string
+}
+
+func WithClientIdentity(ctx context.Context, identity ClientIdentity) context.Context {
+	return context.WithValue(ctx, contextKeyClientIdentity{}, identity)
+}
+
+func ClientIdentityFromContext(ctx context.Context) (ClientIdentity, error) {
+	identity, ok := ctx.Value(contextKeyClientIdentity{}).(ClientIdentity)
+	if !ok {
+		return ClientIdentity{}, fmt.Errorf("client identity: %w", ErrNoValue)
+	}
+
+	return identity, nil
+}
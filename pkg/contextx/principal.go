@@ -0,0 +1,42 @@
+package contextx
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+type contextKeyPrincipal struct{}
+
+const ScopeAll = "*"
+
+// Principal аутентифицированный субъект запроса.
+type Principal struct {
+	Subject string
+	Scopes  []string
+	// Method способ аутентификации: api_key, jwt или anonymous.
+	Method string
+	// KeyID идентификатор API-ключа, если аутентификация по ключу.
+	KeyID string
+	// Claims исходные claims токена, если аутентификация по JWT.
+	Claims map[string]any
+	// Tenant арендатор, к которому привязан субъект; пустой означает без привязки.
+	Tenant string
+}
+
+func (p Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope) || slices.Contains(p.Scopes, ScopeAll)
+}
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, contextKeyPrincipal{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (Principal, error) {
+	principal, ok := ctx.Value(contextKeyPrincipal{}).(Principal)
+	if !ok {
+		return Principal{}, fmt.Errorf("principal: %w", ErrNoValue)
+	}
+
+	return principal, nil
+}
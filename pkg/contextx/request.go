@@ -0,0 +1,44 @@
+package contextx
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type (
+	contextKeyRequestID   struct{}
+	contextKeyRequestMeta struct{}
+)
+
+type RequestMeta struct {
+	StartTime  time.Time
+	RemoteAddr string
+	UserAgent  string
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKeyRequestID{}, id)
+}
+
+func RequestIDFromContext(ctx context.Context) (string, error) {
+	id, ok := ctx.Value(contextKeyRequestID{}).(string)
+	if !ok {
+		return "", fmt.Errorf("request id: %w", ErrNoValue)
+	}
+
+	return id, nil
+}
+
+func WithRequestMeta(ctx context.Context, meta RequestMeta) context.Context {
+	return context.WithValue(ctx, contextKeyRequestMeta{}, meta)
+}
+
+func RequestMetaFromContext(ctx context.Context) (RequestMeta, error) {
+	meta, ok := ctx.Value(contextKeyRequestMeta{}).(RequestMeta)
+	if !ok {
+		return RequestMeta{}, fmt.Errorf("request meta: %w", ErrNoValue)
+	}
+
+	return meta, nil
+}
@@ -0,0 +1,21 @@
+package contextx
+
+import (
+	"context"
+	"fmt"
+)
+
+type contextKeyTenant struct{}
+
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, contextKeyTenant{}, tenantID)
+}
+
+func TenantFromContext(ctx context.Context) (string, error) {
+	tenantID, ok := ctx.Value(contextKeyTenant{}).(string)
+	if !ok {
+		return "", fmt.Errorf("tenant: %w", ErrNoValue)
+	}
+
+	return tenantID, nil
+}
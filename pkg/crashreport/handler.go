@@ -0,0 +1,39 @@
+package crashreport
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// RegisterRoutes подключает просмотр отчётов, монтировать только на admin-листенер
+// и только за проверкой прав администратора.
+func (s *Store) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/crashes", func(w http.ResponseWriter, r *http.Request) {
+		summaries, err := s.List()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal_server_error"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"crashes": summaries})
+	})
+
+	mux.HandleFunc("GET /admin/crashes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		report, err := s.Get(r.PathValue("id"))
+		if errors.Is(err, ErrNotFound) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "crash_report_not_found"})
+			return
+		}
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal_server_error"})
+			return
+		}
+		writeJSON(w, http.StatusOK, report)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
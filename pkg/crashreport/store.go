@@ -0,0 +1,198 @@
+package crashreport
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const fileSuffix = ".json"
+
+var ErrNotFound = errors.New("crash report not found")
+
+type Report struct {
+	ID         string              `json:"id"`
+	Time       time.Time           `json:"time"`
+	RequestID  string              `json:"request_id,omitempty"`
+	Method     string              `json:"method"`
+	URL        string              `json:"url"`
+	Route      string              `json:"route,omitempty"`
+	RemoteAddr string              `json:"remote_addr,omitempty"`
+	Headers    map[string][]string `json:"headers,omitempty"`
+	Panic      string              `json:"panic"`
+	Stack      string              `json:"stack"`
+	Goroutines string              `json:"goroutines,omitempty"`
+}
+
+type Summary struct {
+	ID        string    `json:"id"`
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"request_id,omitempty"`
+	Method    string    `json:"method"`
+	URL       string    `json:"url"`
+	Panic     string    `json:"panic"`
+}
+
+// Store хранит отчёты о падениях в каталоге, держа не больше maxReports самых свежих файлов.
+type Store struct {
+	dir        string
+	maxReports int
+	mu         sync.Mutex
+}
+
+func NewStore(dir string, maxReports int) (*Store, error) {
+	if maxReports <= 0 {
+		maxReports = 100
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("os.MkdirAll: %w", err)
+	}
+	return &Store{dir: dir, maxReports: maxReports}, nil
+}
+
+func (s *Store) Save(report Report) (string, error) {
+	var suffix [4]byte
+	_, _ = rand.Read(suffix[:])
+	// время в начале имени даёт сортировку файлов по возрасту без чтения содержимого
+	report.ID = strconv.FormatInt(report.Time.UnixNano(), 10) + "-" + hex.EncodeToString(suffix[:])
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("os.CreateTemp: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("write: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("close: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(report.ID)); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("os.Rename: %w", err)
+	}
+
+	return report.ID, s.prune()
+}
+
+func (s *Store) prune() error {
+	ids, err := s.ids()
+	if err != nil {
+		return err
+	}
+	for len(ids) > s.maxReports {
+		if err := os.Remove(s.path(ids[0])); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("os.Remove: %w", err)
+		}
+		ids = ids[1:]
+	}
+	return nil
+}
+
+// ids возвращает идентификаторы от старых к новым.
+func (s *Store) ids() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadDir: %w", err)
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, fileSuffix) || !validID(strings.TrimSuffix(name, fileSuffix)) {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(name, fileSuffix))
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if len(ids[i]) != len(ids[j]) {
+			return len(ids[i]) < len(ids[j])
+		}
+		return ids[i] < ids[j]
+	})
+	return ids, nil
+}
+
+// List возвращает краткие сводки от новых к старым.
+func (s *Store) List() ([]Summary, error) {
+	s.mu.Lock()
+	ids, err := s.ids()
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]Summary, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- {
+		report, err := s.Get(ids[i])
+		if err != nil {
+			continue
+		}
+		summaries = append(summaries, Summary{
+			ID:        report.ID,
+			Time:      report.Time,
+			RequestID: report.RequestID,
+			Method:    report.Method,
+			URL:       report.URL,
+			Panic:     report.Panic,
+		})
+	}
+	return summaries, nil
+}
+
+func (s *Store) Get(id string) (Report, error) {
+	if !validID(id) {
+		return Report{}, ErrNotFound
+	}
+
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return Report{}, ErrNotFound
+	}
+	if err != nil {
+		return Report{}, fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return Report{}, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	return report, nil
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+fileSuffix)
+}
+
+// validID защищает от выхода за пределы каталога через идентификатор из URL.
+func validID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') && c != '-' {
+			return false
+		}
+	}
+	return true
+}
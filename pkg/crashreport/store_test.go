@@ -0,0 +1,38 @@
+package crashreport
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStore_KeepsNewestReports(t *testing.T) {
+	store, err := NewStore(t.TempDir(), 2)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+
+	base := time.Now()
+	var ids []string
+	for i := 0; i < 3; i++ {
+		id, err := store.Save(Report{Time: base.Add(time.Duration(i) * time.Second), Panic: "boom"})
+		if err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	summaries, err := store.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(summaries) != 2 || summaries[0].ID != ids[2] || summaries[1].ID != ids[1] {
+		t.Errorf("Expected two newest reports newest first, got %+v", summaries)
+	}
+	if _, err := store.Get(ids[0]); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected oldest report to be pruned, got %v", err)
+	}
+	if _, err := store.Get("../../etc/passwd"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected traversal id to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// DirWritable проверяет что в каталог можно записать файл (например каталог WAL).
+func DirWritable(dir string) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		f, err := os.CreateTemp(dir, ".healthcheck-*")
+		if err != nil {
+			return fmt.Errorf("os.CreateTemp: %w", err)
+		}
+		name := f.Name()
+		defer os.Remove(name)
+
+		if _, err := f.Write([]byte("ok")); err != nil {
+			f.Close()
+			return fmt.Errorf("write: %w", err)
+		}
+		if err := f.Sync(); err != nil {
+			f.Close()
+			return fmt.Errorf("sync: %w", err)
+		}
+		return f.Close()
+	})
+}
+
+// Threshold падает когда значение (например глубина очереди) достигает порога.
+func Threshold(value func() int, limit int) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		if v := value(); v >= limit {
+			return fmt.Errorf("value %d exceeds threshold %d", v, limit)
+		}
+		return nil
+	})
+}
+
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+func Ping(p Pinger) Checker {
+	return CheckerFunc(p.Ping)
+}
@@ -0,0 +1,212 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+var (
+	ErrShuttingDown = errors.New("shutting down")
+	ErrCheckTimeout = errors.New("check timed out")
+)
+
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+type check struct {
+	name    string
+	checker Checker
+	timeout time.Duration
+}
+
+type Registry struct {
+	mu             sync.RWMutex
+	checks         map[string]check
+	defaultTimeout time.Duration
+	shuttingDown   atomic.Bool
+	startedAt      time.Time
+}
+
+func NewRegistry(defaultTimeout time.Duration) *Registry {
+	if defaultTimeout <= 0 {
+		defaultTimeout = time.Second
+	}
+	return &Registry{
+		checks:         make(map[string]check),
+		defaultTimeout: defaultTimeout,
+		startedAt:      time.Now(),
+	}
+}
+
+// Register добавляет проверку готовности, timeout <= 0 означает таймаут по умолчанию.
+func (r *Registry) Register(name string, checker Checker, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = r.defaultTimeout
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = check{name: name, checker: checker, timeout: timeout}
+}
+
+// Shutdown переводит readiness в состояние fail, liveness при этом не меняется.
+func (r *Registry) Shutdown() {
+	r.shuttingDown.Store(true)
+}
+
+func (r *Registry) ShuttingDown() bool {
+	return r.shuttingDown.Load()
+}
+
+type CheckResult struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string        `json:"status"`
+	Uptime string        `json:"uptime,omitempty"`
+	Error  string        `json:"error,omitempty"`
+	Checks []CheckResult `json:"checks,omitempty"`
+}
+
+func (r *Registry) Ready(ctx context.Context) Report {
+	report := Report{
+		Status: StatusOK,
+		Uptime: time.Since(r.startedAt).Round(time.Second).String(),
+	}
+	if r.ShuttingDown() {
+		report.Status = StatusFail
+		report.Error = ErrShuttingDown.Error()
+	}
+
+	r.mu.RLock()
+	checks := make([]check, 0, len(r.checks))
+	for _, c := range r.checks {
+		checks = append(checks, c)
+	}
+	r.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = runCheck(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	for _, res := range results {
+		if res.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	report.Checks = results
+	return report
+}
+
+// runCheck не доверяет проверке соблюдать контекст, поэтому таймаут обеспечивается снаружи.
+func runCheck(ctx context.Context, c check) (res CheckResult) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("check panicked: %v", p)
+			}
+		}()
+		done <- c.checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ErrCheckTimeout
+	}
+
+	res = CheckResult{
+		Name:     c.name,
+		Status:   StatusOK,
+		Duration: time.Since(start).String(),
+	}
+	if err != nil {
+		res.Status = StatusFail
+		res.Error = err.Error()
+	}
+	return res
+}
+
+func (r *Registry) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !allowedMethod(w, req) {
+			return
+		}
+
+		report := Report{Status: StatusOK}
+		if req.URL.Query().Has("verbose") {
+			report.Uptime = time.Since(r.startedAt).Round(time.Second).String()
+		}
+		writeReport(w, http.StatusOK, report)
+	})
+}
+
+func (r *Registry) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !allowedMethod(w, req) {
+			return
+		}
+
+		report := r.Ready(req.Context())
+		status := http.StatusOK
+		if report.Status != StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+		if !req.URL.Query().Has("verbose") {
+			report = Report{Status: report.Status}
+		}
+		writeReport(w, status, report)
+	})
+}
+
+func allowedMethod(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	w.WriteHeader(http.StatusMethodNotAllowed)
+	return false
+}
+
+func writeReport(w http.ResponseWriter, status int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(report)
+}
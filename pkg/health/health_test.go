@@ -0,0 +1,101 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRegistry_Ready(t *testing.T) {
+	tests := []struct {
+		name       string
+		checker    Checker
+		shutdown   bool
+		wantStatus string
+		wantErr    string
+	}{
+		{
+			name:       "All checks pass",
+			checker:    CheckerFunc(func(ctx context.Context) error { return nil }),
+			wantStatus: StatusOK,
+		},
+		{
+			name:       "Check fails",
+			checker:    CheckerFunc(func(ctx context.Context) error { return errors.New("boom") }),
+			wantStatus: StatusFail,
+			wantErr:    "boom",
+		},
+		{
+			name: "Check ignores context and times out",
+			checker: CheckerFunc(func(ctx context.Context) error {
+				time.Sleep(time.Second)
+				return nil
+			}),
+			wantStatus: StatusFail,
+			wantErr:    ErrCheckTimeout.Error(),
+		},
+		{
+			name:       "Shutdown started",
+			checker:    CheckerFunc(func(ctx context.Context) error { return nil }),
+			shutdown:   true,
+			wantStatus: StatusFail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry(10 * time.Millisecond)
+			r.Register("dep", tt.checker, 0)
+			if tt.shutdown {
+				r.Shutdown()
+			}
+
+			report := r.Ready(context.Background())
+			if report.Status != tt.wantStatus {
+				t.Errorf("Ready() status = %s, want %s", report.Status, tt.wantStatus)
+			}
+			if len(report.Checks) != 1 {
+				t.Fatalf("Expected 1 check result, got %d", len(report.Checks))
+			}
+			if report.Checks[0].Error != tt.wantErr {
+				t.Errorf("Check error = %q, want %q", report.Checks[0].Error, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegistry_ReadinessHandler(t *testing.T) {
+	r := NewRegistry(time.Second)
+	r.Register("threshold", Threshold(func() int { return 1 }, 10), 0)
+
+	rec := httptest.NewRecorder()
+	r.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz?verbose", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+
+	var report Report
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if len(report.Checks) != 1 || report.Checks[0].Name != "threshold" {
+		t.Errorf("Expected verbose report with threshold check, got %+v", report)
+	}
+
+	r.Shutdown()
+	rec = httptest.NewRecorder()
+	r.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 after shutdown, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	r.LivenessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected liveness 200 after shutdown, got %d", rec.Code)
+	}
+}
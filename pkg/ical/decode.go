@@ -0,0 +1,249 @@
+// Package ical чтение и запись iCalendar (RFC 5545) в объёме, нужном для задач: компоненты,
+// свойства с параметрами, экранирование текста, свёртка строк и даты.
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxLineLength ограничение развёрнутой строки, защищает от файла без переводов строк.
+const maxLineLength = 1 << 20
+
+var ErrInvalidCalendar = errors.New("invalid iCalendar data")
+
+// Component BEGIN:NAME … END:NAME. Line номер строки BEGIN в файле, от единицы.
+type Component struct {
+	Name       string
+	Line       int
+	Props      []Property
+	Components []*Component
+}
+
+// Property Value хранится как в файле, текст без экранирования отдаёт Text.
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Get первое свойство с таким именем, имена сравниваются без учёта регистра.
+func (c *Component) Get(name string) (Property, bool) {
+	for _, p := range c.Props {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return Property{}, false
+}
+
+// Text значение текстового свойства без экранирования, пустая строка если свойства нет.
+func (c *Component) Text(name string) string {
+	p, ok := c.Get(name)
+	if !ok {
+		return ""
+	}
+	return Unescape(p.Value)
+}
+
+// Children вложенные компоненты с таким именем.
+func (c *Component) Children(name string) []*Component {
+	var out []*Component
+	for _, child := range c.Components {
+		if strings.EqualFold(child.Name, name) {
+			out = append(out, child)
+		}
+	}
+	return out
+}
+
+// Decode читает один объект верхнего уровня, обычно VCALENDAR.
+func Decode(r io.Reader) (*Component, error) {
+	lines := newUnfolder(r)
+
+	var (
+		root  *Component
+		stack []*Component
+	)
+	for {
+		line, num, err := lines.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		prop, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidCalendar, num, err)
+		}
+		switch {
+		case strings.EqualFold(prop.Name, "BEGIN"):
+			if root != nil && len(stack) == 0 {
+				return nil, fmt.Errorf("%w: line %d: data after END:%s", ErrInvalidCalendar, num, root.Name)
+			}
+			c := &Component{Name: strings.ToUpper(prop.Value), Line: num}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Components = append(parent.Components, c)
+			} else {
+				root = c
+			}
+			stack = append(stack, c)
+		case strings.EqualFold(prop.Name, "END"):
+			if len(stack) == 0 || !strings.EqualFold(stack[len(stack)-1].Name, prop.Value) {
+				return nil, fmt.Errorf("%w: line %d: unexpected END:%s", ErrInvalidCalendar, num, prop.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("%w: line %d: property %s outside of a component", ErrInvalidCalendar, num, prop.Name)
+			}
+			c := stack[len(stack)-1]
+			c.Props = append(c.Props, prop)
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("%w: no BEGIN line", ErrInvalidCalendar)
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("%w: missing END:%s", ErrInvalidCalendar, stack[len(stack)-1].Name)
+	}
+	return root, nil
+}
+
+// unfolder склеивает свёрнутые строки: продолжение начинается с пробела или табуляции.
+type unfolder struct {
+	scanner *bufio.Scanner
+	num     int
+	pending string
+	start   int
+	has     bool
+}
+
+func newUnfolder(r io.Reader) *unfolder {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+	return &unfolder{scanner: scanner}
+}
+
+// next возвращает развёрнутую строку и номер её первой физической строки.
+func (u *unfolder) next() (string, int, error) {
+	for u.scanner.Scan() {
+		u.num++
+		raw := strings.TrimSuffix(u.scanner.Text(), "\r")
+		if u.has && (strings.HasPrefix(raw, " ") || strings.HasPrefix(raw, "\t")) {
+			if len(u.pending)+len(raw) > maxLineLength {
+				return "", 0, fmt.Errorf("%w: line %d is too long", ErrInvalidCalendar, u.start)
+			}
+			u.pending += raw[1:]
+			continue
+		}
+		line, start, had := u.pending, u.start, u.has
+		u.pending, u.start, u.has = raw, u.num, true
+		if had {
+			return line, start, nil
+		}
+	}
+	if err := u.scanner.Err(); err != nil {
+		return "", 0, fmt.Errorf("%w: %w", ErrInvalidCalendar, err)
+	}
+	if u.has {
+		u.has = false
+		return u.pending, u.start, nil
+	}
+	return "", 0, io.EOF
+}
+
+// parseLine NAME *(;PARAM=VALUE) : VALUE, значения параметров могут быть в кавычках с : и ; внутри.
+func parseLine(line string) (Property, error) {
+	end := strings.IndexAny(line, ";:")
+	if end <= 0 {
+		return Property{}, fmt.Errorf("expected NAME:VALUE, got %q", line)
+	}
+	prop := Property{Name: strings.ToUpper(line[:end])}
+	rest := line[end:]
+
+	for strings.HasPrefix(rest, ";") {
+		rest = rest[1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return Property{}, fmt.Errorf("malformed parameter in %s", prop.Name)
+		}
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			closing := strings.IndexByte(rest[1:], '"')
+			if closing < 0 {
+				return Property{}, fmt.Errorf("unterminated quoted parameter %s", name)
+			}
+			value, rest = rest[1:closing+1], rest[closing+2:]
+		} else {
+			stop := strings.IndexAny(rest, ";:")
+			if stop < 0 {
+				return Property{}, fmt.Errorf("missing value of %s", prop.Name)
+			}
+			value, rest = rest[:stop], rest[stop:]
+		}
+		if prop.Params == nil {
+			prop.Params = make(map[string]string)
+		}
+		prop.Params[name] = value
+	}
+
+	if !strings.HasPrefix(rest, ":") {
+		return Property{}, fmt.Errorf("missing value of %s", prop.Name)
+	}
+	prop.Value = rest[1:]
+	return prop, nil
+}
+
+// Unescape обратное к Escape: \n, \, \; и \\.
+func Unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// SplitList значения через запятую с учётом экранированных запятых, как в CATEGORIES.
+func SplitList(value string) []string {
+	var (
+		out  []string
+		from int
+	)
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case ',':
+			out = append(out, Unescape(value[from:i]))
+			from = i + 1
+		}
+	}
+	return append(out, Unescape(value[from:]))
+}
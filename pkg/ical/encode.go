@@ -0,0 +1,144 @@
+package ical
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineOctets длина строки без CRLF, после которой RFC 5545 требует свёртку.
+const maxLineOctets = 75
+
+// Encoder пишет компоненты и свойства построчно с CRLF и свёрткой длинных строк. Первая ошибка
+// записи запоминается, последующие вызовы ничего не пишут и возвращают её.
+type Encoder struct {
+	w   io.Writer
+	buf []byte
+	err error
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+func (e *Encoder) Begin(name string) error {
+	return e.line("BEGIN:" + name)
+}
+
+func (e *Encoder) End(name string) error {
+	return e.line("END:" + name)
+}
+
+// WriteProperty Value пишется как есть, текст нужно экранировать Escape или собрать через TextProperty.
+// Параметры выводятся по алфавиту, значения с : ; или , берутся в кавычки.
+func (e *Encoder) WriteProperty(p Property) error {
+	var b strings.Builder
+	b.WriteString(p.Name)
+	keys := make([]string, 0, len(p.Params))
+	for k := range p.Params {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		b.WriteByte(';')
+		b.WriteString(k)
+		b.WriteByte('=')
+		if v := p.Params[k]; strings.ContainsAny(v, ":;,") {
+			b.WriteString(`"` + strings.ReplaceAll(v, `"`, "") + `"`)
+		} else {
+			b.WriteString(v)
+		}
+	}
+	b.WriteByte(':')
+	b.WriteString(p.Value)
+	return e.line(b.String())
+}
+
+// Encode компонент целиком с вложенными компонентами.
+func (e *Encoder) Encode(c *Component) error {
+	_ = e.Begin(c.Name)
+	for _, p := range c.Props {
+		_ = e.WriteProperty(p)
+	}
+	for _, child := range c.Components {
+		_ = e.Encode(child)
+	}
+	return e.End(c.Name)
+}
+
+// line сворачивает строку по 75 октетов, не разрывая символы UTF-8: продолжение начинается с пробела.
+func (e *Encoder) line(s string) error {
+	if e.err != nil {
+		return e.err
+	}
+	e.buf = e.buf[:0]
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		e.buf = append(e.buf, s[:cut]...)
+		e.buf = append(e.buf, "\r\n "...)
+		s = s[cut:]
+		limit = maxLineOctets - 1
+	}
+	e.buf = append(e.buf, s...)
+	e.buf = append(e.buf, "\r\n"...)
+	_, e.err = e.w.Write(e.buf)
+	return e.err
+}
+
+// Escape экранирует текстовое значение: \, ; , и перевод строки. Возврат каретки отбрасывается.
+func Escape(s string) string {
+	if !strings.ContainsAny(s, "\\;,\n\r") {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\\', ';', ',':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// JoinList обратное к SplitList.
+func JoinList(values []string) string {
+	escaped := make([]string, len(values))
+	for i, v := range values {
+		escaped[i] = Escape(v)
+	}
+	return strings.Join(escaped, ",")
+}
+
+func TextProperty(name, text string) Property {
+	return Property{Name: name, Value: Escape(text)}
+}
+
+// DateTimeProperty значение в UTC с суффиксом Z.
+func DateTimeProperty(name string, t time.Time) Property {
+	return Property{Name: name, Value: FormatDateTime(t)}
+}
+
+// DateProperty VALUE=DATE, календарный день t в UTC.
+func DateProperty(name string, t time.Time) Property {
+	return Property{Name: name, Params: map[string]string{"VALUE": "DATE"}, Value: FormatDate(t)}
+}
+
+func FormatDateTime(t time.Time) string {
+	return t.UTC().Format(dateTimeLayout) + "Z"
+}
+
+func FormatDate(t time.Time) string {
+	return t.UTC().Format(dateLayout)
+}
@@ -0,0 +1,136 @@
+package ical
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestDecode(t *testing.T) {
+	src := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VTODO",
+		"UID:1@example.com",
+		"SUMMARY:Buy milk\\, bread and a very long line that was folded by the client",
+		"  into two physical lines",
+		"DESCRIPTION;LANGUAGE=ru:Строка\\nвторая",
+		`X-NOTE;X-LABEL="a:b;c":value`,
+		"CATEGORIES:home,shop\\,ping",
+		"DUE;TZID=Europe/Moscow:20261025T150000",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"SUMMARY:Second",
+		"DUE;VALUE=DATE:20261101",
+		"END:VTODO",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+
+	cal, err := Decode(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	todos := cal.Children("VTODO")
+	if cal.Name != "VCALENDAR" || len(todos) != 2 {
+		t.Fatalf("unexpected structure: %s with %d todos", cal.Name, len(todos))
+	}
+
+	first := todos[0]
+	if first.Line != 3 || todos[1].Line != 12 {
+		t.Errorf("Line = %d, %d, want 3, 12", first.Line, todos[1].Line)
+	}
+	if got, want := first.Text("summary"), "Buy milk, bread and a very long line that was folded by the client into two physical lines"; got != want {
+		t.Errorf("SUMMARY = %q, want %q", got, want)
+	}
+	if got := first.Text("DESCRIPTION"); got != "Строка\nвторая" {
+		t.Errorf("DESCRIPTION = %q", got)
+	}
+	if note, _ := first.Get("X-NOTE"); note.Params["X-LABEL"] != "a:b;c" || note.Value != "value" {
+		t.Errorf("X-NOTE = %+v", note)
+	}
+	cats, _ := first.Get("CATEGORIES")
+	if got := SplitList(cats.Value); !slices.Equal(got, []string{"home", "shop,ping"}) {
+		t.Errorf("CATEGORIES = %q", got)
+	}
+
+	due, _ := first.Get("DUE")
+	at, allDay, err := due.Time()
+	if err != nil || allDay || !at.Equal(time.Date(2026, 10, 25, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("DUE = %v %v %v", at, allDay, err)
+	}
+	due, _ = todos[1].Get("DUE")
+	at, allDay, err = due.Time()
+	if err != nil || !allDay || !at.Equal(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("DUE = %v %v %v", at, allDay, err)
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{name: "Empty", src: "", want: "no BEGIN"},
+		{name: "Unclosed", src: "BEGIN:VCALENDAR\nBEGIN:VTODO\nEND:VCALENDAR\n", want: "line 3: unexpected END:VCALENDAR"},
+		{name: "Missing END", src: "BEGIN:VCALENDAR\n", want: "missing END:VCALENDAR"},
+		{name: "No colon", src: "BEGIN:VCALENDAR\nSUMMARY\nEND:VCALENDAR\n", want: "line 2"},
+		{name: "Outside", src: "SUMMARY:x\n", want: "outside of a component"},
+		{name: "Unterminated param", src: "BEGIN:VCALENDAR\nX;A=\"b:c\nEND:VCALENDAR\n", want: "unterminated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(strings.NewReader(tt.src))
+			if !errors.Is(err, ErrInvalidCalendar) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Decode() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	summary := "Купить молоко; хлеб, сыр\nи " + strings.Repeat("очень длинную строку ", 5)
+	cal := &Component{Name: "VCALENDAR", Components: []*Component{{
+		Name: "VTODO",
+		Props: []Property{
+			TextProperty("SUMMARY", summary),
+			{Name: "CATEGORIES", Value: JoinList([]string{"home", "shop,ping"})},
+			{Name: "X-NOTE", Params: map[string]string{"X-LABEL": "a:b", "ALTREP": "x"}, Value: "v"},
+			DateTimeProperty("DUE", time.Date(2026, 10, 25, 15, 0, 0, 0, time.FixedZone("MSK", 3*3600))),
+			DateProperty("DTSTART", time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)),
+		},
+	}}}
+
+	var b strings.Builder
+	if err := NewEncoder(&b).Encode(cal); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	out := b.String()
+	for i, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets || !utf8.ValidString(line) {
+			t.Errorf("line %d is not folded correctly: %q", i+1, line)
+		}
+	}
+	for _, want := range []string{`X-NOTE;ALTREP=x;X-LABEL="a:b":v`, "DUE:20261025T120000Z", "DTSTART;VALUE=DATE:20261020"} {
+		if !strings.Contains(out, want+"\r\n") {
+			t.Errorf("output has no line %q:\n%s", want, out)
+		}
+	}
+
+	decoded, err := Decode(strings.NewReader(out))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	todo := decoded.Children("VTODO")[0]
+	if got := todo.Text("SUMMARY"); got != summary {
+		t.Errorf("SUMMARY = %q, want %q", got, summary)
+	}
+	cats, _ := todo.Get("CATEGORIES")
+	if got := SplitList(cats.Value); !slices.Equal(got, []string{"home", "shop,ping"}) {
+		t.Errorf("CATEGORIES = %q", got)
+	}
+}
@@ -0,0 +1,38 @@
+package ical
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405"
+)
+
+// Time значение DATE или DATE-TIME. DATE даёт полночь UTC и allDay=true; DATE-TIME с Z в UTC,
+// с TZID в этой зоне, без зоны («плавающее» время) считается UTC. Результат всегда в UTC.
+func (p Property) Time() (_ time.Time, allDay bool, _ error) {
+	value := p.Value
+	if p.Params["VALUE"] == "DATE" || len(value) == len(dateLayout) {
+		t, err := time.Parse(dateLayout, value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%w: %s: invalid date %q", ErrInvalidCalendar, p.Name, value)
+		}
+		return t, true, nil
+	}
+
+	loc := time.UTC
+	if len(value) > 0 && value[len(value)-1] == 'Z' {
+		value = value[:len(value)-1]
+	} else if tzid := p.Params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation(dateTimeLayout, value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%w: %s: invalid date-time %q", ErrInvalidCalendar, p.Name, p.Value)
+	}
+	return t.UTC(), false, nil
+}
@@ -0,0 +1,86 @@
+package jwt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+type Claims map[string]any
+
+func (c Claims) String(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+func (c Claims) Subject() string { return c.String("sub") }
+
+func (c Claims) Issuer() string { return c.String("iss") }
+
+// Time читает NumericDate, ok == false если claim отсутствует.
+func (c Claims) Time(name string) (time.Time, bool, error) {
+	v, ok := c[name]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	var seconds float64
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		if err != nil {
+			return time.Time{}, true, fmt.Errorf("%s: %w", name, ErrInvalidClaimFormat)
+		}
+		seconds = f
+	case float64:
+		seconds = n
+	case int64:
+		seconds = float64(n)
+	case int:
+		seconds = float64(n)
+	default:
+		return time.Time{}, true, fmt.Errorf("%s: %w", name, ErrInvalidClaimFormat)
+	}
+
+	whole, frac := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(frac*1e9)), true, nil
+}
+
+// Audience aud может быть строкой или массивом строк.
+func (c Claims) Audience() []string {
+	switch v := c["aud"].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, a := range v {
+			if s, ok := a.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// Scopes читает scope (строка через пробел, RFC 8693) или scp (массив, как у некоторых IdP).
+func (c Claims) Scopes() []string {
+	if s, ok := c["scope"].(string); ok {
+		return strings.Fields(s)
+	}
+	switch v := c["scp"].(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, a := range v {
+			if s, ok := a.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
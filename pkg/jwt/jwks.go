@@ -0,0 +1,305 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	// симметричный ключ
+	K string `json:"k,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+type KeyProvider interface {
+	Key(ctx context.Context, kid, alg string) (any, error)
+}
+
+type publicKey struct {
+	kid string
+	alg string
+	key any
+}
+
+// KeySet кэширует JWKS из файла или по URL. Кэш обновляется по истечении TTL, а также
+// при встрече неизвестного kid (ротация ключей у IdP), но не чаще MinRefreshInterval.
+type KeySet struct {
+	source             string
+	client             *http.Client
+	ttl                time.Duration
+	minRefreshInterval time.Duration
+	now                func() time.Time
+
+	mu          sync.RWMutex
+	keys        []publicKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+	refreshMu   sync.Mutex
+}
+
+type KeySetOptions struct {
+	TTL                time.Duration
+	MinRefreshInterval time.Duration
+	Client             *http.Client
+}
+
+// NewKeySet source это путь к файлу или http(s) URL.
+func NewKeySet(source string, opts KeySetOptions) *KeySet {
+	if opts.TTL <= 0 {
+		opts.TTL = time.Hour
+	}
+	if opts.MinRefreshInterval <= 0 {
+		opts.MinRefreshInterval = 30 * time.Second
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &KeySet{
+		source:             source,
+		client:             opts.Client,
+		ttl:                opts.TTL,
+		minRefreshInterval: opts.MinRefreshInterval,
+		now:                time.Now,
+	}
+}
+
+// StaticKeySet набор ключей без внешнего источника, например один HS256 секрет из конфига.
+func StaticKeySet(keys JWKS) (*KeySet, error) {
+	parsed, err := parseJWKS(context.Background(), keys)
+	if err != nil {
+		return nil, err
+	}
+	ks := NewKeySet("", KeySetOptions{})
+	ks.keys = parsed
+	ks.fetchedAt = time.Now()
+	return ks, nil
+}
+
+func (ks *KeySet) Key(ctx context.Context, kid, alg string) (any, error) {
+	ks.mu.RLock()
+	key, found := ks.find(kid, alg)
+	stale := ks.source != "" && ks.now().Sub(ks.fetchedAt) > ks.ttl
+	ks.mu.RUnlock()
+
+	if found && !stale {
+		return key, nil
+	}
+	if ks.source == "" {
+		return nil, ErrUnknownKey
+	}
+
+	if err := ks.refresh(ctx); err != nil {
+		// при недоступности JWKS продолжаем работать на старых ключах
+		logger(ctx).Warn("jwks refresh failed", slog.String("source", ks.source), slog.String("error", err.Error()))
+		if found {
+			return key, nil
+		}
+	}
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	if key, found = ks.find(kid, alg); found {
+		return key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+// Refresh принудительно загружает ключи, используется при старте для раннего обнаружения ошибок.
+func (ks *KeySet) Refresh(ctx context.Context) error {
+	ks.refreshMu.Lock()
+	defer ks.refreshMu.Unlock()
+	return ks.load(ctx)
+}
+
+func (ks *KeySet) refresh(ctx context.Context) error {
+	ks.refreshMu.Lock()
+	defer ks.refreshMu.Unlock()
+
+	ks.mu.RLock()
+	throttled := ks.now().Sub(ks.lastAttempt) < ks.minRefreshInterval
+	ks.mu.RUnlock()
+	if throttled {
+		return nil
+	}
+	return ks.load(ctx)
+}
+
+func (ks *KeySet) load(ctx context.Context) error {
+	ks.mu.Lock()
+	ks.lastAttempt = ks.now()
+	ks.mu.Unlock()
+
+	data, err := ks.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	var set JWKS
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	keys, err := parseJWKS(ctx, set)
+	if err != nil {
+		return err
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys = keys
+	ks.fetchedAt = ks.now()
+	return nil
+}
+
+func (ks *KeySet) fetch(ctx context.Context) ([]byte, error) {
+	if len(ks.source) < 7 || (ks.source[:7] != "http://" && (len(ks.source) < 8 || ks.source[:8] != "https://")) {
+		data, err := os.ReadFile(ks.source)
+		if err != nil {
+			return nil, fmt.Errorf("os.ReadFile: %w", err)
+		}
+		return data, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.source, nil)
+	if err != nil {
+		return nil, fmt.Errorf("http.NewRequest: %w", err)
+	}
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("client.Do: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks endpoint responded with status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("io.ReadAll: %w", err)
+	}
+	return data, nil
+}
+
+// find без kid допускает единственный подходящий по алгоритму ключ.
+func (ks *KeySet) find(kid, alg string) (any, bool) {
+	var candidate any
+	matches := 0
+	for _, k := range ks.keys {
+		if k.alg != alg {
+			continue
+		}
+		if kid != "" && k.kid == kid {
+			return k.key, true
+		}
+		if kid == "" {
+			candidate = k.key
+			matches++
+		}
+	}
+	return candidate, matches == 1
+}
+
+// parseJWKS пропускает ключи, которые не удалось разобрать, чтобы один битый или слабый ключ
+// не ломал проверку токенов остальными. Ошибка только если не разобрался ни один из ключей.
+func parseJWKS(ctx context.Context, set JWKS) ([]publicKey, error) {
+	keys := make([]publicKey, 0, len(set.Keys))
+	var firstErr error
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, alg, err := jwk.publicKey()
+		if err != nil {
+			logger(ctx).Warn("jwk skipped", slog.String("kid", jwk.Kid), slog.String("error", err.Error()))
+			if firstErr == nil {
+				firstErr = fmt.Errorf("jwk %q: %w", jwk.Kid, err)
+			}
+			continue
+		}
+		if key == nil {
+			continue
+		}
+		keys = append(keys, publicKey{kid: jwk.Kid, alg: alg, key: key})
+	}
+	if len(keys) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return keys, nil
+}
+
+// publicKey возвращает nil без ошибки для неподдерживаемых типов ключей, их просто пропускаем.
+func (k JWK) publicKey() (any, string, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, "", err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, "", err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || n.BitLen() < 2048 {
+			return nil, "", ErrInvalidClaimFormat
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, RS256, nil
+
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, "", nil
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, "", err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, "", err
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		if !pub.Curve.IsOnCurve(x, y) { //nolint:staticcheck
+			return nil, "", ErrInvalidClaimFormat
+		}
+		return pub, ES256, nil
+
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil || len(secret) < 32 {
+			return nil, "", ErrInvalidClaimFormat
+		}
+		return secret, HS256, nil
+	}
+	return nil, "", nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, ErrInvalidClaimFormat
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,184 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+var (
+	ErrMalformed          = errors.New("malformed token")
+	ErrUnsupportedAlg     = errors.New("unsupported signing algorithm")
+	ErrInvalidSignature   = errors.New("invalid token signature")
+	ErrKeyMismatch        = errors.New("key type does not match token algorithm")
+	ErrUnknownKey         = errors.New("no key found for token")
+	ErrExpired            = errors.New("token is expired")
+	ErrNotYetValid        = errors.New("token is not valid yet")
+	ErrInvalidIssuer      = errors.New("invalid token issuer")
+	ErrInvalidAudience    = errors.New("invalid token audience")
+	ErrMissingClaim       = errors.New("required claim is missing")
+	ErrInvalidClaimFormat = errors.New("invalid claim format")
+)
+
+type Header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+type Token struct {
+	Header       Header
+	Claims       Claims
+	signingInput string
+	signature    []byte
+}
+
+// Parse разбирает компактную JWS-сериализацию без проверки подписи.
+func Parse(raw string) (*Token, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("header: %w", ErrMalformed)
+	}
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("claims: %w", ErrMalformed)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("signature: %w", ErrMalformed)
+	}
+
+	t := &Token{signingInput: parts[0] + "." + parts[1], signature: signature}
+	if err := json.Unmarshal(headerJSON, &t.Header); err != nil {
+		return nil, fmt.Errorf("header: %w", ErrMalformed)
+	}
+
+	dec := json.NewDecoder(strings.NewReader(string(claimsJSON)))
+	dec.UseNumber()
+	if err := dec.Decode(&t.Claims); err != nil || t.Claims == nil {
+		return nil, fmt.Errorf("claims: %w", ErrMalformed)
+	}
+	return t, nil
+}
+
+// Verify проверяет подпись ключом. Тип ключа обязан соответствовать alg из заголовка,
+// иначе возможна подмена алгоритма (например RS256 -> HS256 с публичным ключом как секретом).
+func (t *Token) Verify(key any) error {
+	digest := sha256.Sum256([]byte(t.signingInput))
+
+	switch t.Header.Alg {
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return ErrKeyMismatch
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(t.signingInput))
+		if !hmac.Equal(mac.Sum(nil), t.signature) {
+			return ErrInvalidSignature
+		}
+		return nil
+
+	case RS256:
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrKeyMismatch
+		}
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], t.signature); err != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+
+	case ES256:
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve.Params().BitSize != 256 {
+			return ErrKeyMismatch
+		}
+		// подпись ES256 в JWS это r||s фиксированной длины, а не DER
+		if len(t.signature) != 64 {
+			return ErrInvalidSignature
+		}
+		r := new(big.Int).SetBytes(t.signature[:32])
+		s := new(big.Int).SetBytes(t.signature[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+	return fmt.Errorf("%q: %w", t.Header.Alg, ErrUnsupportedAlg)
+}
+
+// Sign выпускает токен, нужен для тестов и локальной отладки.
+func Sign(alg, kid string, claims Claims, key any) (string, error) {
+	headerJSON, err := json.Marshal(Header{Alg: alg, Kid: kid, Typ: "JWT"})
+	if err != nil {
+		return "", fmt.Errorf("json.Marshal: %w", err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch alg {
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return "", ErrKeyMismatch
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signingInput))
+		signature = mac.Sum(nil)
+
+	case RS256:
+		priv, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return "", ErrKeyMismatch
+		}
+		signature, err = rsa.SignPKCS1v15(rand.Reader, priv, crypto.SHA256, digest[:])
+		if err != nil {
+			return "", fmt.Errorf("rsa.SignPKCS1v15: %w", err)
+		}
+
+	case ES256:
+		priv, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return "", ErrKeyMismatch
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, priv, digest[:])
+		if err != nil {
+			return "", fmt.Errorf("ecdsa.Sign: %w", err)
+		}
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+
+	default:
+		return "", fmt.Errorf("%q: %w", alg, ErrUnsupportedAlg)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
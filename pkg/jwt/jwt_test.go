@@ -0,0 +1,244 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func rsaJWK(kid string, pub *rsa.PublicKey) JWK {
+	return JWK{
+		Kty: "RSA", Kid: kid, Use: "sig",
+		N: base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, pub *ecdsa.PublicKey) JWK {
+	x, y := make([]byte, 32), make([]byte, 32)
+	pub.X.FillBytes(x) //nolint:staticcheck
+	pub.Y.FillBytes(y) //nolint:staticcheck
+	return JWK{
+		Kty: "EC", Kid: kid, Crv: "P-256",
+		X: base64.RawURLEncoding.EncodeToString(x),
+		Y: base64.RawURLEncoding.EncodeToString(y),
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	tests := []struct {
+		name      string
+		alg       string
+		signKey   any
+		verifyKey any
+		wantErr   error
+	}{
+		{name: "HS256", alg: HS256, signKey: secret, verifyKey: secret},
+		{name: "RS256", alg: RS256, signKey: rsaKey, verifyKey: &rsaKey.PublicKey},
+		{name: "ES256", alg: ES256, signKey: ecKey, verifyKey: &ecKey.PublicKey},
+		{name: "HS256 wrong secret", alg: HS256, signKey: secret, verifyKey: []byte("another secret another secret!!"), wantErr: ErrInvalidSignature},
+		{name: "RS256 with HMAC key", alg: RS256, signKey: rsaKey, verifyKey: secret, wantErr: ErrKeyMismatch},
+		{name: "ES256 with RSA key", alg: ES256, signKey: ecKey, verifyKey: &rsaKey.PublicKey, wantErr: ErrKeyMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := Sign(tt.alg, "k1", Claims{"sub": "alice"}, tt.signKey)
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			token, err := Parse(raw)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if err := token.Verify(tt.verifyKey); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAlgorithmConfusion(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	keys, err := StaticKeySet(JWKS{Keys: []JWK{rsaJWK("k1", &rsaKey.PublicKey)}})
+	if err != nil {
+		t.Fatalf("StaticKeySet() error = %v", err)
+	}
+	v := NewValidator(keys, ValidatorOptions{})
+
+	// подписываем HS256, используя модуль публичного ключа как секрет
+	forged, _ := Sign(HS256, "k1", Claims{"sub": "mallory", "exp": time.Now().Add(time.Hour).Unix()}, rsaKey.N.Bytes())
+	if _, err := v.Validate(context.Background(), forged); err == nil {
+		t.Errorf("Expected forged HS256 token to be rejected")
+	}
+
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"mallory"}`)) + "."
+	if _, err := v.Validate(context.Background(), none); !errors.Is(err, ErrUnsupportedAlg) {
+		t.Errorf("Expected alg none to be rejected, got %v", err)
+	}
+}
+
+func TestValidatorClaims(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	keys, err := StaticKeySet(JWKS{Keys: []JWK{{Kty: "oct", K: base64.RawURLEncoding.EncodeToString(secret)}}})
+	if err != nil {
+		t.Fatalf("StaticKeySet() error = %v", err)
+	}
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	v := NewValidator(keys, ValidatorOptions{
+		Issuer:   "https://sso",
+		Audience: []string{"todo-api"},
+		Leeway:   30 * time.Second,
+	})
+	v.now = func() time.Time { return now }
+
+	base := func() Claims {
+		return Claims{
+			"sub":   "alice",
+			"iss":   "https://sso",
+			"aud":   []string{"other", "todo-api"},
+			"exp":   now.Add(time.Minute).Unix(),
+			"scope": "tasks:read tasks:write",
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(Claims)
+		wantErr error
+	}{
+		{name: "Valid", mutate: func(Claims) {}},
+		{name: "Audience as string", mutate: func(c Claims) { c["aud"] = "todo-api" }},
+		{name: "Expired within skew", mutate: func(c Claims) { c["exp"] = now.Add(-10 * time.Second).Unix() }},
+		{name: "Expired", mutate: func(c Claims) { c["exp"] = now.Add(-time.Minute).Unix() }, wantErr: ErrExpired},
+		{name: "Missing exp", mutate: func(c Claims) { delete(c, "exp") }, wantErr: ErrMissingClaim},
+		{name: "Malformed exp", mutate: func(c Claims) { c["exp"] = "tomorrow" }, wantErr: ErrInvalidClaimFormat},
+		{name: "Not yet valid within skew", mutate: func(c Claims) { c["nbf"] = now.Add(10 * time.Second).Unix() }},
+		{name: "Not yet valid", mutate: func(c Claims) { c["nbf"] = now.Add(time.Minute).Unix() }, wantErr: ErrNotYetValid},
+		{name: "Wrong issuer", mutate: func(c Claims) { c["iss"] = "https://evil" }, wantErr: ErrInvalidIssuer},
+		{name: "Wrong audience", mutate: func(c Claims) { c["aud"] = "billing" }, wantErr: ErrInvalidAudience},
+		{name: "Missing subject", mutate: func(c Claims) { delete(c, "sub") }, wantErr: ErrMissingClaim},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := base()
+			tt.mutate(claims)
+			raw, _ := Sign(HS256, "", claims, secret)
+
+			principal, err := v.Authenticate(context.Background(), raw)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (principal.Subject != "alice" || principal.Method != MethodJWT || !principal.HasScope("tasks:write")) {
+				t.Errorf("Unexpected principal: %+v", principal)
+			}
+		})
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	oldKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	newKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	var (
+		mu       sync.Mutex
+		current  = JWKS{Keys: []JWK{ecJWK("old", &oldKey.PublicKey)}}
+		failing  bool
+		requests atomic.Int32
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		mu.Lock()
+		defer mu.Unlock()
+		if failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(current)
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	keys := NewKeySet(srv.URL, KeySetOptions{TTL: time.Hour, MinRefreshInterval: time.Minute, Client: srv.Client()})
+	keys.now = func() time.Time { return now }
+	v := NewValidator(keys, ValidatorOptions{})
+	ctx := context.Background()
+	claims := Claims{"sub": "alice", "exp": now.Add(time.Hour).Unix()}
+
+	oldToken, _ := Sign(ES256, "old", claims, oldKey)
+	newToken, _ := Sign(ES256, "new", claims, newKey)
+
+	if _, err := v.Validate(ctx, oldToken); err != nil {
+		t.Fatalf("Validate(old) error = %v", err)
+	}
+	if _, err := v.Validate(ctx, oldToken); err != nil || requests.Load() != 1 {
+		t.Fatalf("Expected cached keys to be reused, error = %v, requests = %d", err, requests.Load())
+	}
+
+	// IdP опубликовал новый ключ, но обновление ограничено MinRefreshInterval
+	mu.Lock()
+	current = JWKS{Keys: []JWK{ecJWK("old", &oldKey.PublicKey), ecJWK("new", &newKey.PublicKey)}}
+	mu.Unlock()
+	now = now.Add(2 * time.Minute)
+	if _, err := v.Validate(ctx, newToken); err != nil {
+		t.Fatalf("Validate(new) after rotation error = %v", err)
+	}
+	if _, err := v.Validate(ctx, "x"+newToken[1:]); err == nil {
+		t.Errorf("Expected tampered token to fail")
+	}
+	before := requests.Load()
+	forged, _ := Sign(ES256, "unknown", claims, newKey)
+	for range 3 {
+		if _, err := v.Validate(ctx, forged); !errors.Is(err, ErrUnknownKey) {
+			t.Fatalf("Expected ErrUnknownKey, got %v", err)
+		}
+	}
+	if requests.Load()-before != 0 {
+		t.Errorf("Expected unknown kids within MinRefreshInterval not to hit JWKS, got %d requests", requests.Load()-before)
+	}
+
+	// после TTL источник недоступен, продолжаем работать на закэшированных ключах
+	mu.Lock()
+	failing = true
+	mu.Unlock()
+	now = now.Add(2 * time.Hour)
+	if _, err := v.Validate(ctx, newToken); err != nil {
+		t.Errorf("Expected stale keys to be used when JWKS is unavailable, got %v", err)
+	}
+}
+
+func TestKeySetSkipsUnusableKeys(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	weak, _ := rsa.GenerateKey(rand.Reader, 1024)
+	broken := ecJWK("broken", &key.PublicKey)
+	broken.X = "!!"
+
+	keys, err := StaticKeySet(JWKS{Keys: []JWK{rsaJWK("weak", &weak.PublicKey), broken, ecJWK("good", &key.PublicKey)}})
+	if err != nil {
+		t.Fatalf("StaticKeySet() error = %v", err)
+	}
+	token, _ := Sign(ES256, "good", Claims{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()}, key)
+	if _, err := NewValidator(keys, ValidatorOptions{}).Validate(context.Background(), token); err != nil {
+		t.Errorf("Validate() with a usable key next to broken ones error = %v", err)
+	}
+
+	if _, err := StaticKeySet(JWKS{Keys: []JWK{rsaJWK("weak", &weak.PublicKey)}}); err == nil {
+		t.Errorf("Expected a set without a single usable key to fail")
+	}
+}
@@ -0,0 +1,112 @@
+package jwt
+
+import (
+	"context"
+	"ecom_test/pkg/contextx"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const MethodJWT = "jwt"
+
+type ValidatorOptions struct {
+	// Issuer ожидаемый iss, пустая строка отключает проверку.
+	Issuer string
+	// Audience токен должен содержать хотя бы одно из значений в aud.
+	Audience []string
+	// Leeway допустимое расхождение часов при проверке exp и nbf.
+	Leeway time.Duration
+	// Algorithms разрешённые alg, по умолчанию все поддерживаемые.
+	Algorithms []string
+}
+
+type Validator struct {
+	keys KeyProvider
+	opts ValidatorOptions
+	now  func() time.Time
+}
+
+func NewValidator(keys KeyProvider, opts ValidatorOptions) *Validator {
+	if len(opts.Algorithms) == 0 {
+		opts.Algorithms = []string{HS256, RS256, ES256}
+	}
+	return &Validator{keys: keys, opts: opts, now: time.Now}
+}
+
+// Validate проверяет подпись и стандартные claims, возвращает разобранный токен.
+func (v *Validator) Validate(ctx context.Context, raw string) (*Token, error) {
+	t, err := Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(v.opts.Algorithms, t.Header.Alg) {
+		return nil, fmt.Errorf("%q: %w", t.Header.Alg, ErrUnsupportedAlg)
+	}
+
+	key, err := v.keys.Key(ctx, t.Header.Kid, t.Header.Alg)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.Verify(key); err != nil {
+		return nil, err
+	}
+
+	if err := v.validateClaims(t.Claims); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (v *Validator) validateClaims(c Claims) error {
+	now := v.now()
+
+	exp, ok, err := c.Time("exp")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("exp: %w", ErrMissingClaim)
+	}
+	if !now.Before(exp.Add(v.opts.Leeway)) {
+		return ErrExpired
+	}
+
+	nbf, ok, err := c.Time("nbf")
+	if err != nil {
+		return err
+	}
+	if ok && now.Add(v.opts.Leeway).Before(nbf) {
+		return ErrNotYetValid
+	}
+
+	if v.opts.Issuer != "" && c.Issuer() != v.opts.Issuer {
+		return ErrInvalidIssuer
+	}
+
+	if len(v.opts.Audience) > 0 {
+		aud := c.Audience()
+		if !slices.ContainsFunc(v.opts.Audience, func(a string) bool { return slices.Contains(aud, a) }) {
+			return ErrInvalidAudience
+		}
+	}
+
+	if c.Subject() == "" {
+		return fmt.Errorf("sub: %w", ErrMissingClaim)
+	}
+	return nil
+}
+
+// Authenticate реализует middlewarex.Authenticator.
+func (v *Validator) Authenticate(ctx context.Context, token string) (contextx.Principal, error) {
+	t, err := v.Validate(ctx, token)
+	if err != nil {
+		return contextx.Principal{}, fmt.Errorf("jwt.Validate: %w", err)
+	}
+	return contextx.Principal{
+		Subject: t.Claims.Subject(),
+		Scopes:  t.Claims.Scopes(),
+		Method:  MethodJWT,
+		Claims:  t.Claims,
+	}, nil
+}
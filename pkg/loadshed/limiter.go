@@ -0,0 +1,209 @@
+package loadshed
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+type Priority int
+
+const (
+	// PriorityLow запросы на запись, первыми отбрасываются при перегрузке.
+	PriorityLow Priority = iota
+	// PriorityNormal запросы на чтение.
+	PriorityNormal
+	// PriorityCritical пробы и служебные запросы, проходят мимо лимита.
+	PriorityCritical
+
+	numPriorities = 3
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityCritical:
+		return "critical"
+	}
+	return "unknown"
+}
+
+var (
+	ErrQueueFull    = errors.New("concurrency limit reached and queue is full")
+	ErrQueueTimeout = errors.New("timed out waiting for a concurrency slot")
+)
+
+type Options struct {
+	InitialLimit int
+	MinLimit     int
+	MaxLimit     int
+	// TargetLatency латентность, выше которой лимит уменьшается.
+	TargetLatency time.Duration
+	// Backoff множитель уменьшения лимита при превышении TargetLatency.
+	Backoff      float64
+	MaxQueue     int
+	QueueTimeout time.Duration
+}
+
+type waiter struct {
+	ready     chan struct{}
+	granted   bool
+	grantedAt time.Time
+}
+
+// Limiter ограничивает число одновременных запросов по алгоритму AIMD: лимит растёт на
+// единицу за каждое "окно" из limit быстрых ответов и умножается на Backoff при медленном ответе.
+// Сверх лимита запросы ждут в очереди по приоритетам не дольше QueueTimeout.
+type Limiter struct {
+	opts Options
+
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+	queues   [numPriorities][]*waiter
+	queued   int
+}
+
+func New(opts Options) *Limiter {
+	if opts.MinLimit <= 0 {
+		opts.MinLimit = 1
+	}
+	if opts.MaxLimit < opts.MinLimit {
+		opts.MaxLimit = 1000
+	}
+	if opts.InitialLimit < opts.MinLimit {
+		opts.InitialLimit = opts.MinLimit
+	}
+	if opts.InitialLimit > opts.MaxLimit {
+		opts.InitialLimit = opts.MaxLimit
+	}
+	if opts.TargetLatency <= 0 {
+		opts.TargetLatency = 250 * time.Millisecond
+	}
+	if opts.Backoff <= 0 || opts.Backoff >= 1 {
+		opts.Backoff = 0.9
+	}
+	if opts.QueueTimeout <= 0 {
+		opts.QueueTimeout = 100 * time.Millisecond
+	}
+	return &Limiter{opts: opts, limit: float64(opts.InitialLimit)}
+}
+
+// Acquire занимает слот и возвращает функцию освобождения, которую надо вызвать ровно один раз.
+// Латентность для AIMD считается с момента выдачи слота, время в очереди в неё не входит.
+func (l *Limiter) Acquire(ctx context.Context, prio Priority) (func(), error) {
+	return l.acquire(ctx, prio, true)
+}
+
+// AcquireStream как Acquire, но длительность запроса не влияет на лимит: потоковый ответ
+// длится столько, сколько клиент читает, и по нему нельзя судить о перегрузке.
+func (l *Limiter) AcquireStream(ctx context.Context, prio Priority) (func(), error) {
+	return l.acquire(ctx, prio, false)
+}
+
+func (l *Limiter) acquire(ctx context.Context, prio Priority, measured bool) (func(), error) {
+	if prio >= PriorityCritical {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	if l.inFlight < int(l.limit) && l.queued == 0 {
+		l.inFlight++
+		l.mu.Unlock()
+		return l.releaser(time.Now(), measured), nil
+	}
+	if l.queued >= l.opts.MaxQueue {
+		l.mu.Unlock()
+		return nil, ErrQueueFull
+	}
+	w := &waiter{ready: make(chan struct{})}
+	l.queues[prio] = append(l.queues[prio], w)
+	l.queued++
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.opts.QueueTimeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-w.ready:
+		return l.releaser(w.grantedAt, measured), nil
+	case <-timer.C:
+		err = ErrQueueTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// слот мог быть выдан одновременно с таймаутом, тогда запрос всё-таки выполняем
+	if w.granted {
+		return l.releaser(w.grantedAt, measured), nil
+	}
+	l.remove(prio, w)
+	return nil, err
+}
+
+func (l *Limiter) releaser(start time.Time, measured bool) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.release(time.Since(start), measured)
+		})
+	}
+}
+
+func (l *Limiter) release(latency time.Duration, measured bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	switch {
+	case !measured:
+	case latency > l.opts.TargetLatency:
+		l.limit = math.Max(float64(l.opts.MinLimit), l.limit*l.opts.Backoff)
+	case float64(l.inFlight+1)*2 >= l.limit:
+		// растём только когда лимит действительно используется, иначе он раздуется вхолостую
+		l.limit = math.Min(float64(l.opts.MaxLimit), l.limit+1/l.limit)
+	}
+
+	for prio := numPriorities - 1; prio >= 0 && l.inFlight < int(l.limit); prio-- {
+		for len(l.queues[prio]) > 0 && l.inFlight < int(l.limit) {
+			w := l.queues[prio][0]
+			l.queues[prio] = l.queues[prio][1:]
+			l.queued--
+			l.inFlight++
+			w.granted = true
+			w.grantedAt = time.Now()
+			close(w.ready)
+		}
+	}
+}
+
+func (l *Limiter) remove(prio Priority, w *waiter) {
+	q := l.queues[prio]
+	for i, candidate := range q {
+		if candidate == w {
+			l.queues[prio] = append(q[:i], q[i+1:]...)
+			l.queued--
+			return
+		}
+	}
+}
+
+type Stats struct {
+	Limit    int
+	InFlight int
+	Queued   int
+}
+
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return Stats{Limit: int(l.limit), InFlight: l.inFlight, Queued: l.queued}
+}
@@ -0,0 +1,151 @@
+package loadshed
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiter_QueueAndShed(t *testing.T) {
+	l := New(Options{InitialLimit: 1, MinLimit: 1, MaxLimit: 1, MaxQueue: 1, QueueTimeout: 20 * time.Millisecond})
+	ctx := context.Background()
+
+	release, err := l.Acquire(ctx, PriorityNormal)
+	if err != nil {
+		t.Fatalf("Expected first request to acquire slot: %v", err)
+	}
+
+	if _, err := l.Acquire(ctx, PriorityLow); !errors.Is(err, ErrQueueTimeout) {
+		t.Errorf("Expected queue timeout, got %v", err)
+	}
+
+	if _, err := l.Acquire(ctx, PriorityCritical); err != nil {
+		t.Errorf("Expected critical request to bypass limit, got %v", err)
+	}
+
+	granted := make(chan error, 1)
+	go func() {
+		rel, err := l.Acquire(ctx, PriorityNormal)
+		if rel != nil {
+			defer rel()
+		}
+		granted <- err
+	}()
+	waitFor(t, func() bool { return l.Stats().Queued == 1 })
+
+	if _, err := l.Acquire(ctx, PriorityNormal); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected queue full, got %v", err)
+	}
+
+	release()
+	if err := <-granted; err != nil {
+		t.Errorf("Expected queued request to get the released slot, got %v", err)
+	}
+}
+
+func TestLimiter_ReadsBeforeWrites(t *testing.T) {
+	l := New(Options{InitialLimit: 1, MinLimit: 1, MaxLimit: 1, MaxQueue: 10, QueueTimeout: time.Second})
+	ctx := context.Background()
+
+	release, _ := l.Acquire(ctx, PriorityNormal)
+
+	order := make(chan Priority, 2)
+	for _, prio := range []Priority{PriorityLow, PriorityNormal} {
+		go func() {
+			rel, err := l.Acquire(ctx, prio)
+			if err != nil {
+				t.Errorf("Acquire(%s): %v", prio, err)
+				return
+			}
+			order <- prio
+			rel()
+		}()
+		waitFor(t, func() bool { return l.Stats().Queued == int(prio)+1 })
+	}
+
+	release()
+	if first := <-order; first != PriorityNormal {
+		t.Errorf("Expected read to be served before write, got %s first", first)
+	}
+	<-order
+}
+
+func TestLimiter_AIMD(t *testing.T) {
+	l := New(Options{InitialLimit: 10, MinLimit: 2, MaxLimit: 20, TargetLatency: 5 * time.Millisecond, Backoff: 0.5})
+	ctx := context.Background()
+
+	release, _ := l.Acquire(ctx, PriorityNormal)
+	time.Sleep(10 * time.Millisecond)
+	release()
+	if got := l.Stats().Limit; got != 5 {
+		t.Errorf("Expected limit halved to 5 after slow response, got %d", got)
+	}
+
+	var releases []func()
+	for i := 0; i < 5; i++ {
+		rel, _ := l.Acquire(ctx, PriorityNormal)
+		releases = append(releases, rel)
+	}
+	for _, rel := range releases {
+		rel()
+	}
+	if got := l.Stats().Limit; got != 5 && got != 6 {
+		t.Errorf("Expected limit to grow additively, got %d", got)
+	}
+	if l.limit <= 5 {
+		t.Errorf("Expected fractional growth above 5, got %f", l.limit)
+	}
+}
+
+func TestLimiter_LatencyExcludesQueueAndStreams(t *testing.T) {
+	l := New(Options{InitialLimit: 2, MinLimit: 1, MaxLimit: 4, TargetLatency: 20 * time.Millisecond,
+		Backoff: 0.5, MaxQueue: 1, QueueTimeout: time.Second})
+	ctx := context.Background()
+
+	var streams []func()
+	for range 2 {
+		rel, err := l.AcquireStream(ctx, PriorityNormal)
+		if err != nil {
+			t.Fatalf("AcquireStream: %v", err)
+		}
+		streams = append(streams, rel)
+	}
+
+	granted := make(chan func(), 1)
+	go func() {
+		rel, err := l.Acquire(ctx, PriorityNormal)
+		if err != nil {
+			t.Errorf("Acquire: %v", err)
+			close(granted)
+			return
+		}
+		granted <- rel
+	}()
+	waitFor(t, func() bool { return l.Stats().Queued == 1 })
+
+	time.Sleep(50 * time.Millisecond)
+	for _, rel := range streams {
+		rel()
+	}
+	if got := l.Stats().Limit; got != 2 {
+		t.Errorf("Expected long streams to leave the limit at 2, got %d", got)
+	}
+	if rel := <-granted; rel != nil {
+		rel()
+	}
+	if got := l.Stats().Limit; got < 2 {
+		t.Errorf("Expected time spent in the queue not to count as latency, limit dropped to %d", got)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,282 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+)
+
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10} //nolint:gochecknoglobals
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+type Sample struct {
+	// Suffix дописывается к имени семейства, например _bucket или _sum у гистограмм.
+	Suffix string
+	Labels []Label
+	Value  float64
+}
+
+type Family struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+type Collector interface {
+	Collect() []Family
+}
+
+type CollectorFunc func() []Family
+
+func (f CollectorFunc) Collect() []Family {
+	return f()
+}
+
+// value хранит float64 в атомике, чтобы счётчики не требовали мьютекса на горячем пути.
+type value struct {
+	bits atomic.Uint64
+}
+
+func (v *value) Add(delta float64) {
+	for {
+		old := v.bits.Load()
+		next := math.Float64bits(math.Float64frombits(old) + delta)
+		if v.bits.CompareAndSwap(old, next) {
+			return
+		}
+	}
+}
+
+func (v *value) Set(val float64) {
+	v.bits.Store(math.Float64bits(val))
+}
+
+func (v *value) Load() float64 {
+	return math.Float64frombits(v.bits.Load())
+}
+
+// vec общая часть метрик с метками, дочерние метрики создаются лениво по значениям меток.
+type vec[T any] struct {
+	name       string
+	help       string
+	labelNames []string
+	newChild   func() *T
+
+	mu       sync.RWMutex
+	children map[string]*T
+	values   map[string][]string
+}
+
+func newVec[T any](name, help string, labelNames []string, newChild func() *T) *vec[T] {
+	return &vec[T]{
+		name:       name,
+		help:       help,
+		labelNames: labelNames,
+		newChild:   newChild,
+		children:   make(map[string]*T),
+		values:     make(map[string][]string),
+	}
+}
+
+func (v *vec[T]) with(labelValues ...string) *T {
+	if len(labelValues) != len(v.labelNames) {
+		panic("metrics: " + v.name + ": wrong number of label values")
+	}
+	key := strings.Join(labelValues, "\xff")
+
+	v.mu.RLock()
+	child, ok := v.children[key]
+	v.mu.RUnlock()
+	if ok {
+		return child
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if child, ok = v.children[key]; ok {
+		return child
+	}
+	child = v.newChild()
+	v.children[key] = child
+	v.values[key] = append([]string(nil), labelValues...)
+	return child
+}
+
+func (v *vec[T]) each(fn func(labels []Label, child *T)) {
+	v.mu.RLock()
+	keys := make([]string, 0, len(v.children))
+	for k := range v.children {
+		keys = append(keys, k)
+	}
+	v.mu.RUnlock()
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v.mu.RLock()
+		child, values := v.children[k], v.values[k]
+		v.mu.RUnlock()
+
+		labels := make([]Label, len(values))
+		for i, val := range values {
+			labels[i] = Label{Name: v.labelNames[i], Value: val}
+		}
+		fn(labels, child)
+	}
+}
+
+type Counter struct {
+	v value
+}
+
+func (c *Counter) Inc() {
+	c.v.Add(1)
+}
+
+// Add паникует на отрицательном значении, счётчик только растёт.
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+	c.v.Add(delta)
+}
+
+type CounterVec struct {
+	*vec[Counter]
+}
+
+func (c *CounterVec) With(labelValues ...string) *Counter {
+	return c.with(labelValues...)
+}
+
+func (c *CounterVec) Collect() []Family {
+	f := Family{Name: c.name, Help: c.help, Type: TypeCounter}
+	c.each(func(labels []Label, child *Counter) {
+		f.Samples = append(f.Samples, Sample{Labels: labels, Value: child.v.Load()})
+	})
+	return []Family{f}
+}
+
+type Gauge struct {
+	v value
+}
+
+func (g *Gauge) Set(val float64) {
+	g.v.Set(val)
+}
+
+func (g *Gauge) Add(delta float64) {
+	g.v.Add(delta)
+}
+
+func (g *Gauge) Inc() {
+	g.v.Add(1)
+}
+
+func (g *Gauge) Dec() {
+	g.v.Add(-1)
+}
+
+type GaugeVec struct {
+	*vec[Gauge]
+}
+
+func (g *GaugeVec) With(labelValues ...string) *Gauge {
+	return g.with(labelValues...)
+}
+
+func (g *GaugeVec) Collect() []Family {
+	f := Family{Name: g.name, Help: g.help, Type: TypeGauge}
+	g.each(func(labels []Label, child *Gauge) {
+		f.Samples = append(f.Samples, Sample{Labels: labels, Value: child.v.Load()})
+	})
+	return []Family{f}
+}
+
+type Histogram struct {
+	upperBounds []float64
+	counts      []atomic.Uint64
+	sum         value
+	count       atomic.Uint64
+}
+
+func newHistogram(buckets []float64) *Histogram {
+	return &Histogram{
+		upperBounds: buckets,
+		counts:      make([]atomic.Uint64, len(buckets)),
+	}
+}
+
+func (h *Histogram) Observe(val float64) {
+	i := sort.SearchFloat64s(h.upperBounds, val)
+	if i < len(h.counts) {
+		h.counts[i].Add(1)
+	}
+	h.sum.Add(val)
+	h.count.Add(1)
+}
+
+type HistogramVec struct {
+	*vec[Histogram]
+	buckets []float64
+}
+
+func (h *HistogramVec) With(labelValues ...string) *Histogram {
+	return h.with(labelValues...)
+}
+
+func (h *HistogramVec) Collect() []Family {
+	f := Family{Name: h.name, Help: h.help, Type: TypeHistogram}
+	h.each(func(labels []Label, child *Histogram) {
+		var cumulative uint64
+		for i, bound := range child.upperBounds {
+			cumulative += child.counts[i].Load()
+			f.Samples = append(f.Samples, Sample{
+				Suffix: "_bucket",
+				Labels: withLabel(labels, "le", formatFloat(bound)),
+				Value:  float64(cumulative),
+			})
+		}
+		count := child.count.Load()
+		f.Samples = append(f.Samples,
+			Sample{Suffix: "_bucket", Labels: withLabel(labels, "le", "+Inf"), Value: float64(count)},
+			Sample{Suffix: "_sum", Labels: labels, Value: child.sum.Load()},
+			Sample{Suffix: "_count", Labels: labels, Value: float64(count)},
+		)
+	})
+	return []Family{f}
+}
+
+func withLabel(labels []Label, name, value string) []Label {
+	out := make([]Label, 0, len(labels)+1)
+	out = append(out, labels...)
+	return append(out, Label{Name: name, Value: value})
+}
+
+type gaugeFunc struct {
+	name   string
+	help   string
+	labels []Label
+	fn     func() float64
+}
+
+func (g *gaugeFunc) Collect() []Family {
+	return []Family{{
+		Name:    g.name,
+		Help:    g.help,
+		Type:    TypeGauge,
+		Samples: []Sample{{Labels: g.labels, Value: g.fn()}},
+	}}
+}
@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type Registry struct {
+	mu         sync.RWMutex
+	collectors []Collector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) Register(c Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+func (r *Registry) NewCounter(name, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{newVec(name, help, labelNames, func() *Counter { return &Counter{} })}
+	r.Register(c)
+	return c
+}
+
+func (r *Registry) NewGauge(name, help string, labelNames ...string) *GaugeVec {
+	g := &GaugeVec{newVec(name, help, labelNames, func() *Gauge { return &Gauge{} })}
+	r.Register(g)
+	return g
+}
+
+// NewHistogram с buckets == nil использует DefaultBuckets, границы должны быть отсортированы.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	h := &HistogramVec{
+		vec:     newVec(name, help, labelNames, func() *Histogram { return newHistogram(buckets) }),
+		buckets: buckets,
+	}
+	r.Register(h)
+	return h
+}
+
+// NewGaugeFunc вычисляет значение в момент сбора, одноимённые gauge с разными метками объединяются в одно семейство.
+func (r *Registry) NewGaugeFunc(name, help string, labels []Label, fn func() float64) {
+	r.Register(&gaugeFunc{name: name, help: help, labels: labels, fn: fn})
+}
+
+func (r *Registry) Gather() []Family {
+	r.mu.RLock()
+	collectors := append([]Collector(nil), r.collectors...)
+	r.mu.RUnlock()
+
+	byName := make(map[string]*Family)
+	names := make([]string, 0, len(collectors))
+	for _, c := range collectors {
+		for _, f := range c.Collect() {
+			existing, ok := byName[f.Name]
+			if !ok {
+				f := f
+				byName[f.Name] = &f
+				names = append(names, f.Name)
+				continue
+			}
+			existing.Samples = append(existing.Samples, f.Samples...)
+		}
+	}
+	sort.Strings(names)
+
+	families := make([]Family, 0, len(names))
+	for _, name := range names {
+		families = append(families, *byName[name])
+	}
+	return families
+}
+
+func (r *Registry) WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range r.Gather() {
+		if f.Help != "" {
+			bw.WriteString("# HELP " + f.Name + " " + escapeHelp(f.Help) + "\n")
+		}
+		bw.WriteString("# TYPE " + f.Name + " " + f.Type + "\n")
+		for _, s := range f.Samples {
+			bw.WriteString(f.Name + s.Suffix)
+			writeLabels(bw, s.Labels)
+			bw.WriteString(" " + formatFloat(s.Value) + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", ContentType)
+		_ = r.WriteText(w)
+	})
+}
+
+func writeLabels(w *bufio.Writer, labels []Label) {
+	if len(labels) == 0 {
+		return
+	}
+	w.WriteByte('{')
+	for i, l := range labels {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		w.WriteString(l.Name + `="` + escapeLabelValue(l.Value) + `"`)
+	}
+	w.WriteByte('}')
+}
+
+var (
+	helpReplacer       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)            //nolint:gochecknoglobals
+	labelValueReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`) //nolint:gochecknoglobals
+)
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelValueReplacer.Replace(s)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	r := NewRegistry()
+
+	requests := r.NewCounter("http_requests_total", "Total requests.", "method", "route")
+	requests.With("GET", "/todos").Inc()
+	requests.With("GET", "/todos").Add(2)
+	requests.With("POST", `/weird"path`).Inc()
+
+	latency := r.NewHistogram("latency_seconds", "Latency\nwith newline.", []float64{0.1, 1})
+	latency.With().Observe(0.05)
+	latency.With().Observe(0.1)
+	latency.With().Observe(5)
+
+	r.NewGaugeFunc("tasks", "Tasks by state.", []Label{{Name: "state", Value: "open"}}, func() float64 { return 3 })
+	r.NewGaugeFunc("tasks", "Tasks by state.", []Label{{Name: "state", Value: "completed"}}, func() float64 { return 1 })
+
+	var sb strings.Builder
+	if err := r.WriteText(&sb); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+
+	want := `# HELP http_requests_total Total requests.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",route="/todos"} 3
+http_requests_total{method="POST",route="/weird\"path"} 1
+# HELP latency_seconds Latency\nwith newline.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.1"} 2
+latency_seconds_bucket{le="1"} 2
+latency_seconds_bucket{le="+Inf"} 3
+latency_seconds_sum 5.15
+latency_seconds_count 3
+# HELP tasks Tasks by state.
+# TYPE tasks gauge
+tasks{state="open"} 3
+tasks{state="completed"} 1
+`
+	if got := sb.String(); got != want {
+		t.Errorf("Unexpected exposition:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCounter_AddNegativePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected panic on negative counter increment")
+		}
+	}()
+	NewRegistry().NewCounter("c", "").With().Add(-1)
+}
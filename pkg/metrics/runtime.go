@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"runtime"
+	"time"
+)
+
+// RuntimeCollector отдаёт базовую статистику рантайма Go и процесса.
+type RuntimeCollector struct {
+	startTime time.Time
+}
+
+func NewRuntimeCollector() *RuntimeCollector {
+	return &RuntimeCollector{startTime: time.Now()}
+}
+
+func (c *RuntimeCollector) Collect() []Family {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	gauge := func(name, help string, v float64) Family {
+		return Family{Name: name, Help: help, Type: TypeGauge, Samples: []Sample{{Value: v}}}
+	}
+	counter := func(name, help string, v float64) Family {
+		return Family{Name: name, Help: help, Type: TypeCounter, Samples: []Sample{{Value: v}}}
+	}
+
+	return []Family{
+		{
+			Name:    "go_info",
+			Help:    "Information about the Go environment.",
+			Type:    TypeGauge,
+			Samples: []Sample{{Labels: []Label{{Name: "version", Value: runtime.Version()}}, Value: 1}},
+		},
+		gauge("go_goroutines", "Number of goroutines that currently exist.", float64(runtime.NumGoroutine())),
+		gauge("go_sched_gomaxprocs_threads", "The current runtime.GOMAXPROCS setting.", float64(runtime.GOMAXPROCS(0))),
+		gauge("go_memstats_alloc_bytes", "Number of bytes allocated and still in use.", float64(ms.Alloc)),
+		counter("go_memstats_alloc_bytes_total", "Total number of bytes allocated, even if freed.", float64(ms.TotalAlloc)),
+		gauge("go_memstats_sys_bytes", "Number of bytes obtained from system.", float64(ms.Sys)),
+		gauge("go_memstats_heap_inuse_bytes", "Number of heap bytes that are in use.", float64(ms.HeapInuse)),
+		gauge("go_memstats_heap_objects", "Number of allocated objects.", float64(ms.HeapObjects)),
+		counter("go_gc_cycles_total", "Number of completed GC cycles.", float64(ms.NumGC)),
+		counter("go_gc_pause_seconds_total", "Total GC stop-the-world pause time.", float64(ms.PauseTotalNs)/float64(time.Second)),
+		gauge("process_start_time_seconds", "Start time of the process since unix epoch in seconds.", float64(c.startTime.Unix())),
+	}
+}
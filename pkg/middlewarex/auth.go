@@ -0,0 +1,102 @@
+package middlewarex
+
+import (
+	"context"
+	"ecom_test/pkg/contextx"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (contextx.Principal, error)
+}
+
+// Authenticate проверяет Authorization: Bearer и кладёт Principal в контекст. Basic тоже принимается,
+// токеном считается пароль, имя пользователя игнорируется: CalDAV-клиенты умеют только Basic.
+// Запрос без заголовка получает anonymous, если он задан, иначе идёт дальше без Principal
+// и будет отклонён на маршрутах с RequireScope. С authenticator == nil все запросы анонимные.
+// Вызов Basic в ответ 401 добавляется только под basicPrefixes: на остальных путях браузер
+// показывал бы окно входа вместо ответа API.
+func Authenticate(authenticator Authenticator, anonymous *contextx.Principal, basicPrefixes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if slices.ContainsFunc(basicPrefixes, func(prefix string) bool { return strings.HasPrefix(r.URL.Path, prefix) }) {
+				r = r.WithContext(context.WithValue(r.Context(), contextKeyBasic{}, true))
+			}
+			header := r.Header.Get("Authorization")
+			if header == "" || authenticator == nil {
+				if anonymous != nil {
+					r = r.WithContext(contextx.WithPrincipal(r.Context(), *anonymous))
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := bearerToken(header)
+			if !ok {
+				token, ok = basicPassword(r)
+			}
+			if !ok {
+				unauthorized(w, r, "invalid_request")
+				return
+			}
+			principal, err := authenticator.Authenticate(r.Context(), token)
+			if err != nil {
+				logger(r.Context()).Warn("authentication failed", "error", err.Error())
+				unauthorized(w, r, "invalid_token")
+				return
+			}
+
+			ctx := contextx.WithPrincipal(r.Context(), principal)
+			ctx = contextx.WithLogger(ctx, logger(ctx).With("subject", principal.Subject))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireScope пропускает запрос только если у Principal есть нужный scope.
+func RequireScope(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, err := contextx.PrincipalFromContext(r.Context())
+		if err != nil {
+			unauthorized(w, r, "")
+			return
+		}
+		if !principal.HasScope(scope) {
+			w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+scope+`"`)
+			WriteProblem(w, r, http.StatusForbidden, "insufficient_scope", "required scope: "+scope)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func bearerToken(header string) (string, bool) {
+	const prefix = "bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+	token := strings.TrimSpace(header[len(prefix):])
+	return token, token != ""
+}
+
+func basicPassword(r *http.Request) (string, bool) {
+	_, password, ok := r.BasicAuth()
+	return password, ok && password != ""
+}
+
+// contextKeyBasic отмечает запросы, клиентам которых в 401 предлагается Basic.
+type contextKeyBasic struct{}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, code string) {
+	challenge := "Bearer"
+	if code != "" {
+		challenge += ` error="` + code + `"`
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	if basic, _ := r.Context().Value(contextKeyBasic{}).(bool); basic {
+		w.Header().Add("WWW-Authenticate", `Basic realm="api", charset="UTF-8"`)
+	}
+	WriteProblem(w, r, http.StatusUnauthorized, "unauthorized", "")
+}
@@ -0,0 +1,47 @@
+package middlewarex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAuthenticate_BasicChallengeOnlyUnderPrefix(t *testing.T) {
+	handler := Authenticate(knownKey{}, nil, "/dav/")(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			RequireScope("tasks:read", func(w http.ResponseWriter, r *http.Request) {})(w, r)
+		}))
+
+	tests := []struct {
+		name      string
+		path      string
+		token     string
+		wantBasic bool
+	}{
+		{name: "API without credentials", path: "/todos"},
+		{name: "API with unknown key", path: "/todos", token: "random"},
+		{name: "CalDAV without credentials", path: "/dav/calendars/", wantBasic: true},
+		{name: "CalDAV with unknown key", path: "/dav/calendars/", token: "random", wantBasic: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want 401", rec.Code)
+			}
+			challenges := rec.Header().Values("WWW-Authenticate")
+			hasBasic := slices.ContainsFunc(challenges, func(c string) bool { return strings.HasPrefix(c, "Basic ") })
+			if hasBasic != tt.wantBasic {
+				t.Errorf("WWW-Authenticate = %q, want Basic challenge: %v", challenges, tt.wantBasic)
+			}
+		})
+	}
+}
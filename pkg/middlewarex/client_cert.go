@@ -0,0 +1,32 @@
+package middlewarex
+
+import (
+	"crypto/sha256"
+	"ecom_test/pkg/contextx"
+	"encoding/hex"
+	"net/http"
+)
+
+// ClientCert кладёт в контекст личность клиента из проверенного сертификата mTLS.
+func ClientCert(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cert := r.TLS.VerifiedChains[0][0]
+		fingerprint := sha256.Sum256(cert.Raw)
+		identity := contextx.ClientIdentity{
+			CommonName:   cert.Subject.CommonName,
+			DNSNames:     cert.DNSNames,
+			Emails:       cert.EmailAddresses,
+			SerialNumber: cert.SerialNumber.Text(16),
+			Fingerprint:  hex.EncodeToString(fingerprint[:]),
+		}
+
+		ctx := contextx.WithClientIdentity(r.Context(), identity)
+		ctx = contextx.WithLogger(ctx, logger(ctx).With("client_cn", identity.CommonName))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
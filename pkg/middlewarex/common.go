@@ -1,5 +1,34 @@
 package middlewarex
 
-import "ecom_test/pkg/contextx"
+import (
+	"ecom_test/pkg/contextx"
+	"net/url"
+	"strings"
+)
 
 var logger = contextx.LoggerFromContextOrDefault //nolint:gochecknoglobals
+
+// secretPaths префиксы путей, остаток которых сам является секретом (токен ленты календаря):
+// в журнал, трассы и отчёты о падениях такой путь попадает замазанным.
+var secretPaths = []string{"/feed/"} //nolint:gochecknoglobals
+
+func redactPath(path string) string {
+	for _, prefix := range secretPaths {
+		if rest, ok := strings.CutPrefix(path, prefix); ok && rest != "" {
+			return prefix + "[redacted]"
+		}
+	}
+	return path
+}
+
+// redactURL у запроса к серверу URL без схемы и хоста: путь и строка запроса.
+func redactURL(u *url.URL) string {
+	path := redactPath(u.Path)
+	if path == u.Path {
+		return u.String()
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return path
+}
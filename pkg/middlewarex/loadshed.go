@@ -0,0 +1,89 @@
+package middlewarex
+
+import (
+	"ecom_test/pkg/loadshed"
+	"ecom_test/pkg/metrics"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type LoadShedOptions struct {
+	Limiter  *loadshed.Limiter
+	Priority func(r *http.Request) loadshed.Priority
+	// Streaming отмечает потоковые ответы, их длительность не влияет на лимит. nil значит таких нет.
+	Streaming func(r *http.Request) bool
+	Metrics   *metrics.Registry
+	// RetryAfter значение заголовка Retry-After для отброшенных запросов.
+	RetryAfter time.Duration
+}
+
+// PriorityByMethod отдаёт критический приоритет перечисленным маршрутам, чтениям обычный, записям низкий.
+func PriorityByMethod(route RouteFunc, critical ...string) func(*http.Request) loadshed.Priority {
+	criticalRoutes := make(map[string]bool, len(critical))
+	for _, pattern := range critical {
+		criticalRoutes[pattern] = true
+	}
+
+	return func(r *http.Request) loadshed.Priority {
+		if criticalRoutes[route(r)] {
+			return loadshed.PriorityCritical
+		}
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return loadshed.PriorityNormal
+		}
+		return loadshed.PriorityLow
+	}
+}
+
+// StreamingRoutes отмечает запросы к перечисленным маршрутам в виде "<метод> <шаблон mux>",
+// например "GET /export".
+func StreamingRoutes(route RouteFunc, streaming ...string) func(*http.Request) bool {
+	streamingRoutes := make(map[string]bool, len(streaming))
+	for _, pattern := range streaming {
+		streamingRoutes[pattern] = true
+	}
+
+	return func(r *http.Request) bool {
+		return streamingRoutes[r.Method+" "+route(r)]
+	}
+}
+
+func LoadShed(opts LoadShedOptions) func(http.Handler) http.Handler {
+	var shed *metrics.CounterVec
+	if opts.Metrics != nil {
+		shed = opts.Metrics.NewCounter("http_load_shed_total",
+			"Total number of requests rejected by the concurrency limiter.", "priority", "reason")
+	}
+	retryAfter := strconv.Itoa(max(1, ceilSeconds(opts.RetryAfter)))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			prio := opts.Priority(r)
+			acquire := opts.Limiter.Acquire
+			if opts.Streaming != nil && opts.Streaming(r) {
+				acquire = opts.Limiter.AcquireStream
+			}
+			release, err := acquire(r.Context(), prio)
+			if err != nil {
+				reason := "queue_timeout"
+				if errors.Is(err, loadshed.ErrQueueFull) {
+					reason = "queue_full"
+				} else if r.Context().Err() != nil {
+					reason = "canceled"
+				}
+				if shed != nil {
+					shed.With(prio.String(), reason).Inc()
+				}
+				w.Header().Set("Retry-After", retryAfter)
+				WriteProblem(w, r, http.StatusServiceUnavailable, "overloaded", "")
+				return
+			}
+			defer release()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
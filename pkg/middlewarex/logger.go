@@ -2,6 +2,7 @@ package middlewarex
 
 import (
 	"context"
+	"ecom_test/pkg/contextx"
 	"log/slog"
 	"net/http"
 	"time"
@@ -9,12 +10,13 @@ import (
 
 func Logger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		startTime := time.Now()
 		logger(r.Context()).Info("request", RequestLogRestapi(r))
 
-		ctx := context.WithValue(r.Context(), "startTime", startTime)
-		ctx = context.WithValue(ctx, "ip", r.RemoteAddr)
-		ctx = context.WithValue(ctx, "userAgent", r.UserAgent())
+		ctx := contextx.WithRequestMeta(r.Context(), contextx.RequestMeta{
+			StartTime:  time.Now(),
+			RemoteAddr: r.RemoteAddr,
+			UserAgent:  r.UserAgent(),
+		})
 		r = r.WithContext(ctx)
 
 		lw := LoggingResponseWriter{
@@ -30,7 +32,7 @@ func Logger(next http.Handler) http.Handler {
 func RequestLogRestapi(r *http.Request) slog.Attr {
 	requestInfo := []slog.Attr{
 		slog.String("method", r.Method),
-		slog.String("path", r.URL.String()),
+		slog.String("path", redactURL(r.URL)),
 		slog.String("host", r.Host),
 		slog.String("user_agent", r.UserAgent()),
 		slog.String("ip", r.RemoteAddr),
@@ -39,12 +41,12 @@ func RequestLogRestapi(r *http.Request) slog.Attr {
 }
 
 func ResponseLogRestapi(ctx context.Context, w LoggingResponseWriter) slog.Attr {
-	start := ctx.Value("startTime")
-	startTime := start.(time.Time)
 	responseInfo := []slog.Attr{
 		slog.Int("status", w.StatusCode),
 		slog.Int("Size", w.Size),
-		slog.Int64("duration", time.Since(startTime).Milliseconds()),
+	}
+	if meta, err := contextx.RequestMetaFromContext(ctx); err == nil {
+		responseInfo = append(responseInfo, slog.Int64("duration", time.Since(meta.StartTime).Milliseconds()))
 	}
 	return slog.Any("response_info", responseInfo)
 }
@@ -68,3 +70,8 @@ func (lw *LoggingResponseWriter) WriteHeader(statusCode int) {
 	lw.ResponseWriter.WriteHeader(statusCode)
 	lw.StatusCode = statusCode
 }
+
+// Unwrap открывает http.ResponseController доступ к исходному writer (Flush, дедлайны).
+func (lw *LoggingResponseWriter) Unwrap() http.ResponseWriter {
+	return lw.ResponseWriter
+}
@@ -0,0 +1,38 @@
+package middlewarex
+
+import (
+	"bytes"
+	"ecom_test/pkg/contextx"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLogger_RedactsSecretPath(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+		secret string
+	}{
+		{target: "/feed/cal_s3cr3t.ics?type=todo", want: "/feed/[redacted]?type=todo", secret: "cal_s3cr3t"},
+		{target: "/feed", want: "path=/feed "},
+		{target: "/todos/1?fields=id", want: "/todos/1?fields=id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			var out bytes.Buffer
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			r = r.WithContext(contextx.WithLogger(r.Context(), slog.New(slog.NewTextHandler(&out, nil))))
+			Logger(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})).ServeHTTP(httptest.NewRecorder(), r)
+
+			if !strings.Contains(out.String(), tt.want) {
+				t.Errorf("log does not contain %q:\n%s", tt.want, out.String())
+			}
+			if tt.secret != "" && strings.Contains(out.String(), tt.secret) {
+				t.Errorf("log leaks %q:\n%s", tt.secret, out.String())
+			}
+		})
+	}
+}
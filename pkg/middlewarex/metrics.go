@@ -0,0 +1,50 @@
+package middlewarex
+
+import (
+	"ecom_test/pkg/metrics"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var sizeBuckets = []float64{100, 1_000, 10_000, 100_000, 1_000_000, 10_000_000} //nolint:gochecknoglobals
+
+func Metrics(registry *metrics.Registry, route RouteFunc) func(http.Handler) http.Handler {
+	requests := registry.NewCounter("http_requests_total",
+		"Total number of HTTP requests.", "method", "route", "status")
+	duration := registry.NewHistogram("http_request_duration_seconds",
+		"HTTP request latency.", nil, "method", "route", "status")
+	size := registry.NewHistogram("http_response_size_bytes",
+		"HTTP response size.", sizeBuckets, "method", "route", "status")
+	inFlight := registry.NewGauge("http_requests_in_flight",
+		"Number of HTTP requests currently being served.")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			inFlight.With().Inc()
+			defer inFlight.With().Dec()
+
+			lw := &LoggingResponseWriter{
+				ResponseWriter: w,
+				StatusCode:     http.StatusOK,
+			}
+			next.ServeHTTP(lw, r)
+
+			labels := []string{normalizeMethod(r.Method), route(r), strconv.Itoa(lw.StatusCode)}
+			requests.With(labels...).Inc()
+			duration.With(labels...).Observe(time.Since(start).Seconds())
+			size.With(labels...).Observe(float64(lw.Size))
+		})
+	}
+}
+
+// normalizeMethod не даёт произвольным методам раздувать кардинальность меток.
+func normalizeMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return method
+	}
+	return "OTHER"
+}
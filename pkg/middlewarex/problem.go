@@ -0,0 +1,34 @@
+package middlewarex
+
+import (
+	"ecom_test/pkg/contextx"
+	"encoding/json"
+	"net/http"
+)
+
+// Problem тело ответа по RFC 9457, поле error дублирует код ошибки в формате остального API.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+func WriteProblem(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	problem := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Error:  code,
+	}
+	if id, err := contextx.RequestIDFromContext(r.Context()); err == nil {
+		problem.RequestID = id
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(problem)
+}
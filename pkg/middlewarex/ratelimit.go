@@ -0,0 +1,101 @@
+package middlewarex
+
+import (
+	"ecom_test/pkg/contextx"
+	"ecom_test/pkg/metrics"
+	"ecom_test/pkg/ratelimit"
+	"math"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"time"
+)
+
+// KeyFunc возвращает ключ клиента, по которому считается лимит.
+type KeyFunc func(r *http.Request) string
+
+func KeyByIP(trustedProxies []netip.Prefix) KeyFunc {
+	return func(r *http.Request) string {
+		return "ip:" + ClientIP(r, trustedProxies)
+	}
+}
+
+// KeyByPrincipal считает лимит по субъекту, которого проверил Authenticate, поэтому RateLimit
+// с ним ставится после Authenticate: ключ из заголовка без проверки давал бы новую корзину
+// на каждый выдуманный ключ. Запросы без Principal и анонимные считаются по fallback.
+func KeyByPrincipal(fallback KeyFunc) KeyFunc {
+	return func(r *http.Request) string {
+		principal, err := contextx.PrincipalFromContext(r.Context())
+		if err != nil || principal.Method == "anonymous" {
+			return fallback(r)
+		}
+		id := principal.KeyID
+		if id == "" {
+			id = principal.Subject
+		}
+		return principal.Method + ":" + principal.Tenant + "/" + id
+	}
+}
+
+type RateLimitOptions struct {
+	Limiter *ratelimit.Limiter
+	Default ratelimit.Limit
+	// Routes лимиты по маршруту, ключ "METHOD /pattern" приоритетнее чем "/pattern".
+	Routes  map[string]ratelimit.Limit
+	Key     KeyFunc
+	Route   RouteFunc
+	Metrics *metrics.Registry
+}
+
+func RateLimit(opts RateLimitOptions) func(http.Handler) http.Handler {
+	var limited *metrics.CounterVec
+	if opts.Metrics != nil {
+		limited = opts.Metrics.NewCounter("http_rate_limited_total",
+			"Total number of requests rejected by the rate limiter.", "route")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pattern := opts.Route(r)
+			scope, limit := opts.match(r.Method, pattern)
+			if limit.Unlimited() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			res := opts.Limiter.Allow(scope+"|"+opts.Key(r), limit)
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+			h.Set("RateLimit-Policy", strconv.Itoa(limit.Burst)+";w="+strconv.Itoa(ceilSeconds(limit.Window())))
+
+			if !res.Allowed {
+				if limited != nil {
+					limited.With(pattern).Inc()
+				}
+				h.Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+				WriteProblem(w, r, http.StatusTooManyRequests, "rate_limited", "")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// match возвращает правило и область корзины: у маршрутов с отдельным правилом своя корзина,
+// все остальные делят общую корзину клиента.
+func (o RateLimitOptions) match(method, pattern string) (string, ratelimit.Limit) {
+	if limit, ok := o.Routes[method+" "+pattern]; ok {
+		return method + " " + pattern, limit
+	}
+	if limit, ok := o.Routes[pattern]; ok {
+		return pattern, limit
+	}
+	return "*", o.Default
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
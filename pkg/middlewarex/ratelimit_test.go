@@ -0,0 +1,123 @@
+package middlewarex
+
+import (
+	"context"
+	"ecom_test/pkg/contextx"
+	"ecom_test/pkg/ratelimit"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trustedProxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        string
+		want       string
+	}{
+		{name: "Direct client", remoteAddr: "203.0.113.7:5000", want: "203.0.113.7"},
+		{name: "Untrusted peer cannot spoof XFF", remoteAddr: "203.0.113.7:5000", xff: "1.2.3.4", want: "203.0.113.7"},
+		{name: "Trusted proxy", remoteAddr: "10.0.0.1:5000", xff: "198.51.100.1", want: "198.51.100.1"},
+		{name: "Chain of trusted proxies", remoteAddr: "10.0.0.1:5000", xff: "1.2.3.4, 198.51.100.1, 10.0.0.2", want: "198.51.100.1"},
+		{name: "Only trusted hops", remoteAddr: "10.0.0.1:5000", xff: "10.0.0.3", want: "10.0.0.3"},
+		{name: "Garbage in XFF", remoteAddr: "10.0.0.1:5000", xff: "not-an-ip", want: "10.0.0.1"},
+		{name: "IPv4-mapped IPv6", remoteAddr: "[::ffff:203.0.113.7]:5000", want: "203.0.113.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.xff != "" {
+				r.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			if got := ClientIP(r, trustedProxies); got != tt.want {
+				t.Errorf("ClientIP() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	handler := RateLimit(RateLimitOptions{
+		Limiter: ratelimit.New(ratelimit.Options{}),
+		Default: ratelimit.Limit{Rate: 100, Burst: 100},
+		Routes:  map[string]ratelimit.Limit{"POST /todos": {Rate: 0.5, Burst: 1}},
+		Key:     KeyByIP(nil),
+		Route:   func(*http.Request) string { return "/todos" },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	do := func(method string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, "/todos", nil))
+		return rec
+	}
+
+	if rec := do(http.MethodPost); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("Expected first POST to pass with 0 remaining, got %d %v", rec.Code, rec.Header())
+	}
+	rec := do(http.MethodPost)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Expected Retry-After 2, got %q", got)
+	}
+	if got := rec.Header().Get("RateLimit-Policy"); got != "1;w=2" {
+		t.Errorf("Expected RateLimit-Policy 1;w=2, got %q", got)
+	}
+	if rec := do(http.MethodGet); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "100" {
+		t.Errorf("Expected GET to use default limit, got %d %v", rec.Code, rec.Header())
+	}
+}
+
+// knownKey принимает единственный токен "good".
+type knownKey struct{}
+
+func (knownKey) Authenticate(_ context.Context, token string) (contextx.Principal, error) {
+	if token != "good" {
+		return contextx.Principal{}, errors.New("unknown key")
+	}
+	return contextx.Principal{Subject: "alice", Method: "api_key", KeyID: "k1"}, nil
+}
+
+func TestRateLimit_KeyByPrincipalIgnoresUnverifiedKeys(t *testing.T) {
+	limited := RateLimit(RateLimitOptions{
+		Limiter: ratelimit.New(ratelimit.Options{MaxKeys: 2}),
+		Default: ratelimit.Limit{Rate: 1, Burst: 1},
+		Key:     KeyByPrincipal(KeyByIP(nil)),
+		Route:   func(*http.Request) string { return "/todos" },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	handler := Authenticate(knownKey{}, &contextx.Principal{Subject: "anonymous", Method: "anonymous"})(limited)
+
+	do := func(token string) int {
+		r := httptest.NewRequest(http.MethodGet, "/todos", nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		return rec.Code
+	}
+
+	for i := range 50 {
+		if code := do(fmt.Sprintf("random-%d", i)); code != http.StatusUnauthorized {
+			t.Fatalf("Random key %d: expected 401, got %d", i, code)
+		}
+	}
+	if code := do(""); code != http.StatusOK {
+		t.Fatalf("Expected first anonymous request to pass, got %d", code)
+	}
+	if code := do(""); code != http.StatusTooManyRequests {
+		t.Errorf("Expected anonymous requests to share the client IP bucket, got %d", code)
+	}
+	if code := do("good"); code != http.StatusOK {
+		t.Errorf("Expected verified key to get its own bucket, got %d", code)
+	}
+}
@@ -0,0 +1,120 @@
+package middlewarex
+
+import (
+	"ecom_test/pkg/contextx"
+	"ecom_test/pkg/crashreport"
+	"ecom_test/pkg/metrics"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+const maxGoroutineDump = 1 << 20
+
+// заголовки с секретами не должны попадать в отчёты на диске
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization", "X-Api-Key"} //nolint:gochecknoglobals
+
+type CrashReporter interface {
+	Save(report crashreport.Report) (string, error)
+}
+
+// Recovery превращает панику обработчика в 500 с телом problem+json. registry и reporter опциональны.
+func Recovery(route RouteFunc, registry *metrics.Registry, reporter CrashReporter) func(http.Handler) http.Handler {
+	var panics *metrics.CounterVec
+	if registry != nil {
+		panics = registry.NewCounter("http_panics_total", "Total number of recovered handler panics.", "route")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := &recoveryWriter{ResponseWriter: w}
+			defer func() {
+				p := recover()
+				if p == nil {
+					return
+				}
+				// ErrAbortHandler штатный способ оборвать ответ, его net/http обрабатывает сам
+				if err, ok := p.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(p)
+				}
+
+				stack := debug.Stack()
+				pattern := route(r)
+				log := logger(r.Context())
+				log.Error("panic recovered",
+					slog.String("panic", fmt.Sprint(p)),
+					slog.String("stack", string(stack)),
+				)
+
+				if panics != nil {
+					panics.With(pattern).Inc()
+				}
+				if reporter != nil {
+					report := newCrashReport(r, pattern, p, stack)
+					if id, err := reporter.Save(report); err != nil {
+						log.Error("failed to save crash report", slog.String("error", err.Error()))
+					} else {
+						log.Info("crash report saved", slog.String("crash_id", id))
+					}
+				}
+
+				if !rw.wroteHeader {
+					WriteProblem(rw, r, http.StatusInternalServerError, "internal_server_error", "")
+				}
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+func newCrashReport(r *http.Request, route string, p any, stack []byte) crashreport.Report {
+	headers := r.Header.Clone()
+	for _, h := range sensitiveHeaders {
+		if headers.Get(h) != "" {
+			headers.Set(h, "[redacted]")
+		}
+	}
+
+	goroutines := make([]byte, maxGoroutineDump)
+	goroutines = goroutines[:runtime.Stack(goroutines, true)]
+
+	report := crashreport.Report{
+		Time:       time.Now(),
+		Method:     r.Method,
+		URL:        redactURL(r.URL),
+		Route:      route,
+		RemoteAddr: r.RemoteAddr,
+		Headers:    headers,
+		Panic:      fmt.Sprint(p),
+		Stack:      string(stack),
+		Goroutines: string(goroutines),
+	}
+	if id, err := contextx.RequestIDFromContext(r.Context()); err == nil {
+		report.RequestID = id
+	}
+	return report
+}
+
+type recoveryWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *recoveryWriter) WriteHeader(statusCode int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *recoveryWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap открывает http.ResponseController доступ к исходному writer (Flush, дедлайны).
+func (w *recoveryWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,101 @@
+package middlewarex
+
+import (
+	"ecom_test/pkg/contextx"
+	"ecom_test/pkg/crashreport"
+	"ecom_test/pkg/metrics"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type memoryReporter struct {
+	reports []crashreport.Report
+}
+
+func (m *memoryReporter) Save(report crashreport.Report) (string, error) {
+	m.reports = append(m.reports, report)
+	return "1", nil
+}
+
+func TestRecovery(t *testing.T) {
+	registry := metrics.NewRegistry()
+	reporter := &memoryReporter{}
+	route := func(*http.Request) string { return "/todos/{id}" }
+
+	handler := Recovery(route, registry, reporter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var task *struct{ Title string }
+		_ = task.Title
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/todos/1", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req = req.WithContext(contextx.WithRequestID(req.Context(), "req-1"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected 500, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("Expected problem+json, got %s", ct)
+	}
+	var problem Problem
+	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
+		t.Fatalf("Failed to decode problem: %v", err)
+	}
+	if problem.RequestID != "req-1" || problem.Status != http.StatusInternalServerError {
+		t.Errorf("Unexpected problem: %+v", problem)
+	}
+
+	if len(reporter.reports) != 1 {
+		t.Fatalf("Expected 1 crash report, got %d", len(reporter.reports))
+	}
+	report := reporter.reports[0]
+	if report.RequestID != "req-1" || !strings.Contains(report.Panic, "nil pointer") {
+		t.Errorf("Unexpected crash report: %+v", report)
+	}
+	if got := report.Headers["Authorization"]; len(got) != 1 || got[0] != "[redacted]" {
+		t.Errorf("Authorization header was not redacted: %v", got)
+	}
+
+	var sb strings.Builder
+	_ = registry.WriteText(&sb)
+	if !strings.Contains(sb.String(), `http_panics_total{route="/todos/{id}"} 1`) {
+		t.Errorf("Panic was not counted:\n%s", sb.String())
+	}
+}
+
+func TestRecovery_RedactsSecretPath(t *testing.T) {
+	reporter := &memoryReporter{}
+	handler := Recovery(func(*http.Request) string { return "/feed/{token}" }, nil, reporter)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}),
+	)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/feed/cal_s3cr3t.ics?type=todo", nil))
+
+	if len(reporter.reports) != 1 {
+		t.Fatalf("Expected 1 crash report, got %d", len(reporter.reports))
+	}
+	if got := reporter.reports[0].URL; got != "/feed/[redacted]?type=todo" {
+		t.Errorf("URL = %q, want feed token redacted", got)
+	}
+}
+
+func TestRecovery_AbortHandlerIsRepanicked(t *testing.T) {
+	handler := Recovery(func(*http.Request) string { return "" }, nil, nil)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		}),
+	)
+
+	defer func() {
+		if p := recover(); p != http.ErrAbortHandler {
+			t.Errorf("Expected ErrAbortHandler to propagate, got %v", p)
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
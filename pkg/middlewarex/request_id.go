@@ -0,0 +1,59 @@
+package middlewarex
+
+import (
+	"crypto/rand"
+	"ecom_test/pkg/contextx"
+	"encoding/hex"
+	"net/http"
+)
+
+const (
+	HeaderRequestID = "X-Request-ID"
+
+	maxRequestIDLength = 128
+)
+
+// RequestID принимает X-Request-ID клиента или генерирует новый, возвращает его в ответе
+// и кладёт в контекст логгер с request_id, method и route.
+func RequestID(route RouteFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(HeaderRequestID)
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+			w.Header().Set(HeaderRequestID, id)
+
+			ctx := contextx.WithRequestID(r.Context(), id)
+			ctx = contextx.WithLogger(ctx, logger(ctx).With(
+				"request_id", id,
+				"method", r.Method,
+				"route", route(r),
+			))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// validRequestID не пускает в логи произвольный мусор из заголовка.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
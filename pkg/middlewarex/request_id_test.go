@@ -0,0 +1,59 @@
+package middlewarex
+
+import (
+	"bytes"
+	"ecom_test/pkg/contextx"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		wantSame bool
+	}{
+		{name: "Accepts client id", incoming: "abc-123_DEF.4:5", wantSame: true},
+		{name: "Generates when missing", incoming: ""},
+		{name: "Replaces invalid id", incoming: "bad id\nwith newline"},
+		{name: "Replaces too long id", incoming: strings.Repeat("a", maxRequestIDLength+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			var ctxID string
+
+			handler := RequestID(func(*http.Request) string { return "/todos/{id}" })(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					ctxID, _ = contextx.RequestIDFromContext(r.Context())
+					contextx.LoggerFromContextOrDefault(r.Context()).Info("handled")
+				}),
+			)
+
+			req := httptest.NewRequest(http.MethodGet, "/todos/1", nil)
+			if tt.incoming != "" {
+				req.Header.Set(HeaderRequestID, tt.incoming)
+			}
+			req = req.WithContext(contextx.WithLogger(req.Context(), slog.New(slog.NewTextHandler(&logs, nil))))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(HeaderRequestID)
+			if got == "" || got != ctxID {
+				t.Fatalf("Response id %q does not match context id %q", got, ctxID)
+			}
+			if tt.wantSame != (got == tt.incoming) {
+				t.Errorf("Got id %q for incoming %q", got, tt.incoming)
+			}
+			for _, want := range []string{"request_id=" + got, "method=GET", "route=/todos/{id}"} {
+				if !strings.Contains(logs.String(), want) {
+					t.Errorf("Log %q does not contain %q", logs.String(), want)
+				}
+			}
+		})
+	}
+}
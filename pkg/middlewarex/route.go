@@ -0,0 +1,19 @@
+package middlewarex
+
+import "net/http"
+
+// RouteFunc возвращает шаблон маршрута (например "/todos/{id}") для меток и логов,
+// сырые пути использовать нельзя из-за неограниченной кардинальности.
+type RouteFunc func(r *http.Request) string
+
+const UnmatchedRoute = "unmatched"
+
+func MuxRoute(mux *http.ServeMux) RouteFunc {
+	return func(r *http.Request) string {
+		_, pattern := mux.Handler(r)
+		if pattern == "" {
+			return UnmatchedRoute
+		}
+		return pattern
+	}
+}
@@ -0,0 +1,165 @@
+package middlewarex
+
+import (
+	"cmp"
+	"context"
+	"ecom_test/pkg/contextx"
+	"ecom_test/pkg/ratelimit"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type TenantSource string
+
+const (
+	TenantFromHeader    TenantSource = "header"
+	TenantFromSubdomain TenantSource = "subdomain"
+	TenantFromClaim     TenantSource = "claim"
+)
+
+type TenantInfo struct {
+	Active bool
+	// Rate квота запросов арендатора, нулевой Rate снимает ограничение.
+	Rate ratelimit.Limit
+}
+
+type TenantDirectory interface {
+	LookupTenant(ctx context.Context, tenantID string) (TenantInfo, bool)
+}
+
+type TenantOptions struct {
+	// Sources порядок источников, первый найденный побеждает.
+	Sources    []TenantSource
+	Header     string
+	BaseDomain string
+	Claim      string
+	// Default арендатор для запросов без явного указания, пустая строка требует указания.
+	Default string
+	// Directory без него любой арендатор считается активным и без квоты.
+	Directory TenantDirectory
+	Limiter   *ratelimit.Limiter
+	// Exempt префиксы путей, которые обслуживаются вне арендаторов (пробы, администрирование).
+	Exempt []string
+	// RequireBinding отклоняет субъектов без арендатора: иначе такой субъект выбирал бы любого
+	// арендатора заголовком или поддоменом. Запросы без Principal не затрагиваются, их отклонит RequireScope.
+	RequireBinding bool
+}
+
+// Tenant определяет арендатора запроса и кладёт его в контекст. Должен стоять после Authenticate,
+// чтобы читать Principal. Субъект привязан к арендатору через Principal.Tenant (API-ключ) или
+// claim токена; другой арендатор из заголовка или поддомена для него отклоняется, а без явного
+// указания берётся его арендатор.
+func Tenant(opts TenantOptions) func(http.Handler) http.Handler {
+	if opts.Header == "" {
+		opts.Header = "X-Tenant-ID"
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, prefix := range opts.Exempt {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			principal, err := contextx.PrincipalFromContext(r.Context())
+			authenticated := err == nil
+			bound := opts.bound(principal)
+			if authenticated && bound == "" && opts.RequireBinding {
+				WriteProblem(w, r, http.StatusForbidden, "tenant_unbound", "credentials are not bound to a tenant")
+				return
+			}
+
+			tenantID, conflict := opts.resolve(r, bound)
+			if tenantID == "" {
+				tenantID = cmp.Or(bound, opts.Default)
+			}
+			if conflict || (bound != "" && tenantID != bound) {
+				WriteProblem(w, r, http.StatusForbidden, "tenant_mismatch", "credentials are issued for another tenant")
+				return
+			}
+			if tenantID == "" {
+				WriteProblem(w, r, http.StatusBadRequest, "tenant_required", "")
+				return
+			}
+
+			info, ok := TenantInfo{Active: true}, true
+			if opts.Directory != nil {
+				info, ok = opts.Directory.LookupTenant(r.Context(), tenantID)
+			}
+			if !ok {
+				WriteProblem(w, r, http.StatusNotFound, "tenant_not_found", "")
+				return
+			}
+			if !info.Active {
+				WriteProblem(w, r, http.StatusForbidden, "tenant_suspended", "")
+				return
+			}
+
+			if opts.Limiter != nil && !info.Rate.Unlimited() {
+				res := opts.Limiter.Allow("tenant:"+tenantID, info.Rate)
+				if !res.Allowed {
+					w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+					WriteProblem(w, r, http.StatusTooManyRequests, "tenant_rate_limited", "")
+					return
+				}
+			}
+
+			ctx := contextx.WithTenant(r.Context(), tenantID)
+			ctx = contextx.WithLogger(ctx, logger(ctx).With("tenant", tenantID))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// resolve возвращает арендатора по источникам. conflict означает, что субъект привязан к bound,
+// а какой-то источник указывает на другого арендатора.
+func (o TenantOptions) resolve(r *http.Request, bound string) (tenantID string, conflict bool) {
+	for _, source := range o.Sources {
+		var v string
+		switch source {
+		case TenantFromHeader:
+			v = r.Header.Get(o.Header)
+		case TenantFromSubdomain:
+			v = o.subdomain(r.Host)
+		case TenantFromClaim:
+			v = bound
+		}
+		if v == "" {
+			continue
+		}
+		if bound != "" && v != bound {
+			return "", true
+		}
+		if tenantID == "" {
+			tenantID = v
+		}
+	}
+	return tenantID, false
+}
+
+func (o TenantOptions) subdomain(host string) string {
+	if o.BaseDomain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	label, ok := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(o.BaseDomain))
+	if !ok || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
+
+// bound арендатор субъекта: Tenant API-ключа, иначе claim токена.
+func (o TenantOptions) bound(principal contextx.Principal) string {
+	if principal.Tenant != "" || o.Claim == "" {
+		return principal.Tenant
+	}
+	v, _ := principal.Claims[o.Claim].(string)
+	return v
+}
@@ -0,0 +1,173 @@
+package middlewarex
+
+import (
+	"context"
+	"ecom_test/pkg/auth"
+	"ecom_test/pkg/contextx"
+	"ecom_test/pkg/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeDirectory map[string]TenantInfo
+
+func (d fakeDirectory) LookupTenant(_ context.Context, tenantID string) (TenantInfo, bool) {
+	info, ok := d[tenantID]
+	return info, ok
+}
+
+func TestTenant(t *testing.T) {
+	handler := Tenant(TenantOptions{
+		Sources:    []TenantSource{TenantFromClaim, TenantFromHeader, TenantFromSubdomain},
+		BaseDomain: "todo.internal",
+		Claim:      "tenant",
+		Directory: fakeDirectory{
+			"team-a":  {Active: true},
+			"team-b":  {Active: true},
+			"frozen":  {Active: false},
+			"limited": {Active: true, Rate: ratelimit.Limit{Rate: 0.001, Burst: 1}},
+		},
+		Limiter: ratelimit.New(ratelimit.Options{}),
+		Exempt:  []string{"/healthz"},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenantID, _ := contextx.TenantFromContext(r.Context())
+		w.Header().Set("X-Resolved", tenantID)
+	}))
+
+	tests := []struct {
+		name       string
+		path       string
+		host       string
+		header     string
+		claim      string
+		wantStatus int
+		wantTenant string
+	}{
+		{name: "Header", header: "team-a", wantStatus: http.StatusOK, wantTenant: "team-a"},
+		{name: "Subdomain", host: "team-b.todo.internal:8080", wantStatus: http.StatusOK, wantTenant: "team-b"},
+		{name: "Header wins over subdomain", host: "team-b.todo.internal", header: "team-a", wantStatus: http.StatusOK, wantTenant: "team-a"},
+		{name: "Claim", claim: "team-b", wantStatus: http.StatusOK, wantTenant: "team-b"},
+		{name: "Claim agrees with header", claim: "team-a", header: "team-a", wantStatus: http.StatusOK, wantTenant: "team-a"},
+		{name: "Claim conflicts with header", claim: "team-a", header: "team-b", wantStatus: http.StatusForbidden},
+		{name: "Claim conflicts with subdomain", claim: "team-a", host: "team-b.todo.internal", wantStatus: http.StatusForbidden},
+		{name: "Nested subdomain ignored", host: "x.team-a.todo.internal", wantStatus: http.StatusBadRequest},
+		{name: "Missing", wantStatus: http.StatusBadRequest},
+		{name: "Unknown", header: "nobody", wantStatus: http.StatusNotFound},
+		{name: "Suspended", header: "frozen", wantStatus: http.StatusForbidden},
+		{name: "Exempt path", path: "/healthz", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := tt.path
+			if path == "" {
+				path = "/todos"
+			}
+			r := httptest.NewRequest(http.MethodGet, path, nil)
+			if tt.host != "" {
+				r.Host = tt.host
+			}
+			if tt.header != "" {
+				r.Header.Set("X-Tenant-ID", tt.header)
+			}
+			if tt.claim != "" {
+				r = r.WithContext(contextx.WithPrincipal(r.Context(), contextx.Principal{
+					Subject: "alice",
+					Claims:  map[string]any{"tenant": tt.claim},
+				}))
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("X-Resolved"); got != tt.wantTenant {
+				t.Errorf("tenant = %q, want %q", got, tt.wantTenant)
+			}
+		})
+	}
+
+	t.Run("Rate quota", func(t *testing.T) {
+		codes := make([]int, 0, 2)
+		for range 2 {
+			r := httptest.NewRequest(http.MethodGet, "/todos", nil)
+			r.Header.Set("X-Tenant-ID", "limited")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			codes = append(codes, w.Code)
+		}
+		if codes[0] != http.StatusOK || codes[1] != http.StatusTooManyRequests {
+			t.Errorf("Expected second request over quota to be rejected, got %v", codes)
+		}
+	})
+}
+
+func TestTenant_KeyBinding(t *testing.T) {
+	store := auth.NewKeyStore()
+	keyA, _, err := store.Mint("ci", "robot", "team-a", []string{"tasks:read"})
+	if err != nil {
+		t.Fatalf("Mint failed: %v", err)
+	}
+	unbound, _, err := store.Mint("ops", "admin", "", []string{"admin"})
+	if err != nil {
+		t.Fatalf("Mint failed: %v", err)
+	}
+
+	handler := Authenticate(store, nil)(Tenant(TenantOptions{
+		Sources:        []TenantSource{TenantFromHeader, TenantFromSubdomain},
+		BaseDomain:     "todo.internal",
+		Default:        "team-b",
+		Directory:      fakeDirectory{"team-a": {Active: true}, "team-b": {Active: true}},
+		Exempt:         []string{"/admin/"},
+		RequireBinding: true,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenantID, _ := contextx.TenantFromContext(r.Context())
+		w.Header().Set("X-Resolved", tenantID)
+	})))
+
+	tests := []struct {
+		name       string
+		path       string
+		key        string
+		host       string
+		header     string
+		wantStatus int
+		wantTenant string
+	}{
+		{name: "Own tenant by header", key: keyA, header: "team-a", wantStatus: http.StatusOK, wantTenant: "team-a"},
+		{name: "Own tenant instead of default", key: keyA, wantStatus: http.StatusOK, wantTenant: "team-a"},
+		{name: "Other tenant by header", key: keyA, header: "team-b", wantStatus: http.StatusForbidden},
+		{name: "Other tenant by subdomain", key: keyA, host: "team-b.todo.internal", wantStatus: http.StatusForbidden},
+		{name: "Unbound key", key: unbound, header: "team-a", wantStatus: http.StatusForbidden},
+		{name: "Unbound key on exempt path", path: "/admin/keys", key: unbound, wantStatus: http.StatusOK},
+		{name: "No credentials", header: "team-a", wantStatus: http.StatusOK, wantTenant: "team-a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := tt.path
+			if path == "" {
+				path = "/todos"
+			}
+			r := httptest.NewRequest(http.MethodGet, path, nil)
+			if tt.host != "" {
+				r.Host = tt.host
+			}
+			if tt.header != "" {
+				r.Header.Set("X-Tenant-ID", tt.header)
+			}
+			if tt.key != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.key)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("X-Resolved"); got != tt.wantTenant {
+				t.Errorf("tenant = %q, want %q", got, tt.wantTenant)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package middlewarex
+
+import (
+	"ecom_test/pkg/contextx"
+	"ecom_test/pkg/tracing"
+	"net/http"
+	"strings"
+)
+
+func Tracing(tracer *tracing.Tracer, route RouteFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			remote, _ := tracing.Extract(r.Header)
+
+			pattern := route(r)
+			name := pattern
+			if !strings.Contains(pattern, " ") {
+				name = r.Method + " " + pattern
+			}
+
+			ctx, span := tracer.StartServer(r.Context(), name, remote)
+			defer span.End()
+
+			span.SetAttribute("http.request.method", r.Method)
+			span.SetAttribute("http.route", pattern)
+			span.SetAttribute("url.path", redactPath(r.URL.Path))
+			span.SetAttribute("user_agent.original", r.UserAgent())
+			if id, err := contextx.RequestIDFromContext(ctx); err == nil {
+				span.SetAttribute("http.request.id", id)
+			}
+
+			sc := span.SpanContext()
+			w.Header().Set(tracing.HeaderTraceresponse, sc.Traceparent())
+
+			lw := &LoggingResponseWriter{
+				ResponseWriter: w,
+				StatusCode:     http.StatusOK,
+			}
+			next.ServeHTTP(lw, r.WithContext(ctx))
+
+			span.SetAttribute("http.response.status_code", lw.StatusCode)
+			if lw.StatusCode >= http.StatusInternalServerError {
+				span.SetStatus(tracing.StatusError, http.StatusText(lw.StatusCode))
+			}
+		})
+	}
+}
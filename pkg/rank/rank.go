@@ -0,0 +1,114 @@
+// Package rank дробные лексикографические ключи для ручной сортировки: между любыми двумя
+// ключами всегда есть третий, поэтому перемещение элемента меняет только его собственный ключ.
+package rank
+
+import (
+	"errors"
+	"strings"
+)
+
+// digits упорядочены по ASCII, поэтому сравнение строк совпадает со сравнением дробей.
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+const base = len(digits)
+
+var (
+	ErrInvalidKey = errors.New("invalid rank key")
+	ErrOrder      = errors.New("rank keys are out of order")
+)
+
+// Valid ключ непустой, из цифр base-62 и не оканчивается нулём
+// (иначе между "A" и "A0" не нашлось бы места).
+func Valid(key string) bool {
+	if key == "" || key[len(key)-1] == '0' {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(digits, key[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Between возвращает ключ строго между a и b. Пустой a означает начало списка, пустой b конец.
+func Between(a, b string) (string, error) {
+	if (a != "" && !Valid(a)) || (b != "" && !Valid(b)) {
+		return "", ErrInvalidKey
+	}
+	if a != "" && b != "" && a >= b {
+		return "", ErrOrder
+	}
+	return midpoint(a, b), nil
+}
+
+func midpoint(a, b string) string {
+	if b != "" {
+		// общий префикс переносится как есть, недостающие цифры a считаются нулями
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + midpoint(rest, b[n:])
+		}
+	}
+
+	da := 0
+	if a != "" {
+		da = strings.IndexByte(digits, a[0])
+	}
+	db := base
+	if b != "" {
+		db = strings.IndexByte(digits, b[0])
+	}
+	if db-da > 1 {
+		return string(digits[(da+db)/2])
+	}
+
+	// цифры соседние: если у b есть продолжение, его первая цифра уже больше a
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(digits[da]) + midpoint(rest, "")
+}
+
+func digitAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return digits[0]
+}
+
+// Spread n равномерно распределённых ключей минимальной длины, используется для ребалансировки.
+func Spread(n int) []string {
+	if n <= 0 {
+		return nil
+	}
+
+	width, capacity := 1, base
+	for capacity <= n {
+		width++
+		capacity *= base
+	}
+
+	keys := make([]string, n)
+	buf := make([]byte, width)
+	for i := range n {
+		v := (i + 1) * capacity / (n + 1)
+		for j := width - 1; j >= 0; j-- {
+			buf[j] = digits[v%base]
+			v /= base
+		}
+		keys[i] = strings.TrimRight(string(buf), "0")
+	}
+	return keys
+}
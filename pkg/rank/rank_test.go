@@ -0,0 +1,81 @@
+package rank
+
+import (
+	"errors"
+	"math/rand/v2"
+	"sort"
+	"testing"
+)
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		wantErr error
+	}{
+		{name: "Empty list"},
+		{name: "Append", a: "V"},
+		{name: "Prepend", b: "V"},
+		{name: "Wide gap", a: "1", b: "z"},
+		{name: "Adjacent digits", a: "A", b: "B"},
+		{name: "Prefix", a: "A", b: "A1"},
+		{name: "Deep prefix", a: "AzzzY", b: "B"},
+		{name: "Prepend to smallest", b: "01"},
+		{name: "Append after largest", a: "zzz"},
+		{name: "Out of order", a: "B", b: "A", wantErr: ErrOrder},
+		{name: "Equal", a: "B", b: "B", wantErr: ErrOrder},
+		{name: "Trailing zero", a: "A0", wantErr: ErrInvalidKey},
+		{name: "Bad digit", a: "A-", wantErr: ErrInvalidKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Between(tt.a, tt.b)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Between(%q, %q) error = %v, wantErr %v", tt.a, tt.b, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !Valid(got) || (tt.a != "" && got <= tt.a) || (tt.b != "" && got >= tt.b) {
+				t.Errorf("Between(%q, %q) = %q, not strictly between", tt.a, tt.b, got)
+			}
+		})
+	}
+}
+
+func TestBetweenRandomInserts(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	keys := []string{}
+	for range 2000 {
+		i := rng.IntN(len(keys) + 1)
+		var a, b string
+		if i > 0 {
+			a = keys[i-1]
+		}
+		if i < len(keys) {
+			b = keys[i]
+		}
+		key, err := Between(a, b)
+		if err != nil {
+			t.Fatalf("Between(%q, %q) error = %v", a, b, err)
+		}
+		keys = append(keys[:i], append([]string{key}, keys[i:]...)...)
+	}
+	if !sort.StringsAreSorted(keys) {
+		t.Errorf("Keys are not sorted after random inserts")
+	}
+}
+
+func TestSpread(t *testing.T) {
+	for _, n := range []int{1, 2, 61, 62, 1000} {
+		keys := Spread(n)
+		if len(keys) != n || !sort.StringsAreSorted(keys) {
+			t.Fatalf("Spread(%d) is not a sorted list of %d keys", n, n)
+		}
+		for i, k := range keys {
+			if !Valid(k) || (i > 0 && keys[i-1] == k) {
+				t.Fatalf("Spread(%d)[%d] = %q is invalid or duplicate", n, i, k)
+			}
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+var ErrInvalidLimit = errors.New("invalid rate limit")
+
+// Limit задаёт скорость пополнения в токенах в секунду и ёмкость корзины, Rate <= 0 снимает ограничение.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0
+}
+
+// Validate проверяет, что ограниченная корзина вмещает хотя бы один запрос, иначе она отказывала бы всегда.
+func (l Limit) Validate() error {
+	if math.IsNaN(l.Rate) || math.IsInf(l.Rate, 0) {
+		return fmt.Errorf("%w: rate %v", ErrInvalidLimit, l.Rate)
+	}
+	if !l.Unlimited() && l.Burst < 1 {
+		return fmt.Errorf("%w: burst %d must be at least 1", ErrInvalidLimit, l.Burst)
+	}
+	return nil
+}
+
+// Window время полного пополнения корзины, используется в RateLimit-Policy.
+func (l Limit) Window() time.Duration {
+	if l.Unlimited() {
+		return 0
+	}
+	return time.Duration(float64(l.Burst) / l.Rate * float64(time.Second))
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset через сколько корзина снова будет полной.
+	Reset time.Duration
+	// RetryAfter через сколько появится следующий токен, имеет смысл только при Allowed == false.
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type Options struct {
+	// IdleTTL после скольких секунд простоя корзина удаляется.
+	IdleTTL time.Duration
+	// MaxKeys верхняя граница числа корзин, новые ключи сверх неё получают отказ.
+	MaxKeys int
+}
+
+// Limiter набор token bucket по ключам, память ограничена вытеснением простаивающих корзин и MaxKeys.
+type Limiter struct {
+	opts Options
+	now  func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	// nextSweep раньше этого момента ни одна корзина не простаивает IdleTTL, и переполненный
+	// лимитер отказывает новым ключам без обхода всех корзин.
+	nextSweep time.Time
+}
+
+func New(opts Options) *Limiter {
+	if opts.IdleTTL <= 0 {
+		opts.IdleTTL = 10 * time.Minute
+	}
+	if opts.MaxKeys <= 0 {
+		opts.MaxKeys = 100_000
+	}
+	return &Limiter{
+		opts:    opts,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow списывает токен из корзины ключа. Burst меньше 1 считается равным 1: такой лимит
+// не проходит Validate, но и не блокирует ключ навсегда.
+func (l *Limiter) Allow(key string, limit Limit) Result {
+	if limit.Unlimited() {
+		return Result{Allowed: true}
+	}
+	limit.Burst = max(limit.Burst, 1)
+
+	now := l.now()
+	burst := float64(limit.Burst)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= l.opts.MaxKeys && !now.Before(l.nextSweep) {
+			l.sweepLocked(now)
+		}
+		if len(l.buckets) >= l.opts.MaxKeys {
+			return Result{Limit: limit.Burst, RetryAfter: time.Second, Reset: limit.Window()}
+		}
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	res := Result{Limit: limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = time.Duration((burst - b.tokens) / limit.Rate * float64(time.Second))
+	return res
+}
+
+// Sweep удаляет корзины без обращений дольше IdleTTL и возвращает их число.
+func (l *Limiter) Sweep() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sweepLocked(l.now())
+}
+
+func (l *Limiter) sweepLocked(now time.Time) int {
+	removed := 0
+	oldest := now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= l.opts.IdleTTL {
+			delete(l.buckets, key)
+			removed++
+			continue
+		}
+		if b.last.Before(oldest) {
+			oldest = b.last
+		}
+	}
+	// last корзин только растёт, поэтому до oldest+IdleTTL новых кандидатов на удаление не будет
+	l.nextSweep = oldest.Add(l.opts.IdleTTL)
+	return removed
+}
+
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+func (l *Limiter) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = l.opts.IdleTTL / 2
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.Sweep()
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package ratelimit
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestLimiter(opts Options) (*Limiter, *fakeClock) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	l := New(opts)
+	l.now = clock.now
+	return l, clock
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	l, clock := newTestLimiter(Options{})
+	limit := Limit{Rate: 1, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		if res := l.Allow("client", limit); !res.Allowed || res.Remaining != 2-i {
+			t.Fatalf("Request %d: unexpected result %+v", i, res)
+		}
+	}
+
+	res := l.Allow("client", limit)
+	if res.Allowed {
+		t.Fatalf("Expected 4th request to be limited")
+	}
+	if res.RetryAfter != time.Second {
+		t.Errorf("Expected RetryAfter 1s, got %v", res.RetryAfter)
+	}
+	if res.Reset != 3*time.Second {
+		t.Errorf("Expected Reset 3s, got %v", res.Reset)
+	}
+
+	if res := l.Allow("other", limit); !res.Allowed {
+		t.Errorf("Expected other client to have its own bucket")
+	}
+
+	clock.advance(time.Second)
+	if res := l.Allow("client", limit); !res.Allowed {
+		t.Errorf("Expected token to be refilled after 1s")
+	}
+
+	if res := l.Allow("client", Limit{}); !res.Allowed {
+		t.Errorf("Expected zero limit to be unlimited")
+	}
+}
+
+func TestLimiter_EvictsIdleBuckets(t *testing.T) {
+	l, clock := newTestLimiter(Options{IdleTTL: time.Minute, MaxKeys: 2})
+	limit := Limit{Rate: 1, Burst: 1}
+
+	l.Allow("a", limit)
+	l.Allow("b", limit)
+	if res := l.Allow("c", limit); res.Allowed {
+		t.Errorf("Expected new key to be rejected when limiter is full")
+	}
+
+	clock.advance(time.Minute)
+	if res := l.Allow("c", limit); !res.Allowed {
+		t.Errorf("Expected idle buckets to be evicted for new key")
+	}
+	if l.Len() != 1 {
+		t.Errorf("Expected 1 bucket after eviction, got %d", l.Len())
+	}
+}
+
+func TestLimiter_SweepsFullLimiterOnlyWhenBucketsCanExpire(t *testing.T) {
+	l, clock := newTestLimiter(Options{IdleTTL: time.Minute, MaxKeys: 2})
+	limit := Limit{Rate: 1, Burst: 1}
+
+	l.Allow("a", limit)
+	clock.advance(30 * time.Second)
+	l.Allow("b", limit)
+	if res := l.Allow("c", limit); res.Allowed {
+		t.Fatalf("Expected new key to be rejected when limiter is full")
+	}
+	want := clock.t.Add(30 * time.Second)
+	if !l.nextSweep.Equal(want) {
+		t.Fatalf("Expected next sweep at %v when a expires, got %v", want, l.nextSweep)
+	}
+
+	// обходить корзины до истечения самой старой бессмысленно
+	l.buckets["stale"] = &bucket{last: clock.t.Add(-time.Hour)}
+	delete(l.buckets, "b")
+	clock.advance(10 * time.Second)
+	if res := l.Allow("c", limit); res.Allowed {
+		t.Errorf("Expected full limiter to reject without sweeping before next sweep")
+	}
+	if _, ok := l.buckets["stale"]; !ok {
+		t.Errorf("Expected no sweep before %v", l.nextSweep)
+	}
+
+	clock.advance(20 * time.Second)
+	if res := l.Allow("c", limit); !res.Allowed {
+		t.Errorf("Expected expired buckets to be evicted for new key")
+	}
+}
+
+func TestLimit_Validate(t *testing.T) {
+	tests := []struct {
+		limit   Limit
+		wantErr bool
+	}{
+		{limit: Limit{}},
+		{limit: Limit{Rate: -1}},
+		{limit: Limit{Rate: 1, Burst: 1}},
+		{limit: Limit{Rate: 1}, wantErr: true},
+		{limit: Limit{Rate: 10, Burst: -5}, wantErr: true},
+		{limit: Limit{Rate: math.Inf(1), Burst: 1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		err := tt.limit.Validate()
+		if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrInvalidLimit)) {
+			t.Errorf("%+v.Validate() = %v, wantErr %v", tt.limit, err, tt.wantErr)
+		}
+	}
+
+	l, _ := newTestLimiter(Options{})
+	if res := l.Allow("client", Limit{Rate: 1}); !res.Allowed || res.Limit != 1 {
+		t.Errorf("Expected zero burst to behave as burst 1, got %+v", res)
+	}
+}
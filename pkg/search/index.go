@@ -0,0 +1,216 @@
+package search
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Параметры BM25, стандартные значения из литературы.
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Hit документ, прошедший все условия запроса, и его оценка.
+type Hit struct {
+	ID    int
+	Score float64
+}
+
+// Index инвертированный индекс с позициями по полям документа. Не потокобезопасен:
+// синхронизация на вызывающем, как и у хранилища, в которое он встроен.
+type Index struct {
+	weights []float64
+	// postings терм -> документ -> позиции терма в каждом поле
+	postings map[string]map[int][][]int
+	docs     map[int]document
+	totalLen []int
+}
+
+type document struct {
+	lengths []int
+	terms   []string
+}
+
+// NewIndex веса задают число полей документа и их вклад в оценку, например заголовок важнее описания.
+func NewIndex(weights ...float64) *Index {
+	return &Index{
+		weights:  weights,
+		postings: make(map[string]map[int][][]int),
+		docs:     make(map[int]document),
+		totalLen: make([]int, len(weights)),
+	}
+}
+
+// Len число документов в индексе.
+func (ix *Index) Len() int {
+	return len(ix.docs)
+}
+
+// Add индексирует документ, повторный Add заменяет прежнее содержимое. Лишние поля игнорируются.
+func (ix *Index) Add(id int, fields ...string) {
+	ix.Remove(id)
+
+	doc := document{lengths: make([]int, len(ix.weights))}
+	for f := range min(len(fields), len(ix.weights)) {
+		tokens := Tokenize(fields[f])
+		doc.lengths[f] = len(tokens)
+		ix.totalLen[f] += len(tokens)
+
+		for pos, tok := range tokens {
+			byDoc, ok := ix.postings[tok.Term]
+			if !ok {
+				byDoc = make(map[int][][]int)
+				ix.postings[tok.Term] = byDoc
+			}
+			positions, ok := byDoc[id]
+			if !ok {
+				positions = make([][]int, len(ix.weights))
+				doc.terms = append(doc.terms, tok.Term)
+			}
+			positions[f] = append(positions[f], pos)
+			byDoc[id] = positions
+		}
+	}
+	ix.docs[id] = doc
+}
+
+func (ix *Index) Remove(id int) {
+	doc, ok := ix.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range doc.terms {
+		delete(ix.postings[term], id)
+		if len(ix.postings[term]) == 0 {
+			delete(ix.postings, term)
+		}
+	}
+	for f, n := range doc.lengths {
+		ix.totalLen[f] -= n
+	}
+	delete(ix.docs, id)
+}
+
+// Search документы, удовлетворяющие всем условиям запроса, по убыванию оценки BM25F.
+func (ix *Index) Search(q Query) []Hit {
+	if len(q.Clauses) == 0 || len(ix.docs) == 0 {
+		return nil
+	}
+
+	var scores map[int]float64
+	for _, clause := range q.Clauses {
+		matched := ix.match(clause)
+		if scores == nil {
+			scores = matched
+		} else {
+			for id, score := range scores {
+				if extra, ok := matched[id]; ok {
+					scores[id] = score + extra
+				} else {
+					delete(scores, id)
+				}
+			}
+		}
+		if len(scores) == 0 {
+			return nil
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	slices.SortFunc(hits, func(a, b Hit) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.ID, b.ID))
+	})
+	return hits
+}
+
+// match оценки документов, подходящих под одно условие запроса.
+func (ix *Index) match(c Clause) map[int]float64 {
+	scores := make(map[int]float64)
+	switch {
+	case c.Prefix:
+		// префикс раскрывается в термы словаря, документ получает лучшую из их оценок
+		for term := range ix.postings {
+			if !slices.ContainsFunc(c.Terms, func(prefix string) bool { return strings.HasPrefix(term, prefix) }) {
+				continue
+			}
+			for id, score := range ix.termScores(term, nil) {
+				scores[id] = max(scores[id], score)
+			}
+		}
+	case len(c.Terms) > 1:
+		candidates := ix.phraseMatches(c.Terms)
+		for _, term := range c.Terms {
+			for id, score := range ix.termScores(term, candidates) {
+				scores[id] += score
+			}
+		}
+	default:
+		scores = ix.termScores(c.Terms[0], nil)
+	}
+	return scores
+}
+
+// termScores BM25F: частоты по полям складываются с весами и нормализацией на длину поля,
+// насыщение k1 применяется к сумме. only ограничивает набор документов, nil без ограничения.
+func (ix *Index) termScores(term string, only map[int]struct{}) map[int]float64 {
+	byDoc := ix.postings[term]
+	n, df := float64(len(ix.docs)), float64(len(byDoc))
+	idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+	scores := make(map[int]float64, len(byDoc))
+	for id, positions := range byDoc {
+		if only != nil {
+			if _, ok := only[id]; !ok {
+				continue
+			}
+		}
+		var tf float64
+		for f, pos := range positions {
+			if len(pos) == 0 {
+				continue
+			}
+			avg := float64(ix.totalLen[f]) / n
+			norm := 1 - b + b*float64(ix.docs[id].lengths[f])/avg
+			tf += ix.weights[f] * float64(len(pos)) / norm
+		}
+		scores[id] = idf * tf * (k1 + 1) / (tf + k1)
+	}
+	return scores
+}
+
+// phraseMatches документы, где термы идут подряд в одном поле.
+func (ix *Index) phraseMatches(terms []string) map[int]struct{} {
+	found := make(map[int]struct{})
+	for id, first := range ix.postings[terms[0]] {
+		for f, starts := range first {
+			if ix.phraseIn(id, f, starts, terms[1:]) {
+				found[id] = struct{}{}
+				break
+			}
+		}
+	}
+	return found
+}
+
+func (ix *Index) phraseIn(id, field int, starts []int, rest []string) bool {
+	for _, start := range starts {
+		ok := true
+		for i, term := range rest {
+			positions := ix.postings[term][id]
+			if positions == nil || !slices.Contains(positions[field], start+i+1) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+var ErrInvalidQuery = errors.New("invalid search query")
+
+// Query условия объединяются через И.
+type Query struct {
+	Clauses []Clause
+}
+
+// Clause одно слово, фраза в кавычках (несколько термов подряд) или префикс со звёздочкой.
+// У префикса Terms варианты: слово в нижнем регистре и его основа, подходит любой.
+type Clause struct {
+	Terms  []string
+	Prefix bool
+}
+
+// ParseQuery разбирает строку вида `купить "свежий хлеб" молок*`. Слова нормализуются так же,
+// как текст при индексации. Префикс ищется и как есть, и по основе: в индексе лежат основы, и
+// целое слово со звёздочкой (молоко*) иначе не нашло бы само себя.
+func ParseQuery(s string) (Query, error) {
+	var q Query
+	rest := strings.TrimSpace(s)
+	for rest != "" {
+		if rest[0] == '"' {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return Query{}, fmt.Errorf("%w: unterminated phrase at %d", ErrInvalidQuery, len(s)-len(rest))
+			}
+			if terms := termsOf(rest[1 : end+1]); len(terms) > 0 {
+				q.Clauses = append(q.Clauses, Clause{Terms: terms})
+			}
+			rest = strings.TrimSpace(rest[end+2:])
+			continue
+		}
+
+		word := rest
+		if i := strings.IndexFunc(rest, unicode.IsSpace); i >= 0 {
+			word = rest[:i]
+		}
+		rest = strings.TrimSpace(rest[len(word):])
+		if prefix, ok := strings.CutSuffix(word, "*"); ok {
+			tokens := Tokenize(prefix)
+			if len(tokens) != 1 {
+				return Query{}, fmt.Errorf("%w: bad prefix %q", ErrInvalidQuery, word)
+			}
+			folded := fold(prefix[tokens[0].Start:tokens[0].End])
+			terms := []string{folded}
+			if stem := Stem(folded); stem != folded {
+				terms = append(terms, stem)
+			}
+			q.Clauses = append(q.Clauses, Clause{Terms: terms, Prefix: true})
+			continue
+		}
+		for _, term := range termsOf(word) {
+			q.Clauses = append(q.Clauses, Clause{Terms: []string{term}})
+		}
+	}
+
+	if len(q.Clauses) == 0 {
+		return Query{}, fmt.Errorf("%w: empty query", ErrInvalidQuery)
+	}
+	return q, nil
+}
+
+func termsOf(text string) []string {
+	tokens := Tokenize(text)
+	terms := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		terms = append(terms, t.Term)
+	}
+	return terms
+}
+
+// Matches подходит ли нормализованное слово текста под какое-либо условие, для подсветки.
+func (q Query) Matches(term string) bool {
+	for _, c := range q.Clauses {
+		for _, t := range c.Terms {
+			if c.Prefix && strings.HasPrefix(term, t) || !c.Prefix && term == t {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package search
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	groups := [][]string{
+		{"задача", "Задачи", "задачу", "задачами"},
+		{"купить", "купил", "купила"},
+		{"молоко", "молока", "Молоку"},
+		{"ёлка", "елки"},
+		{"task", "tasks", "Tasks"},
+		{"run", "running", "runs"},
+		{"plan", "planned", "planning"},
+		{"query", "queries"},
+	}
+	for _, group := range groups {
+		want := Normalize(group[0])
+		for _, word := range group[1:] {
+			if got := Normalize(word); got != want {
+				t.Errorf("Normalize(%q) = %q, want %q like %q", word, got, want, group[0])
+			}
+		}
+	}
+
+	distinct := [][2]string{{"fall", "fail"}, {"стол", "стул"}, {"bus", "bu"}}
+	for _, pair := range distinct {
+		if Normalize(pair[0]) == Normalize(pair[1]) {
+			t.Errorf("Normalize(%q) == Normalize(%q), want different stems", pair[0], pair[1])
+		}
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    int
+		wantErr error
+	}{
+		{query: "купить хлеб", want: 2},
+		{query: `"fresh bread" milk`, want: 2},
+		{query: "молок*", want: 1},
+		{query: "  ", wantErr: ErrInvalidQuery},
+		{query: `"unterminated`, wantErr: ErrInvalidQuery},
+		{query: "*", wantErr: ErrInvalidQuery},
+		{query: `""`, wantErr: ErrInvalidQuery},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := ParseQuery(tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseQuery() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(q.Clauses) != tt.want {
+				t.Errorf("ParseQuery() = %d clauses, want %d", len(q.Clauses), tt.want)
+			}
+		})
+	}
+}
+
+func TestIndex_Search(t *testing.T) {
+	ix := NewIndex(2, 1)
+	ix.Add(1, "Купить молоко", "Свежее молоко в магазине у дома")
+	ix.Add(2, "Позвонить маме", "Спросить про молоко")
+	ix.Add(3, "Write release notes", "Notes for the planned release of the search feature")
+	ix.Add(4, "Release party", "Plan the party after the release")
+	ix.Add(5, "Черновик", "будет удалён")
+	ix.Remove(5)
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{query: "молока", want: []int{1, 2}},
+		{query: "купили молоко", want: []int{1}},
+		{query: `"свежее молоко"`, want: []int{1}},
+		{query: `"молоко свежее"`, want: nil},
+		{query: "RELEASES", want: []int{4, 3}},
+		{query: `"release notes"`, want: []int{3}},
+		{query: "plan", want: []int{4, 3}},
+		{query: "маг*", want: []int{1}},
+		{query: "молоко*", want: []int{1, 2}},
+		{query: "releases*", want: []int{4, 3}},
+		{query: "черновик", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery() error = %v", err)
+			}
+			var got []int
+			for _, hit := range ix.Search(q) {
+				got = append(got, hit.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Search() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if ix.Len() != 4 {
+		t.Errorf("Len() = %d, want 4", ix.Len())
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	q, _ := ParseQuery("молоко")
+	opts := SnippetOptions{Before: "[", After: "]", Width: 20}
+
+	text := "Утром нужно зайти в магазин у дома и купить свежее молоко, хлеб и немного сыра к ужину"
+	if got, want := Snippet(text, q, opts), "…свежее [молоко], хлеб и…"; got != want {
+		t.Errorf("Snippet() = %q, want %q", got, want)
+	}
+	if got, want := Highlight("Молоко и молока", q, opts), "[Молоко] и [молока]"; got != want {
+		t.Errorf("Highlight() = %q, want %q", got, want)
+	}
+	if got := Snippet("нет совпадений", q, opts); got != "" {
+		t.Errorf("Snippet() = %q, want empty", got)
+	}
+}
@@ -0,0 +1,95 @@
+package search
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// SnippetOptions нулевое значение даёт <mark>…</mark> и окно в 80 символов.
+type SnippetOptions struct {
+	Before string
+	After  string
+	// Width примерная длина фрагмента в символах.
+	Width int
+}
+
+func (o SnippetOptions) withDefaults() SnippetOptions {
+	if o.Before == "" && o.After == "" {
+		o.Before, o.After = "<mark>", "</mark>"
+	}
+	if o.Width <= 0 {
+		o.Width = 80
+	}
+	return o
+}
+
+// Highlight размечает все совпадения с запросом во всём тексте.
+func Highlight(text string, q Query, opts SnippetOptions) string {
+	opts = opts.withDefaults()
+	return mark(text, 0, len(text), Tokenize(text), q, opts)
+}
+
+// Snippet фрагмент вокруг первого совпадения с подсветкой, обрезанный по границам слов.
+// Пустая строка, если в тексте совпадений нет.
+func Snippet(text string, q Query, opts SnippetOptions) string {
+	opts = opts.withDefaults()
+	tokens := Tokenize(text)
+
+	first := -1
+	for i, t := range tokens {
+		if q.Matches(t.Term) {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return ""
+	}
+
+	// окно расширяется по словам поочерёдно влево и вправо, пока не наберётся Width символов
+	lo, hi := first, first
+	width := func() int { return utf8.RuneCountInString(text[tokens[lo].Start:tokens[hi].End]) }
+	for width() < opts.Width && (lo > 0 || hi < len(tokens)-1) {
+		if hi < len(tokens)-1 {
+			hi++
+		}
+		if lo > 0 && width() < opts.Width {
+			lo--
+		}
+	}
+
+	start, end := tokens[lo].Start, tokens[hi].End
+	if lo == 0 {
+		start = 0
+	}
+	if hi == len(tokens)-1 {
+		end = len(text)
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("…")
+	}
+	sb.WriteString(strings.TrimSpace(mark(text, start, end, tokens[lo:hi+1], q, opts)))
+	if end < len(text) {
+		sb.WriteString("…")
+	}
+	return sb.String()
+}
+
+func mark(text string, start, end int, tokens []Token, q Query, opts SnippetOptions) string {
+	var sb strings.Builder
+	pos := start
+	for _, t := range tokens {
+		if !q.Matches(t.Term) {
+			continue
+		}
+		sb.WriteString(text[pos:t.Start])
+		sb.WriteString(opts.Before)
+		sb.WriteString(text[t.Start:t.End])
+		sb.WriteString(opts.After)
+		pos = t.End
+	}
+	sb.WriteString(text[pos:end])
+	return sb.String()
+}
@@ -0,0 +1,93 @@
+package search
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Стеммеры намеренно простые: задача сводить формы одного слова к общей основе, а не
+// получать лингвистически точный корень. Одна и та же функция применяется к запросу и к индексу.
+
+// stemEnglish урезанный Porter: множественное число, -ing/-ed и -ly.
+func stemEnglish(w string) string {
+	switch {
+	case strings.HasSuffix(w, "sses"):
+		w = w[:len(w)-2]
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		w = w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") &&
+		!strings.HasSuffix(w, "us") && !strings.HasSuffix(w, "is"):
+		w = w[:len(w)-1]
+	}
+
+	for _, suffix := range []string{"ing", "ed"} {
+		stem, ok := strings.CutSuffix(w, suffix)
+		if !ok || len(stem) < 3 || !strings.ContainsAny(stem, "aeiouy") {
+			continue
+		}
+		// running -> runn -> run, но не fall -> fal
+		if n := len(stem); stem[n-1] == stem[n-2] && !strings.ContainsRune("aeiouylsz", rune(stem[n-1])) {
+			stem = stem[:n-1]
+		}
+		return stem
+	}
+
+	if stem, ok := strings.CutSuffix(w, "ly"); ok && len(stem) >= 3 {
+		return stem
+	}
+	return w
+}
+
+const russianVowels = "аеиоуыэюя"
+
+// russianEndings окончания прилагательных, глаголов и существительных из Snowball,
+// длинные раньше коротких, чтобы срабатывало самое длинное совпадение.
+var russianEndings = sortByLength([]string{ //nolint:gochecknoglobals
+	// прилагательные и причастия
+	"ими", "ыми", "его", "ого", "ему", "ому", "ее", "ие", "ые", "ое", "ей", "ий", "ый", "ой",
+	"ем", "им", "ым", "ом", "их", "ых", "ую", "юю", "ая", "яя", "ою", "ею",
+	// глаголы
+	"ейте", "уйте", "ила", "ыла", "ена", "ите", "или", "ыли", "ило", "ыло", "ено", "ует", "уют",
+	"ены", "ить", "ыть", "ишь", "ете", "йте", "ешь", "нно", "ла", "на", "ли", "ло", "но", "ет",
+	"ют", "ны", "ть", "ил", "ыл", "ен", "ят", "ит", "ыт", "л", "н",
+	// существительные
+	"иями", "ями", "ами", "ией", "иям", "ием", "иях", "ев", "ов", "ье", "еи", "ии", "ям", "ам",
+	"ах", "ях", "ию", "ью", "ия", "ья", "а", "е", "и", "й", "о", "у", "ы", "ь", "ю", "я",
+})
+
+func sortByLength(endings []string) []string {
+	for i := 1; i < len(endings); i++ {
+		for j := i; j > 0 && utf8.RuneCountInString(endings[j]) > utf8.RuneCountInString(endings[j-1]); j-- {
+			endings[j], endings[j-1] = endings[j-1], endings[j]
+		}
+	}
+	return endings
+}
+
+// stemRussian окончания снимаются только в RV (часть слова после первой гласной),
+// а основа не становится короче двух букв.
+func stemRussian(w string) string {
+	rv := strings.IndexAny(w, russianVowels)
+	if rv < 0 {
+		return w
+	}
+	_, size := utf8.DecodeRuneInString(w[rv:])
+	rv += size
+
+	cut := func(suffixes ...string) bool {
+		for _, s := range suffixes {
+			if stem, ok := strings.CutSuffix(w, s); ok && len(stem) >= rv && utf8.RuneCountInString(stem) >= 2 {
+				w = stem
+				return true
+			}
+		}
+		return false
+	}
+
+	cut("ся", "сь")
+	cut(russianEndings...)
+	if strings.HasSuffix(w, "нн") {
+		w = strings.TrimSuffix(w, "н")
+	}
+	return w
+}
@@ -0,0 +1,69 @@
+// Package search полнотекстовый поиск в памяти: токенизация с учётом Unicode, упрощённый
+// стемминг для русского и английского, инвертированный индекс с позициями и ранжирование BM25F.
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token слово текста после нормализации, Start и End байтовые смещения в исходной строке.
+type Token struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// Tokenize режет текст по всему, что не буква и не цифра, и нормализует каждое слово через Normalize.
+func Tokenize(text string) []Token {
+	var tokens []Token
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, Token{Term: Normalize(text[start:i]), Start: start, End: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, Token{Term: Normalize(text[start:]), Start: start, End: len(text)})
+	}
+	return tokens
+}
+
+// Normalize приводит слово к нижнему регистру, заменяет ё на е и отрезает окончание.
+func Normalize(word string) string {
+	return Stem(fold(word))
+}
+
+func fold(word string) string {
+	return strings.Map(func(r rune) rune {
+		r = unicode.ToLower(r)
+		if r == 'ё' {
+			return 'е'
+		}
+		return r
+	}, word)
+}
+
+// Stem выбирает стеммер по алфавиту слова, остальные слова (цифры, другие языки) не меняются.
+func Stem(word string) string {
+	if utf8.RuneCountInString(word) < 3 {
+		return word
+	}
+	for _, r := range word {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			return stemRussian(word)
+		case r < utf8.RuneSelf && unicode.IsLetter(r):
+			return stemEnglish(word)
+		}
+	}
+	return word
+}
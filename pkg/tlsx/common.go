@@ -0,0 +1,5 @@
+package tlsx
+
+import "ecom_test/pkg/contextx"
+
+var logger = contextx.LoggerFromContextOrDefault //nolint:gochecknoglobals
@@ -0,0 +1,76 @@
+package tlsx
+
+import (
+	"crypto/tls"
+	"errors"
+)
+
+const (
+	// CipherPolicyDefault оставляет выбор наборов шифров стандартной библиотеке.
+	CipherPolicyDefault = ""
+	// CipherPolicyIntermediate разрешает в TLS 1.2 только ECDHE + AEAD.
+	CipherPolicyIntermediate = "intermediate"
+	// CipherPolicyModern разрешает только TLS 1.3.
+	CipherPolicyModern = "modern"
+)
+
+var intermediateSuites = []uint16{ //nolint:gochecknoglobals
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+}
+
+var ErrNoClientCAs = errors.New("client certificate verification requires a client CA bundle")
+
+type Options struct {
+	MinVersion   uint16
+	CipherPolicy string
+	ClientAuth   tls.ClientAuthType
+}
+
+// ClientAuth режим проверки клиентов с учётом бандла CA: с бандлом и без явного режима
+// сертификат клиента обязателен, проверять сертификаты без бандла не по чему.
+func ClientAuth(mode tls.ClientAuthType, clientCAFile string) (tls.ClientAuthType, error) {
+	if clientCAFile == "" {
+		if mode >= tls.VerifyClientCertIfGiven {
+			return mode, ErrNoClientCAs
+		}
+		return mode, nil
+	}
+	if mode == tls.NoClientCert {
+		return tls.RequireAndVerifyClientCert, nil
+	}
+	return mode, nil
+}
+
+// ServerConfig собирает tls.Config, сертификат и CA клиентов берутся из reloader на каждом хендшейке.
+func ServerConfig(opts Options, reloader *Reloader) *tls.Config {
+	cfg := &tls.Config{
+		MinVersion:     opts.MinVersion,
+		ClientAuth:     opts.ClientAuth,
+		GetCertificate: reloader.GetCertificate,
+	}
+	if cfg.MinVersion == 0 {
+		cfg.MinVersion = tls.VersionTLS12
+	}
+
+	switch opts.CipherPolicy {
+	case CipherPolicyIntermediate:
+		cfg.CipherSuites = intermediateSuites
+	case CipherPolicyModern:
+		cfg.MinVersion = tls.VersionTLS13
+	}
+
+	if opts.ClientAuth != tls.NoClientCert {
+		cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			c := cfg.Clone()
+			c.GetConfigForClient = nil
+			c.ClientCAs = reloader.ClientCAs()
+			return c, nil
+		}
+	}
+	return cfg
+}
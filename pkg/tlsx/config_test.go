@@ -0,0 +1,35 @@
+package tlsx
+
+import (
+	"crypto/tls"
+	"errors"
+	"testing"
+)
+
+func TestClientAuth(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    tls.ClientAuthType
+		caFile  string
+		want    tls.ClientAuthType
+		wantErr error
+	}{
+		{name: "no mtls", mode: tls.NoClientCert, want: tls.NoClientCert},
+		{name: "ca defaults to require", mode: tls.NoClientCert, caFile: "ca.pem", want: tls.RequireAndVerifyClientCert},
+		{name: "explicit mode kept", mode: tls.VerifyClientCertIfGiven, caFile: "ca.pem", want: tls.VerifyClientCertIfGiven},
+		{name: "request without ca", mode: tls.RequestClientCert, want: tls.RequestClientCert},
+		{name: "verify if given without ca", mode: tls.VerifyClientCertIfGiven, wantErr: ErrNoClientCAs},
+		{name: "require and verify without ca", mode: tls.RequireAndVerifyClientCert, wantErr: ErrNoClientCAs},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ClientAuth(tt.mode, tt.caFile)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ClientAuth() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("ClientAuth() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package tlsx
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+var ErrNoCertificates = errors.New("no certificates found in CA bundle")
+
+// Reloader отдаёт актуальный сертификат сервера и пул CA клиентов, перечитывая файлы при их ротации.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	caPool   *x509.CertPool
+	modTimes map[string]time.Time
+}
+
+func NewReloader(certFile, keyFile, caFile string) (*Reloader, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+		modTimes: make(map[string]time.Time),
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// NewSelfSigned создаёт Reloader со сгенерированным в памяти сертификатом для dev-режима.
+func NewSelfSigned(hosts []string) (*Reloader, error) {
+	certPEM, keyPEM, err := GenerateSelfSigned(hosts, 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("tls.X509KeyPair: %w", err)
+	}
+	return &Reloader{cert: &cert, modTimes: make(map[string]time.Time)}, nil
+}
+
+func (r *Reloader) Reload() error {
+	if r.certFile == "" {
+		return nil
+	}
+
+	modTimes := make(map[string]time.Time, 3)
+	for _, path := range []string{r.certFile, r.keyFile, r.caFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("os.Stat: %w", err)
+		}
+		modTimes[path] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("tls.LoadX509KeyPair: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		bundle, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("os.ReadFile: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(bundle) {
+			return fmt.Errorf("%s: %w", r.caFile, ErrNoCertificates)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.caPool = pool
+	r.modTimes = modTimes
+	return nil
+}
+
+func (r *Reloader) changed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for path, modTime := range r.modTimes {
+		info, err := os.Stat(path)
+		if err != nil {
+			// во время ротации файл может на мгновение исчезнуть, дождёмся следующего тика
+			return false
+		}
+		if !info.ModTime().Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch опрашивает файлы с заданным интервалом, при ошибке продолжаем работать со старым сертификатом.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	if r.certFile == "" || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				logger(ctx).Error("tls certificate reload failed", slog.String("error", err.Error()))
+				continue
+			}
+			logger(ctx).Info("tls certificate reloaded", slog.String("cert", r.certFile))
+		}
+	}
+}
+
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+func (r *Reloader) ClientCAs() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.caPool
+}
@@ -0,0 +1,135 @@
+package tlsx
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writePair(t *testing.T, dir string, hosts []string) (certPEM []byte) {
+	t.Helper()
+	certPEM, keyPEM, err := GenerateSelfSigned(hosts, time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateSelfSigned: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tls.crt"), certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tls.key"), keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certPEM
+}
+
+func leaf(t *testing.T, r *Reloader) *x509.Certificate {
+	t.Helper()
+	cert, err := r.GetCertificate(nil)
+	if err != nil {
+		t.Fatalf("GetCertificate: %v", err)
+	}
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("ParseCertificate: %v", err)
+	}
+	return parsed
+}
+
+func TestReloader_Watch(t *testing.T) {
+	dir := t.TempDir()
+	writePair(t, dir, []string{"old.local"})
+
+	r, err := NewReloader(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), "")
+	if err != nil {
+		t.Fatalf("NewReloader: %v", err)
+	}
+	if got := leaf(t, r).DNSNames[0]; got != "old.local" {
+		t.Fatalf("Expected old.local, got %s", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Watch(ctx, 5*time.Millisecond)
+
+	writePair(t, dir, []string{"new.local"})
+	future := time.Now().Add(time.Minute)
+	for _, name := range []string{"tls.crt", "tls.key"} {
+		if err := os.Chtimes(filepath.Join(dir, name), future, future); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if leaf(t, r).DNSNames[0] == "new.local" {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Errorf("Certificate was not reloaded after rotation")
+}
+
+func TestServerConfig_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	writePair(t, dir, []string{"127.0.0.1"})
+
+	clientCertPEM, clientKeyPEM, err := GenerateSelfSigned([]string{"client.local"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caFile, clientCertPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReloader(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), caFile)
+	if err != nil {
+		t.Fatalf("NewReloader: %v", err)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.TLS.VerifiedChains[0][0].DNSNames[0])
+	}))
+	srv.TLS = ServerConfig(Options{
+		CipherPolicy: CipherPolicyIntermediate,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}, r)
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(leaf(t, r))
+	clientPair, err := tls.X509KeyPair(clientCertPEM, clientKeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Client without certificate is rejected", func(t *testing.T) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+		if _, err := client.Get(srv.URL); err == nil {
+			t.Errorf("Expected handshake error without client certificate")
+		}
+	})
+
+	t.Run("Client with trusted certificate is accepted", func(t *testing.T) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      roots,
+			Certificates: []tls.Certificate{clientPair},
+		}}}
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if string(body) != "client.local" {
+			t.Errorf("Expected client identity client.local, got %q", body)
+		}
+	})
+}
@@ -0,0 +1,163 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// StdoutExporter пишет каждый спан отдельной JSON-строкой.
+type StdoutExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewStdoutExporter(w io.Writer) *StdoutExporter {
+	return &StdoutExporter{w: w}
+}
+
+func (e *StdoutExporter) Export(ctx context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	enc := json.NewEncoder(e.w)
+	for _, s := range spans {
+		if err := enc.Encode(s); err != nil {
+			return fmt.Errorf("json.Encode: %w", err)
+		}
+	}
+	return nil
+}
+
+func (e *StdoutExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// OTLPExporter отправляет спаны в коллектор по OTLP/HTTP в JSON-кодировке.
+type OTLPExporter struct {
+	endpoint    string
+	serviceName string
+	headers     map[string]string
+	client      *http.Client
+}
+
+func NewOTLPExporter(endpoint, serviceName string, headers map[string]string, client *http.Client) *OTLPExporter {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &OTLPExporter{
+		endpoint:    endpoint,
+		serviceName: serviceName,
+		headers:     headers,
+		client:      client,
+	}
+}
+
+func (e *OTLPExporter) Export(ctx context.Context, spans []SpanData) error {
+	body, err := json.Marshal(e.payload(spans))
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("http.NewRequest: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("client.Do: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("otlp collector responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+type otlpKeyValue struct {
+	Key   string         `json:"key"`
+	Value map[string]any `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    StatusCode `json:"code,omitempty"`
+	Message string     `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              SpanKind       `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+func (e *OTLPExporter) payload(spans []SpanData) map[string]any {
+	out := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		span := otlpSpan{
+			TraceID:           s.TraceID,
+			SpanID:            s.SpanID,
+			ParentSpanID:      s.ParentSpanID,
+			Name:              s.Name,
+			Kind:              s.Kind,
+			StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.End.UnixNano(), 10),
+			Status:            otlpStatus{Code: s.StatusCode, Message: s.StatusMessage},
+		}
+		for _, a := range s.Attributes {
+			span.Attributes = append(span.Attributes, otlpKeyValue{Key: a.Key, Value: otlpValue(a.Value)})
+		}
+		out = append(out, span)
+	}
+
+	return map[string]any{
+		"resourceSpans": []any{map[string]any{
+			"resource": map[string]any{
+				"attributes": []otlpKeyValue{{Key: "service.name", Value: otlpValue(e.serviceName)}},
+			},
+			"scopeSpans": []any{map[string]any{
+				"scope": map[string]any{"name": "ecom_test/pkg/tracing"},
+				"spans": out,
+			}},
+		}},
+	}
+}
+
+// otlpValue кодирует AnyValue, int64 в OTLP JSON передаётся строкой.
+func otlpValue(v any) map[string]any {
+	switch val := v.(type) {
+	case string:
+		return map[string]any{"stringValue": val}
+	case bool:
+		return map[string]any{"boolValue": val}
+	case int:
+		return map[string]any{"intValue": strconv.Itoa(val)}
+	case int64:
+		return map[string]any{"intValue": strconv.FormatInt(val, 10)}
+	case float64:
+		return map[string]any{"doubleValue": val}
+	default:
+		return map[string]any{"stringValue": fmt.Sprint(val)}
+	}
+}
@@ -0,0 +1,46 @@
+package tracing
+
+import (
+	"context"
+	"ecom_test/pkg/contextx"
+	"log/slog"
+)
+
+var logger = contextx.LoggerFromContextOrDefault //nolint:gochecknoglobals
+
+// logHandler дописывает trace_id и span_id к каждой записи. Он всегда остаётся внешним
+// обработчиком, поэтому при открытии дочернего спана меняется только span_id, а атрибуты
+// добавленные через With раньше не теряются и не дублируются.
+type logHandler struct {
+	inner slog.Handler
+	sc    SpanContext
+}
+
+func withSpanLogger(l *slog.Logger, sc SpanContext) *slog.Logger {
+	inner := l.Handler()
+	if h, ok := inner.(*logHandler); ok {
+		inner = h.inner
+	}
+	return slog.New(&logHandler{inner: inner, sc: sc})
+}
+
+func (h *logHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+func (h *logHandler) Handle(ctx context.Context, record slog.Record) error {
+	record = record.Clone()
+	record.AddAttrs(
+		slog.String("trace_id", h.sc.TraceID.String()),
+		slog.String("span_id", h.sc.SpanID.String()),
+	)
+	return h.inner.Handle(ctx, record)
+}
+
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &logHandler{inner: h.inner.WithAttrs(attrs), sc: h.sc}
+}
+
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	return &logHandler{inner: h.inner.WithGroup(name), sc: h.sc}
+}
@@ -0,0 +1,146 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type SpanKind int
+
+// значения совпадают с enum SpanKind в OTLP
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+type Attribute struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+}
+
+// SpanData неизменяемый снимок завершённого спана, который получает Exporter.
+type SpanData struct {
+	Name          string      `json:"name"`
+	TraceID       string      `json:"trace_id"`
+	SpanID        string      `json:"span_id"`
+	ParentSpanID  string      `json:"parent_span_id,omitempty"`
+	Kind          SpanKind    `json:"kind"`
+	Start         time.Time   `json:"start"`
+	End           time.Time   `json:"end"`
+	Attributes    []Attribute `json:"attributes,omitempty"`
+	StatusCode    StatusCode  `json:"status_code"`
+	StatusMessage string      `json:"status_message,omitempty"`
+}
+
+// Span безопасен для nil-получателя, так код не проверяет включена ли трассировка.
+type Span struct {
+	tracer *Tracer
+	sc     SpanContext
+	parent SpanID
+	name   string
+	kind   SpanKind
+	start  time.Time
+
+	mu         sync.Mutex
+	ended      bool
+	attributes []Attribute
+	status     StatusCode
+	statusMsg  string
+}
+
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+func (s *Span) SetAttribute(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attributes = append(s.attributes, Attribute{Key: key, Value: value})
+}
+
+func (s *Span) SetStatus(code StatusCode, message string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = code
+	s.statusMsg = message
+}
+
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.SetAttribute("error.message", err.Error())
+	s.SetStatus(StatusError, err.Error())
+}
+
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	data := SpanData{
+		Name:          s.name,
+		TraceID:       s.sc.TraceID.String(),
+		SpanID:        s.sc.SpanID.String(),
+		Kind:          s.kind,
+		Start:         s.start,
+		End:           time.Now(),
+		Attributes:    s.attributes,
+		StatusCode:    s.status,
+		StatusMessage: s.statusMsg,
+	}
+	if s.parent.IsValid() {
+		data.ParentSpanID = s.parent.String()
+	}
+	s.mu.Unlock()
+
+	if s.sc.Sampled() {
+		s.tracer.enqueue(data)
+	}
+}
+
+// EndWithError удобно вызывать из defer с именованным возвращаемым err.
+func (s *Span) EndWithError(err error) {
+	s.RecordError(err)
+	s.End()
+}
+
+type contextKeySpan struct{}
+
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(contextKeySpan{}).(*Span)
+	return span
+}
+
+// Start открывает дочерний спан текущего, без активного спана в контексте возвращает nil-спан.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	parent := SpanFromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	return parent.tracer.start(ctx, name, SpanKindInternal, parent.sc)
+}
@@ -0,0 +1,135 @@
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const (
+	HeaderTraceparent   = "traceparent"
+	HeaderTracestate    = "tracestate"
+	HeaderTraceresponse = "traceresponse"
+
+	FlagSampled byte = 0x01
+)
+
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+type TraceID [16]byte
+
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+func (id TraceID) IsValid() bool { return id != TraceID{} }
+
+type SpanID [8]byte
+
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+func (id SpanID) IsValid() bool { return id != SpanID{} }
+
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Flags      byte
+	TraceState string
+	Remote     bool
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+func (sc SpanContext) Sampled() bool {
+	return sc.Flags&FlagSampled != 0
+}
+
+// Traceparent форматирует заголовок версии 00.
+func (sc SpanContext) Traceparent() string {
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + hex.EncodeToString([]byte{sc.Flags})
+}
+
+// ParseTraceparent разбирает заголовок по W3C Trace Context, поля будущих версий после flags игнорируются.
+func ParseTraceparent(header string) (SpanContext, error) {
+	header = strings.TrimSpace(header)
+	if len(header) < 55 {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	version, ok := decodeHex(header[0:2])
+	if !ok || version[0] == 0xff || header[2] != '-' || header[35] != '-' || header[52] != '-' {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	if version[0] == 0 && len(header) != 55 {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	if len(header) > 55 && header[55] != '-' {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	traceID, ok1 := decodeHex(header[3:35])
+	spanID, ok2 := decodeHex(header[36:52])
+	flags, ok3 := decodeHex(header[53:55])
+	if !ok1 || !ok2 || !ok3 {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	var sc SpanContext
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	sc.Flags = flags[0]
+	sc.Remote = true
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	return sc, nil
+}
+
+// decodeHex принимает только строчные hex-символы, как требует спецификация.
+func decodeHex(s string) ([]byte, bool) {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return nil, false
+		}
+	}
+	b, err := hex.DecodeString(s)
+	return b, err == nil
+}
+
+func Extract(h http.Header) (SpanContext, bool) {
+	sc, err := ParseTraceparent(h.Get(HeaderTraceparent))
+	if err != nil {
+		return SpanContext{}, false
+	}
+	sc.TraceState = h.Get(HeaderTracestate)
+	return sc, true
+}
+
+func Inject(sc SpanContext, h http.Header) {
+	if !sc.IsValid() {
+		return
+	}
+	h.Set(HeaderTraceparent, sc.Traceparent())
+	if sc.TraceState != "" {
+		h.Set(HeaderTracestate, sc.TraceState)
+	}
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
@@ -0,0 +1,184 @@
+package tracing
+
+import (
+	"context"
+	"ecom_test/pkg/contextx"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+	Shutdown(ctx context.Context) error
+}
+
+type Options struct {
+	QueueSize     int
+	BatchSize     int
+	FlushInterval time.Duration
+}
+
+// Tracer копит завершённые спаны в очереди и отдаёт их экспортеру пачками в фоне,
+// при переполнении очереди спаны отбрасываются, запросы никогда не ждут экспорта.
+type Tracer struct {
+	exporter Exporter
+	opts     Options
+
+	queue   chan SpanData
+	flush   chan chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+	dropped atomic.Uint64
+}
+
+func NewTracer(exporter Exporter, opts Options) *Tracer {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 2048
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 256
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 5 * time.Second
+	}
+
+	t := &Tracer{
+		exporter: exporter,
+		opts:     opts,
+		queue:    make(chan SpanData, opts.QueueSize),
+		flush:    make(chan chan struct{}),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go t.loop()
+	return t
+}
+
+// StartServer открывает корневой спан запроса, продолжая удалённый trace если он передан.
+func (t *Tracer) StartServer(ctx context.Context, name string, remote SpanContext) (context.Context, *Span) {
+	return t.start(ctx, name, SpanKindServer, remote)
+}
+
+func (t *Tracer) start(ctx context.Context, name string, kind SpanKind, parent SpanContext) (context.Context, *Span) {
+	span := &Span{
+		tracer: t,
+		name:   name,
+		kind:   kind,
+		start:  time.Now(),
+	}
+	if parent.IsValid() {
+		span.sc = SpanContext{
+			TraceID:    parent.TraceID,
+			SpanID:     newSpanID(),
+			Flags:      parent.Flags,
+			TraceState: parent.TraceState,
+		}
+		span.parent = parent.SpanID
+	} else {
+		span.sc = SpanContext{TraceID: newTraceID(), SpanID: newSpanID(), Flags: FlagSampled}
+	}
+
+	ctx = context.WithValue(ctx, contextKeySpan{}, span)
+	ctx = contextx.WithLogger(ctx, withSpanLogger(logger(ctx), span.sc))
+	return ctx, span
+}
+
+func (t *Tracer) Dropped() uint64 {
+	return t.dropped.Load()
+}
+
+func (t *Tracer) enqueue(data SpanData) {
+	select {
+	case t.queue <- data:
+	default:
+		t.dropped.Add(1)
+	}
+}
+
+func (t *Tracer) loop() {
+	defer close(t.stopped)
+	ticker := time.NewTicker(t.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, t.opts.BatchSize)
+	export := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), t.opts.FlushInterval)
+		defer cancel()
+		if err := t.exporter.Export(ctx, batch); err != nil {
+			logger(ctx).Error("tracing: export failed",
+				slog.Int("spans", len(batch)), slog.String("error", err.Error()))
+		}
+		batch = make([]SpanData, 0, t.opts.BatchSize)
+	}
+	drain := func() {
+		for {
+			select {
+			case data := <-t.queue:
+				batch = append(batch, data)
+				if len(batch) >= t.opts.BatchSize {
+					export()
+				}
+			default:
+				export()
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case data := <-t.queue:
+			batch = append(batch, data)
+			if len(batch) >= t.opts.BatchSize {
+				export()
+			}
+		case <-ticker.C:
+			export()
+		case ack := <-t.flush:
+			drain()
+			close(ack)
+		case <-t.done:
+			drain()
+			return
+		}
+	}
+}
+
+// ForceFlush экспортирует всё, что накопилось в очереди на момент вызова.
+func (t *Tracer) ForceFlush(ctx context.Context) error {
+	ack := make(chan struct{})
+	select {
+	case t.flush <- ack:
+	case <-t.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-ack:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown дожидается, пока фоновый цикл выгрузит остаток очереди, и только потом закрывает экспортер.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	err := t.ForceFlush(ctx)
+	t.once.Do(func() { close(t.done) })
+	select {
+	case <-t.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if shutdownErr := t.exporter.Shutdown(ctx); shutdownErr != nil {
+		return shutdownErr
+	}
+	return err
+}
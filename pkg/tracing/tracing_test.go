@@ -0,0 +1,190 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"ecom_test/pkg/contextx"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		wantErr bool
+	}{
+		{name: "Valid sampled", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{name: "Valid not sampled", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+		{name: "Future version with extra fields", header: "cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-what"},
+		{name: "Version 00 with extra fields", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-what", wantErr: true},
+		{name: "Forbidden version ff", header: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", wantErr: true},
+		{name: "Uppercase hex", header: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", wantErr: true},
+		{name: "Zero trace id", header: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", wantErr: true},
+		{name: "Zero span id", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", wantErr: true},
+		{name: "Too short", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", wantErr: true},
+		{name: "Empty", header: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := ParseTraceparent(tt.header)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidTraceparent) {
+					t.Errorf("ParseTraceparent() error = %v, want ErrInvalidTraceparent", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTraceparent() unexpected error = %v", err)
+			}
+			// исходящий заголовок всегда версии 00
+			if want := "00" + tt.header[2:55]; sc.Traceparent() != want {
+				t.Errorf("Traceparent() = %s, want %s", sc.Traceparent(), want)
+			}
+		})
+	}
+}
+
+type memoryExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+func (e *memoryExporter) Export(ctx context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *memoryExporter) Shutdown(ctx context.Context) error { return nil }
+
+func TestTracer_ChildSpansAndLogCorrelation(t *testing.T) {
+	exporter := &memoryExporter{}
+	tracer := NewTracer(exporter, Options{FlushInterval: time.Hour})
+
+	var logs bytes.Buffer
+	ctx := contextx.WithLogger(context.Background(), slog.New(slog.NewTextHandler(&logs, nil)).With("request_id", "r1"))
+
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx, root := tracer.StartServer(ctx, "GET /todos", remote)
+	childCtx, child := Start(ctx, "TaskService.GetAll")
+	contextx.LoggerFromContextOrDefault(childCtx).Info("inside child")
+	child.EndWithError(errors.New("boom"))
+	root.End()
+
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	if len(exporter.spans) != 2 {
+		t.Fatalf("Expected 2 exported spans, got %d", len(exporter.spans))
+	}
+	gotChild, gotRoot := exporter.spans[0], exporter.spans[1]
+	if gotRoot.TraceID != remote.TraceID.String() || gotRoot.ParentSpanID != remote.SpanID.String() {
+		t.Errorf("Root span did not continue remote trace: %+v", gotRoot)
+	}
+	if gotChild.ParentSpanID != gotRoot.SpanID || gotChild.StatusCode != StatusError {
+		t.Errorf("Unexpected child span: %+v", gotChild)
+	}
+
+	line := logs.String()
+	for _, want := range []string{"request_id=r1", "trace_id=" + gotRoot.TraceID, "span_id=" + gotChild.SpanID} {
+		if !strings.Contains(line, want) {
+			t.Errorf("Log line %q does not contain %q", line, want)
+		}
+	}
+	if strings.Count(line, "span_id=") != 1 {
+		t.Errorf("Expected exactly one span_id in log line, got %q", line)
+	}
+}
+
+// lateExporter медленно экспортирует и на первой пачке завершает ещё один спан,
+// как будто запрос закончился между ForceFlush и остановкой цикла.
+type lateExporter struct {
+	memoryExporter
+	late func()
+	once sync.Once
+}
+
+func (e *lateExporter) Export(ctx context.Context, spans []SpanData) error {
+	time.Sleep(20 * time.Millisecond)
+	e.once.Do(e.late)
+	return e.memoryExporter.Export(ctx, spans)
+}
+
+func TestTracer_ShutdownWaitsForFinalDrain(t *testing.T) {
+	exporter := &lateExporter{}
+	tracer := NewTracer(exporter, Options{FlushInterval: time.Hour})
+	exporter.late = func() {
+		_, span := tracer.StartServer(context.Background(), "late", SpanContext{})
+		span.End()
+	}
+
+	_, span := tracer.StartServer(context.Background(), "GET /todos", SpanContext{})
+	span.End()
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	exporter.mu.Lock()
+	defer exporter.mu.Unlock()
+	if len(exporter.spans) != 2 {
+		t.Fatalf("Expected 2 spans exported before Shutdown returned, got %d", len(exporter.spans))
+	}
+}
+
+func TestStart_WithoutTracerIsNoop(t *testing.T) {
+	ctx, span := Start(context.Background(), "noop")
+	span.SetAttribute("k", "v")
+	span.EndWithError(errors.New("ignored"))
+	if SpanFromContext(ctx) != nil {
+		t.Errorf("Expected no span in context")
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var received map[string]any
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	tracer := NewTracer(NewOTLPExporter(collector.URL+"/v1/traces", "todo-api", nil, collector.Client()), Options{})
+	_, span := tracer.StartServer(context.Background(), "GET /todos", SpanContext{})
+	span.SetAttribute("http.response.status_code", 200)
+	span.End()
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	resourceSpans, _ := received["resourceSpans"].([]any)
+	if len(resourceSpans) != 1 {
+		t.Fatalf("Expected 1 resourceSpans entry, got %v", received)
+	}
+	rs := resourceSpans[0].(map[string]any)
+	spans := rs["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)
+	got := spans[0].(map[string]any)
+	if got["name"] != "GET /todos" || got["traceId"] != span.SpanContext().TraceID.String() {
+		t.Errorf("Unexpected span payload: %v", got)
+	}
+	attr := got["attributes"].([]any)[0].(map[string]any)
+	if attr["value"].(map[string]any)["intValue"] != "200" {
+		t.Errorf("Expected int attribute encoded as string, got %v", attr)
+	}
+}